@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/domains"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
 	"backend/internal/repositories"
@@ -17,15 +19,35 @@ func RoutesCustomerManual(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	CustomerManualRepository := repositories.NewCustomerManualRepository(db)
-	CustomerManualService := services.NewCustomerManualService(CustomerManualRepository)
-	CustomerManualHandler := handlers.NewCustomerManualHandler(CustomerManualService)
+	DocumentTextService := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	CustomerManualService := services.NewCustomerManualService(CustomerManualRepository, DocumentTextService)
+	DocumentRevisionService := services.NewDocumentRevisionService(repositories.NewDocumentRevisionRepositoryDB(db))
+	CustomerManualHandler := handlers.NewCustomerManualHandler(CustomerManualService, DocumentRevisionService, DocumentTextService)
+	DocumentAccessService := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	DocumentRevisionHandler := handlers.NewDocumentRevisionHandler(DocumentRevisionService, DocumentTextService, newFileService(db), DocumentAccessService, domains.DocModuleCustomerManual)
+	DocumentTextHandler := handlers.NewDocumentTextHandler(DocumentTextService, domains.DocModuleCustomerManual)
+	DocumentImportService := services.NewDocumentImportService(repositories.NewDocumentImportRepositoryDB(db))
+	DocumentImportHandler := handlers.NewDocumentImportHandler(DocumentImportService, DocumentTextService, domains.DocModuleCustomerManual)
+	DocumentAccessHandler := handlers.NewDocumentAccessHandler(DocumentAccessService, domains.DocModuleCustomerManual)
 
-	app.Post("/create", CustomerManualHandler.CreateCustomerManualHandler)
-	app.Post("/create-nested", CustomerManualHandler.CreateCustomerManualNestedHandler)
+	app.Post("/create", middlewares.NewAuthMiddleware, CustomerManualHandler.CreateCustomerManualHandler)
+	app.Post("/create-nested", middlewares.NewAuthMiddleware, CustomerManualHandler.CreateCustomerManualNestedHandler)
 	app.Get("/list", CustomerManualHandler.GetAllCustomerManualHandler)
 	app.Get("/tree", CustomerManualHandler.GetCustomerManualTreeHandler)
-	app.Put("/update/:customer_manual_id", CustomerManualHandler.UpdateCustomerManualHandler)
+	app.Get("/search", CustomerManualHandler.SearchCustomerManualHandler)
+	app.Put("/update/:customer_manual_id", middlewares.NewAuthMiddleware, CustomerManualHandler.UpdateCustomerManualHandler)
 	app.Delete("/delete/:customer_manual_id", CustomerManualHandler.DeleteCustomerManualHandler)
 
+	app.Get("/revisions/:document_id", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.GetRevisionsHandler)
+	app.Get("/revisions/:document_id/:revision_no/download", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.DownloadRevisionHandler)
+	app.Put("/revisions/:document_id/:revision_no/current", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentRevisionHandler.SetCurrentRevisionHandler)
+
+	app.Post("/text-index/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentTextHandler.RebuildTextIndexHandler)
+	app.Post("/bulk-import", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentImportHandler.ImportDocumentsHandler)
+
+	app.Get("/analytics/most-viewed", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetMostViewedHandler)
+	app.Get("/analytics/never-opened", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentAccessHandler.GetUnopenedDocumentsHandler)
+	app.Get("/analytics/:document_id", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetDocumentAccessStatHandler)
+
 	return app
 }
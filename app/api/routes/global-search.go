@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"backend/app/middlewares"
+	"backend/internal/core/services"
+	"backend/internal/handlers"
+	"backend/internal/repositories"
+)
+
+func RoutesGlobalSearch(db *gorm.DB) *fiber.App {
+	if db == nil {
+		panic("Database connection is nil")
+	}
+
+	app := fiber.New()
+
+	DocumentTextService := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	GlobalSearchService := services.NewGlobalSearchService(
+		services.NewProcedureManualService(repositories.NewProcedureManualRepository(db), DocumentTextService),
+		services.NewQmsDocumentsService(repositories.NewQmsDocumentsRepository(db), DocumentTextService),
+		services.NewSafetyDocumentService(repositories.NewSafetyDocumentRepository(db), DocumentTextService),
+		services.NewCustomerManualService(repositories.NewCustomerManualRepository(db), DocumentTextService),
+		services.NewOrganizationDocService(repositories.NewOrganizationDocRepository(db), DocumentTextService),
+		services.NewAppSystemService(repositories.NewAppSystemRepository(db)),
+		services.NewWelfareBenefitService(repositories.NewWelfareBenefitRepository(db)),
+		services.NewCompanyNewsService(repositories.NewCompanyNewsRepositoryDB(db), repositories.NewUserRepositoryDB(db)),
+	)
+	GlobalSearchHandler := handlers.NewGlobalSearchHandler(GlobalSearchService)
+
+	app.Get("/", middlewares.NewOptionalAuthMiddleware, GlobalSearchHandler.GlobalSearchHandler)
+
+	return app
+}
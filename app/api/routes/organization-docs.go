@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/domains"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
 	"backend/internal/repositories"
@@ -17,14 +19,34 @@ func RoutesOrganizationDoc(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	OrganizationDocRepository := repositories.NewOrganizationDocRepository(db)
-	OrganizationDocService := services.NewOrganizationDocService(OrganizationDocRepository)
-	OrganizationDocHandler := handlers.NewOrganizationDocHandler(OrganizationDocService)
+	DocumentTextService := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	OrganizationDocService := services.NewOrganizationDocService(OrganizationDocRepository, DocumentTextService)
+	DocumentRevisionService := services.NewDocumentRevisionService(repositories.NewDocumentRevisionRepositoryDB(db))
+	OrganizationDocHandler := handlers.NewOrganizationDocHandler(OrganizationDocService, DocumentRevisionService, DocumentTextService)
+	DocumentAccessService := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	DocumentRevisionHandler := handlers.NewDocumentRevisionHandler(DocumentRevisionService, DocumentTextService, newFileService(db), DocumentAccessService, domains.DocModuleOrganizationDocs)
+	DocumentTextHandler := handlers.NewDocumentTextHandler(DocumentTextService, domains.DocModuleOrganizationDocs)
+	DocumentImportService := services.NewDocumentImportService(repositories.NewDocumentImportRepositoryDB(db))
+	DocumentImportHandler := handlers.NewDocumentImportHandler(DocumentImportService, DocumentTextService, domains.DocModuleOrganizationDocs)
+	DocumentAccessHandler := handlers.NewDocumentAccessHandler(DocumentAccessService, domains.DocModuleOrganizationDocs)
 
-	app.Post("/create", OrganizationDocHandler.CreateOrganizationDocHandler)
+	app.Post("/create", middlewares.NewAuthMiddleware, OrganizationDocHandler.CreateOrganizationDocHandler)
 	app.Get("/list", OrganizationDocHandler.GetAllOrganizationDocHandler)
 	app.Get("/department/:department", OrganizationDocHandler.GetOrganizationDocByDepartmentHandler)
-	app.Put("/update/:organization_doc_id", OrganizationDocHandler.UpdateOrganizationDocHandler)
+	app.Get("/search", OrganizationDocHandler.SearchOrganizationDocHandler)
+	app.Put("/update/:organization_doc_id", middlewares.NewAuthMiddleware, OrganizationDocHandler.UpdateOrganizationDocHandler)
 	app.Delete("/delete/:organization_doc_id", OrganizationDocHandler.DeleteOrganizationDocHandler)
 
+	app.Get("/revisions/:document_id", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.GetRevisionsHandler)
+	app.Get("/revisions/:document_id/:revision_no/download", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.DownloadRevisionHandler)
+	app.Put("/revisions/:document_id/:revision_no/current", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentRevisionHandler.SetCurrentRevisionHandler)
+
+	app.Post("/text-index/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentTextHandler.RebuildTextIndexHandler)
+	app.Post("/bulk-import", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentImportHandler.ImportDocumentsHandler)
+
+	app.Get("/analytics/most-viewed", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetMostViewedHandler)
+	app.Get("/analytics/never-opened", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentAccessHandler.GetUnopenedDocumentsHandler)
+	app.Get("/analytics/:document_id", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetDocumentAccessStatHandler)
+
 	return app
 }
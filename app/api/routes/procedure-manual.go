@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/domains"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
 	"backend/internal/repositories"
@@ -17,13 +19,33 @@ func RoutesProcedureManual(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	ProcedureManualRepository := repositories.NewProcedureManualRepository(db)
-	ProcedureManualService := services.NewProcedureManualService(ProcedureManualRepository)
-	ProcedureManualHandler := handlers.NewProcedureManualHandler(ProcedureManualService)
+	DocumentTextService := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	ProcedureManualService := services.NewProcedureManualService(ProcedureManualRepository, DocumentTextService)
+	DocumentRevisionService := services.NewDocumentRevisionService(repositories.NewDocumentRevisionRepositoryDB(db))
+	ProcedureManualHandler := handlers.NewProcedureManualHandler(ProcedureManualService, DocumentRevisionService, DocumentTextService)
+	DocumentAccessService := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	DocumentRevisionHandler := handlers.NewDocumentRevisionHandler(DocumentRevisionService, DocumentTextService, newFileService(db), DocumentAccessService, domains.DocModuleProcedureManual)
+	DocumentTextHandler := handlers.NewDocumentTextHandler(DocumentTextService, domains.DocModuleProcedureManual)
+	DocumentImportService := services.NewDocumentImportService(repositories.NewDocumentImportRepositoryDB(db))
+	DocumentImportHandler := handlers.NewDocumentImportHandler(DocumentImportService, DocumentTextService, domains.DocModuleProcedureManual)
+	DocumentAccessHandler := handlers.NewDocumentAccessHandler(DocumentAccessService, domains.DocModuleProcedureManual)
 
-	app.Post("/create", ProcedureManualHandler.CreateProcedureManualHandler)
+	app.Post("/create", middlewares.NewAuthMiddleware, ProcedureManualHandler.CreateProcedureManualHandler)
 	app.Get("/list", ProcedureManualHandler.GetAllProcedureManualHandler)
-	app.Put("/update/:procedure_manual_id", ProcedureManualHandler.UpdateProcedureManualHandler)
+	app.Get("/search", ProcedureManualHandler.SearchProcedureManualHandler)
+	app.Put("/update/:procedure_manual_id", middlewares.NewAuthMiddleware, ProcedureManualHandler.UpdateProcedureManualHandler)
 	app.Delete("/delete/:procedure_manual_id", ProcedureManualHandler.DeleteProcedureManualHandler)
 
+	app.Get("/revisions/:document_id", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.GetRevisionsHandler)
+	app.Get("/revisions/:document_id/:revision_no/download", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.DownloadRevisionHandler)
+	app.Put("/revisions/:document_id/:revision_no/current", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentRevisionHandler.SetCurrentRevisionHandler)
+
+	app.Post("/text-index/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentTextHandler.RebuildTextIndexHandler)
+	app.Post("/bulk-import", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentImportHandler.ImportDocumentsHandler)
+
+	app.Get("/analytics/most-viewed", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetMostViewedHandler)
+	app.Get("/analytics/never-opened", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentAccessHandler.GetUnopenedDocumentsHandler)
+	app.Get("/analytics/:document_id", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetDocumentAccessStatHandler)
+
 	return app
 }
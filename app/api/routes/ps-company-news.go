@@ -4,8 +4,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
+	"backend/internal/pkgs/mail"
 	"backend/internal/repositories"
 )
 
@@ -17,15 +19,47 @@ func RoutesCompanyNews(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	CompanyNewsRepository := repositories.NewCompanyNewsRepositoryDB(db)
-	CompanyNewsService := services.NewCompanyNewsService(CompanyNewsRepository)
+	UserRepository := repositories.NewUserRepositoryDB(db)
+	CompanyNewsService := services.NewCompanyNewsService(CompanyNewsRepository, UserRepository)
 	CompanyNewsHandler := handlers.NewCompanyNewsHandler(CompanyNewsService)
 
-	app.Post("/create-company-news", CompanyNewsHandler.CreateCompanyNewsFormHandler)
+	DigestRepository := repositories.NewCompanyNewsDigestRepositoryDB(db)
+	MailService := services.NewMailService(repositories.NewEmailOutboxRepository(db), mail.NewSender(mail.ConfigFromEnv()))
+	DigestService := services.NewCompanyNewsDigestService(CompanyNewsRepository, DigestRepository, UserRepository, MailService)
+	DigestHandler := handlers.NewCompanyNewsDigestHandler(DigestService)
+
+	app.Post("/create-company-news", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.CreateCompanyNewsFormHandler)
 	app.Post("/upload-image", CompanyNewsHandler.UploadImageHandler)
-	app.Get("/get-company-news", CompanyNewsHandler.GetCompanyNewsHandler)
-	app.Get("/get-company-news-by-title", CompanyNewsHandler.GetCompanyNewsByTitleHandler)
-	app.Get("/get-company-news/:id", CompanyNewsHandler.GetCompanyNewsByIDHandler)
-	app.Put("/update-company-news/:company_news_id", CompanyNewsHandler.UpdateCompanyNewsFormHandler)
-	app.Delete("/delete-company-news/:company_news_id", CompanyNewsHandler.DeleteCompanyNewsHandler)
+	app.Get("/get-company-news", middlewares.NewOptionalAuthMiddleware, CompanyNewsHandler.GetCompanyNewsHandler)
+	app.Get("/get-company-news-by-title", middlewares.NewOptionalAuthMiddleware, CompanyNewsHandler.GetCompanyNewsByTitleHandler)
+	app.Get("/search-company-news", middlewares.NewOptionalAuthMiddleware, CompanyNewsHandler.SearchCompanyNewsHandler)
+	app.Get("/feed/rss", CompanyNewsHandler.GetCompanyNewsRSSHandler)
+	app.Get("/feed/atom", CompanyNewsHandler.GetCompanyNewsAtomHandler)
+	app.Get("/feed/json", CompanyNewsHandler.GetCompanyNewsJSONFeedHandler)
+	app.Get("/get-company-news/:id", middlewares.NewOptionalAuthMiddleware, CompanyNewsHandler.GetCompanyNewsByIDHandler)
+	app.Put("/update-company-news/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.UpdateCompanyNewsFormHandler)
+	app.Delete("/delete-company-news/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.DeleteCompanyNewsHandler)
+
+	app.Get("/get-attachments/:company_news_id", middlewares.NewOptionalAuthMiddleware, CompanyNewsHandler.GetCompanyNewsAttachmentsHandler)
+	app.Post("/add-attachments/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.AddCompanyNewsAttachmentsHandler)
+	app.Put("/reorder-attachments/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.ReorderCompanyNewsAttachmentsHandler)
+	app.Patch("/update-attachment/:attachment_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.UpdateCompanyNewsAttachmentHandler)
+	app.Delete("/delete-attachment/:attachment_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.DeleteCompanyNewsAttachmentHandler)
+
+	app.Get("/get-revisions/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.GetCompanyNewsRevisionsHandler)
+	app.Get("/get-revision/:company_news_id/:revision_no", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.GetCompanyNewsRevisionHandler)
+	app.Get("/diff-revisions/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.DiffCompanyNewsRevisionsHandler)
+	app.Post("/restore-revision/:company_news_id/:revision_no", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.RestoreCompanyNewsRevisionHandler)
+	app.Post("/undelete-company-news/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.UndeleteCompanyNewsHandler)
+
+	app.Post("/audience-preview", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.PreviewCompanyNewsAudienceHandler)
+	app.Get("/audience-preview/:company_news_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), CompanyNewsHandler.PreviewCompanyNewsAudienceByIDHandler)
+
+	app.Get("/digest/subscription", middlewares.NewAuthMiddleware, DigestHandler.GetDigestSubscriptionHandler)
+	app.Put("/digest/subscription", middlewares.NewAuthMiddleware, DigestHandler.UpdateDigestSubscriptionHandler)
+	app.Get("/digest/unsubscribe", DigestHandler.UnsubscribeDigestPageHandler)
+	app.Post("/digest/unsubscribe", DigestHandler.UnsubscribeDigestHandler)
+	app.Get("/digest/preview", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), DigestHandler.PreviewDigestHandler)
+	app.Post("/digest/send-now", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "HR"), DigestHandler.SendDigestNowHandler)
 	return app
 }
@@ -2,14 +2,48 @@ package api
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/services"
 	"backend/internal/handlers"
+	"backend/internal/pkgs/resumable"
+	"backend/internal/repositories"
 )
 
-func RoutesFile() *fiber.App {
+func RoutesFile(db *gorm.DB) *fiber.App {
+	if db == nil {
+		panic("Database connection is nil")
+	}
+
 	app := fiber.New()
 
-	app.Get("/get-file", handlers.ServeUploadFile)
+	FileService := newFileService(db)
+	DocumentAccessService := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	UploadGCService := services.NewUploadGCService(repositories.NewUploadGCRepositoryDB(db), repositories.NewFileBlobRepositoryDB(db))
+	FileHandler := handlers.NewFileHandler(FileService, DocumentAccessService, UploadGCService)
+	UploadSessionHandler := handlers.NewUploadSessionHandler(resumable.Default())
+
+	app.Get("/get-file", middlewares.NewOptionalAuthMiddleware, FileHandler.ServeUploadFile)
+	app.Get("/document/:module/:document_id", middlewares.NewOptionalAuthMiddleware, FileHandler.ServeDocumentFileHandler)
+	app.Get("/news-attachment/:attachment_id", middlewares.NewOptionalAuthMiddleware, FileHandler.ServeNewsAttachmentHandler)
+	app.Get("/export/:module", middlewares.NewOptionalAuthMiddleware, FileHandler.ExportDocumentsHandler)
+	app.Post("/sign", middlewares.NewOptionalAuthMiddleware, FileHandler.SignFileURLHandler)
+	app.Post("/thumbnails/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), FileHandler.RebuildThumbnailsHandler)
+	app.Post("/integrity-check", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), FileHandler.CheckFileIntegrityHandler)
+	app.Post("/gc", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), FileHandler.CollectOrphanUploadsHandler)
+	app.Post("/upload-sessions", middlewares.NewAuthMiddleware, UploadSessionHandler.CreateUploadSessionHandler)
+	app.Get("/upload-sessions/:token", middlewares.NewAuthMiddleware, UploadSessionHandler.GetUploadSessionHandler)
+	app.Patch("/upload-sessions/:token", middlewares.NewAuthMiddleware, UploadSessionHandler.AppendUploadSessionHandler)
+	app.Post("/upload-sessions/:token/complete", middlewares.NewAuthMiddleware, UploadSessionHandler.CompleteUploadSessionHandler)
+	app.Delete("/upload-sessions/:token", middlewares.NewAuthMiddleware, UploadSessionHandler.DeleteUploadSessionHandler)
+	app.Get("/uploads", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), FileHandler.GetUploadedFilesHandler)
 
 	return app
 }
+
+// newFileService builds the file service, which the document modules also use for their access checks
+func newFileService(db *gorm.DB) *services.FileService {
+	CompanyNewsService := services.NewCompanyNewsService(repositories.NewCompanyNewsRepositoryDB(db), repositories.NewUserRepositoryDB(db))
+	return services.NewFileService(repositories.NewFileRepositoryDB(db), repositories.NewUploadedFileRepositoryDB(db), repositories.NewFileBlobRepositoryDB(db), repositories.NewQmsDocumentsRepository(db), CompanyNewsService)
+}
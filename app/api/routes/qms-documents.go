@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/domains"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
 	"backend/internal/repositories"
@@ -17,13 +19,37 @@ func RoutesQmsDocuments(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	repo := repositories.NewQmsDocumentsRepository(db)
-	srv := services.NewQmsDocumentsService(repo)
-	h := handlers.NewQmsDocumentsHandler(srv)
+	textSrv := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	srv := services.NewQmsDocumentsService(repo, textSrv)
+	revisionSrv := services.NewDocumentRevisionService(repositories.NewDocumentRevisionRepositoryDB(db))
+	h := handlers.NewQmsDocumentsHandler(srv, revisionSrv, textSrv)
+	accessSrv := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	revisionH := handlers.NewDocumentRevisionHandler(revisionSrv, textSrv, newFileService(db), accessSrv, domains.DocModuleQms)
+	textH := handlers.NewDocumentTextHandler(textSrv, domains.DocModuleQms)
+	importH := handlers.NewDocumentImportHandler(services.NewDocumentImportService(repositories.NewDocumentImportRepositoryDB(db)), textSrv, domains.DocModuleQms)
+	accessH := handlers.NewDocumentAccessHandler(accessSrv, domains.DocModuleQms)
 
-	app.Post("/create", h.CreateQmsDocumentHandler)
-	app.Get("/list", h.GetAllQmsDocumentsHandler)
-	app.Put("/update/:qms_documents_id", h.UpdateQmsDocumentHandler)
-	app.Delete("/delete/:qms_documents_id", h.DeleteQmsDocumentHandler)
+	app.Post("/create", middlewares.NewAuthMiddleware, h.CreateQmsDocumentHandler)
+	app.Get("/list", middlewares.NewOptionalAuthMiddleware, h.GetAllQmsDocumentsHandler)
+	app.Get("/search", middlewares.NewOptionalAuthMiddleware, h.SearchQmsDocumentsHandler)
+	app.Put("/update/:qms_documents_id", middlewares.NewAuthMiddleware, h.UpdateQmsDocumentHandler)
+	app.Delete("/delete/:qms_documents_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), h.DeleteQmsDocumentHandler)
+
+	app.Post("/workflow/:qms_documents_id/:action", middlewares.NewAuthMiddleware, h.WorkflowQmsDocumentHandler)
+	app.Get("/approvals/:qms_documents_id", middlewares.NewAuthMiddleware, h.GetQmsDocumentApprovalsHandler)
+	app.Get("/my-tasks", middlewares.NewAuthMiddleware, h.GetMyQmsDocumentTasksHandler)
+	app.Get("/review-due", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), h.GetQmsDocumentsReviewDueHandler)
+
+	app.Get("/revisions/:document_id", middlewares.NewOptionalAuthMiddleware, revisionH.GetRevisionsHandler)
+	app.Get("/revisions/:document_id/:revision_no/download", middlewares.NewOptionalAuthMiddleware, revisionH.DownloadRevisionHandler)
+	app.Put("/revisions/:document_id/:revision_no/current", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), revisionH.SetCurrentRevisionHandler)
+
+	app.Post("/text-index/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), textH.RebuildTextIndexHandler)
+	app.Post("/bulk-import", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), importH.ImportDocumentsHandler)
+
+	app.Get("/analytics/most-viewed", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), accessH.GetMostViewedHandler)
+	app.Get("/analytics/never-opened", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), accessH.GetUnopenedDocumentsHandler)
+	app.Get("/analytics/:document_id", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "QMS"), accessH.GetDocumentAccessStatHandler)
 
 	return app
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"backend/app/middlewares"
+	"backend/internal/core/domains"
 	"backend/internal/core/services"
 	"backend/internal/handlers"
 	"backend/internal/repositories"
@@ -17,16 +19,45 @@ func RoutesSafetyDocument(db *gorm.DB) *fiber.App {
 	app := fiber.New()
 
 	SafetyDocumentRepository := repositories.NewSafetyDocumentRepository(db)
-	SafetyDocumentService := services.NewSafetyDocumentService(SafetyDocumentRepository)
-	SafetyDocumentHandler := handlers.NewSafetyDocumentHandler(SafetyDocumentService)
+	DocumentTextService := services.NewDocumentTextService(repositories.NewDocumentTextRepositoryDB(db))
+	SafetyDocumentService := services.NewSafetyDocumentService(SafetyDocumentRepository, DocumentTextService)
+	DocumentRevisionService := services.NewDocumentRevisionService(repositories.NewDocumentRevisionRepositoryDB(db))
+	SafetyDocumentHandler := handlers.NewSafetyDocumentHandler(SafetyDocumentService, DocumentRevisionService, DocumentTextService)
+	DocumentAccessService := services.NewDocumentAccessService(repositories.NewDocumentAccessRepositoryDB(db))
+	DocumentRevisionHandler := handlers.NewDocumentRevisionHandler(DocumentRevisionService, DocumentTextService, newFileService(db), DocumentAccessService, domains.DocModuleSafety)
+	DocumentTextHandler := handlers.NewDocumentTextHandler(DocumentTextService, domains.DocModuleSafety)
+	DocumentImportService := services.NewDocumentImportService(repositories.NewDocumentImportRepositoryDB(db))
+	DocumentImportHandler := handlers.NewDocumentImportHandler(DocumentImportService, DocumentTextService, domains.DocModuleSafety)
+	DocumentAccessHandler := handlers.NewDocumentAccessHandler(DocumentAccessService, domains.DocModuleSafety)
+	SafetyAcknowledgementService := services.NewSafetyAcknowledgementService(repositories.NewSafetyAcknowledgementRepositoryDB(db), SafetyDocumentRepository, DocumentRevisionService)
+	SafetyAcknowledgementHandler := handlers.NewSafetyAcknowledgementHandler(SafetyAcknowledgementService)
 
-	app.Post("/create", SafetyDocumentHandler.CreateSafetyDocumentHandler)
+	app.Post("/create", middlewares.NewAuthMiddleware, SafetyDocumentHandler.CreateSafetyDocumentHandler)
 	app.Get("/list", SafetyDocumentHandler.GetAllSafetyDocumentHandler)
 	app.Get("/category/:category", SafetyDocumentHandler.GetSafetyDocumentByCategoryHandler)
 	app.Get("/department/:department", SafetyDocumentHandler.GetSafetyDocumentByDepartmentHandler)
-	app.Put("/update/:safety_document_id", SafetyDocumentHandler.UpdateSafetyDocumentHandler)
+	app.Put("/update/:safety_document_id", middlewares.NewAuthMiddleware, SafetyDocumentHandler.UpdateSafetyDocumentHandler)
 	app.Delete("/delete/:safety_document_id", SafetyDocumentHandler.DeleteSafetyDocumentHandler)
 	app.Get("/search", SafetyDocumentHandler.SearchSafetyDocumentHandler)
+	app.Get("/review-status", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), SafetyDocumentHandler.GetSafetyReviewStatusHandler)
+
+	app.Get("/revisions/:document_id", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.GetRevisionsHandler)
+	app.Get("/revisions/:document_id/:revision_no/download", middlewares.NewOptionalAuthMiddleware, DocumentRevisionHandler.DownloadRevisionHandler)
+	app.Put("/revisions/:document_id/:revision_no/current", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), DocumentRevisionHandler.SetCurrentRevisionHandler)
+
+	app.Post("/text-index/rebuild", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU"), DocumentTextHandler.RebuildTextIndexHandler)
+	app.Post("/bulk-import", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), DocumentImportHandler.ImportDocumentsHandler)
+
+	app.Get("/analytics/most-viewed", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetMostViewedHandler)
+	app.Get("/analytics/never-opened", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), DocumentAccessHandler.GetUnopenedDocumentsHandler)
+	app.Get("/analytics/:document_id", middlewares.NewAuthMiddleware, DocumentAccessHandler.GetDocumentAccessStatHandler)
+
+	app.Get("/acknowledgement/pending", middlewares.NewAuthMiddleware, SafetyAcknowledgementHandler.GetPendingAcknowledgementsHandler)
+	app.Post("/acknowledgement/:safety_document_id", middlewares.NewAuthMiddleware, SafetyAcknowledgementHandler.AcknowledgeHandler)
+	app.Get("/acknowledgement/:safety_document_id/requirement", SafetyAcknowledgementHandler.GetAckRequirementHandler)
+	app.Put("/acknowledgement/:safety_document_id/requirement", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), SafetyAcknowledgementHandler.SetAckRequirementHandler)
+	app.Delete("/acknowledgement/:safety_document_id/requirement", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), SafetyAcknowledgementHandler.CloseAckRequirementHandler)
+	app.Get("/acknowledgement/:safety_document_id/report", middlewares.NewAuthMiddleware, middlewares.RequireRoles("SU", "SAFETY"), SafetyAcknowledgementHandler.GetAckReportHandler)
 
 	return app
 }
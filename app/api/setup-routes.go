@@ -16,7 +16,7 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
 	api := app.Group("/api", logger.New())
 	api.Mount("/user", routes.RoutesUser(db))
 	api.Mount("/company-news", routes.RoutesCompanyNews(db))
-	api.Mount("/file", routes.RoutesFile())
+	api.Mount("/file", routes.RoutesFile(db))
 	api.Mount("/organization", routes.RoutesOrganization(db))
 	api.Mount("/app-system", routes.RoutesAppSystem(db))
 	api.Mount("/procedure-manual", routes.RoutesProcedureManual(db))
@@ -28,4 +28,5 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
 	api.Mount("/dashboard", routes.RoutesDashboard(db))
 	api.Mount("/questionnaire", routes.RoutesQuestionnaire(db))
 	api.Mount("/calendar-event", routes.RoutesCompanyCalendarEvent(db))
+	api.Mount("/search", routes.RoutesGlobalSearch(db))
 }
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/pkgs/utils"
+)
+
+// NewAuthMiddleware rejects requests without a valid employee token
+func NewAuthMiddleware(c *fiber.Ctx) error {
+	claims, err := utils.ParseBearerClaims(c)
+	if err != nil || claims == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized access",
+		})
+	}
+
+	utils.SetAuthLocals(c, claims)
+	return c.Next()
+}
+
+// NewOptionalAuthMiddleware identifies the employee when a token is sent but lets anonymous requests through
+func NewOptionalAuthMiddleware(c *fiber.Ctx) error {
+	claims, err := utils.ParseBearerClaims(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid token",
+		})
+	}
+
+	if claims != nil {
+		utils.SetAuthLocals(c, claims)
+	}
+	return c.Next()
+}
+
+// RequireRoles allows only employees holding one of the given roles. Use after NewAuthMiddleware.
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !utils.HasAnyRole(utils.AuthRole(c), roles...) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Permission denied",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -49,6 +49,14 @@ var NewCorsMiddleware = cors.New(cors.Config{
 		"Accept",
 		"Authorization",
 		"X-Requested-With",
+		// resumable uploads
+		"Upload-Offset",
+		"Upload-Checksum",
+	}, ","),
+
+	ExposeHeaders: strings.Join([]string{
+		"Upload-Offset",
+		"Upload-Length",
 	}, ","),
 
 	AllowCredentials: true,
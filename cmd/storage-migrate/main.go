@@ -0,0 +1,187 @@
+// Command storage-migrate copies the files under a local uploads directory into the storage selected by
+// STORAGE_DRIVER (usually s3) and rewrites the file paths stored in the database to their storage keys
+// (uploads/<folder>/<name>, forward slashes, no "./").
+//
+//	STORAGE_DRIVER=s3 S3_ENDPOINT=localhost:9000 S3_BUCKET=uploads S3_USE_SSL=false \
+//	S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin go run ./cmd/storage-migrate -src . -dry-run
+//
+// Copying is idempotent: objects already present with the same size are skipped, so the command can be
+// re-run after a partial failure or once more right before switching the API over.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/configs"
+	database "backend/external/db"
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/storage"
+)
+
+// pathColumn is a database column holding a stored file path, plain or as a JSON array of paths
+type pathColumn struct {
+	Table    string
+	IDColumn string
+	Column   string
+}
+
+func pathColumns() []pathColumn {
+	modules := make([]string, 0, len(domains.DocumentModules))
+	for name := range domains.DocumentModules {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+
+	cols := make([]pathColumn, 0, len(modules)+5)
+	for _, name := range modules {
+		mod := domains.DocumentModules[name]
+		cols = append(cols, pathColumn{Table: mod.Table, IDColumn: mod.IDColumn, Column: "file_name"})
+	}
+	return append(cols,
+		pathColumn{Table: domains.DocumentRevision{}.TableName(), IDColumn: "document_revision_id", Column: "file_name"},
+		pathColumn{Table: domains.CompanyNews{}.TableName(), IDColumn: "company_news_id", Column: "company_news_photo"},
+		pathColumn{Table: domains.CompanyNewsAttachment{}.TableName(), IDColumn: "company_news_attachment_id", Column: "file_path"},
+		pathColumn{Table: domains.WelfareBenefit{}.TableName(), IDColumn: "welfare_benefit_id", Column: "file_name"},
+		pathColumn{Table: domains.WelfareBenefit{}.TableName(), IDColumn: "welfare_benefit_id", Column: "image_url"},
+	)
+}
+
+func main() {
+	srcRoot := flag.String("src", ".", "directory holding the local uploads/ folder to copy from")
+	dryRun := flag.Bool("dry-run", false, "report what would be copied and rewritten without changing anything")
+	skipFiles := flag.Bool("skip-files", false, "only rewrite stored paths")
+	skipDB := flag.Bool("skip-db", false, "only copy files")
+	flag.Parse()
+
+	configs.Init()
+	ctx := context.Background()
+	exitCode := 0
+
+	if !*skipFiles {
+		dst, err := storage.New(storage.ConfigFromEnv())
+		if err != nil {
+			log.Fatalf("[StorageMigrate] %v", err)
+		}
+		if dst.Driver() == storage.DriverLocal {
+			log.Fatalf("[StorageMigrate] STORAGE_DRIVER is local; set it to the backend to migrate to")
+		}
+
+		copied, skipped, failed, err := copyUploads(ctx, storage.NewLocal(*srcRoot), dst, *dryRun)
+		if err != nil {
+			log.Fatalf("[StorageMigrate] Walk uploads: %v", err)
+		}
+		log.Printf("[StorageMigrate] Files: %d copied, %d already present, %d failed\n", copied, skipped, failed)
+		if failed > 0 {
+			exitCode = 1
+		}
+	}
+
+	if !*skipDB {
+		db := database.InitDataBase()
+		for _, col := range pathColumns() {
+			changed, err := rewritePaths(db, col, *dryRun)
+			if err != nil {
+				log.Printf("[StorageMigrate] %s.%s: %v\n", col.Table, col.Column, err)
+				continue
+			}
+			log.Printf("[StorageMigrate] %s.%s: %d paths rewritten\n", col.Table, col.Column, changed)
+		}
+	}
+
+	if *dryRun {
+		log.Println("[StorageMigrate] Dry run, nothing was changed")
+	}
+	os.Exit(exitCode)
+}
+
+func copyUploads(ctx context.Context, src, dst storage.Storage, dryRun bool) (copied, skipped, failed int, err error) {
+	err = src.Walk(ctx, storage.Root+"/", func(info storage.Info) error {
+		if existing, err := dst.Stat(ctx, info.Key); err == nil && existing.Size == info.Size {
+			skipped++
+			return nil
+		}
+		if dryRun {
+			fmt.Printf("copy %s (%d bytes)\n", info.Key, info.Size)
+			copied++
+			return nil
+		}
+		if err := storage.Copy(ctx, src, dst, info.Key, mime.TypeByExtension(strings.ToLower(path.Ext(info.Key)))); err != nil {
+			log.Printf("[StorageMigrate] Copy %s: %v\n", info.Key, err)
+			failed++
+			return nil
+		}
+		copied++
+		return nil
+	})
+	return copied, skipped, failed, err
+}
+
+func rewritePaths(db *gorm.DB, col pathColumn, dryRun bool) (int, error) {
+	type row struct {
+		ID   string
+		Path string
+	}
+	var rows []row
+	err := db.Table(col.Table).
+		Select(fmt.Sprintf("CAST(%s AS nvarchar(100)) AS id, %s AS path", col.IDColumn, col.Column)).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", col.Column, col.Column)).
+		Scan(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, r := range rows {
+		canonical, ok := canonicalPath(r.Path)
+		if !ok || canonical == r.Path {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("%s.%s %s: %s -> %s\n", col.Table, col.Column, r.ID, r.Path, canonical)
+			changed++
+			continue
+		}
+		err := db.Table(col.Table).Where(col.IDColumn+" = ?", r.ID).Update(col.Column, canonical).Error
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// canonicalPath rewrites every upload path in a stored value to its storage key, keeping the JSON array form.
+// Values that are not upload paths (external URLs, free text) are left alone.
+func canonicalPath(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") {
+		var paths []string
+		if err := json.Unmarshal([]byte(trimmed), &paths); err != nil {
+			return "", false
+		}
+		for i, p := range paths {
+			if key := storage.CleanKey(p); key != "" {
+				paths[i] = key
+			}
+		}
+		b, err := json.Marshal(paths)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+
+	key := storage.CleanKey(trimmed)
+	return key, key != ""
+}
@@ -44,3 +44,19 @@ func initTimeZone() {
 	}
 	time.Local = ict
 }
+
+// TrustedProxies lists the addresses (IPs or CIDR ranges) whose X-Forwarded-For header is believed,
+// from TRUSTED_PROXIES; it defaults to the private ranges the nginx container is reached from
+func TrustedProxies() []string {
+	v := os.Getenv("TRUSTED_PROXIES")
+	if strings.TrimSpace(v) == "" {
+		return []string{"127.0.0.1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+	}
+	var proxies []string
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
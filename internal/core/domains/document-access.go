@@ -0,0 +1,36 @@
+package domains
+
+import "time"
+
+// Document access actions
+const (
+	DocAccessView     = "view"
+	DocAccessDownload = "download"
+)
+
+// DocumentAccessLog records one view or download of a module document's file
+type DocumentAccessLog struct {
+	DocumentAccessLogID int       `gorm:"column:document_access_log_id;primaryKey;autoIncrement"`
+	Module              string    `gorm:"column:module;type:varchar(30);index:IX_document_access_logs_doc;not null"`
+	DocumentID          int       `gorm:"column:document_id;index:IX_document_access_logs_doc;not null"`
+	Action              string    `gorm:"column:action;type:varchar(10);not null"`
+	EmpCode             string    `gorm:"column:emp_code;type:nvarchar(50);index:IX_document_access_logs_emp_code"`
+	IPAddress           string    `gorm:"column:ip_address;type:varchar(45)"`
+	AccessedAt          time.Time `gorm:"column:accessed_at;index:IX_document_access_logs_doc;not null"`
+}
+
+func (DocumentAccessLog) TableName() string {
+	return "document_access_logs"
+}
+
+// DocumentAccessStat is the access summary of one document
+type DocumentAccessStat struct {
+	DocumentID     int
+	Name           string
+	FileName       string
+	Views          int64
+	Downloads      int64
+	UniqueViewers  int64
+	LastAccessedAt *time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,42 @@
+package domains
+
+import "time"
+
+// DocumentExportFilter selects the documents of one module put into a ZIP export
+type DocumentExportFilter struct {
+	Category   string
+	Department string
+	All        bool // include QMS documents that are not effective and expired safety documents
+}
+
+// DocumentExportRow is a live document with the metadata written to the export manifest;
+// columns a module does not have stay empty
+type DocumentExportRow struct {
+	DocumentID      int
+	Name            string
+	FileName        string
+	Category        string
+	Department      string
+	DocumentNo      string
+	Revision        string
+	Status          string
+	OwnerEmpCode    string
+	ReviewerEmpCode string
+	ApproverEmpCode string
+	ExpiryDate      *time.Time
+	UpdatedAt       time.Time
+}
+
+// DocumentExportEntry is one file of an export: where it is stored and where it goes in the archive
+type DocumentExportEntry struct {
+	DocumentExportRow
+	Path    string // storage key, "" when the document has no file
+	ZipPath string
+}
+
+// DocumentExport is a prepared ZIP export, streamed entry by entry
+type DocumentExport struct {
+	Module   string
+	FileName string
+	Entries  []DocumentExportEntry
+}
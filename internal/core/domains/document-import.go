@@ -0,0 +1,16 @@
+package domains
+
+// DocumentImport is one validated manifest row of a bulk import, ready to insert into its module table
+type DocumentImport struct {
+	Name         string
+	Desc         string
+	Category     string
+	Department   string
+	FileName     string // stored JSON array form, "" for a customer manual folder without a file
+	DocumentNo   string
+	Revision     string
+	OwnerEmpCode string
+	ParentID     *int // existing customer manual
+	ParentIndex  int  // earlier import in the same batch, -1 when none
+	SortOrder    int
+}
@@ -0,0 +1,48 @@
+package domains
+
+import "time"
+
+// Document modules sharing the document_revisions table
+const (
+	DocModuleSafety           = "safety_documents"
+	DocModuleProcedureManual  = "procedure_manual"
+	DocModuleQms              = "qms_documents"
+	DocModuleCustomerManual   = "customer_manual"
+	DocModuleOrganizationDocs = "organization_docs"
+)
+
+// DocumentModule describes where a module keeps its documents and current file
+type DocumentModule struct {
+	Name       string
+	Table      string
+	IDColumn   string
+	NameColumn string
+	UploadDir  string
+}
+
+// DocumentModules lists every module with revision tracking
+var DocumentModules = map[string]DocumentModule{
+	DocModuleSafety:           {Name: DocModuleSafety, Table: "ps_safetys_documents", IDColumn: "safety_document_id", NameColumn: "safety_document_name", UploadDir: "./uploads/safety_documents"},
+	DocModuleProcedureManual:  {Name: DocModuleProcedureManual, Table: "ps_procedure_manuals", IDColumn: "procedure_manual_id", NameColumn: "procedure_manual_name", UploadDir: "./uploads/procedure_manual"},
+	DocModuleQms:              {Name: DocModuleQms, Table: "ps_qms_documents", IDColumn: "qms_documents_id", NameColumn: "qms_documents_name", UploadDir: "./uploads/qms_documents"},
+	DocModuleCustomerManual:   {Name: DocModuleCustomerManual, Table: "customer_manuals", IDColumn: "customer_manual_id", NameColumn: "customer_manual_name", UploadDir: "./uploads/customer_manual"},
+	DocModuleOrganizationDocs: {Name: DocModuleOrganizationDocs, Table: "organization_docs", IDColumn: "organization_doc_id", NameColumn: "name", UploadDir: "./uploads/organization_docs"},
+}
+
+type DocumentRevision struct {
+	DocumentRevisionID int        `gorm:"column:document_revision_id;primaryKey;autoIncrement"`
+	Module             string     `gorm:"column:module;type:varchar(30);uniqueIndex:UX_document_revisions_doc_revision;not null"`
+	DocumentID         int        `gorm:"column:document_id;uniqueIndex:UX_document_revisions_doc_revision;not null"`
+	RevisionNo         int        `gorm:"column:revision_no;uniqueIndex:UX_document_revisions_doc_revision;not null"`
+	RevisionLabel      string     `gorm:"column:revision_label;type:nvarchar(50)"`
+	FileName           string     `gorm:"column:file_name;type:nvarchar(max);not null"` // same JSON array form as the module table
+	UploadedBy         string     `gorm:"column:uploaded_by;type:nvarchar(100)"`
+	ChangeNote         string     `gorm:"column:change_note;type:nvarchar(max)"`
+	EffectiveDate      *time.Time `gorm:"column:effective_date;type:date"`
+	IsCurrent          bool       `gorm:"column:is_current;not null;default:false"`
+	CreatedAt          time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (DocumentRevision) TableName() string {
+	return "document_revisions"
+}
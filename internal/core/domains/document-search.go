@@ -0,0 +1,24 @@
+package domains
+
+// DocumentSearch is a keyword search over one document module, optionally narrowed by category and department
+type DocumentSearch struct {
+	Keyword    string
+	Category   string
+	Department string
+}
+
+// DocumentSearchSpec describes what a module's search matches and can be filtered on
+type DocumentSearchSpec struct {
+	Columns       []string // text columns matched in addition to the indexed file text
+	HasCategory   bool
+	HasDepartment bool
+}
+
+// DocumentSearchSpecs lists the search setup of every document module
+var DocumentSearchSpecs = map[string]DocumentSearchSpec{
+	DocModuleSafety:           {Columns: []string{"safety_document_name", "safety_document_desc", "department", "category"}, HasCategory: true, HasDepartment: true},
+	DocModuleProcedureManual:  {Columns: []string{"procedure_manual_name", "[desc]", "category"}, HasCategory: true},
+	DocModuleQms:              {Columns: []string{"qms_documents_name", "d_qms_documents_desc", "document_no", "category"}, HasCategory: true},
+	DocModuleCustomerManual:   {Columns: []string{"customer_manual_name", "[desc]", "category"}, HasCategory: true},
+	DocModuleOrganizationDocs: {Columns: []string{"name", "[desc]", "department"}, HasDepartment: true},
+}
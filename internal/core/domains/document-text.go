@@ -0,0 +1,24 @@
+package domains
+
+import "time"
+
+// DocumentTextPage holds the text extracted from one page of a document's current file
+type DocumentTextPage struct {
+	DocumentTextPageID int       `gorm:"column:document_text_page_id;primaryKey;autoIncrement"`
+	Module             string    `gorm:"column:module;type:varchar(30);index:IX_document_text_pages_doc;not null"`
+	DocumentID         int       `gorm:"column:document_id;index:IX_document_text_pages_doc;not null"`
+	PageNo             int       `gorm:"column:page_no;not null"`
+	Content            string    `gorm:"column:content;type:nvarchar(max);not null"`
+	CreatedAt          time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (DocumentTextPage) TableName() string {
+	return "document_text_pages"
+}
+
+// DocumentFile is the current file of a live document
+type DocumentFile struct {
+	DocumentID int
+	FileName   string
+	Name       string // document title, used as the download name
+}
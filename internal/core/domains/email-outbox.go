@@ -0,0 +1,35 @@
+package domains
+
+import "time"
+
+// Outbox statuses
+const (
+	EmailPending = "pending"
+	EmailSending = "sending"
+	EmailSent    = "sent"
+	EmailFailed  = "failed" // gave up after MaxAttempts
+)
+
+type EmailOutbox struct {
+	EmailOutboxID  int        `gorm:"column:email_outbox_id;primaryKey;autoIncrement"`
+	ToAddresses    string     `gorm:"column:to_addresses;type:nvarchar(max);not null"` // comma separated
+	CcAddresses    string     `gorm:"column:cc_addresses;type:nvarchar(max)"`
+	Subject        string     `gorm:"column:subject;type:nvarchar(500);not null"`
+	HTMLBody       string     `gorm:"column:html_body;type:nvarchar(max)"`
+	TextBody       string     `gorm:"column:text_body;type:nvarchar(max)"`
+	UnsubscribeURL string     `gorm:"column:unsubscribe_url;type:nvarchar(500)"`
+	Template       string     `gorm:"column:template;type:varchar(100)"`
+	Lang           string     `gorm:"column:lang;type:varchar(5)"`
+	Status         string     `gorm:"column:status;type:varchar(20);index:IX_email_outbox_status_next;not null"`
+	Attempts       int        `gorm:"column:attempts;not null;default:0"`
+	MaxAttempts    int        `gorm:"column:max_attempts;not null;default:8"`
+	NextAttemptAt  time.Time  `gorm:"column:next_attempt_at;index:IX_email_outbox_status_next;not null"`
+	LastError      string     `gorm:"column:last_error;type:nvarchar(max)"`
+	SentAt         *time.Time `gorm:"column:sent_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
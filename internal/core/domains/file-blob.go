@@ -0,0 +1,38 @@
+package domains
+
+import "time"
+
+// Problems reported by the file integrity check
+const (
+	FileIntegrityMissing    = "missing"    // the blob is not in storage
+	FileIntegrityMismatch   = "mismatch"   // the stored content no longer hashes to its SHA-256 or size
+	FileIntegrityUnreadable = "unreadable" // the blob exists but could not be read
+)
+
+// FileBlob is one distinct file content, stored once under its SHA-256 however many keys link to it.
+// Blobs whose RefCount dropped to zero are kept until the orphan collector removes them.
+type FileBlob struct {
+	SHA256      string     `gorm:"column:sha256;type:char(64);primaryKey"`
+	Size        int64      `gorm:"column:size;not null"`
+	ContentType string     `gorm:"column:content_type;type:varchar(150)"`
+	RefCount    int        `gorm:"column:ref_count;not null;default:0"`
+	VerifiedAt  *time.Time `gorm:"column:verified_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FileBlob) TableName() string {
+	return "file_blobs"
+}
+
+// FileLink ties a stored path (uploads/<folder>/<name>, as kept in the module tables) to its blob
+type FileLink struct {
+	StorageKey string    `gorm:"column:storage_key;type:nvarchar(450);primaryKey"`
+	SHA256     string    `gorm:"column:sha256;type:char(64);index:IX_file_links_sha256;not null"`
+	Thumbnails bool      `gorm:"column:thumbnails;not null;default:0"` // previews generated
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (FileLink) TableName() string {
+	return "file_links"
+}
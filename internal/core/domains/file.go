@@ -0,0 +1,63 @@
+package domains
+
+import (
+	"path"
+	"strconv"
+)
+
+// Sources the file service resolves files from
+const (
+	FileSourceDocument       = "document"        // current file of a document module record
+	FileSourceNewsAttachment = "news-attachment" // company news attachment by its ID
+	FileSourceFolder         = "folder"          // file in an allow-listed uploads folder
+)
+
+// Upload folders outside the document modules that get-file may serve
+const (
+	CompanyNewsUploadFolder    = "company_news"
+	WelfareBenefitUploadFolder = "welfare_benefits"
+	ProductUploadFolder        = "product"
+)
+
+// PublicUploadFolders belong to records everyone can read, so their files are served without an owner lookup
+var PublicUploadFolders = map[string]bool{
+	WelfareBenefitUploadFolder: true,
+	ProductUploadFolder:        true,
+}
+
+// FileRef identifies a file by the record that owns it, or by folder and name
+type FileRef struct {
+	Source       string
+	Module       string
+	DocumentID   int
+	AttachmentID int
+	Folder       string
+	FileName     string
+}
+
+// Key is the canonical form of the reference covered by signed URLs
+func (r FileRef) Key() string {
+	switch r.Source {
+	case FileSourceDocument:
+		return FileSourceDocument + ":" + r.Module + ":" + strconv.Itoa(r.DocumentID)
+	case FileSourceNewsAttachment:
+		return FileSourceNewsAttachment + ":" + strconv.Itoa(r.AttachmentID)
+	}
+	return FileSourceFolder + ":" + path.Join(r.Folder, r.FileName)
+}
+
+// FileViewer is the caller a file is resolved for; Signed skips the permission checks
+type FileViewer struct {
+	EmpCode string
+	Role    string
+	Signed  bool
+}
+
+// ServedFile is a resolved stored file; Path is its storage key; Module and DocumentID are set when it belongs to a document
+type ServedFile struct {
+	Path       string
+	Name       string
+	Module     string
+	DocumentID int
+	Preview    bool // a generated thumbnail, not the document itself
+}
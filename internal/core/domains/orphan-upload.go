@@ -0,0 +1,25 @@
+package domains
+
+import "time"
+
+// OrphanUpload tracks a stored file no record refers to. The garbage collector deletes it once it has
+// stayed unreferenced for the grace period; the row is dropped when the file is referenced again or deleted.
+type OrphanUpload struct {
+	StorageKey  string    `gorm:"column:storage_key;type:nvarchar(450);primaryKey"`
+	Folder      string    `gorm:"column:folder;type:nvarchar(100)"`
+	FileSize    int64     `gorm:"column:file_size"`
+	FirstSeenAt time.Time `gorm:"column:first_seen_at;not null"`
+	LastSeenAt  time.Time `gorm:"column:last_seen_at;not null"`
+}
+
+func (OrphanUpload) TableName() string {
+	return "orphan_uploads"
+}
+
+// FileBlobStats sums the content-addressed blobs, and those no key links to any more
+type FileBlobStats struct {
+	Blobs             int64 `gorm:"column:blobs"`
+	Bytes             int64 `gorm:"column:bytes"`
+	UnreferencedBlobs int64 `gorm:"column:unreferenced_blobs"`
+	UnreferencedBytes int64 `gorm:"column:unreferenced_bytes"`
+}
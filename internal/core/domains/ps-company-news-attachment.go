@@ -0,0 +1,28 @@
+package domains
+
+import "time"
+
+// Attachment kinds: images form the article gallery, documents are downloadable files
+const (
+	AttachmentImage    = "image"
+	AttachmentDocument = "document"
+)
+
+type CompanyNewsAttachment struct {
+	CompanyNewsAttachmentID int       `gorm:"column:company_news_attachment_id;primaryKey;autoIncrement"`
+	CompanyNewsID           string    `gorm:"column:company_news_id;type:uniqueidentifier;index;not null"`
+	Kind                    string    `gorm:"column:kind;type:varchar(20);not null"`
+	FilePath                string    `gorm:"column:file_path;type:nvarchar(500);not null"`
+	OriginalName            string    `gorm:"column:original_name;type:nvarchar(255)"`
+	MimeType                string    `gorm:"column:mime_type;type:varchar(150)"`
+	FileSize                int64     `gorm:"column:file_size"`
+	Caption                 string    `gorm:"column:caption;type:nvarchar(500)"`
+	SortOrder               int       `gorm:"column:sort_order;default:0"`
+	UsernameCreator         string    `gorm:"column:username_creator;type:nvarchar(100)"`
+	CreatedAt               time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt               time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (CompanyNewsAttachment) TableName() string {
+	return "company_news_attachments"
+}
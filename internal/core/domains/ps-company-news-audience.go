@@ -0,0 +1,44 @@
+package domains
+
+import "time"
+
+// Audience target types, matching the employee attributes stored in ps_employees
+const (
+	AudienceDepartment      = "department"
+	AudienceGroupDepartment = "group_department"
+	AudienceOrgGroup        = "org_group"
+	AudienceRole            = "role"
+)
+
+// CompanyNewsAudience restricts an article to employees matching at least one target.
+// An article without any rows is company-wide.
+type CompanyNewsAudience struct {
+	CompanyNewsAudienceID int       `gorm:"column:company_news_audience_id;primaryKey;autoIncrement"`
+	CompanyNewsID         string    `gorm:"column:company_news_id;type:uniqueidentifier;index;not null"`
+	TargetType            string    `gorm:"column:target_type;type:varchar(30);not null"`
+	TargetValue           string    `gorm:"column:target_value;type:nvarchar(255);not null"`
+	CreatedAt             time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (CompanyNewsAudience) TableName() string {
+	return "company_news_audiences"
+}
+
+// NewsViewer holds the attributes of the employee reading company news
+type NewsViewer struct {
+	EmpCode         string
+	Department      string
+	GroupDepartment string
+	OrgGroup        string
+	Role            string
+	SeeAll          bool // editors see every article regardless of audience
+}
+
+// IsValidAudienceType reports whether t is a supported audience target type
+func IsValidAudienceType(t string) bool {
+	switch t {
+	case AudienceDepartment, AudienceGroupDepartment, AudienceOrgGroup, AudienceRole:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package domains
+
+import "time"
+
+// CompanyNewsDigestSubscription is an employee's opt-in to the company news email digest
+type CompanyNewsDigestSubscription struct {
+	CompanyNewsDigestSubscriptionID int       `gorm:"column:company_news_digest_subscription_id;primaryKey;autoIncrement"`
+	EmpCode                         string    `gorm:"column:emp_code;type:nvarchar(50);uniqueIndex:UX_company_news_digest_subscriptions_emp_code;not null"`
+	Subscribed                      bool      `gorm:"column:subscribed;not null;default:true"`
+	Lang                            string    `gorm:"column:lang;type:varchar(5);not null;default:th"`
+	UnsubscribeToken                string    `gorm:"column:unsubscribe_token;type:varchar(64);uniqueIndex:UX_company_news_digest_subscriptions_token;not null"`
+	CreatedAt                       time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt                       time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (CompanyNewsDigestSubscription) TableName() string {
+	return "company_news_digest_subscriptions"
+}
+
+// CompanyNewsDigestRun records one digest sent for the period [PeriodFrom, PeriodTo)
+type CompanyNewsDigestRun struct {
+	CompanyNewsDigestRunID int       `gorm:"column:company_news_digest_run_id;primaryKey;autoIncrement"`
+	PeriodFrom             time.Time `gorm:"column:period_from;not null"`
+	PeriodTo               time.Time `gorm:"column:period_to;not null"`
+	Recipients             int       `gorm:"column:recipients;not null"`
+	Skipped                int       `gorm:"column:skipped;not null"` // subscribers with no news in the period
+	Trigger                string    `gorm:"column:trigger_type;type:varchar(20);not null"`
+	Username               string    `gorm:"column:username;type:nvarchar(100)"`
+	CreatedAt              time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (CompanyNewsDigestRun) TableName() string {
+	return "company_news_digest_runs"
+}
+
+// Digest run triggers
+const (
+	DigestTriggerSchedule = "schedule"
+	DigestTriggerManual   = "manual"
+)
+
+// CompanyNewsDigestRecipient is an opted-in, enabled employee with a mailbox
+type CompanyNewsDigestRecipient struct {
+	PSEmployee
+	Lang             string `gorm:"column:lang"`
+	UnsubscribeToken string `gorm:"column:unsubscribe_token"`
+}
@@ -0,0 +1,44 @@
+package domains
+
+import "time"
+
+// Revision actions recorded for company news
+const (
+	RevisionBaseline = "baseline" // state found before the first tracked change of an older article
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionUndelete = "undelete"
+)
+
+type CompanyNewsRevision struct {
+	CompanyNewsRevisionID int       `gorm:"column:company_news_revision_id;primaryKey;autoIncrement"`
+	CompanyNewsID         string    `gorm:"column:company_news_id;type:uniqueidentifier;uniqueIndex:UX_company_news_revisions_news_revision;not null"`
+	RevisionNo            int       `gorm:"column:revision_no;uniqueIndex:UX_company_news_revisions_news_revision;not null"`
+	Action                string    `gorm:"column:action;type:varchar(20);not null"`
+	ChangedFields         string    `gorm:"column:changed_fields;type:nvarchar(max)"` // JSON array of field names
+	Snapshot              string    `gorm:"column:snapshot;type:nvarchar(max)"`       // JSON CompanyNewsSnapshot
+	RestoredFrom          *int      `gorm:"column:restored_from"`
+	Username              string    `gorm:"column:username;type:nvarchar(100)"`
+	CreatedAt             time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (CompanyNewsRevision) TableName() string {
+	return "company_news_revisions"
+}
+
+// CompanyNewsSnapshot is the full editable state of an article at one revision
+type CompanyNewsSnapshot struct {
+	Title            string                        `json:"title"`
+	Content          string                        `json:"content"`
+	Category         string                        `json:"category"`
+	CompanyNewsPhoto string                        `json:"company_news_photo"`
+	UsernameCreator  string                        `json:"username_creator"`
+	Audiences        []CompanyNewsSnapshotAudience `json:"audiences"`
+}
+
+type CompanyNewsSnapshotAudience struct {
+	TargetType  string `json:"target_type"`
+	TargetValue string `json:"target_value"`
+}
@@ -15,6 +15,7 @@ type CompanyNews struct {
 	CompanyNewsPhoto string         `json:"company_news_photo"`
 	Title            string         `json:"title"`
 	Content          string         `json:"content"`
+	ContentText      string         `gorm:"type:nvarchar(max)" json:"-"` // content without HTML, used for search
 	Category         string         `json:"category"`
 	UsernameCreator  string         `json:"username_creator"`
 	CreatedAt        time.Time      `json:"created_at"`
@@ -122,3 +123,21 @@ func (cn *CompanyNews) MarshalJSON() ([]byte, error) {
 // 	CreatedAt        time.Time `json:"created_at"`
 // 	UpdatedAt        time.Time `json:"updated_at"`
 // }
+
+// CompanyNewsSearch holds the keyword terms and filters for searching company news
+type CompanyNewsSearch struct {
+	Terms    []string
+	Category string
+	Creator  string
+	DateFrom *time.Time
+	DateTo   *time.Time // exclusive upper bound
+	Viewer   NewsViewer
+	Limit    int
+	Offset   int
+}
+
+// CompanyNewsSearchHit is a company news row with its relevance score
+type CompanyNewsSearchHit struct {
+	CompanyNews
+	Score int
+}
@@ -6,12 +6,43 @@ import (
 	"gorm.io/gorm"
 )
 
+// QMS document control statuses (IATF 16949 lifecycle)
+const (
+	QmsStatusDraft       = "draft"
+	QmsStatusUnderReview = "under_review"
+	QmsStatusApproved    = "approved"
+	QmsStatusEffective   = "effective"
+	QmsStatusObsolete    = "obsolete"
+)
+
+// QMS workflow actions
+const (
+	QmsActionSubmit   = "submit"   // draft -> under_review, by the owner
+	QmsActionReview   = "review"   // reviewer signs off, stays under_review
+	QmsActionApprove  = "approve"  // under_review -> approved, by the approver after review
+	QmsActionReject   = "reject"   // under_review/approved -> draft, by the reviewer or approver
+	QmsActionRelease  = "release"  // approved -> effective
+	QmsActionObsolete = "obsolete" // effective -> obsolete
+)
+
 type QmsDocuments struct {
 	QmsDocumentsID    int            `gorm:"primaryKey;autoIncrement"`
 	QmsDocumentsName  string         `gorm:"type:nvarchar(255);not null"`
 	DQmsDocumentsDesc string         `gorm:"type:nvarchar(max);not null"` // Description in Thai
 	Category          string         `gorm:"type:varchar(50);not null"`
 	FileName          string         `gorm:"type:nvarchar(max);not null"`
+	DocumentNo        string         `gorm:"column:document_no;type:nvarchar(50);index"`
+	Revision          string         `gorm:"column:revision;type:nvarchar(20)"`
+	Status            string         `gorm:"column:status;type:varchar(20);not null;default:effective;index"` // rows from before the workflow stay effective
+	OwnerEmpCode      string         `gorm:"column:owner_emp_code;type:nvarchar(50)"`
+	ReviewerEmpCode   string         `gorm:"column:reviewer_emp_code;type:nvarchar(50)"`
+	ApproverEmpCode   string         `gorm:"column:approver_emp_code;type:nvarchar(50)"`
+	SubmittedAt       *time.Time     `gorm:"column:submitted_at"`
+	ReviewedAt        *time.Time     `gorm:"column:reviewed_at"`
+	ApprovedAt        *time.Time     `gorm:"column:approved_at"`
+	EffectiveAt       *time.Time     `gorm:"column:effective_at"`
+	ObsoletedAt       *time.Time     `gorm:"column:obsoleted_at"`
+	ReviewDueDate     *time.Time     `gorm:"column:review_due_date;type:date"`
 	CreatedAt         time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt         time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt         gorm.DeletedAt `gorm:"index"`
@@ -20,3 +51,25 @@ type QmsDocuments struct {
 func (QmsDocuments) TableName() string {
 	return "ps_qms_documents"
 }
+
+// QmsDocumentApproval is one step of the document control trail
+type QmsDocumentApproval struct {
+	QmsDocumentApprovalID int       `gorm:"column:qms_document_approval_id;primaryKey;autoIncrement"`
+	QmsDocumentsID        int       `gorm:"column:qms_documents_id;index;not null"`
+	Action                string    `gorm:"column:action;type:varchar(20);not null"`
+	FromStatus            string    `gorm:"column:from_status;type:varchar(20)"`
+	ToStatus              string    `gorm:"column:to_status;type:varchar(20)"`
+	EmpCode               string    `gorm:"column:emp_code;type:nvarchar(50)"`
+	Comment               string    `gorm:"column:comment;type:nvarchar(max)"`
+	CreatedAt             time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (QmsDocumentApproval) TableName() string {
+	return "qms_document_approvals"
+}
+
+// QmsDocumentFilter narrows QMS document lists
+type QmsDocumentFilter struct {
+	Statuses []string
+	Search   *DocumentSearch
+}
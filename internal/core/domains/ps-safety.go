@@ -13,6 +13,9 @@ type SafetyDocument struct {
 	Category           string         `gorm:"type:varchar(50);not null"`   // office, production, quality, support
 	FileName           string         `gorm:"type:nvarchar(255)"`
 	Department         string         `gorm:"type:nvarchar(255)"`
+	OwnerEmpCode       string         `gorm:"type:nvarchar(50)"` // responsible for the periodic review
+	NextReviewDate     *time.Time     `gorm:"type:date;index"`   // procedure must be reviewed by this date
+	ExpiryDate         *time.Time     `gorm:"type:date;index"`   // document is no longer valid after this date
 	CreatedAt          time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt          time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt          gorm.DeletedAt `gorm:"index"`
@@ -22,3 +25,26 @@ type SafetyDocument struct {
 func (SafetyDocument) TableName() string {
 	return "ps_safetys_documents"
 }
+
+// Safety document reminder kinds
+const (
+	SafetyReminderReviewUpcoming = "review_upcoming"
+	SafetyReminderReviewOverdue  = "review_overdue"
+	SafetyReminderExpiryUpcoming = "expiry_upcoming"
+	SafetyReminderExpired        = "expired"
+)
+
+// SafetyDocumentReminder records a reminder e-mail, so each kind is sent once per due date.
+// Moving the due date (after a review) arms the reminders again.
+type SafetyDocumentReminder struct {
+	SafetyDocumentReminderID int       `gorm:"primaryKey;autoIncrement"`
+	SafetyDocumentID         int       `gorm:"not null;uniqueIndex:ux_safety_document_reminder"`
+	Kind                     string    `gorm:"type:varchar(20);not null;uniqueIndex:ux_safety_document_reminder"`
+	DueDate                  time.Time `gorm:"type:date;not null;uniqueIndex:ux_safety_document_reminder"`
+	Recipients               int       `gorm:"not null;default:0"`
+	CreatedAt                time.Time `gorm:"autoCreateTime"`
+}
+
+func (SafetyDocumentReminder) TableName() string {
+	return "safety_document_reminders"
+}
@@ -0,0 +1,79 @@
+package domains
+
+import "time"
+
+// Acknowledgement target types, matching the employee attributes stored in ps_employees
+const (
+	AckTargetAll        = "all"
+	AckTargetDepartment = "department"
+	AckTargetPosition   = "position"
+)
+
+// SafetyAckRequirement asks the targeted employees to confirm they have read a safety document revision.
+// Only the latest requirement of a document is active; a new revision starts a new round.
+type SafetyAckRequirement struct {
+	SafetyAckRequirementID int        `gorm:"primaryKey;autoIncrement"`
+	SafetyDocumentID       int        `gorm:"not null;index"`
+	RevisionNo             int        `gorm:"not null;default:0"` // document_revisions.revision_no, 0 when untracked
+	DueDate                *time.Time `gorm:"type:date"`
+	RequiredBy             string     `gorm:"type:nvarchar(100)"`
+	Active                 bool       `gorm:"not null;default:1;index"`
+	CreatedAt              time.Time  `gorm:"autoCreateTime"`
+}
+
+func (SafetyAckRequirement) TableName() string {
+	return "safety_document_ack_requirements"
+}
+
+type SafetyAckTarget struct {
+	SafetyAckTargetID      int    `gorm:"primaryKey;autoIncrement"`
+	SafetyAckRequirementID int    `gorm:"not null;index"`
+	TargetType             string `gorm:"type:varchar(20);not null"`
+	TargetValue            string `gorm:"type:nvarchar(255);not null"`
+}
+
+func (SafetyAckTarget) TableName() string {
+	return "safety_document_ack_targets"
+}
+
+// SafetyAcknowledgement is an employee's confirmation that they read the document
+type SafetyAcknowledgement struct {
+	SafetyAcknowledgementID int       `gorm:"primaryKey;autoIncrement"`
+	SafetyAckRequirementID  int       `gorm:"not null;uniqueIndex:ux_safety_acknowledgement"`
+	SafetyDocumentID        int       `gorm:"not null;index"`
+	RevisionNo              int       `gorm:"not null;default:0"`
+	EmpCode                 string    `gorm:"type:nvarchar(50);not null;uniqueIndex:ux_safety_acknowledgement"`
+	AcknowledgedAt          time.Time `gorm:"not null"`
+}
+
+func (SafetyAcknowledgement) TableName() string {
+	return "safety_document_acknowledgements"
+}
+
+// SafetyAckEmployeeStatus is one targeted employee and when (if) they acknowledged
+type SafetyAckEmployeeStatus struct {
+	EmpCode        string     `gorm:"column:UHR_EmpCode"`
+	FullNameTh     string     `gorm:"column:UHR_FullName_th"`
+	FullNameEn     string     `gorm:"column:UHR_FullName_en"`
+	Department     string     `gorm:"column:UHR_Department"`
+	Position       string     `gorm:"column:UHR_Position"`
+	AcknowledgedAt *time.Time `gorm:"column:acknowledged_at"`
+}
+
+// SafetyAckPending is a document the employee still has to acknowledge
+type SafetyAckPending struct {
+	SafetyAckRequirement
+	SafetyDocumentName string
+	Category           string
+	Department         string
+	FileName           string
+}
+
+// IsValidAckTargetType reports whether t is a supported acknowledgement target type
+func IsValidAckTargetType(t string) bool {
+	switch t {
+	case AckTargetAll, AckTargetDepartment, AckTargetPosition:
+		return true
+	}
+	return false
+}
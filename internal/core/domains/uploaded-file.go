@@ -0,0 +1,49 @@
+package domains
+
+import "time"
+
+// Upload scan statuses
+const (
+	UploadScanClean    = "clean"
+	UploadScanInfected = "infected"
+	UploadScanError    = "error"   // the scanner could not be reached or failed
+	UploadScanSkipped  = "skipped" // scanning is disabled
+)
+
+// Actions for infected uploads, selected with UPLOAD_SCAN_ACTION
+const (
+	UploadScanActionReject     = "reject"     // discard the file
+	UploadScanActionQuarantine = "quarantine" // keep it under QuarantineUploadFolder for review
+)
+
+// QuarantineUploadFolder holds infected uploads; the file service never serves it
+const QuarantineUploadFolder = "quarantine"
+
+// UploadedFile registers every file stored through the uploader with its scan verdict.
+// StorageKey is empty when an infected upload was rejected without being kept.
+type UploadedFile struct {
+	UploadedFileID int        `gorm:"column:uploaded_file_id;primaryKey;autoIncrement"`
+	StorageKey     string     `gorm:"column:storage_key;type:nvarchar(500);index:IX_uploaded_files_storage_key"`
+	OriginalName   string     `gorm:"column:original_name;type:nvarchar(255)"`
+	MimeType       string     `gorm:"column:mime_type;type:varchar(150)"`
+	FileSize       int64      `gorm:"column:file_size"`
+	ScanStatus     string     `gorm:"column:scan_status;type:varchar(20);index:IX_uploaded_files_scan_status;not null"`
+	ScanEngine     string     `gorm:"column:scan_engine;type:varchar(30)"`
+	ScanSignature  string     `gorm:"column:scan_signature;type:nvarchar(255)"`
+	ScanError      string     `gorm:"column:scan_error;type:nvarchar(500)"`
+	ScannedAt      *time.Time `gorm:"column:scanned_at"`
+	Quarantined    bool       `gorm:"column:quarantined;not null;default:false"`
+	UploadedBy     string     `gorm:"column:uploaded_by;type:nvarchar(100)"`
+	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UploadedFile) TableName() string {
+	return "uploaded_files"
+}
+
+// UploadedFileQuery filters the upload registry
+type UploadedFileQuery struct {
+	ScanStatus string
+	Page       int
+	PageSize   int
+}
@@ -23,15 +23,18 @@ type UpdateCustomerManualRequest struct {
 }
 
 type CustomerManualResponse struct {
-	CustomerManualID   int       `json:"customer_manual_id"`
-	CustomerManualName string    `json:"customer_manual_name"`
-	Desc               string    `json:"desc"`
-	Category           string    `json:"category"`
-	FileName           string    `json:"file_name"`
-	ParentID           *int      `json:"parent_id"`
-	SortOrder          int       `json:"sort_order"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	CustomerManualID   int                     `json:"customer_manual_id"`
+	CustomerManualName string                  `json:"customer_manual_name"`
+	Desc               string                  `json:"desc"`
+	Category           string                  `json:"category"`
+	FileName           string                  `json:"file_name"`
+	Thumbnails         *ThumbnailURLs          `json:"thumbnails,omitempty"`
+	FileSHA256         string                  `json:"file_sha256,omitempty"`
+	ParentID           *int                    `json:"parent_id"`
+	SortOrder          int                     `json:"sort_order"`
+	CreatedAt          time.Time               `json:"created_at"`
+	UpdatedAt          time.Time               `json:"updated_at"`
+	Matches            []DocumentTextMatchResp `json:"matches,omitempty"` // pages of the file that matched a search
 }
 
 type CustomerManualListResponse struct {
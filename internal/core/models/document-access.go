@@ -0,0 +1,28 @@
+package models
+
+type DocumentAccessStatResp struct {
+	DocumentID     int     `json:"document_id"`
+	Name           string  `json:"name"`
+	FileName       string  `json:"file_name"`
+	Views          int64   `json:"views"`
+	Downloads      int64   `json:"downloads"`
+	Total          int64   `json:"total"`
+	UniqueViewers  int64   `json:"unique_viewers"`
+	LastAccessedAt *string `json:"last_accessed_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+type DocumentMostViewedResp struct {
+	Module string                   `json:"module"`
+	From   string                   `json:"from"`
+	To     string                   `json:"to"`
+	Data   []DocumentAccessStatResp `json:"data"`
+}
+
+type DocumentUnopenedResp struct {
+	Module string                   `json:"module"`
+	Months int                      `json:"months"`
+	Since  string                   `json:"since"`
+	Total  int                      `json:"total"`
+	Data   []DocumentAccessStatResp `json:"data"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// DocumentExportRequest is the query of /api/file/export/:module
+type DocumentExportRequest struct {
+	Category   string
+	Department string
+	All        bool
+}
@@ -0,0 +1,42 @@
+package models
+
+// Statuses of a bulk import row
+const (
+	DocumentImportValid    = "valid"
+	DocumentImportInvalid  = "invalid"
+	DocumentImportImported = "imported"
+	DocumentImportFailed   = "failed"
+)
+
+// DocumentImportRow is one manifest row with its validation result; Row is its line in the manifest
+type DocumentImportRow struct {
+	Row          int      `json:"row"`
+	File         string   `json:"file"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Category     string   `json:"category,omitempty"`
+	Department   string   `json:"department,omitempty"`
+	Parent       string   `json:"parent,omitempty"`
+	DocumentNo   string   `json:"document_no,omitempty"`
+	Revision     string   `json:"revision,omitempty"`
+	OwnerEmpCode string   `json:"owner_emp_code,omitempty"`
+	SortOrder    int      `json:"sort_order,omitempty"`
+	Status       string   `json:"status"`
+	DocumentID   int      `json:"document_id,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+
+	ParentID    *int   `json:"-"`
+	ParentIndex int    `json:"-"`
+	FileName    string `json:"-"` // stored file, set once the file is saved
+}
+
+// DocumentImportReport is the per-row result of a bulk import; nothing is imported unless every row is valid
+type DocumentImportReport struct {
+	Module       string              `json:"module"`
+	DryRun       bool                `json:"dry_run"`
+	TotalRows    int                 `json:"total_rows"`
+	ValidRows    int                 `json:"valid_rows"`
+	InvalidRows  int                 `json:"invalid_rows"`
+	ImportedRows int                 `json:"imported_rows"`
+	Rows         []DocumentImportRow `json:"rows"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DocumentRevisionReq carries the revision details sent with a new file
+type DocumentRevisionReq struct {
+	RevisionLabel string
+	ChangeNote    string
+	EffectiveDate *time.Time
+	UploadedBy    string
+}
+
+type DocumentRevisionResp struct {
+	Module        string  `json:"module"`
+	DocumentID    int     `json:"document_id"`
+	RevisionNo    int     `json:"revision_no"`
+	RevisionLabel string  `json:"revision_label"`
+	FileName      string  `json:"file_name"`
+	FileURL       string  `json:"file_url"`
+	FileSHA256    string  `json:"file_sha256,omitempty"`
+	UploadedBy    string  `json:"uploaded_by"`
+	ChangeNote    string  `json:"change_note"`
+	EffectiveDate *string `json:"effective_date"`
+	IsCurrent     bool    `json:"is_current"`
+	CreatedAt     string  `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// DocumentSearchRequest is the query of a document module /search endpoint
+type DocumentSearchRequest struct {
+	Keyword    string
+	Category   string
+	Department string
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,7 @@
+package models
+
+// DocumentTextMatchResp is a page of the document file that contains the search keyword
+type DocumentTextMatchResp struct {
+	Page    int    `json:"page"`
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,97 @@
+package models
+
+// SignFileURLReq names the file to sign: module + document_id, attachment_id, or folder + filename
+type SignFileURLReq struct {
+	Module       string `json:"module"`
+	DocumentID   int    `json:"document_id"`
+	AttachmentID int    `json:"attachment_id"`
+	Folder       string `json:"folder"`
+	FileName     string `json:"filename"`
+	TTLSeconds   int    `json:"ttl_seconds"`
+}
+
+type SignedFileURLResp struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// UploadedFileResp is one entry of the upload registry with its malware scan verdict
+type UploadedFileResp struct {
+	UploadedFileID int     `json:"uploaded_file_id"`
+	StorageKey     string  `json:"storage_key"`
+	OriginalName   string  `json:"original_name"`
+	MimeType       string  `json:"mime_type"`
+	FileSize       int64   `json:"file_size"`
+	ScanStatus     string  `json:"scan_status"`
+	ScanEngine     string  `json:"scan_engine"`
+	ScanSignature  string  `json:"scan_signature,omitempty"`
+	ScanError      string  `json:"scan_error,omitempty"`
+	ScannedAt      *string `json:"scanned_at"`
+	Quarantined    bool    `json:"quarantined"`
+	UploadedBy     string  `json:"uploaded_by"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+type UploadedFileListResp struct {
+	Data       []UploadedFileResp `json:"data"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// ThumbnailURLs links the generated previews of an uploaded image or the first page of a PDF
+type ThumbnailURLs struct {
+	Small  string `json:"small,omitempty"`
+	Medium string `json:"medium,omitempty"`
+	Large  string `json:"large,omitempty"`
+}
+
+// FileIntegrityIssue is a blob whose stored content is missing or no longer matches its hash
+type FileIntegrityIssue struct {
+	SHA256       string   `json:"sha256"`
+	Problem      string   `json:"problem"`
+	Size         int64    `json:"size"`
+	ActualSHA256 string   `json:"actual_sha256,omitempty"`
+	ActualSize   int64    `json:"actual_size,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	Keys         []string `json:"keys"`
+}
+
+// FileIntegrityReport is the result of re-hashing every content-addressed blob
+type FileIntegrityReport struct {
+	CheckedBlobs    int                  `json:"checked_blobs"`
+	VerifiedBlobs   int                  `json:"verified_blobs"`
+	MissingBlobs    int                  `json:"missing_blobs"`
+	MismatchedBlobs int                  `json:"mismatched_blobs"`
+	UnreadableBlobs int                  `json:"unreadable_blobs"`
+	LegacyFiles     int                  `json:"legacy_files"`
+	Issues          []FileIntegrityIssue `json:"issues"`
+	IssuesTruncated bool                 `json:"issues_truncated"`
+	StartedAt       string               `json:"started_at"`
+	FinishedAt      string               `json:"finished_at"`
+}
+
+// CreateUploadSessionReq announces a file sent in parts; SHA256 (hex) may instead be given on completion
+type CreateUploadSessionReq struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+type CompleteUploadSessionReq struct {
+	SHA256 string `json:"sha256"`
+}
+
+// UploadSessionResp reports a resumable upload; once Completed, UploadToken replaces the file in the
+// create and update requests of the document modules (file_upload_token)
+type UploadSessionResp struct {
+	UploadToken string `json:"upload_token"`
+	FileName    string `json:"file_name"`
+	Size        int64  `json:"size"`
+	Offset      int64  `json:"offset"`
+	SHA256      string `json:"sha256,omitempty"`
+	Completed   bool   `json:"completed"`
+	ChunkSize   int    `json:"chunk_size"`
+	ExpiresAt   string `json:"expires_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type GlobalSearchReq struct {
+	Keyword    string
+	Types      []string // empty searches every type
+	Category   string
+	Department string
+	EmpCode    string
+	Role       string
+	BaseURL    string
+	Limit      int
+}
+
+type GlobalSearchItem struct {
+	Type           string                  `json:"type"`
+	ID             string                  `json:"id"`
+	Title          string                  `json:"title"`
+	TitleHighlight string                  `json:"title_highlight"`
+	Snippet        string                  `json:"snippet"`
+	Link           string                  `json:"link"`
+	Category       string                  `json:"category,omitempty"`
+	Department     string                  `json:"department,omitempty"`
+	Score          int                     `json:"score"`
+	Matches        []DocumentTextMatchResp `json:"matches,omitempty"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+}
+
+type GlobalSearchFacet struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type GlobalSearchFacets struct {
+	Type       []GlobalSearchFacet `json:"type"`
+	Category   []GlobalSearchFacet `json:"category"`
+	Department []GlobalSearchFacet `json:"department"`
+}
+
+type GlobalSearchResp struct {
+	Keyword string             `json:"keyword"`
+	Total   int64              `json:"total"`
+	Data    []GlobalSearchItem `json:"data"`
+	Facets  GlobalSearchFacets `json:"facets"`
+	Failed  []string           `json:"failed,omitempty"` // types whose search failed; the rest are still returned
+}
@@ -17,13 +17,16 @@ type UpdateOrganizationDocRequest struct {
 }
 
 type OrganizationDocResponse struct {
-	OrganizationDocID int       `json:"organization_doc_id"`
-	Name              string    `json:"name"`
-	Desc              string    `json:"desc"`
-	Department        string    `json:"department"`
-	FileName          string    `json:"file_name"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	OrganizationDocID int                     `json:"organization_doc_id"`
+	Name              string                  `json:"name"`
+	Desc              string                  `json:"desc"`
+	Department        string                  `json:"department"`
+	FileName          string                  `json:"file_name"`
+	Thumbnails        *ThumbnailURLs          `json:"thumbnails,omitempty"`
+	FileSHA256        string                  `json:"file_sha256,omitempty"`
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+	Matches           []DocumentTextMatchResp `json:"matches,omitempty"` // pages of the file that matched a search
 }
 
 type OrganizationDocListResponse struct {
@@ -17,13 +17,16 @@ type UpdateProcedureManualRequest struct {
 }
 
 type ProcedureManualResponse struct {
-	ProcedureManualID   int       `json:"procedure_manual_id"`
-	ProcedureManualName string    `json:"procedure_manual_name"`
-	Desc                string    `json:"desc"`
-	Category            string    `json:"category"`
-	FileName            string    `json:"file_name"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ProcedureManualID   int                     `json:"procedure_manual_id"`
+	ProcedureManualName string                  `json:"procedure_manual_name"`
+	Desc                string                  `json:"desc"`
+	Category            string                  `json:"category"`
+	FileName            string                  `json:"file_name"`
+	Thumbnails          *ThumbnailURLs          `json:"thumbnails,omitempty"`
+	FileSHA256          string                  `json:"file_sha256,omitempty"`
+	CreatedAt           time.Time               `json:"created_at"`
+	UpdatedAt           time.Time               `json:"updated_at"`
+	Matches             []DocumentTextMatchResp `json:"matches,omitempty"` // pages of the file that matched a search
 }
 
 type ProcedureManualListResponse struct {
@@ -1,16 +1,25 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
 
 type CompanyNewsReq struct {
-	CompanyNewsID    uuid.UUID `json:"company_news_id"`
-	CompanyNewsPhoto string    `json:"company_news_photo"`
-	Title            string    `json:"title"`
-	Content          string    `json:"content"`
-	Category         string    `json:"category"`
-	UsernameCreator  string    `json:"username_creator"`
-	CreatedAt        string    `json:"created_at"`
-	UpdatedAt        string    `json:"updated_at"`
+	CompanyNewsID    uuid.UUID      `json:"company_news_id"`
+	CompanyNewsPhoto string         `json:"company_news_photo"`
+	Thumbnails       *ThumbnailURLs `json:"thumbnails,omitempty"`
+	PhotoSHA256      string         `json:"photo_sha256,omitempty"`
+	Title            string         `json:"title"`
+	Content          string         `json:"content"`
+	Category         string         `json:"category"`
+	UsernameCreator  string         `json:"username_creator"`
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
+
+	Audiences   []CompanyNewsAudienceReq    `json:"audiences,omitempty"`
+	Attachments []CompanyNewsAttachmentResp `json:"attachments,omitempty"`
 }
 
 type CompanyNewsListResp struct {
@@ -30,4 +39,140 @@ type CompanyNewsResp struct {
 	UsernameCreator  string `json:"username_creator"`
 	CreatedAt        string `json:"created_at"`
 	UpdatedAt        string `json:"updated_at"`
+
+	// nil keeps the current audience on update; an empty slice makes the news company-wide
+	Audiences *[]CompanyNewsAudienceReq `json:"audiences"`
+}
+
+type CompanyNewsAudienceReq struct {
+	TargetType  string `json:"target_type"`
+	TargetValue string `json:"target_value"`
+}
+
+type CompanyNewsAudiencePreviewReq struct {
+	Audiences []CompanyNewsAudienceReq `json:"audiences"`
+}
+
+type CompanyNewsAudienceDepartmentCount struct {
+	Department string `json:"department"`
+	Total      int64  `json:"total"`
+}
+
+type CompanyNewsAudienceEmployee struct {
+	EmpCode    string `json:"emp_code"`
+	FullNameEn string `json:"full_name_en"`
+	FullNameTh string `json:"full_name_th"`
+	Department string `json:"department"`
+	Position   string `json:"position"`
+}
+
+type CompanyNewsAudiencePreviewResp struct {
+	CompanyWide     bool                                 `json:"company_wide"`
+	Audiences       []CompanyNewsAudienceReq             `json:"audiences"`
+	Total           int64                                `json:"total"`
+	Departments     []CompanyNewsAudienceDepartmentCount `json:"departments"`
+	SampleEmployees []CompanyNewsAudienceEmployee        `json:"sample_employees"`
+}
+
+type CompanyNewsSearchItem struct {
+	CompanyNewsReq
+	Score          int    `json:"score"`
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+type CompanyNewsSearchResp struct {
+	Total      int64                   `json:"total"`
+	TotalPages int                     `json:"total_pages"`
+	Limit      int                     `json:"limit"`
+	Offset     int                     `json:"offset"`
+	Data       []CompanyNewsSearchItem `json:"data"`
+}
+
+type CompanyNewsSearchReq struct {
+	Keyword  string
+	Category string
+	Creator  string
+	DateFrom string // YYYY-MM-DD, inclusive
+	DateTo   string // YYYY-MM-DD, inclusive
+	EmpCode  string
+	Role     string
+	Limit    int
+	Offset   int
+}
+
+type CompanyNewsAttachmentResp struct {
+	CompanyNewsAttachmentID int    `json:"company_news_attachment_id"`
+	CompanyNewsID           string `json:"company_news_id"`
+	Kind                    string `json:"kind"`
+	FilePath                string `json:"file_path"`
+	SHA256                  string `json:"sha256,omitempty"`
+	URL                     string `json:"url"`
+	OriginalName            string `json:"original_name"`
+	MimeType                string `json:"mime_type"`
+	FileSize                int64  `json:"file_size"`
+	Caption                 string `json:"caption"`
+	SortOrder               int    `json:"sort_order"`
+	UsernameCreator         string `json:"username_creator"`
+	CreatedAt               string `json:"created_at"`
+}
+
+// CompanyNewsAttachmentUpload describes a file already saved by the handler
+type CompanyNewsAttachmentUpload struct {
+	FilePath     string
+	OriginalName string
+	MimeType     string
+	FileSize     int64
+	Caption      string
+}
+
+type CompanyNewsAttachmentUpdateReq struct {
+	Caption *string `json:"caption"`
+}
+
+type CompanyNewsAttachmentReorderReq struct {
+	AttachmentIDs []int `json:"attachment_ids"`
+}
+
+type CompanyNewsRevisionResp struct {
+	CompanyNewsID string          `json:"company_news_id"`
+	RevisionNo    int             `json:"revision_no"`
+	Action        string          `json:"action"`
+	ChangedFields []string        `json:"changed_fields"`
+	RestoredFrom  *int            `json:"restored_from,omitempty"`
+	Username      string          `json:"username"`
+	CreatedAt     string          `json:"created_at"`
+	Snapshot      json.RawMessage `json:"snapshot,omitempty"`
+}
+
+type CompanyNewsRevisionFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type CompanyNewsRevisionDiffResp struct {
+	CompanyNewsID string                           `json:"company_news_id"`
+	From          int                              `json:"from"`
+	To            int                              `json:"to"`
+	Changes       []CompanyNewsRevisionFieldChange `json:"changes"`
+}
+
+type CompanyNewsDigestSubscriptionResp struct {
+	EmpCode    string `json:"emp_code"`
+	Email      string `json:"email"`
+	Subscribed bool   `json:"subscribed"`
+	Lang       string `json:"lang"`
+}
+
+type CompanyNewsDigestSubscriptionReq struct {
+	Subscribed *bool  `json:"subscribed"`
+	Lang       string `json:"lang"`
+}
+
+type CompanyNewsDigestSendResp struct {
+	PeriodFrom string `json:"period_from"`
+	PeriodTo   string `json:"period_to"`
+	Recipients int    `json:"recipients"`
+	Skipped    int    `json:"skipped"`
 }
@@ -7,6 +7,14 @@ type CreateQmsDocumentRequest struct {
 	DQmsDocumentsDesc string `form:"dqms_documents_desc" binding:"required"`
 	Category          string `form:"category" binding:"required"`
 	FileName          string `form:"file_name"`
+	DocumentNo        string `form:"document_no"`
+	Revision          string `form:"revision"`
+	OwnerEmpCode      string `form:"owner_emp_code"`
+	ReviewerEmpCode   string `form:"reviewer_emp_code"`
+	ApproverEmpCode   string `form:"approver_emp_code"`
+	ReviewDueDate     string `form:"review_due_date"` // YYYY-MM-DD
+	EmpCode           string `form:"-"`               // uploader, from the token
+	Role              string `form:"-"`
 }
 
 type UpdateQmsDocumentRequest struct {
@@ -14,16 +22,54 @@ type UpdateQmsDocumentRequest struct {
 	DQmsDocumentsDesc string `form:"dqms_documents_desc"`
 	Category          string `form:"category"`
 	FileName          string `form:"file_name"`
+	DocumentNo        string `form:"document_no"`
+	Revision          string `form:"revision"`
+	OwnerEmpCode      string `form:"owner_emp_code"`
+	ReviewerEmpCode   string `form:"reviewer_emp_code"`
+	ApproverEmpCode   string `form:"approver_emp_code"`
+	ReviewDueDate     string `form:"review_due_date"` // YYYY-MM-DD
+	EmpCode           string `form:"-"`               // editor, from the token
+	Role              string `form:"-"`
 }
 
 type QmsDocumentResponse struct {
-	QmsDocumentsID    int       `json:"qms_documents_id"`
-	QmsDocumentsName  string    `json:"qms_documents_name"`
-	DQmsDocumentsDesc string    `json:"dqms_documents_desc"`
-	Category          string    `json:"category"`
-	FileName          string    `json:"file_name"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	QmsDocumentsID    int                     `json:"qms_documents_id"`
+	QmsDocumentsName  string                  `json:"qms_documents_name"`
+	DQmsDocumentsDesc string                  `json:"dqms_documents_desc"`
+	Category          string                  `json:"category"`
+	FileName          string                  `json:"file_name"`
+	Thumbnails        *ThumbnailURLs          `json:"thumbnails,omitempty"`
+	FileSHA256        string                  `json:"file_sha256,omitempty"`
+	DocumentNo        string                  `json:"document_no"`
+	Revision          string                  `json:"revision"`
+	Status            string                  `json:"status"`
+	OwnerEmpCode      string                  `json:"owner_emp_code"`
+	ReviewerEmpCode   string                  `json:"reviewer_emp_code"`
+	ApproverEmpCode   string                  `json:"approver_emp_code"`
+	SubmittedAt       *time.Time              `json:"submitted_at"`
+	ReviewedAt        *time.Time              `json:"reviewed_at"`
+	ApprovedAt        *time.Time              `json:"approved_at"`
+	EffectiveAt       *time.Time              `json:"effective_at"`
+	ObsoletedAt       *time.Time              `json:"obsoleted_at"`
+	ReviewDueDate     *string                 `json:"review_due_date"`
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+	Matches           []DocumentTextMatchResp `json:"matches,omitempty"` // pages of the file that matched a search
+}
+
+// QmsDocumentWorkflowRequest is the body of a workflow action
+type QmsDocumentWorkflowRequest struct {
+	Comment       string `json:"comment"`
+	ReviewDueDate string `json:"review_due_date"` // release: YYYY-MM-DD, defaults to one year after release
+}
+
+type QmsDocumentApprovalResponse struct {
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	EmpCode    string    `json:"emp_code"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type QmsDocumentListResponse struct {
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+type SafetyAckTargetReq struct {
+	TargetType  string `json:"target_type"` // department, position
+	TargetValue string `json:"target_value"`
+}
+
+// SafetyAckRequirementReq marks a document revision as requiring acknowledgement
+type SafetyAckRequirementReq struct {
+	Everyone   bool                 `json:"everyone"`
+	Targets    []SafetyAckTargetReq `json:"targets"`
+	RevisionNo int                  `json:"revision_no"` // defaults to the current revision
+	DueDate    string               `json:"due_date"`    // YYYY-MM-DD
+}
+
+type SafetyAckRequirementResp struct {
+	SafetyAckRequirementID int                  `json:"safety_ack_requirement_id"`
+	SafetyDocumentID       int                  `json:"safety_document_id"`
+	RevisionNo             int                  `json:"revision_no"`
+	Everyone               bool                 `json:"everyone"`
+	Targets                []SafetyAckTargetReq `json:"targets"`
+	DueDate                *string              `json:"due_date"`
+	RequiredBy             string               `json:"required_by"`
+	CreatedAt              time.Time            `json:"created_at"`
+}
+
+type SafetyAcknowledgementResp struct {
+	SafetyDocumentID int       `json:"safety_document_id"`
+	RevisionNo       int       `json:"revision_no"`
+	EmpCode          string    `json:"emp_code"`
+	AcknowledgedAt   time.Time `json:"acknowledged_at"`
+}
+
+type SafetyAckPendingResp struct {
+	SafetyDocumentID   int     `json:"safety_document_id"`
+	SafetyDocumentName string  `json:"safety_document_name"`
+	Category           string  `json:"category"`
+	Department         string  `json:"department"`
+	RevisionNo         int     `json:"revision_no"`
+	DueDate            *string `json:"due_date"`
+	FileURL            string  `json:"file_url"`
+}
+
+type SafetyAckEmployeeResp struct {
+	EmpCode        string     `json:"emp_code"`
+	FullNameTh     string     `json:"full_name_th"`
+	FullNameEn     string     `json:"full_name_en"`
+	Department     string     `json:"department"`
+	Position       string     `json:"position"`
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+}
+
+type SafetyAckDepartmentResp struct {
+	Department   string  `json:"department"`
+	Total        int     `json:"total"`
+	Acknowledged int     `json:"acknowledged"`
+	Pending      int     `json:"pending"`
+	Percent      float64 `json:"percent"`
+}
+
+// SafetyAckReportResp compares acknowledged and pending employees of the active requirement
+type SafetyAckReportResp struct {
+	SafetyDocumentID   int                       `json:"safety_document_id"`
+	SafetyDocumentName string                    `json:"safety_document_name"`
+	Requirement        SafetyAckRequirementResp  `json:"requirement"`
+	Total              int                       `json:"total"`
+	Acknowledged       int                       `json:"acknowledged"`
+	Pending            int                       `json:"pending"`
+	Departments        []SafetyAckDepartmentResp `json:"departments"`
+	Employees          []SafetyAckEmployeeResp   `json:"employees"`
+}
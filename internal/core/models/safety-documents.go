@@ -8,6 +8,9 @@ type CreateSafetyDocumentRequest struct {
 	Category           string `json:"category"`
 	Department         string `json:"department"`
 	FileName           string `json:"file_name"`
+	OwnerEmpCode       string `json:"owner_emp_code"`
+	NextReviewDate     string `json:"next_review_date"` // YYYY-MM-DD
+	ExpiryDate         string `json:"expiry_date"`      // YYYY-MM-DD
 }
 
 type UpdateSafetyDocumentRequest struct {
@@ -16,17 +19,26 @@ type UpdateSafetyDocumentRequest struct {
 	Category           string `json:"category"`
 	Department         string `json:"department"`
 	FileName           string `json:"file_name"`
+	OwnerEmpCode       string `json:"owner_emp_code"`
+	NextReviewDate     string `json:"next_review_date"` // YYYY-MM-DD
+	ExpiryDate         string `json:"expiry_date"`      // YYYY-MM-DD
 }
 
 type SafetyDocumentResponse struct {
-	SafetyDocumentID   int       `json:"safety_document_id"`
-	SafetyDocumentName string    `json:"safety_document_name"`
-	SafetyDocumentDesc string    `json:"safety_document_desc"`
-	Category           string    `json:"category"`
-	Department         string    `json:"department"`
-	FileName           string    `json:"file_name"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	SafetyDocumentID   int                     `json:"safety_document_id"`
+	SafetyDocumentName string                  `json:"safety_document_name"`
+	SafetyDocumentDesc string                  `json:"safety_document_desc"`
+	Category           string                  `json:"category"`
+	Department         string                  `json:"department"`
+	FileName           string                  `json:"file_name"`
+	Thumbnails         *ThumbnailURLs          `json:"thumbnails,omitempty"`
+	FileSHA256         string                  `json:"file_sha256,omitempty"`
+	OwnerEmpCode       string                  `json:"owner_emp_code"`
+	NextReviewDate     *string                 `json:"next_review_date"`
+	ExpiryDate         *string                 `json:"expiry_date"`
+	CreatedAt          time.Time               `json:"created_at"`
+	UpdatedAt          time.Time               `json:"updated_at"`
+	Matches            []DocumentTextMatchResp `json:"matches,omitempty"` // pages of the file that matched a search
 }
 
 type SafetyDocumentListResponse struct {
@@ -36,3 +48,28 @@ type SafetyDocumentListResponse struct {
 	PageSize   int                      `json:"page_size"`
 	TotalPages int                      `json:"total_pages"`
 }
+
+// SafetyReviewItemResp is one review or expiry date that is overdue or coming up
+type SafetyReviewItemResp struct {
+	SafetyDocumentID   int    `json:"safety_document_id"`
+	SafetyDocumentName string `json:"safety_document_name"`
+	Category           string `json:"category"`
+	OwnerEmpCode       string `json:"owner_emp_code"`
+	DueType            string `json:"due_type"` // review, expiry
+	DueDate            string `json:"due_date"`
+	DaysLeft           int    `json:"days_left"` // negative when overdue
+}
+
+type SafetyReviewDepartmentResp struct {
+	Department string                 `json:"department"`
+	Overdue    []SafetyReviewItemResp `json:"overdue"`
+	Upcoming   []SafetyReviewItemResp `json:"upcoming"`
+}
+
+type SafetyReviewStatusResp struct {
+	AsOf          string                       `json:"as_of"`
+	Days          int                          `json:"days"`
+	TotalOverdue  int                          `json:"total_overdue"`
+	TotalUpcoming int                          `json:"total_upcoming"`
+	Departments   []SafetyReviewDepartmentResp `json:"departments"`
+}
@@ -0,0 +1,51 @@
+package models
+
+// UploadGCFolderStats is the disk usage of one uploads folder. Unmanaged folders have no table the collector
+// can check references against, so their files are only counted.
+type UploadGCFolderStats struct {
+	Folder          string `json:"folder"`
+	Managed         bool   `json:"managed"`
+	Files           int    `json:"files"`
+	Bytes           int64  `json:"bytes"`
+	ReferencedFiles int    `json:"referenced_files"`
+	ReferencedBytes int64  `json:"referenced_bytes"`
+	OrphanFiles     int    `json:"orphan_files"`
+	OrphanBytes     int64  `json:"orphan_bytes"`
+	DeletedFiles    int    `json:"deleted_files"`
+	DeletedBytes    int64  `json:"deleted_bytes"`
+}
+
+// UploadGCOrphan is a stored file no record refers to; Due is set once its grace period is over
+type UploadGCOrphan struct {
+	Key         string `json:"key"`
+	Folder      string `json:"folder"`
+	Size        int64  `json:"size"`
+	FirstSeenAt string `json:"first_seen_at"`
+	DeleteAfter string `json:"delete_after"`
+	Due         bool   `json:"due"`
+	Deleted     bool   `json:"deleted"`
+}
+
+// UploadGCBlobStats covers the content-addressed blobs behind the stored files
+type UploadGCBlobStats struct {
+	Blobs             int64 `json:"blobs"`
+	Bytes             int64 `json:"bytes"`
+	UnreferencedBlobs int64 `json:"unreferenced_blobs"`
+	UnreferencedBytes int64 `json:"unreferenced_bytes"`
+	DeletedBlobs      int   `json:"deleted_blobs"`
+	DeletedBytes      int64 `json:"deleted_bytes"`
+}
+
+type UploadGCReport struct {
+	DryRun           bool                  `json:"dry_run"`
+	GraceDays        int                   `json:"grace_days"`
+	Folders          []UploadGCFolderStats `json:"folders"`
+	Orphans          []UploadGCOrphan      `json:"orphans"`
+	OrphansTruncated bool                  `json:"orphans_truncated"`
+	DeletedFiles     int                   `json:"deleted_files"`
+	DeletedBytes     int64                 `json:"deleted_bytes"`
+	FailedFiles      int                   `json:"failed_files"`
+	Blobs            *UploadGCBlobStats    `json:"blobs,omitempty"`
+	StartedAt        string                `json:"started_at"`
+	FinishedAt       string                `json:"finished_at"`
+}
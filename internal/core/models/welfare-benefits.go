@@ -19,14 +19,17 @@ type UpdateWelfareBenefitRequest struct {
 }
 
 type WelfareBenefitResponse struct {
-	WelfareBenefitID int       `json:"welfare_benefit_id"`
-	Title            string    `json:"title"`
-	Description      string    `json:"description"`
-	Category         string    `json:"category"`
-	ImageURL         string    `json:"image_url"`
-	FileName         string    `json:"file_name"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	WelfareBenefitID int            `json:"welfare_benefit_id"`
+	Title            string         `json:"title"`
+	Description      string         `json:"description"`
+	Category         string         `json:"category"`
+	ImageURL         string         `json:"image_url"`
+	Thumbnails       *ThumbnailURLs `json:"thumbnails,omitempty"`
+	FileSHA256       string         `json:"file_sha256,omitempty"`
+	ImageSHA256      string         `json:"image_sha256,omitempty"`
+	FileName         string         `json:"file_name"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
 type WelfareBenefitListResponse struct {
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"time"
+
+	"backend/internal/core/domains"
+)
+
+type DocumentAccessRepository interface {
+	CreateDocumentAccessLog(entry *domains.DocumentAccessLog) error
+	GetDocumentAccessStat(module domains.DocumentModule, documentID int) (*domains.DocumentAccessStat, error)
+	GetMostViewedDocuments(module domains.DocumentModule, from, to time.Time, limit int) ([]domains.DocumentAccessStat, error)
+	GetUnopenedDocuments(module domains.DocumentModule, since time.Time) ([]domains.DocumentAccessStat, error)
+}
@@ -0,0 +1,8 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type DocumentImportRepository interface {
+	GetLiveDocumentIDs(module domains.DocumentModule, ids []int) ([]int, error)
+	CreateImportedDocuments(module string, docs []domains.DocumentImport) ([]int, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type DocumentRevisionRepository interface {
+	GetDocumentFileName(module domains.DocumentModule, documentID int) (string, error)
+	GetQmsDocumentStatus(documentID int) (string, error)
+	CountDocumentRevisions(module string, documentID int) (int64, error)
+	CreateDocumentRevision(revision *domains.DocumentRevision) error
+	GetDocumentRevisions(module string, documentID int) ([]domains.DocumentRevision, error)
+	GetDocumentRevision(module string, documentID, revisionNo int) (domains.DocumentRevision, error)
+	SetCurrentDocumentRevision(module domains.DocumentModule, documentID, revisionNo int) error
+}
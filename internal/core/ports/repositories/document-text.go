@@ -0,0 +1,10 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type DocumentTextRepository interface {
+	ReplaceDocumentText(module string, documentID int, pages []domains.DocumentTextPage) error
+	GetDocumentFiles(module domains.DocumentModule, documentID int) ([]domains.DocumentFile, error)
+	FindDocumentIDByFileName(module domains.DocumentModule, fileName string) (int, error)
+	GetMatchingTextPages(module string, documentIDs []int, keyword string) ([]domains.DocumentTextPage, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"time"
+
+	"backend/internal/core/domains"
+)
+
+type EmailOutboxRepository interface {
+	CreateEmail(email *domains.EmailOutbox) error
+	GetDueEmails(now time.Time, limit int) ([]domains.EmailOutbox, error)
+	ClaimEmail(id int) (bool, error)
+	MarkEmailSent(id int, sentAt time.Time) error
+	MarkEmailFailed(id int, attempts int, status string, nextAttemptAt time.Time, lastError string) error
+	ResetStaleSending(olderThan time.Time) (int64, error)
+}
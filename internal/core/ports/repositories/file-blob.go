@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/storage"
+)
+
+// FileBlobRepository is the index of the content-addressed storage, plus the queries of the integrity check and the garbage collector
+type FileBlobRepository interface {
+	storage.Index
+	GetFileBlobs(afterSHA256 string, limit int) ([]domains.FileBlob, error)
+	GetFileLinkKeys(sha256 string, limit int) ([]string, error)
+	MarkFileBlobVerified(sha256 string, at time.Time) error
+	GetFileBlobStats() (domains.FileBlobStats, error)
+	GetUnreferencedFileBlobs(before time.Time, limit int) ([]domains.FileBlob, error)
+	DeleteUnreferencedFileBlob(sha256 string, before time.Time) (bool, error)
+	FindFileBlobs(sha256s []string) ([]string, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type FileRepository interface {
+	GetDocumentFile(module domains.DocumentModule, documentID int) (domains.DocumentFile, error)
+	FindDocumentIDByFile(module domains.DocumentModule, fileNames []string) (int, error)
+	GetDocumentExportRows(module domains.DocumentModule, filter domains.DocumentExportFilter) ([]domains.DocumentExportRow, error)
+	GetCompanyNewsAttachment(attachmentID int) (domains.CompanyNewsAttachment, error)
+	FindCompanyNewsIDByFile(fileNames []string, fileName string) (string, error)
+}
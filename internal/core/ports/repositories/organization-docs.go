@@ -9,5 +9,5 @@ type OrganizationDocRepository interface {
 	GetOrganizationDocsByDepartment(department string, page, pageSize int) ([]domains.OrganizationDoc, int64, error)
 	UpdateOrganizationDoc(id int, updates map[string]interface{}) error
 	DeleteOrganizationDoc(id int) error
-	SearchOrganizationDocs(keyword string, page, pageSize int) ([]domains.OrganizationDoc, int64, error)
+	SearchOrganizationDocs(search domains.DocumentSearch, page, pageSize int) ([]domains.OrganizationDoc, int64, error)
 }
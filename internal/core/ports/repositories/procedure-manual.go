@@ -9,5 +9,5 @@ type ProcedureManualRepository interface {
 	GetProcedureManualsByCategory(category string, page, pageSize int) ([]domains.ProcedureManual, int64, error)
 	UpdateProcedureManual(id int, updates map[string]interface{}) error
 	DeleteProcedureManual(id int) error
-	SearchProcedureManuals(keyword string, page, pageSize int) ([]domains.ProcedureManual, int64, error)
+	SearchProcedureManuals(search domains.DocumentSearch, page, pageSize int) ([]domains.ProcedureManual, int64, error)
 }
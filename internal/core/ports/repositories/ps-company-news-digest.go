@@ -0,0 +1,13 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type CompanyNewsDigestRepository interface {
+	GetDigestSubscription(empCode string) (domains.CompanyNewsDigestSubscription, error)
+	SaveDigestSubscription(subscription *domains.CompanyNewsDigestSubscription) error
+	UnsubscribeDigestByToken(token string) (bool, error)
+	GetDigestRecipients() ([]domains.CompanyNewsDigestRecipient, error)
+	GetDigestRecipient(empCode string) (domains.CompanyNewsDigestRecipient, error)
+	CreateDigestRun(run *domains.CompanyNewsDigestRun) error
+	GetLastDigestRun() (*domains.CompanyNewsDigestRun, error)
+}
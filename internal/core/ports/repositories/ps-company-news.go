@@ -1,14 +1,39 @@
 package ports
 
-import "backend/internal/core/domains"
+import (
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+)
 
 type CompanyNewsRepository interface {
-	CreateCompanyNews(companyNews *domains.CompanyNews) error
-	GetCompanyNewsByID(companyNewsID string) (domains.CompanyNews, error)
+	CreateCompanyNews(companyNews *domains.CompanyNews, audiences []domains.CompanyNewsAudience) error
+	GetCompanyNewsByID(companyNewsID string, viewer domains.NewsViewer) (domains.CompanyNews, error)
 	GetAllCompanyNews() ([]domains.CompanyNews, error)
-	GetCompanyNews(limit, offset int) ([]domains.CompanyNews, int64, error)
+	GetCompanyNews(viewer domains.NewsViewer, limit, offset int) ([]domains.CompanyNews, int64, error)
 	UpdateCompanyNewsWithMap(companyNewsID string, updates map[string]interface{}) error
+	UpdateCompanyNewsWithAudiences(companyNewsID string, updates map[string]interface{}, audiences []domains.CompanyNewsAudience) error
 	GetCompanyNewsCount() (int64, error)
-	GetCompanyNewsByTitle(title string) (domains.CompanyNews, error)
+	GetCompanyNewsByTitle(title string, viewer domains.NewsViewer) (domains.CompanyNews, error)
 	DeleteCompanyNews(companyNewsID string) error
+	SearchCompanyNews(search domains.CompanyNewsSearch) ([]domains.CompanyNewsSearchHit, int64, error)
+
+	// ====================== Audience ===================================
+	GetCompanyNewsAudiences(companyNewsID string) ([]domains.CompanyNewsAudience, error)
+	CountAudienceEmployees(audiences []domains.CompanyNewsAudience) ([]models.CompanyNewsAudienceDepartmentCount, error)
+	GetAudienceEmployees(audiences []domains.CompanyNewsAudience, limit int) ([]domains.PSEmployee, error)
+
+	// ====================== Attachment ===================================
+	CreateCompanyNewsAttachments(attachments []domains.CompanyNewsAttachment) error
+	GetCompanyNewsAttachments(companyNewsID string) ([]domains.CompanyNewsAttachment, error)
+	GetCompanyNewsAttachmentByID(attachmentID int) (domains.CompanyNewsAttachment, error)
+	UpdateCompanyNewsAttachmentWithMap(attachmentID int, updates map[string]interface{}) error
+	ReorderCompanyNewsAttachments(companyNewsID string, attachmentIDs []int) error
+	DeleteCompanyNewsAttachment(attachmentID int) error
+
+	// ====================== Revision ===================================
+	CreateCompanyNewsRevision(revision *domains.CompanyNewsRevision) error
+	CountCompanyNewsRevisions(companyNewsID string) (int64, error)
+	GetCompanyNewsRevisions(companyNewsID string) ([]domains.CompanyNewsRevision, error)
+	GetCompanyNewsRevision(companyNewsID string, revisionNo int) (domains.CompanyNewsRevision, error)
+	UndeleteCompanyNews(companyNewsID string) error
 }
@@ -0,0 +1,14 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type SafetyAcknowledgementRepository interface {
+	CreateAckRequirement(requirement *domains.SafetyAckRequirement, targets []domains.SafetyAckTarget) error
+	CloseAckRequirement(safetyDocumentID int) error
+	GetActiveAckRequirement(safetyDocumentID int) (*domains.SafetyAckRequirement, []domains.SafetyAckTarget, error)
+	IsAckTargeted(requirementID int, empCode string) (bool, error)
+	GetAcknowledgement(requirementID int, empCode string) (*domains.SafetyAcknowledgement, error)
+	CreateAcknowledgement(ack *domains.SafetyAcknowledgement) error
+	GetAckEmployeeStatuses(requirementID int, department string) ([]domains.SafetyAckEmployeeStatus, error)
+	GetPendingAcknowledgements(empCode string) ([]domains.SafetyAckPending, error)
+}
@@ -1,6 +1,10 @@
 package ports
 
-import "backend/internal/core/domains"
+import (
+	"time"
+
+	"backend/internal/core/domains"
+)
 
 type SafetyDocumentRepository interface {
 	CreateSafetyDocument(doc *domains.SafetyDocument) error
@@ -10,5 +14,9 @@ type SafetyDocumentRepository interface {
 	GetSafetyDocumentsByDepartment(department string, page, pageSize int) ([]domains.SafetyDocument, int64, error)
 	UpdateSafetyDocument(id int, updates map[string]interface{}) error
 	DeleteSafetyDocument(id int) error
-	SearchSafetyDocuments(keyword string, page, pageSize int) ([]domains.SafetyDocument, int64, error)
+	SearchSafetyDocuments(search domains.DocumentSearch, page, pageSize int) ([]domains.SafetyDocument, int64, error)
+	GetSafetyDocumentsDue(before time.Time, department string) ([]domains.SafetyDocument, error)
+	HasSafetyDocumentReminder(id int, kind string, dueDate time.Time) (bool, error)
+	CreateSafetyDocumentReminder(reminder *domains.SafetyDocumentReminder) error
+	GetSafetyReminderRecipients(ownerEmpCode, role string) ([]domains.PSEmployee, error)
 }
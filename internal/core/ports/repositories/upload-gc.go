@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"time"
+
+	"backend/internal/core/domains"
+)
+
+type UploadGCRepository interface {
+	GetUploadReferences() ([]string, error)
+	IsCompanyNewsContentFile(fileName string) (bool, error)
+	GetOrphanUploads() ([]domains.OrphanUpload, error)
+	CreateOrphanUpload(orphan *domains.OrphanUpload) error
+	UpdateOrphanUploadSeen(key string, size int64, at time.Time) error
+	DeleteOrphanUploads(keys []string) error
+}
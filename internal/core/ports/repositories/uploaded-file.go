@@ -0,0 +1,9 @@
+package ports
+
+import "backend/internal/core/domains"
+
+type UploadedFileRepository interface {
+	CreateUploadedFile(file *domains.UploadedFile) error
+	GetUploadedFiles(query domains.UploadedFileQuery) ([]domains.UploadedFile, int64, error)
+	GetUploadScanAlertRecipients(role string) ([]domains.PSEmployee, error)
+}
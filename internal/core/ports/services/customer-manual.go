@@ -14,5 +14,5 @@ type CustomerManualService interface {
 	GetCustomerManualByIDService(id int) (*models.CustomerManualResponse, error)
 	UpdateCustomerManualService(id int, req models.UpdateCustomerManualRequest) error
 	DeleteCustomerManualService(id int) error
-	SearchCustomerManualService(keyword string, limit, offset int) (models.CustomerManualListResponse, error)
+	SearchCustomerManualService(req models.DocumentSearchRequest) (models.CustomerManualListResponse, error)
 }
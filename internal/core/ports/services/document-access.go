@@ -0,0 +1,11 @@
+package ports
+
+import "backend/internal/core/models"
+
+type DocumentAccessService interface {
+	LogDocumentAccess(module string, documentID int, action, empCode, ip string) error
+	GetDocumentAccessStat(module string, documentID int) (models.DocumentAccessStatResp, error)
+	GetMostViewedThisMonth(module string, limit int) (models.DocumentMostViewedResp, error)
+	GetUnopenedDocuments(module string, months int) (models.DocumentUnopenedResp, error)
+	GetUnopenedDocumentsCSV(module string, months int) (data []byte, fileName string, err error)
+}
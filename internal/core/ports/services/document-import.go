@@ -0,0 +1,8 @@
+package ports
+
+import "backend/internal/core/models"
+
+type DocumentImportService interface {
+	PrepareDocumentImport(module string, rows [][]string, empCode string, checkFile func(name string) error) (models.DocumentImportReport, error)
+	ImportDocuments(module string, report *models.DocumentImportReport) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "backend/internal/core/models"
+
+type DocumentRevisionService interface {
+	EnsureBaselineRevision(module string, documentID int) error
+	RecordRevision(module string, documentID int, fileName string, req models.DocumentRevisionReq) (models.DocumentRevisionResp, error)
+	GetRevisions(module string, documentID int) ([]models.DocumentRevisionResp, error)
+	SetCurrentRevision(module string, documentID, revisionNo int, role string) error
+	GetRevisionFile(module string, documentID, revisionNo int) (path, downloadName string, err error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "backend/internal/core/models"
+
+type DocumentTextService interface {
+	IndexDocument(module string, documentID int) error
+	IndexUploadedFile(module, fileName string) error
+	RebuildIndex(module string) (int, error)
+	GetPageMatches(module string, documentIDs []int, keyword string) (map[int][]models.DocumentTextMatchResp, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+)
+
+type FileService interface {
+	ResolveFile(ref domains.FileRef, viewer domains.FileViewer) (domains.ServedFile, error)
+	CheckDocumentAccess(module string, documentID int, viewer domains.FileViewer) error
+	SignFileURL(ref domains.FileRef, viewer domains.FileViewer, baseURL string, ttl time.Duration) (models.SignedFileURLResp, error)
+	RebuildThumbnails() (int, error)
+	GetUploadedFiles(scanStatus string, page, pageSize int) (models.UploadedFileListResp, error)
+	CheckFileIntegrity() (models.FileIntegrityReport, error)
+	ExportDocuments(module string, req models.DocumentExportRequest, viewer domains.FileViewer) (domains.DocumentExport, error)
+	WriteDocumentExport(ctx context.Context, w io.Writer, export domains.DocumentExport) error
+}
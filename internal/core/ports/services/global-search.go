@@ -0,0 +1,7 @@
+package ports
+
+import "backend/internal/core/models"
+
+type GlobalSearchService interface {
+	Search(req models.GlobalSearchReq) (models.GlobalSearchResp, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/mail"
+)
+
+type MailService interface {
+	Enqueue(msg mail.Message) (*domains.EmailOutbox, error)
+	EnqueueTemplate(to, cc []string, name, lang string, data interface{}) (*domains.EmailOutbox, error)
+	EnqueueTemplateMessage(msg mail.Message, name, lang string, data interface{}) (*domains.EmailOutbox, error)
+	StartOutboxWorker(ctx context.Context)
+	ProcessOutbox(ctx context.Context)
+}
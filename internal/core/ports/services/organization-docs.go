@@ -9,5 +9,5 @@ type OrganizationDocService interface {
 	GetOrganizationDocByDepartmentService(department string, page, pageSize int) (*models.OrganizationDocListResponse, error)
 	UpdateOrganizationDocService(id int, req models.UpdateOrganizationDocRequest) error
 	DeleteOrganizationDocService(id int) error
-	SearchOrganizationDocService(keyword string, page, pageSize int) (*models.OrganizationDocListResponse, error)
+	SearchOrganizationDocService(req models.DocumentSearchRequest) (*models.OrganizationDocListResponse, error)
 }
@@ -9,5 +9,5 @@ type ProcedureManualService interface {
 	GetProcedureManualByIDService(id int) (*models.ProcedureManualResponse, error)
 	UpdateProcedureManualService(id int, req models.UpdateProcedureManualRequest) error
 	DeleteProcedureManualService(id int) error
-	SearchProcedureManualService(keyword string, limit, offset int) (models.ProcedureManualListResponse, error)
+	SearchProcedureManualService(req models.DocumentSearchRequest) (models.ProcedureManualListResponse, error)
 }
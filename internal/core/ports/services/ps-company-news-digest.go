@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"backend/internal/core/models"
+	"backend/internal/pkgs/mail"
+)
+
+type CompanyNewsDigestService interface {
+	GetDigestSubscription(empCode string) (models.CompanyNewsDigestSubscriptionResp, error)
+	UpdateDigestSubscription(empCode string, req models.CompanyNewsDigestSubscriptionReq) (models.CompanyNewsDigestSubscriptionResp, error)
+	UnsubscribeDigest(token string) error
+	PreviewDigest(empCode, lang string, days int, baseURL string) (mail.Rendered, error)
+	SendDigestNow(days int, baseURL, actor string) (models.CompanyNewsDigestSendResp, error)
+	StartDigestScheduler(ctx context.Context)
+}
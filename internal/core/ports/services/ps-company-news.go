@@ -1,12 +1,33 @@
 package ports
 
-import "backend/internal/core/models"
+import (
+	"backend/internal/core/models"
+	"backend/internal/pkgs/feeds"
+)
 
 type CompanyNewsService interface {
-	CreateCompanyNewsService(req models.CompanyNewsResp) error
-	GetCompanyNews(limit, offset int) (models.CompanyNewsListResp, error)
-	GetCompanyNewsByTitle(title string) (models.CompanyNewsReq, error)
-	GetCompanyNewsByID(id string) (models.CompanyNewsReq, error)
-	UpdateCompanyNewsService(companyNewsID string, req models.CompanyNewsResp) error
-	DeleteCompanyNewsService(companyNewsID string) error
+	CreateCompanyNewsService(req models.CompanyNewsResp, actor string) error
+	GetCompanyNews(empCode, role string, limit, offset int) (models.CompanyNewsListResp, error)
+	GetCompanyNewsByTitle(title, empCode, role string) (models.CompanyNewsReq, error)
+	GetCompanyNewsByID(id, empCode, role string) (models.CompanyNewsReq, error)
+	UpdateCompanyNewsService(companyNewsID string, req models.CompanyNewsResp, actor string) error
+	DeleteCompanyNewsService(companyNewsID, actor string) error
+	SearchCompanyNews(req models.CompanyNewsSearchReq) (models.CompanyNewsSearchResp, error)
+	GetCompanyNewsFeed(category, baseURL string) (feeds.Feed, error)
+	PreviewCompanyNewsAudience(audiences []models.CompanyNewsAudienceReq) (models.CompanyNewsAudiencePreviewResp, error)
+	PreviewCompanyNewsAudienceByID(companyNewsID string) (models.CompanyNewsAudiencePreviewResp, error)
+
+	// ====================== Attachment ===================================
+	AddCompanyNewsAttachments(companyNewsID, usernameCreator string, uploads []models.CompanyNewsAttachmentUpload) ([]models.CompanyNewsAttachmentResp, error)
+	GetCompanyNewsAttachments(companyNewsID, empCode, role string) ([]models.CompanyNewsAttachmentResp, error)
+	UpdateCompanyNewsAttachment(attachmentID int, req models.CompanyNewsAttachmentUpdateReq) error
+	ReorderCompanyNewsAttachments(companyNewsID string, attachmentIDs []int) error
+	DeleteCompanyNewsAttachment(attachmentID int) error
+
+	// ====================== Revision ===================================
+	GetCompanyNewsRevisions(companyNewsID string) ([]models.CompanyNewsRevisionResp, error)
+	GetCompanyNewsRevision(companyNewsID string, revisionNo int) (models.CompanyNewsRevisionResp, error)
+	DiffCompanyNewsRevisions(companyNewsID string, from, to int) (models.CompanyNewsRevisionDiffResp, error)
+	RestoreCompanyNewsRevision(companyNewsID string, revisionNo int, actor string) error
+	UndeleteCompanyNews(companyNewsID, actor string) error
 }
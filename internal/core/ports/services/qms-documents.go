@@ -4,9 +4,15 @@ import "backend/internal/core/models"
 
 type QmsDocumentsService interface {
 	CreateQmsDocumentService(req models.CreateQmsDocumentRequest) error
-	GetAllQmsDocumentService(limit, offset int) (models.QmsDocumentListResponse, error)
+	GetAllQmsDocumentService(limit, offset int, role, status string) (models.QmsDocumentListResponse, error)
 	GetQmsDocumentByIDService(id int) (*models.QmsDocumentResponse, error)
 	UpdateQmsDocumentService(id int, req models.UpdateQmsDocumentRequest) error
 	DeleteQmsDocumentService(id int) error
-	SearchQmsDocumentService(keyword string, limit, offset int) (models.QmsDocumentListResponse, error)
+	SearchQmsDocumentService(req models.DocumentSearchRequest, role, status string) (models.QmsDocumentListResponse, error)
+
+	// ====================== Document control ===================================
+	TransitionQmsDocumentService(id int, action, empCode, role string, req models.QmsDocumentWorkflowRequest) (*models.QmsDocumentResponse, error)
+	GetQmsDocumentApprovalsService(id int, empCode, role string) ([]models.QmsDocumentApprovalResponse, error)
+	GetQmsDocumentTasksService(empCode string) ([]models.QmsDocumentResponse, error)
+	GetQmsDocumentsReviewDueService(days int) ([]models.QmsDocumentResponse, error)
 }
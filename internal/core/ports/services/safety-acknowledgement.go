@@ -0,0 +1,13 @@
+package ports
+
+import "backend/internal/core/models"
+
+type SafetyAcknowledgementService interface {
+	SetAckRequirement(safetyDocumentID int, req models.SafetyAckRequirementReq, actor string) (models.SafetyAckRequirementResp, error)
+	GetAckRequirement(safetyDocumentID int) (*models.SafetyAckRequirementResp, error)
+	CloseAckRequirement(safetyDocumentID int) error
+	Acknowledge(safetyDocumentID int, empCode string) (models.SafetyAcknowledgementResp, error)
+	GetPendingAcknowledgements(empCode, baseURL string) ([]models.SafetyAckPendingResp, error)
+	GetAckReport(safetyDocumentID int, department string) (models.SafetyAckReportResp, error)
+	GetAckReportCSV(safetyDocumentID int, department, view string) (data []byte, fileName string, err error)
+}
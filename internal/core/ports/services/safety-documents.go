@@ -10,5 +10,6 @@ type SafetyDocumentService interface {
 	GetSafetyDocumentByDepartmentService(department string, page, pageSize int) (*models.SafetyDocumentListResponse, error)
 	UpdateSafetyDocumentService(id int, req models.UpdateSafetyDocumentRequest) error
 	DeleteSafetyDocumentService(id int) error
-	SearchSafetyDocumentService(keyword string, page, pageSize int) (*models.SafetyDocumentListResponse, error)
+	SearchSafetyDocumentService(req models.DocumentSearchRequest) (*models.SafetyDocumentListResponse, error)
+	GetSafetyReviewStatusService(days int, department string) (*models.SafetyReviewStatusResp, error)
 }
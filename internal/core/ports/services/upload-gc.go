@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"backend/internal/core/models"
+)
+
+type UploadGCService interface {
+	CollectOrphanUploads(ctx context.Context, dryRun bool) (models.UploadGCReport, error)
+}
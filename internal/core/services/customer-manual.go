@@ -7,15 +7,17 @@ import (
 
 	"backend/internal/core/domains"
 	"backend/internal/core/models"
+	portServices "backend/internal/core/ports/services"
 	"backend/internal/repositories"
 )
 
 type CustomerManualService struct {
-	repo *repositories.CustomerManualRepository
+	repo    *repositories.CustomerManualRepository
+	textSrv portServices.DocumentTextService
 }
 
-func NewCustomerManualService(repo *repositories.CustomerManualRepository) *CustomerManualService {
-	return &CustomerManualService{repo: repo}
+func NewCustomerManualService(repo *repositories.CustomerManualRepository, textSrv portServices.DocumentTextService) *CustomerManualService {
+	return &CustomerManualService{repo: repo, textSrv: textSrv}
 }
 
 // CreateCustomerManual creates a new customer manual
@@ -45,12 +47,15 @@ func (s *CustomerManualService) CreateCustomerManual(req *models.CreateCustomerM
 		return nil, err
 	}
 
+	files := lookupStoredFiles(manual.FileName)
 	return &models.CustomerManualResponse{
 		CustomerManualID:   manual.CustomerManualID,
 		CustomerManualName: manual.CustomerManualName,
 		Desc:               manual.Desc,
 		Category:           manual.Category,
 		FileName:           manual.FileName,
+		Thumbnails:         files.thumbnails(manual.FileName),
+		FileSHA256:         files.sha256(manual.FileName),
 		CreatedAt:          manual.CreatedAt,
 		UpdatedAt:          manual.UpdatedAt,
 	}, nil
@@ -63,12 +68,15 @@ func (s *CustomerManualService) GetCustomerManualByID(id int) (*models.CustomerM
 		return nil, err
 	}
 
+	files := lookupStoredFiles(manual.FileName)
 	return &models.CustomerManualResponse{
 		CustomerManualID:   manual.CustomerManualID,
 		CustomerManualName: manual.CustomerManualName,
 		Desc:               manual.Desc,
 		Category:           manual.Category,
 		FileName:           manual.FileName,
+		Thumbnails:         files.thumbnails(manual.FileName),
+		FileSHA256:         files.sha256(manual.FileName),
 		CreatedAt:          manual.CreatedAt,
 		UpdatedAt:          manual.UpdatedAt,
 	}, nil
@@ -96,6 +104,7 @@ func (s *CustomerManualService) GetAllCustomerManuals(page, pageSize int) (*mode
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	files := customerManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.CustomerManualResponse{
 			CustomerManualID:   manual.CustomerManualID,
@@ -103,6 +112,8 @@ func (s *CustomerManualService) GetAllCustomerManuals(page, pageSize int) (*mode
 			Desc:               manual.Desc,
 			Category:           manual.Category,
 			FileName:           manual.FileName,
+			Thumbnails:         files.thumbnails(manual.FileName),
+			FileSHA256:         files.sha256(manual.FileName),
 			ParentID:           manual.ParentID,
 			SortOrder:          manual.SortOrder,
 			CreatedAt:          manual.CreatedAt,
@@ -151,6 +162,7 @@ func (s *CustomerManualService) GetCustomerManualsByCategory(category string, pa
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	files := customerManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.CustomerManualResponse{
 			CustomerManualID:   manual.CustomerManualID,
@@ -158,6 +170,8 @@ func (s *CustomerManualService) GetCustomerManualsByCategory(category string, pa
 			Desc:               manual.Desc,
 			Category:           manual.Category,
 			FileName:           manual.FileName,
+			Thumbnails:         files.thumbnails(manual.FileName),
+			FileSHA256:         files.sha256(manual.FileName),
 			CreatedAt:          manual.CreatedAt,
 			UpdatedAt:          manual.UpdatedAt,
 		}
@@ -214,12 +228,15 @@ func (s *CustomerManualService) UpdateCustomerManual(id int, req *models.UpdateC
 		return nil, err
 	}
 
+	files := lookupStoredFiles(updatedManual.FileName)
 	return &models.CustomerManualResponse{
 		CustomerManualID:   updatedManual.CustomerManualID,
 		CustomerManualName: updatedManual.CustomerManualName,
 		Desc:               updatedManual.Desc,
 		Category:           updatedManual.Category,
 		FileName:           updatedManual.FileName,
+		Thumbnails:         files.thumbnails(updatedManual.FileName),
+		FileSHA256:         files.sha256(updatedManual.FileName),
 		CreatedAt:          updatedManual.CreatedAt,
 		UpdatedAt:          updatedManual.UpdatedAt,
 	}, nil
@@ -230,20 +247,19 @@ func (s *CustomerManualService) DeleteCustomerManual(id int) error {
 	return s.repo.DeleteCustomerManual(id)
 }
 
-// SearchCustomerManuals searches customer manuals by keyword
-func (s *CustomerManualService) SearchCustomerManuals(keyword string, page, pageSize int) (*models.CustomerManualListResponse, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 50
-	}
-
-	manuals, total, err := s.repo.SearchCustomerManuals(keyword, page, pageSize)
+// SearchCustomerManuals searches customer manuals by keyword, including the text of their files
+func (s *CustomerManualService) SearchCustomerManuals(search domains.DocumentSearch, page, pageSize int) (*models.CustomerManualListResponse, error) {
+	manuals, total, err := s.repo.SearchCustomerManuals(search, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
 
+	ids := make([]int, len(manuals))
+	for i, manual := range manuals {
+		ids[i] = manual.CustomerManualID
+	}
+	matches := documentPageMatches(s.textSrv, domains.DocModuleCustomerManual, ids, search.Keyword)
+
 	resp := &models.CustomerManualListResponse{
 		Data:       make([]models.CustomerManualResponse, len(manuals)),
 		Total:      total,
@@ -252,6 +268,7 @@ func (s *CustomerManualService) SearchCustomerManuals(keyword string, page, page
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	files := customerManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.CustomerManualResponse{
 			CustomerManualID:   manual.CustomerManualID,
@@ -259,8 +276,13 @@ func (s *CustomerManualService) SearchCustomerManuals(keyword string, page, page
 			Desc:               manual.Desc,
 			Category:           manual.Category,
 			FileName:           manual.FileName,
+			Thumbnails:         files.thumbnails(manual.FileName),
+			FileSHA256:         files.sha256(manual.FileName),
 			CreatedAt:          manual.CreatedAt,
+			ParentID:           manual.ParentID,
+			SortOrder:          manual.SortOrder,
 			UpdatedAt:          manual.UpdatedAt,
+			Matches:            matches[manual.CustomerManualID],
 		}
 	}
 
@@ -315,11 +337,23 @@ func (s *CustomerManualService) DeleteCustomerManualService(id int) error {
 }
 
 // SearchCustomerManualService implements the port interface
-func (s *CustomerManualService) SearchCustomerManualService(keyword string, limit, offset int) (models.CustomerManualListResponse, error) {
-	page := offset/limit + 1
-	resp, err := s.SearchCustomerManuals(keyword, page, limit)
+func (s *CustomerManualService) SearchCustomerManualService(req models.DocumentSearchRequest) (models.CustomerManualListResponse, error) {
+	search, limit, offset, err := documentSearch(domains.DocModuleCustomerManual, req)
+	if err != nil {
+		return models.CustomerManualListResponse{}, err
+	}
+	resp, err := s.SearchCustomerManuals(search, offset/limit+1, limit)
 	if err != nil {
 		return models.CustomerManualListResponse{}, err
 	}
 	return *resp, nil
 }
+
+// customerManualFiles looks up the stored files of a page of customer manuals
+func customerManualFiles(manuals ...domains.CustomerManual) storedFiles {
+	names := make([]string, len(manuals))
+	for i, manual := range manuals {
+		names[i] = manual.FileName
+	}
+	return lookupStoredFiles(names...)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/errs"
+)
+
+const (
+	defaultMostViewedLimit = 10
+	maxMostViewedLimit     = 100
+
+	defaultUnopenedMonths = 6
+	maxUnopenedMonths     = 120
+)
+
+type DocumentAccessService struct {
+	accessRepo ports.DocumentAccessRepository
+}
+
+func NewDocumentAccessService(accessRepo ports.DocumentAccessRepository) *DocumentAccessService {
+	return &DocumentAccessService{accessRepo: accessRepo}
+}
+
+// LogDocumentAccess records one view or download of a document
+func (s *DocumentAccessService) LogDocumentAccess(module string, documentID int, action, empCode, ip string) error {
+	if _, err := documentModule(module); err != nil {
+		return err
+	}
+	if action != domains.DocAccessDownload {
+		action = domains.DocAccessView
+	}
+
+	return s.accessRepo.CreateDocumentAccessLog(&domains.DocumentAccessLog{
+		Module:     module,
+		DocumentID: documentID,
+		Action:     action,
+		EmpCode:    empCode,
+		IPAddress:  ip,
+		AccessedAt: time.Now(),
+	})
+}
+
+func (s *DocumentAccessService) GetDocumentAccessStat(module string, documentID int) (models.DocumentAccessStatResp, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return models.DocumentAccessStatResp{}, err
+	}
+
+	stat, err := s.accessRepo.GetDocumentAccessStat(mod, documentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DocumentAccessStatResp{}, errs.NewNotfoundError("document not found")
+		}
+		return models.DocumentAccessStatResp{}, err
+	}
+	return toDocumentAccessStatResp(*stat), nil
+}
+
+// GetMostViewedThisMonth ranks the module's documents by views and downloads since the 1st of the current month
+func (s *DocumentAccessService) GetMostViewedThisMonth(module string, limit int) (models.DocumentMostViewedResp, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return models.DocumentMostViewedResp{}, err
+	}
+	if limit <= 0 {
+		limit = defaultMostViewedLimit
+	}
+	if limit > maxMostViewedLimit {
+		limit = maxMostViewedLimit
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(0, 1, 0)
+
+	stats, err := s.accessRepo.GetMostViewedDocuments(mod, from, to, limit)
+	if err != nil {
+		return models.DocumentMostViewedResp{}, err
+	}
+	return models.DocumentMostViewedResp{
+		Module: mod.Name,
+		From:   from.Format("2006-01-02"),
+		To:     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Data:   toDocumentAccessStatResps(stats),
+	}, nil
+}
+
+// GetUnopenedDocuments lists documents older than the period that nobody opened during it, as retirement candidates
+func (s *DocumentAccessService) GetUnopenedDocuments(module string, months int) (models.DocumentUnopenedResp, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return models.DocumentUnopenedResp{}, err
+	}
+	if months <= 0 {
+		months = defaultUnopenedMonths
+	}
+	if months > maxUnopenedMonths {
+		return models.DocumentUnopenedResp{}, errs.NewError(fmt.Sprintf("months must be at most %d", maxUnopenedMonths))
+	}
+
+	since := time.Now().AddDate(0, -months, 0)
+	stats, err := s.accessRepo.GetUnopenedDocuments(mod, since)
+	if err != nil {
+		return models.DocumentUnopenedResp{}, err
+	}
+	return models.DocumentUnopenedResp{
+		Module: mod.Name,
+		Months: months,
+		Since:  since.Format("2006-01-02"),
+		Total:  len(stats),
+		Data:   toDocumentAccessStatResps(stats),
+	}, nil
+}
+
+// GetUnopenedDocumentsCSV exports the unopened documents report with a UTF-8 BOM for Excel
+func (s *DocumentAccessService) GetUnopenedDocumentsCSV(module string, months int) ([]byte, string, error) {
+	report, err := s.GetUnopenedDocuments(module, months)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"document_id", "name", "file_name", "created_at", "last_accessed_at", "views", "downloads", "unique_viewers"})
+	for _, d := range report.Data {
+		last := ""
+		if d.LastAccessedAt != nil {
+			last = *d.LastAccessedAt
+		}
+		_ = w.Write([]string{
+			strconv.Itoa(d.DocumentID),
+			d.Name,
+			d.FileName,
+			d.CreatedAt,
+			last,
+			strconv.FormatInt(d.Views, 10),
+			strconv.FormatInt(d.Downloads, 10),
+			strconv.FormatInt(d.UniqueViewers, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+
+	name := fmt.Sprintf("%s-unopened-%dm.csv", report.Module, report.Months)
+	return buf.Bytes(), name, nil
+}
+
+func toDocumentAccessStatResp(stat domains.DocumentAccessStat) models.DocumentAccessStatResp {
+	resp := models.DocumentAccessStatResp{
+		DocumentID:    stat.DocumentID,
+		Name:          stat.Name,
+		FileName:      stat.FileName,
+		Views:         stat.Views,
+		Downloads:     stat.Downloads,
+		Total:         stat.Views + stat.Downloads,
+		UniqueViewers: stat.UniqueViewers,
+		CreatedAt:     stat.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if stat.LastAccessedAt != nil {
+		last := stat.LastAccessedAt.Format("2006-01-02 15:04:05")
+		resp.LastAccessedAt = &last
+	}
+	return resp
+}
+
+func toDocumentAccessStatResps(stats []domains.DocumentAccessStat) []models.DocumentAccessStatResp {
+	resps := make([]models.DocumentAccessStatResp, 0, len(stats))
+	for _, stat := range stats {
+		resps = append(resps, toDocumentAccessStatResp(stat))
+	}
+	return resps
+}
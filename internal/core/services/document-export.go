@@ -0,0 +1,213 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/utils"
+)
+
+const documentExportManifest = "manifest.csv"
+
+// File status written to the export manifest
+const (
+	exportFileIncluded   = "included"
+	exportFileNoFile     = "no_file"
+	exportFileMissing    = "missing"
+	exportFileUnreadable = "unreadable"
+)
+
+// storedExtensions are already compressed, so they are stored in the archive as they are
+var storedExtensions = map[string]bool{
+	".pdf": true, ".zip": true, ".docx": true, ".xlsx": true, ".pptx": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".mp4": true,
+}
+
+// ExportDocuments selects the documents of a module to put into a ZIP and names their files in the archive:
+// <export>/<category or department>/<document name>.<ext>. By default only current documents are exported;
+// req.All adds the QMS documents the viewer may read that are not effective and the expired safety documents.
+func (s *FileService) ExportDocuments(module string, req models.DocumentExportRequest, viewer domains.FileViewer) (domains.DocumentExport, error) {
+	mod, ok := domains.DocumentModules[module]
+	if !ok {
+		return domains.DocumentExport{}, errs.NewNotfoundError("module not found")
+	}
+	spec := domains.DocumentSearchSpecs[module]
+	filter := domains.DocumentExportFilter{
+		Category:   strings.TrimSpace(req.Category),
+		Department: strings.TrimSpace(req.Department),
+		All:        req.All,
+	}
+	if filter.Category != "" && !spec.HasCategory {
+		return domains.DocumentExport{}, errs.NewError("category filter is not supported for this module")
+	}
+	if filter.Department != "" && !spec.HasDepartment {
+		return domains.DocumentExport{}, errs.NewError("department filter is not supported for this module")
+	}
+
+	rows, err := s.fileRepo.GetDocumentExportRows(mod, filter)
+	if err != nil {
+		return domains.DocumentExport{}, err
+	}
+
+	admin := viewer.Signed || utils.HasAnyRole(viewer.Role, qmsDocumentAdminRoles...)
+	root := documentExportName(module, filter)
+	export := domains.DocumentExport{Module: module, FileName: root + ".zip"}
+	taken := make(map[string]bool)
+	for _, row := range rows {
+		if module == domains.DocModuleQms && !admin &&
+			!qmsDocumentVisible(row.Status, viewer.EmpCode, row.OwnerEmpCode, row.ReviewerEmpCode, row.ApproverEmpCode) {
+			continue
+		}
+
+		entry := domains.DocumentExportEntry{DocumentExportRow: row, Path: documentFilePath(row.FileName)}
+		if entry.Path != "" {
+			folder := row.Department
+			if spec.HasCategory {
+				folder = row.Category
+			}
+			if folder = strings.Trim(sanitizeFileName(folder), ". "); folder == "" {
+				folder = "Uncategorized"
+			}
+			title := row.Name
+			if row.DocumentNo != "" {
+				title = row.DocumentNo + " " + title
+			}
+			entry.ZipPath = uniqueZipPath(taken, path.Join(root, folder, documentDownloadName(title, entry.Path)))
+		}
+		export.Entries = append(export.Entries, entry)
+	}
+	if len(export.Entries) == 0 {
+		return domains.DocumentExport{}, errs.NewNotfoundError("no documents match the filters")
+	}
+	return export, nil
+}
+
+// WriteDocumentExport streams the archive to w one file at a time and ends it with the manifest, which lists
+// every document with its metadata and the size and SHA-256 of the bytes written, or why the file is absent
+func (s *FileService) WriteDocumentExport(ctx context.Context, w io.Writer, export domains.DocumentExport) error {
+	zw := zip.NewWriter(w)
+	root := strings.TrimSuffix(export.FileName, ".zip")
+
+	manifest := [][]string{{
+		"module", "document_id", "name", "category", "department", "document_no", "revision", "status",
+		"owner_emp_code", "expiry_date", "updated_at", "file", "file_status", "size", "sha256",
+	}}
+	for _, entry := range export.Entries {
+		status, size, sum, err := writeExportEntry(ctx, zw, entry)
+		if err != nil {
+			return fmt.Errorf("export %s #%d: %w", export.Module, entry.DocumentID, err)
+		}
+
+		file, sizeText := "", ""
+		if status == exportFileIncluded {
+			file, sizeText = strings.TrimPrefix(entry.ZipPath, root+"/"), strconv.FormatInt(size, 10)
+		}
+		expiry := ""
+		if entry.ExpiryDate != nil {
+			expiry = entry.ExpiryDate.Format("2006-01-02")
+		}
+		manifest = append(manifest, []string{
+			export.Module,
+			strconv.Itoa(entry.DocumentID),
+			entry.Name,
+			entry.Category,
+			entry.Department,
+			entry.DocumentNo,
+			entry.Revision,
+			entry.Status,
+			entry.OwnerEmpCode,
+			expiry,
+			entry.UpdatedAt.Format(time.RFC3339),
+			file,
+			status,
+			sizeText,
+			sum,
+		})
+	}
+
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: path.Join(root, documentExportManifest), Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, "\uFEFF"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(mw)
+	if err := cw.WriteAll(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeExportEntry copies one stored file into the archive. A missing or unreadable file is reported in
+// the status and skipped; the error is only set when the archive itself can no longer be written.
+func writeExportEntry(ctx context.Context, zw *zip.Writer, entry domains.DocumentExportEntry) (string, int64, string, error) {
+	if entry.Path == "" {
+		return exportFileNoFile, 0, "", nil
+	}
+	obj, _, err := storage.Default().Open(ctx, entry.Path)
+	if storage.IsNotExist(err) {
+		return exportFileMissing, 0, "", nil
+	}
+	if err != nil {
+		log.Printf("[FileService] Export cannot open %s: %v\n", entry.Path, err)
+		return exportFileUnreadable, 0, "", nil
+	}
+	defer obj.Close()
+
+	method := zip.Deflate
+	if storedExtensions[strings.ToLower(filepath.Ext(entry.Path))] {
+		method = zip.Store
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: entry.ZipPath, Method: method, Modified: entry.UpdatedAt})
+	if err != nil {
+		return "", 0, "", err
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(fw, h), obj)
+	if err != nil {
+		return "", 0, "", err
+	}
+	return exportFileIncluded, size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// documentExportName names the archive and its top folder after the module, the filters and the day
+func documentExportName(module string, filter domains.DocumentExportFilter) string {
+	parts := []string{module}
+	for _, f := range []string{filter.Category, filter.Department} {
+		if f = strings.ReplaceAll(sanitizeFileName(f), " ", "_"); f != "" {
+			parts = append(parts, f)
+		}
+	}
+	if filter.All {
+		parts = append(parts, "all")
+	}
+	return strings.Join(append(parts, time.Now().Format("2006-01-02")), "_")
+}
+
+// uniqueZipPath numbers a name already used in the archive ("Name (2).pdf"), ignoring case for Windows
+func uniqueZipPath(taken map[string]bool, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, n, ext)
+	}
+	taken[strings.ToLower(candidate)] = true
+	return candidate
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/errs"
+)
+
+const maxDocumentImportRows = 1000
+
+// documentImportColumns maps the accepted manifest headers to their field
+var documentImportColumns = map[string]string{
+	"file":           "file",
+	"file_name":      "file",
+	"filename":       "file",
+	"path":           "file",
+	"name":           "name",
+	"title":          "name",
+	"description":    "description",
+	"desc":           "description",
+	"category":       "category",
+	"department":     "department",
+	"parent":         "parent",
+	"parent_id":      "parent",
+	"document_no":    "document_no",
+	"revision":       "revision",
+	"owner_emp_code": "owner_emp_code",
+	"sort_order":     "sort_order",
+}
+
+// manualCategories are the categories accepted by the procedure and customer manuals
+var manualCategories = map[string]bool{"office": true, "production": true, "quality": true, "support": true}
+
+type DocumentImportService struct {
+	importRepo ports.DocumentImportRepository
+}
+
+func NewDocumentImportService(importRepo ports.DocumentImportRepository) *DocumentImportService {
+	return &DocumentImportService{importRepo: importRepo}
+}
+
+// PrepareDocumentImport reads the manifest rows (the first is the header) and validates every row with the
+// rules of the module's create endpoint. checkFile reports why a named file cannot be imported, e.g. it is
+// missing from the archive. New QMS documents are owned by empCode unless the row names an owner.
+func (s *DocumentImportService) PrepareDocumentImport(module string, rows [][]string, empCode string, checkFile func(name string) error) (models.DocumentImportReport, error) {
+	mod, ok := domains.DocumentModules[module]
+	if !ok {
+		return models.DocumentImportReport{}, errs.NewNotfoundError("module not found")
+	}
+	if len(rows) == 0 {
+		return models.DocumentImportReport{}, errs.NewError("manifest is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		key := strings.ToLower(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(header)))
+		field, ok := documentImportColumns[key]
+		if !ok && key == mod.NameColumn {
+			field, ok = "name", true
+		}
+		if _, dup := columns[field]; ok && !dup {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return models.DocumentImportReport{}, errs.NewError("manifest needs a name column")
+	}
+	if _, ok := columns["file"]; !ok && module != domains.DocModuleCustomerManual {
+		return models.DocumentImportReport{}, errs.NewError("manifest needs a file column")
+	}
+
+	report := models.DocumentImportReport{Module: module, Rows: []models.DocumentImportRow{}}
+	var parentIDs []int
+	for i, cells := range rows[1:] {
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue // blank line
+		}
+		cell := func(field string) string {
+			if col, ok := columns[field]; ok && col < len(cells) {
+				return strings.TrimSpace(cells[col])
+			}
+			return ""
+		}
+		row := models.DocumentImportRow{
+			Row:          i + 2,
+			File:         cell("file"),
+			Name:         cell("name"),
+			Description:  cell("description"),
+			Category:     cell("category"),
+			Department:   cell("department"),
+			Parent:       cell("parent"),
+			DocumentNo:   cell("document_no"),
+			Revision:     cell("revision"),
+			OwnerEmpCode: cell("owner_emp_code"),
+			ParentIndex:  -1,
+		}
+		if len(report.Rows) == maxDocumentImportRows {
+			return models.DocumentImportReport{}, errs.NewError(fmt.Sprintf("manifest has more than %d rows", maxDocumentImportRows))
+		}
+
+		row.Errors = validateDocumentImportRow(module, &row, report.Rows, checkFile)
+		if sortOrder := cell("sort_order"); sortOrder != "" {
+			if n, err := strconv.Atoi(sortOrder); err != nil || module != domains.DocModuleCustomerManual {
+				row.Errors = append(row.Errors, "sort_order must be a whole number and is only used by customer manuals")
+			} else {
+				row.SortOrder = n
+			}
+		}
+		if row.ParentID != nil {
+			parentIDs = append(parentIDs, *row.ParentID)
+		}
+		if module == domains.DocModuleQms && row.OwnerEmpCode == "" {
+			row.OwnerEmpCode = empCode
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	if len(report.Rows) == 0 {
+		return models.DocumentImportReport{}, errs.NewError("manifest has no documents")
+	}
+
+	if len(parentIDs) > 0 {
+		live, err := s.importRepo.GetLiveDocumentIDs(mod, parentIDs)
+		if err != nil {
+			return models.DocumentImportReport{}, err
+		}
+		found := make(map[int]bool, len(live))
+		for _, id := range live {
+			found[id] = true
+		}
+		for i, row := range report.Rows {
+			if row.ParentID != nil && !found[*row.ParentID] {
+				report.Rows[i].Errors = append(report.Rows[i].Errors, fmt.Sprintf("parent %d is not an existing customer manual", *row.ParentID))
+			}
+		}
+	}
+
+	countDocumentImportRows(&report)
+	return report, nil
+}
+
+// ImportDocuments inserts the rows of a fully valid report in one transaction; the files must already be
+// stored in each row's FileName. On success every row gets its new document ID.
+func (s *DocumentImportService) ImportDocuments(module string, report *models.DocumentImportReport) error {
+	if report.InvalidRows > 0 {
+		return errs.NewError("the import has invalid rows")
+	}
+
+	docs := make([]domains.DocumentImport, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		docs = append(docs, domains.DocumentImport{
+			Name:         row.Name,
+			Desc:         row.Description,
+			Category:     row.Category,
+			Department:   row.Department,
+			FileName:     row.FileName,
+			DocumentNo:   row.DocumentNo,
+			Revision:     row.Revision,
+			OwnerEmpCode: row.OwnerEmpCode,
+			ParentID:     row.ParentID,
+			ParentIndex:  row.ParentIndex,
+			SortOrder:    row.SortOrder,
+		})
+	}
+
+	ids, err := s.importRepo.CreateImportedDocuments(module, docs)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		report.Rows[i].DocumentID = id
+		report.Rows[i].Status = models.DocumentImportImported
+	}
+	report.ImportedRows = len(ids)
+	return nil
+}
+
+// validateDocumentImportRow applies the required fields and categories of the module's create endpoint,
+// normalising the row like it does, and resolves the parent of a customer manual
+func validateDocumentImportRow(module string, row *models.DocumentImportRow, previous []models.DocumentImportRow, checkFile func(name string) error) []string {
+	spec := domains.DocumentSearchSpecs[module]
+	var problems []string
+	required := func(field, value string) {
+		if value == "" {
+			problems = append(problems, field+" is required")
+		}
+	}
+	unused := func(field, value string, used bool) {
+		if value != "" && !used {
+			problems = append(problems, field+" is not used by this module")
+		}
+	}
+
+	required("name", row.Name)
+	required("description", row.Description)
+	if row.File == "" && module != domains.DocModuleCustomerManual {
+		problems = append(problems, "file is required")
+	} else if row.File != "" {
+		if err := checkFile(row.File); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	unused("category", row.Category, spec.HasCategory)
+	unused("department", row.Department, spec.HasDepartment)
+	unused("parent", row.Parent, module == domains.DocModuleCustomerManual)
+	unused("document_no", row.DocumentNo, module == domains.DocModuleQms)
+	unused("revision", row.Revision, module == domains.DocModuleQms)
+	unused("owner_emp_code", row.OwnerEmpCode, module == domains.DocModuleQms || module == domains.DocModuleSafety)
+
+	switch module {
+	case domains.DocModuleSafety:
+		required("category", row.Category)
+		required("department", row.Department)
+	case domains.DocModuleQms:
+		required("category", row.Category)
+		row.Category = strings.ToLower(row.Category)
+	case domains.DocModuleProcedureManual, domains.DocModuleCustomerManual:
+		row.Category = strings.ToLower(row.Category)
+		if !manualCategories[row.Category] {
+			problems = append(problems, "category must be one of: office, production, quality, support")
+		}
+	case domains.DocModuleOrganizationDocs:
+		required("department", row.Department)
+	}
+
+	if module == domains.DocModuleCustomerManual && row.Parent != "" {
+		if problem := resolveImportParent(row, previous); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// resolveImportParent points a customer manual at its parent: an existing manual by ID, or an earlier
+// row of the manifest by name
+func resolveImportParent(row *models.DocumentImportRow, previous []models.DocumentImportRow) string {
+	if id, err := strconv.Atoi(row.Parent); err == nil {
+		if id <= 0 {
+			return "parent must be a customer manual ID or the name of an earlier row"
+		}
+		row.ParentID = &id
+		return ""
+	}
+
+	for i := range previous {
+		if !strings.EqualFold(previous[i].Name, row.Parent) {
+			continue
+		}
+		if row.ParentIndex >= 0 {
+			return fmt.Sprintf("parent %q matches more than one earlier row", row.Parent)
+		}
+		row.ParentIndex = i
+	}
+	if row.ParentIndex < 0 {
+		return fmt.Sprintf("parent %q must be the name of an earlier row or an existing customer manual ID", row.Parent)
+	}
+	return ""
+}
+
+// countDocumentImportRows sets the status of every row and the totals of the report
+func countDocumentImportRows(report *models.DocumentImportReport) {
+	report.TotalRows, report.ValidRows, report.InvalidRows = len(report.Rows), 0, 0
+	for i := range report.Rows {
+		if len(report.Rows[i].Errors) > 0 {
+			report.Rows[i].Status = models.DocumentImportInvalid
+			report.InvalidRows++
+			continue
+		}
+		report.Rows[i].Status = models.DocumentImportValid
+		report.ValidRows++
+	}
+}
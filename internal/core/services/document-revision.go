@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/logs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/utils"
+)
+
+type DocumentRevisionService struct {
+	revisionRepo ports.DocumentRevisionRepository
+}
+
+func NewDocumentRevisionService(revisionRepo ports.DocumentRevisionRepository) *DocumentRevisionService {
+	return &DocumentRevisionService{revisionRepo: revisionRepo}
+}
+
+// EnsureBaselineRevision records the file a document had before revision tracking, so the
+// first tracked upload does not lose it. Call it before replacing the file.
+func (s *DocumentRevisionService) EnsureBaselineRevision(module string, documentID int) error {
+	mod, err := documentModule(module)
+	if err != nil {
+		return err
+	}
+
+	total, err := s.revisionRepo.CountDocumentRevisions(mod.Name, documentID)
+	if err != nil || total > 0 {
+		return err
+	}
+
+	fileName, err := s.revisionRepo.GetDocumentFileName(mod, documentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("document not found")
+		}
+		return err
+	}
+	if documentFilePath(fileName) == "" {
+		return nil
+	}
+
+	return s.revisionRepo.CreateDocumentRevision(&domains.DocumentRevision{
+		Module:        mod.Name,
+		DocumentID:    documentID,
+		RevisionLabel: "Rev.1",
+		FileName:      fileName,
+		ChangeNote:    "Initial revision",
+		IsCurrent:     true,
+	})
+}
+
+// RecordRevision stores a newly uploaded file as the current revision of the document
+func (s *DocumentRevisionService) RecordRevision(module string, documentID int, fileName string, req models.DocumentRevisionReq) (models.DocumentRevisionResp, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return models.DocumentRevisionResp{}, err
+	}
+
+	revision := &domains.DocumentRevision{
+		Module:        mod.Name,
+		DocumentID:    documentID,
+		RevisionLabel: strings.TrimSpace(req.RevisionLabel),
+		FileName:      fileName,
+		UploadedBy:    strings.TrimSpace(req.UploadedBy),
+		ChangeNote:    strings.TrimSpace(req.ChangeNote),
+		EffectiveDate: req.EffectiveDate,
+		IsCurrent:     true,
+	}
+	if err := s.revisionRepo.CreateDocumentRevision(revision); err != nil {
+		logs.Error(err)
+		return models.DocumentRevisionResp{}, fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return toDocumentRevisionModel(*revision, lookupStoredFiles(revision.FileName)), nil
+}
+
+// GetRevisions lists the revisions of a document, newest first
+func (s *DocumentRevisionService) GetRevisions(module string, documentID int) ([]models.DocumentRevisionResp, error) {
+	if err := s.EnsureBaselineRevision(module, documentID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.GetDocumentRevisions(module, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(revisions))
+	for i, rev := range revisions {
+		names[i] = rev.FileName
+	}
+	files := lookupStoredFiles(names...)
+
+	out := make([]models.DocumentRevisionResp, 0, len(revisions))
+	for _, rev := range revisions {
+		out = append(out, toDocumentRevisionModel(rev, files))
+	}
+	return out, nil
+}
+
+// SetCurrentRevision makes an earlier (or later) revision the file served by the module again.
+// A QMS document past draft only changes file through its workflow, so only QMS admins may do it.
+func (s *DocumentRevisionService) SetCurrentRevision(module string, documentID, revisionNo int, role string) error {
+	mod, err := documentModule(module)
+	if err != nil {
+		return err
+	}
+
+	if mod.Name == domains.DocModuleQms && !utils.HasAnyRole(role, qmsDocumentAdminRoles...) {
+		status, err := s.revisionRepo.GetQmsDocumentStatus(documentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errs.NewNotfoundError("document not found")
+			}
+			return err
+		}
+		if status != domains.QmsStatusDraft {
+			return errs.NewError("qms document is " + status + "; only draft documents can change revision")
+		}
+	}
+
+	if err := s.revisionRepo.SetCurrentDocumentRevision(mod, documentID, revisionNo); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("revision not found")
+		}
+		logs.Error(err)
+		return err
+	}
+	return nil
+}
+
+// GetRevisionFile resolves the stored file of a revision and a readable download name
+func (s *DocumentRevisionService) GetRevisionFile(module string, documentID, revisionNo int) (string, string, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return "", "", err
+	}
+
+	revision, err := s.revisionRepo.GetDocumentRevision(mod.Name, documentID, revisionNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", errs.NewNotfoundError("revision not found")
+		}
+		return "", "", err
+	}
+
+	filePath := documentFilePath(revision.FileName)
+	if filePath == "" {
+		return "", "", errs.NewNotfoundError("file not found")
+	}
+	if _, err := storage.Default().Stat(context.Background(), filePath); err != nil {
+		return "", "", errs.NewNotfoundError("file not found")
+	}
+
+	label := revision.RevisionLabel
+	if label == "" {
+		label = fmt.Sprintf("Rev.%d", revision.RevisionNo)
+	}
+	name := fmt.Sprintf("%s-%d-%s%s", mod.Name, documentID, label, filepath.Ext(filePath))
+	return filePath, strings.ReplaceAll(name, " ", "_"), nil
+}
+
+func documentModule(module string) (domains.DocumentModule, error) {
+	mod, ok := domains.DocumentModules[module]
+	if !ok {
+		return domains.DocumentModule{}, errs.NewError("unknown document module")
+	}
+	return mod, nil
+}
+
+// documentFilePath turns a stored file name (plain path or ["uploads/..."]) into its storage key under uploads/
+func documentFilePath(fileName string) string {
+	fileName = strings.TrimSpace(fileName)
+	if strings.HasPrefix(fileName, "[") {
+		var paths []string
+		if err := json.Unmarshal([]byte(fileName), &paths); err != nil || len(paths) == 0 {
+			return ""
+		}
+		fileName = paths[0]
+	}
+	return storage.CleanKey(fileName)
+}
+
+func toDocumentRevisionModel(rev domains.DocumentRevision, files storedFiles) models.DocumentRevisionResp {
+	resp := models.DocumentRevisionResp{
+		Module:        rev.Module,
+		DocumentID:    rev.DocumentID,
+		RevisionNo:    rev.RevisionNo,
+		RevisionLabel: rev.RevisionLabel,
+		FileName:      rev.FileName,
+		FileURL:       utils.FileURL("", rev.FileName),
+		FileSHA256:    files.sha256(rev.FileName),
+		UploadedBy:    rev.UploadedBy,
+		ChangeNote:    rev.ChangeNote,
+		IsCurrent:     rev.IsCurrent,
+		CreatedAt:     rev.CreatedAt.Format(time.RFC3339),
+	}
+	if resp.RevisionLabel == "" {
+		resp.RevisionLabel = fmt.Sprintf("Rev.%d", rev.RevisionNo)
+	}
+	if rev.EffectiveDate != nil {
+		d := rev.EffectiveDate.Format("2006-01-02")
+		resp.EffectiveDate = &d
+	}
+	return resp
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"strings"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	"backend/internal/pkgs/errs"
+)
+
+const (
+	defaultDocumentSearchLimit = 50
+	maxDocumentSearchLimit     = 100
+)
+
+// documentSearch validates a module search request and returns the repository filter with its limit and offset
+func documentSearch(module string, req models.DocumentSearchRequest) (domains.DocumentSearch, int, int, error) {
+	spec := domains.DocumentSearchSpecs[module]
+	search := domains.DocumentSearch{
+		Keyword:    strings.TrimSpace(req.Keyword),
+		Category:   strings.TrimSpace(req.Category),
+		Department: strings.TrimSpace(req.Department),
+	}
+
+	if search.Category != "" && !spec.HasCategory {
+		return search, 0, 0, errs.NewError("category filter is not supported for this module")
+	}
+	if search.Department != "" && !spec.HasDepartment {
+		return search, 0, 0, errs.NewError("department filter is not supported for this module")
+	}
+	if search.Keyword == "" && search.Category == "" && search.Department == "" {
+		return search, 0, 0, errs.NewError("keyword is required")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultDocumentSearchLimit
+	}
+	if limit > maxDocumentSearchLimit {
+		limit = maxDocumentSearchLimit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	return search, limit, offset, nil
+}
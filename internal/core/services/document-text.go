@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/textextract"
+	"backend/internal/pkgs/utils"
+)
+
+// maxPageMatches limits the page snippets returned per document
+const maxPageMatches = 3
+
+type DocumentTextService struct {
+	textRepo ports.DocumentTextRepository
+}
+
+func NewDocumentTextService(textRepo ports.DocumentTextRepository) *DocumentTextService {
+	return &DocumentTextService{textRepo: textRepo}
+}
+
+// IndexDocument extracts the text of the document's current file and replaces its indexed pages
+func (s *DocumentTextService) IndexDocument(module string, documentID int) error {
+	mod, err := documentModule(module)
+	if err != nil {
+		return err
+	}
+
+	files, err := s.textRepo.GetDocumentFiles(mod, documentID)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errs.NewNotfoundError("document not found")
+	}
+	return s.indexFile(mod, files[0])
+}
+
+// IndexUploadedFile indexes the document that was just created with fileName
+func (s *DocumentTextService) IndexUploadedFile(module, fileName string) error {
+	mod, err := documentModule(module)
+	if err != nil {
+		return err
+	}
+
+	documentID, err := s.textRepo.FindDocumentIDByFileName(mod, fileName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("document not found")
+		}
+		return err
+	}
+	return s.indexFile(mod, domains.DocumentFile{DocumentID: documentID, FileName: fileName})
+}
+
+// RebuildIndex re-extracts every live document of the module and returns how many were indexed
+func (s *DocumentTextService) RebuildIndex(module string) (int, error) {
+	mod, err := documentModule(module)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := s.textRepo.GetDocumentFiles(mod, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, f := range files {
+		if err := s.indexFile(mod, f); err != nil {
+			log.Printf("[DocumentText] %s #%d: %v\n", mod.Name, f.DocumentID, err)
+			continue
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+// indexFile stores the pages of a file; files that cannot be read clear the document's index
+func (s *DocumentTextService) indexFile(mod domains.DocumentModule, f domains.DocumentFile) error {
+	var pages []domains.DocumentTextPage
+
+	if key := documentFilePath(f.FileName); key != "" && textextract.Supported(key) {
+		extracted, err := extractStoredText(key)
+		if err != nil {
+			log.Printf("[DocumentText] Failed to extract %s: %v\n", key, err)
+		}
+		for _, p := range extracted {
+			pages = append(pages, domains.DocumentTextPage{PageNo: p.Number, Content: p.Text})
+		}
+	}
+
+	return s.textRepo.ReplaceDocumentText(mod.Name, f.DocumentID, pages)
+}
+
+// extractStoredText extracts from a local copy of the stored file, since the readers need random access.
+// Content-addressed copies have no extension, so the type comes from the key.
+func extractStoredText(key string) ([]textextract.Page, error) {
+	filePath, cleanup, err := storage.LocalPath(context.Background(), storage.Default(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return textextract.ExtractAs(filePath, filepath.Ext(key))
+}
+
+// GetPageMatches returns, per document, the first pages whose text contains the keyword with a highlighted snippet
+func (s *DocumentTextService) GetPageMatches(module string, documentIDs []int, keyword string) (map[int][]models.DocumentTextMatchResp, error) {
+	pages, err := s.textRepo.GetMatchingTextPages(module, documentIDs, keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := utils.SearchTerms(keyword)
+	matches := make(map[int][]models.DocumentTextMatchResp)
+	for _, p := range pages {
+		if len(matches[p.DocumentID]) >= maxPageMatches {
+			continue
+		}
+		matches[p.DocumentID] = append(matches[p.DocumentID], models.DocumentTextMatchResp{
+			Page:    p.PageNo,
+			Snippet: utils.Snippet(p.Content, terms, searchSnippetLength),
+		})
+	}
+	return matches, nil
+}
+
+// documentPageMatches is used by the module searches; snippets are best effort and never fail a search
+func documentPageMatches(textSrv portServices.DocumentTextService, module string, documentIDs []int, keyword string) map[int][]models.DocumentTextMatchResp {
+	if textSrv == nil || len(documentIDs) == 0 || strings.TrimSpace(keyword) == "" {
+		return nil
+	}
+	matches, err := textSrv.GetPageMatches(module, documentIDs, keyword)
+	if err != nil {
+		log.Printf("[DocumentText] Failed to load %s page matches: %v\n", module, err)
+		return nil
+	}
+	return matches
+}
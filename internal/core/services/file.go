@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/thumbnail"
+	"backend/internal/pkgs/utils"
+	"backend/internal/repositories"
+)
+
+const maxSignedFileURLTTL = 24 * time.Hour
+
+// Limits of the integrity check: blobs loaded per query, issues listed in the report and keys named per issue
+const (
+	integrityBatchSize = 200
+	maxIntegrityIssues = 500
+	maxIntegrityKeys   = 20
+)
+
+// quarantinePrefix holds infected uploads kept for review; nothing is generated from them
+var quarantinePrefix = path.Join(storage.Root, domains.QuarantineUploadFolder) + "/"
+
+// errFileNotFound is returned for every file that is missing, not allowed or not visible to the caller,
+// so responses never reveal which of those it was
+var errFileNotFound = errs.NewNotfoundError("file not found")
+
+type FileService struct {
+	fileRepo   ports.FileRepository
+	uploadRepo ports.UploadedFileRepository
+	blobRepo   ports.FileBlobRepository
+	qmsRepo    *repositories.QmsDocumentsRepository
+	newsSrv    portServices.CompanyNewsService
+}
+
+func NewFileService(fileRepo ports.FileRepository, uploadRepo ports.UploadedFileRepository, blobRepo ports.FileBlobRepository, qmsRepo *repositories.QmsDocumentsRepository, newsSrv portServices.CompanyNewsService) *FileService {
+	return &FileService{fileRepo: fileRepo, uploadRepo: uploadRepo, blobRepo: blobRepo, qmsRepo: qmsRepo, newsSrv: newsSrv}
+}
+
+// ResolveFile finds the file in storage and checks the viewer may read the record owning it
+func (s *FileService) ResolveFile(ref domains.FileRef, viewer domains.FileViewer) (domains.ServedFile, error) {
+	var file domains.ServedFile
+	var err error
+	switch ref.Source {
+	case domains.FileSourceDocument:
+		file, err = s.resolveDocumentFile(ref, viewer)
+	case domains.FileSourceNewsAttachment:
+		file, err = s.resolveNewsAttachmentFile(ref, viewer)
+	case domains.FileSourceFolder:
+		file, err = s.resolveFolderFile(ref, viewer)
+	default:
+		err = errFileNotFound
+	}
+	if err != nil {
+		return domains.ServedFile{}, err
+	}
+
+	if _, err := storage.Default().Stat(context.Background(), file.Path); err != nil {
+		return domains.ServedFile{}, errFileNotFound
+	}
+	return file, nil
+}
+
+// SignFileURL checks the viewer may read the file and returns a URL anyone can use until it expires
+func (s *FileService) SignFileURL(ref domains.FileRef, viewer domains.FileViewer, baseURL string, ttl time.Duration) (models.SignedFileURLResp, error) {
+	viewer.Signed = false
+	if _, err := s.ResolveFile(ref, viewer); err != nil {
+		return models.SignedFileURLResp{}, err
+	}
+
+	if ttl <= 0 {
+		ttl = time.Duration(envInt("FILE_URL_TTL_MINUTES", 15, 1, 1440)) * time.Minute
+	}
+	if ttl > maxSignedFileURLTTL {
+		ttl = maxSignedFileURLTTL
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	return models.SignedFileURLResp{
+		URL:       signedFileURL(baseURL, ref, expiresAt),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// RebuildThumbnails generates the missing previews of every upload, e.g. for files stored before thumbnails
+// existed, and flags existing previews the index does not know about
+func (s *FileService) RebuildThumbnails() (int, error) {
+	generated := 0
+	err := storage.Default().Walk(context.Background(), storage.Root+"/", func(info storage.Info) error {
+		p := info.Key
+		if _, ok := thumbnail.Original(path.Base(p)); ok || strings.HasPrefix(p, quarantinePrefix) {
+			return nil
+		}
+		if !thumbnail.Supported(p) {
+			return nil
+		}
+		if thumbnail.Exists(p) {
+			// previews made before the index recorded them
+			if !info.Thumbnails {
+				_ = storage.SetThumbnails(context.Background(), p, true)
+			}
+			return nil
+		}
+		if err := thumbnail.Generate(p); err != nil {
+			log.Printf("[FileService] Thumbnail error for %s: %v\n", p, err)
+			return nil
+		}
+		generated++
+		return nil
+	})
+	return generated, err
+}
+
+// GetUploadedFiles lists the upload registry, newest first, optionally filtered by scan status
+func (s *FileService) GetUploadedFiles(scanStatus string, page, pageSize int) (models.UploadedFileListResp, error) {
+	switch scanStatus {
+	case "", domains.UploadScanClean, domains.UploadScanInfected, domains.UploadScanError, domains.UploadScanSkipped:
+	default:
+		return models.UploadedFileListResp{}, errs.NewError("invalid scan_status")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	files, total, err := s.uploadRepo.GetUploadedFiles(domains.UploadedFileQuery{ScanStatus: scanStatus, Page: page, PageSize: pageSize})
+	if err != nil {
+		return models.UploadedFileListResp{}, err
+	}
+
+	resp := models.UploadedFileListResp{
+		Data:       make([]models.UploadedFileResp, 0, len(files)),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
+	for _, f := range files {
+		item := models.UploadedFileResp{
+			UploadedFileID: f.UploadedFileID,
+			StorageKey:     f.StorageKey,
+			OriginalName:   f.OriginalName,
+			MimeType:       f.MimeType,
+			FileSize:       f.FileSize,
+			ScanStatus:     f.ScanStatus,
+			ScanEngine:     f.ScanEngine,
+			ScanSignature:  f.ScanSignature,
+			ScanError:      f.ScanError,
+			Quarantined:    f.Quarantined,
+			UploadedBy:     f.UploadedBy,
+			CreatedAt:      f.CreatedAt.Format(time.RFC3339),
+		}
+		if f.ScannedAt != nil {
+			scannedAt := f.ScannedAt.Format(time.RFC3339)
+			item.ScannedAt = &scannedAt
+		}
+		resp.Data = append(resp.Data, item)
+	}
+	return resp, nil
+}
+
+// CheckFileIntegrity re-hashes every content-addressed blob and reports those missing from storage or whose
+// content no longer matches the SHA-256 and size recorded at upload. Intact blobs get their verified_at updated.
+// Files stored before content addressing have no recorded hash and are only counted.
+func (s *FileService) CheckFileIntegrity() (models.FileIntegrityReport, error) {
+	ctx := context.Background()
+	cas, ok := storage.Default().(*storage.ContentAddressed)
+	if !ok {
+		return models.FileIntegrityReport{}, errs.NewError("content-addressed storage is not enabled")
+	}
+
+	report := models.FileIntegrityReport{Issues: []models.FileIntegrityIssue{}, StartedAt: time.Now().Format(time.RFC3339)}
+	after := ""
+	for {
+		blobs, err := s.blobRepo.GetFileBlobs(after, integrityBatchSize)
+		if err != nil {
+			return models.FileIntegrityReport{}, err
+		}
+
+		for _, blob := range blobs {
+			report.CheckedBlobs++
+			issue, ok := checkFileBlob(ctx, cas.Inner(), blob)
+			if ok {
+				report.VerifiedBlobs++
+				if err := s.blobRepo.MarkFileBlobVerified(blob.SHA256, time.Now()); err != nil {
+					log.Printf("[FileService] Failed to mark blob %s verified: %v\n", blob.SHA256, err)
+				}
+				continue
+			}
+
+			switch issue.Problem {
+			case domains.FileIntegrityMissing:
+				report.MissingBlobs++
+			case domains.FileIntegrityMismatch:
+				report.MismatchedBlobs++
+			default:
+				report.UnreadableBlobs++
+			}
+			log.Printf("[FileService] Integrity check: blob %s is %s\n", blob.SHA256, issue.Problem)
+			if len(report.Issues) >= maxIntegrityIssues {
+				report.IssuesTruncated = true
+				continue
+			}
+			if issue.Keys, err = s.blobRepo.GetFileLinkKeys(blob.SHA256, maxIntegrityKeys); err != nil {
+				return models.FileIntegrityReport{}, err
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+
+		if len(blobs) < integrityBatchSize {
+			break
+		}
+		after = blobs[len(blobs)-1].SHA256
+	}
+
+	err := cas.Walk(ctx, storage.Root+"/", func(info storage.Info) error {
+		if info.SHA256 == "" && !strings.HasPrefix(info.Key, quarantinePrefix) {
+			report.LegacyFiles++
+		}
+		return nil
+	})
+	if err != nil {
+		return models.FileIntegrityReport{}, err
+	}
+
+	report.FinishedAt = time.Now().Format(time.RFC3339)
+	return report, nil
+}
+
+// checkFileBlob re-hashes one blob; ok is false with the issue filled in when it is missing or damaged
+func checkFileBlob(ctx context.Context, inner storage.Storage, blob domains.FileBlob) (models.FileIntegrityIssue, bool) {
+	issue := models.FileIntegrityIssue{SHA256: blob.SHA256, Size: blob.Size}
+	sum, size, err := storage.HashObject(ctx, inner, storage.BlobKey(blob.SHA256))
+	switch {
+	case storage.IsNotExist(err):
+		issue.Problem = domains.FileIntegrityMissing
+	case err != nil:
+		issue.Problem = domains.FileIntegrityUnreadable
+		issue.Error = err.Error()
+	case sum != blob.SHA256 || size != blob.Size:
+		issue.Problem = domains.FileIntegrityMismatch
+		issue.ActualSHA256, issue.ActualSize = sum, size
+	default:
+		return issue, true
+	}
+	return issue, false
+}
+
+func (s *FileService) resolveDocumentFile(ref domains.FileRef, viewer domains.FileViewer) (domains.ServedFile, error) {
+	mod, ok := domains.DocumentModules[ref.Module]
+	if !ok || ref.DocumentID <= 0 {
+		return domains.ServedFile{}, errFileNotFound
+	}
+
+	doc, err := s.fileRepo.GetDocumentFile(mod, ref.DocumentID)
+	if err != nil {
+		return domains.ServedFile{}, fileLookupError(err)
+	}
+	if err := s.checkDocumentAccess(mod.Name, doc.DocumentID, viewer); err != nil {
+		return domains.ServedFile{}, err
+	}
+
+	p := documentFilePath(doc.FileName)
+	if p == "" {
+		return domains.ServedFile{}, errFileNotFound
+	}
+	return domains.ServedFile{Path: p, Name: documentDownloadName(doc.Name, p), Module: mod.Name, DocumentID: doc.DocumentID}, nil
+}
+
+func (s *FileService) resolveNewsAttachmentFile(ref domains.FileRef, viewer domains.FileViewer) (domains.ServedFile, error) {
+	if ref.AttachmentID <= 0 {
+		return domains.ServedFile{}, errFileNotFound
+	}
+
+	attachment, err := s.fileRepo.GetCompanyNewsAttachment(ref.AttachmentID)
+	if err != nil {
+		return domains.ServedFile{}, fileLookupError(err)
+	}
+	if err := s.checkCompanyNewsAccess(attachment.CompanyNewsID, viewer); err != nil {
+		return domains.ServedFile{}, err
+	}
+
+	p := documentFilePath(attachment.FilePath)
+	if p == "" {
+		return domains.ServedFile{}, errFileNotFound
+	}
+	name := attachment.OriginalName
+	if name == "" {
+		name = filepath.Base(p)
+	}
+	return domains.ServedFile{Path: p, Name: name}, nil
+}
+
+// resolveFolderFile serves get-file requests: the folder must be allow-listed and, unless it is public,
+// the file must belong to a record the viewer can read
+func (s *FileService) resolveFolderFile(ref domains.FileRef, viewer domains.FileViewer) (domains.ServedFile, error) {
+	if !validUploadName(ref.Folder) || !validUploadName(ref.FileName) {
+		return domains.ServedFile{}, errFileNotFound
+	}
+
+	relPath := path.Join("uploads", ref.Folder, ref.FileName)
+	file := domains.ServedFile{Path: relPath, Name: ref.FileName}
+
+	// a thumbnail is readable by whoever can read the upload it was generated from
+	ownerName := ref.FileName
+	if original, ok := thumbnail.Original(ref.FileName); ok {
+		ownerName, file.Preview = original, true
+	}
+	ownerPath := path.Join("uploads", ref.Folder, ownerName)
+	fileNames := []string{ownerPath, "./" + ownerPath, fmt.Sprintf("[%q]", ownerPath)}
+
+	if domains.PublicUploadFolders[ref.Folder] {
+		return file, nil
+	}
+
+	if ref.Folder == domains.CompanyNewsUploadFolder {
+		newsID, err := s.fileRepo.FindCompanyNewsIDByFile(fileNames, ownerName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// images uploaded from the editor before the article is saved
+			if viewer.Signed || utils.HasAnyRole(viewer.Role, companyNewsEditorRoles...) {
+				return file, nil
+			}
+			return domains.ServedFile{}, errFileNotFound
+		}
+		if err != nil {
+			return domains.ServedFile{}, err
+		}
+		return file, s.checkCompanyNewsAccess(newsID, viewer)
+	}
+
+	mod, ok := documentModuleByFolder(ref.Folder)
+	if !ok {
+		return domains.ServedFile{}, errFileNotFound
+	}
+	documentID, err := s.fileRepo.FindDocumentIDByFile(mod, fileNames)
+	if err != nil {
+		return domains.ServedFile{}, fileLookupError(err)
+	}
+	if err := s.checkDocumentAccess(mod.Name, documentID, viewer); err != nil {
+		return domains.ServedFile{}, err
+	}
+
+	file.Module, file.DocumentID = mod.Name, documentID
+	if doc, err := s.fileRepo.GetDocumentFile(mod, documentID); err == nil && !file.Preview {
+		file.Name = documentDownloadName(doc.Name, file.Path)
+	}
+	return file, nil
+}
+
+// CheckDocumentAccess tells whether the viewer may read the document and its files; a document that
+// is missing or not visible gives the same not found error
+func (s *FileService) CheckDocumentAccess(module string, documentID int, viewer domains.FileViewer) error {
+	if _, ok := domains.DocumentModules[module]; !ok || documentID <= 0 {
+		return errFileNotFound
+	}
+	return s.checkDocumentAccess(module, documentID, viewer)
+}
+
+// checkDocumentAccess mirrors the module list permissions: QMS documents are visible to everyone once
+// effective, otherwise only to QMS admins and the owner, reviewer and approver; other modules are public
+func (s *FileService) checkDocumentAccess(module string, documentID int, viewer domains.FileViewer) error {
+	if viewer.Signed || module != domains.DocModuleQms || utils.HasAnyRole(viewer.Role, qmsDocumentAdminRoles...) {
+		return nil
+	}
+
+	doc, err := s.qmsRepo.GetQmsDocumentByID(documentID)
+	if err != nil {
+		return errFileNotFound
+	}
+	if !qmsDocumentVisible(doc.Status, viewer.EmpCode, doc.OwnerEmpCode, doc.ReviewerEmpCode, doc.ApproverEmpCode) {
+		return errFileNotFound
+	}
+	return nil
+}
+
+// qmsDocumentVisible tells whether a non-admin may read a QMS document: it is effective, or the viewer
+// is one of the people working on it
+func qmsDocumentVisible(status, empCode string, workers ...string) bool {
+	if status == domains.QmsStatusEffective {
+		return true
+	}
+	if empCode == "" {
+		return false
+	}
+	for _, code := range workers {
+		if strings.EqualFold(code, empCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCompanyNewsAccess applies the article's audience, the same check as reading the article
+func (s *FileService) checkCompanyNewsAccess(companyNewsID string, viewer domains.FileViewer) error {
+	if viewer.Signed {
+		return nil
+	}
+	if _, err := s.newsSrv.GetCompanyNewsByID(companyNewsID, viewer.EmpCode, viewer.Role); err != nil {
+		if _, ok := err.(errs.AppError); ok {
+			return errFileNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// fileLookupError hides missing records behind errFileNotFound and passes database errors through
+func fileLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errFileNotFound
+	}
+	return err
+}
+
+// documentDownloadName names a document's file after its title, keeping the stored file's extension
+func documentDownloadName(title, filePath string) string {
+	ext := filepath.Ext(filePath)
+	title = sanitizeFileName(title)
+	if title == "" {
+		return filepath.Base(filePath)
+	}
+	if !strings.EqualFold(filepath.Ext(title), ext) {
+		title += ext
+	}
+	return title
+}
+
+// sanitizeFileName replaces the characters Windows does not allow in file names
+func sanitizeFileName(name string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name))
+}
+
+// validUploadName accepts a single path element, rejecting traversal and hidden files
+func validUploadName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "/\\:\x00")
+}
+
+// documentModuleByFolder maps an uploads sub-folder to the document module storing its files there
+func documentModuleByFolder(folder string) (domains.DocumentModule, bool) {
+	for _, mod := range domains.DocumentModules {
+		if filepath.Base(mod.UploadDir) == folder {
+			return mod, true
+		}
+	}
+	return domains.DocumentModule{}, false
+}
+
+// signedFileURL builds the file service URL of ref carrying an expiring signature
+func signedFileURL(baseURL string, ref domains.FileRef, expiresAt time.Time) string {
+	q := url.Values{}
+	var p string
+	switch ref.Source {
+	case domains.FileSourceDocument:
+		p = fmt.Sprintf("/api/file/document/%s/%d", ref.Module, ref.DocumentID)
+	case domains.FileSourceNewsAttachment:
+		p = fmt.Sprintf("/api/file/news-attachment/%d", ref.AttachmentID)
+	default:
+		p = "/api/file/get-file"
+		q.Set("folder", ref.Folder)
+		q.Set("filename", ref.FileName)
+	}
+
+	expires := expiresAt.Unix()
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", utils.SignFileKey(ref.Key(), expires))
+	return strings.TrimRight(baseURL, "/") + p + "?" + q.Encode()
+}
+
+// signedUploadURL signs a stored upload path for readers that cannot send a token, such as mail clients
+func signedUploadURL(baseURL, relPath string, ttl time.Duration) string {
+	if strings.TrimSpace(relPath) == "" {
+		return ""
+	}
+	folder, name := utils.SplitUploadPath(relPath)
+	ref := domains.FileRef{Source: domains.FileSourceFolder, Folder: folder, FileName: name}
+	return signedFileURL(baseURL, ref, time.Now().Add(ttl))
+}
+
+// storedFiles holds what listings show about the files on one page, the SHA-256 and whether previews
+// exist, as recorded in the storage index when they were uploaded
+type storedFiles map[string]storage.Link
+
+// lookupStoredFiles reads the index entries of the given file names (paths or JSON arrays of paths)
+// in one query. Files stored before content addressing have no entry and show neither.
+func lookupStoredFiles(fileNames ...string) storedFiles {
+	keys := make([]string, 0, len(fileNames))
+	for _, name := range fileNames {
+		if p := documentFilePath(name); p != "" {
+			keys = append(keys, p)
+		}
+	}
+	links, err := storage.LookupLinks(context.Background(), keys)
+	if err != nil {
+		log.Printf("[storedFiles] Failed to look up %d file(s): %v\n", len(keys), err)
+	}
+	return links
+}
+
+// sha256 returns the SHA-256 recorded when the file was stored, or ""
+func (f storedFiles) sha256(fileName string) string {
+	return f[documentFilePath(fileName)].SHA256
+}
+
+// thumbnails links the generated previews of a stored upload, or nil when it has none
+func (f storedFiles) thumbnails(fileName string) *models.ThumbnailURLs {
+	p := documentFilePath(fileName)
+	if p == "" || !f[p].Thumbnails {
+		return nil
+	}
+
+	urls := &models.ThumbnailURLs{}
+	for _, size := range thumbnail.Sizes {
+		u := utils.FileURL("", filepath.ToSlash(thumbnail.Path(p, size.Width)))
+		switch size.Name {
+		case "small":
+			urls.Small = u
+		case "medium":
+			urls.Medium = u
+		case "large":
+			urls.Large = u
+		}
+	}
+	return urls
+}
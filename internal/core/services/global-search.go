@@ -0,0 +1,402 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+// Result types of the global search besides the document modules, which use their module name
+const (
+	SearchTypeAppSystem      = "app_system"
+	SearchTypeWelfareBenefit = "welfare_benefits"
+	SearchTypeCompanyNews    = "company_news"
+)
+
+const (
+	defaultGlobalSearchLimit = 20
+	maxGlobalSearchLimit     = 100
+)
+
+// globalSearchTypes is the order types are searched in and reported in facets
+var globalSearchTypes = []string{
+	domains.DocModuleProcedureManual,
+	domains.DocModuleQms,
+	domains.DocModuleSafety,
+	domains.DocModuleCustomerManual,
+	domains.DocModuleOrganizationDocs,
+	SearchTypeAppSystem,
+	SearchTypeWelfareBenefit,
+	SearchTypeCompanyNews,
+}
+
+type GlobalSearchService struct {
+	procedureSrv portServices.ProcedureManualService
+	qmsSrv       portServices.QmsDocumentsService
+	safetySrv    portServices.SafetyDocumentService
+	customerSrv  portServices.CustomerManualService
+	orgDocSrv    portServices.OrganizationDocService
+	appSystemSrv portServices.AppSystemService
+	welfareSrv   portServices.WelfareBenefitService
+	newsSrv      portServices.CompanyNewsService
+}
+
+func NewGlobalSearchService(
+	procedureSrv portServices.ProcedureManualService,
+	qmsSrv portServices.QmsDocumentsService,
+	safetySrv portServices.SafetyDocumentService,
+	customerSrv portServices.CustomerManualService,
+	orgDocSrv portServices.OrganizationDocService,
+	appSystemSrv portServices.AppSystemService,
+	welfareSrv portServices.WelfareBenefitService,
+	newsSrv portServices.CompanyNewsService,
+) portServices.GlobalSearchService {
+	return &GlobalSearchService{
+		procedureSrv: procedureSrv,
+		qmsSrv:       qmsSrv,
+		safetySrv:    safetySrv,
+		customerSrv:  customerSrv,
+		orgDocSrv:    orgDocSrv,
+		appSystemSrv: appSystemSrv,
+		welfareSrv:   welfareSrv,
+		newsSrv:      newsSrv,
+	}
+}
+
+// globalSearchResult is what one module search contributes to the merged response
+type globalSearchResult struct {
+	searchType string
+	total      int64
+	items      []models.GlobalSearchItem
+	err        error
+}
+
+// Search runs every module search in parallel through the module services, so each keeps its own
+// visibility rules (QMS drafts for QMS/SU only, company news audiences), then ranks the merged hits.
+// A module that fails is listed in Failed instead of failing the whole search.
+func (s *GlobalSearchService) Search(req models.GlobalSearchReq) (models.GlobalSearchResp, error) {
+	req.Keyword = strings.TrimSpace(req.Keyword)
+	req.Category = strings.TrimSpace(req.Category)
+	req.Department = strings.TrimSpace(req.Department)
+	if req.Keyword == "" {
+		return models.GlobalSearchResp{}, errs.NewError("keyword is required")
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultGlobalSearchLimit
+	}
+	if req.Limit > maxGlobalSearchLimit {
+		req.Limit = maxGlobalSearchLimit
+	}
+
+	types, err := globalSearchTypeFilter(req.Types)
+	if err != nil {
+		return models.GlobalSearchResp{}, err
+	}
+
+	terms := utils.SearchTerms(req.Keyword)
+	results := make([]globalSearchResult, len(types))
+	var wg sync.WaitGroup
+	for i, t := range types {
+		wg.Add(1)
+		go func(i int, t string) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[GlobalSearch] %s panicked: %v\n", t, r)
+					results[i] = globalSearchResult{searchType: t, err: errs.NewError("search failed")}
+				}
+			}()
+			results[i] = s.searchType(t, req, terms)
+		}(i, t)
+	}
+	wg.Wait()
+
+	resp := models.GlobalSearchResp{Keyword: req.Keyword, Data: []models.GlobalSearchItem{}}
+	var items []models.GlobalSearchItem
+	for _, r := range results {
+		if r.err != nil {
+			log.Printf("[GlobalSearch] %s: %v\n", r.searchType, r.err)
+			resp.Failed = append(resp.Failed, r.searchType)
+			continue
+		}
+		resp.Total += r.total
+		if r.total > 0 {
+			resp.Facets.Type = append(resp.Facets.Type, models.GlobalSearchFacet{Value: r.searchType, Count: r.total})
+		}
+		items = append(items, r.items...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].UpdatedAt.After(items[j].UpdatedAt)
+	})
+
+	resp.Facets.Category = globalSearchFacet(items, func(it models.GlobalSearchItem) string { return it.Category })
+	resp.Facets.Department = globalSearchFacet(items, func(it models.GlobalSearchItem) string { return it.Department })
+	if resp.Facets.Type == nil {
+		resp.Facets.Type = []models.GlobalSearchFacet{}
+	}
+
+	if len(items) > req.Limit {
+		items = items[:req.Limit]
+	}
+	if len(items) > 0 {
+		resp.Data = items
+	}
+	return resp, nil
+}
+
+// searchType runs one module search; modules that cannot honour the category or department filter are skipped
+func (s *GlobalSearchService) searchType(t string, req models.GlobalSearchReq, terms []string) globalSearchResult {
+	result := globalSearchResult{searchType: t}
+
+	if spec, ok := domains.DocumentSearchSpecs[t]; ok {
+		if (req.Category != "" && !spec.HasCategory) || (req.Department != "" && !spec.HasDepartment) {
+			return result
+		}
+	} else if req.Department != "" {
+		return result
+	}
+
+	docReq := models.DocumentSearchRequest{Keyword: req.Keyword, Category: req.Category, Department: req.Department, Limit: req.Limit}
+	fileLink := func(fileName string) string { return utils.FileURL(req.BaseURL, fileName) }
+
+	switch t {
+	case domains.DocModuleProcedureManual:
+		resp, err := s.procedureSrv.SearchProcedureManualService(docReq)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.ProcedureManualID), Title: d.ProcedureManualName, Link: fileLink(d.FileName),
+				Category: d.Category, Matches: d.Matches, UpdatedAt: d.UpdatedAt,
+			}, d.Desc, terms))
+		}
+
+	case domains.DocModuleQms:
+		resp, err := s.qmsSrv.SearchQmsDocumentService(docReq, req.Role, "")
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			title := d.QmsDocumentsName
+			if d.DocumentNo != "" {
+				title = d.DocumentNo + " " + title
+			}
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.QmsDocumentsID), Title: title, Link: fileLink(d.FileName),
+				Category: d.Category, Matches: d.Matches, UpdatedAt: d.UpdatedAt,
+			}, d.DQmsDocumentsDesc, terms))
+		}
+
+	case domains.DocModuleSafety:
+		resp, err := s.safetySrv.SearchSafetyDocumentService(docReq)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.SafetyDocumentID), Title: d.SafetyDocumentName, Link: fileLink(d.FileName),
+				Category: d.Category, Department: d.Department, Matches: d.Matches, UpdatedAt: d.UpdatedAt,
+			}, d.SafetyDocumentDesc, terms))
+		}
+
+	case domains.DocModuleCustomerManual:
+		resp, err := s.customerSrv.SearchCustomerManualService(docReq)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.CustomerManualID), Title: d.CustomerManualName, Link: fileLink(d.FileName),
+				Category: d.Category, Matches: d.Matches, UpdatedAt: d.UpdatedAt,
+			}, d.Desc, terms))
+		}
+
+	case domains.DocModuleOrganizationDocs:
+		resp, err := s.orgDocSrv.SearchOrganizationDocService(docReq)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.OrganizationDocID), Title: d.Name, Link: fileLink(d.FileName),
+				Department: d.Department, Matches: d.Matches, UpdatedAt: d.UpdatedAt,
+			}, d.Desc, terms))
+		}
+
+	case SearchTypeAppSystem:
+		resp, err := s.appSystemSrv.SearchAppSystemsService(req.Keyword, req.Limit, 0)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			if req.Category != "" && !strings.EqualFold(d.Category, req.Category) {
+				result.total--
+				continue
+			}
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.ID), Title: d.Name, Link: d.Href,
+				Category: d.Category, UpdatedAt: d.UpdatedAt,
+			}, d.Desc, terms))
+		}
+
+	case SearchTypeWelfareBenefit:
+		resp, err := s.welfareSrv.SearchWelfareBenefitService(req.Keyword, 1, req.Limit)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		for _, d := range resp.Data {
+			if req.Category != "" && !strings.EqualFold(d.Category, req.Category) {
+				result.total--
+				continue
+			}
+			result.items = append(result.items, scoreGlobalSearchItem(models.GlobalSearchItem{
+				Type: t, ID: strconv.Itoa(d.WelfareBenefitID), Title: d.Title, Link: fileLink(d.FileName),
+				Category: d.Category, UpdatedAt: d.UpdatedAt,
+			}, d.Description, terms))
+		}
+
+	case SearchTypeCompanyNews:
+		resp, err := s.newsSrv.SearchCompanyNews(models.CompanyNewsSearchReq{
+			Keyword:  req.Keyword,
+			Category: req.Category,
+			EmpCode:  req.EmpCode,
+			Role:     req.Role,
+			Limit:    req.Limit,
+		})
+		if err != nil {
+			result.err = err
+			return result
+		}
+		result.total = resp.Total
+		linkBase := companyNewsLinkBase(req.BaseURL)
+		for _, d := range resp.Data {
+			updatedAt, _ := time.ParseInLocation("2006-01-02 15:04:05", d.UpdatedAt, time.Local)
+			result.items = append(result.items, models.GlobalSearchItem{
+				Type:           t,
+				ID:             d.CompanyNewsID.String(),
+				Title:          d.Title,
+				TitleHighlight: d.TitleHighlight,
+				Snippet:        d.Snippet,
+				Link:           linkBase + d.CompanyNewsID.String(),
+				Category:       d.Category,
+				Score:          d.Score,
+				UpdatedAt:      updatedAt,
+			})
+		}
+	}
+
+	// a filtered-out page can make the adjusted total drift below what was kept
+	if result.total < int64(len(result.items)) {
+		result.total = int64(len(result.items))
+	}
+	return result
+}
+
+// scoreGlobalSearchItem ranks a hit with the same weights as the company news search: 10 per term in the
+// title, 3 per term elsewhere, 20 when the whole phrase is in the title, plus 2 per matching file page.
+func scoreGlobalSearchItem(item models.GlobalSearchItem, desc string, terms []string) models.GlobalSearchItem {
+	title := strings.ToLower(item.Title)
+	body := strings.ToLower(utils.PlainText(desc))
+	inBody := false
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		if strings.Contains(title, term) {
+			item.Score += 10
+		}
+		if strings.Contains(body, term) {
+			item.Score += 3
+			inBody = true
+		}
+	}
+	if len(terms) > 1 && strings.Contains(title, strings.ToLower(strings.Join(terms, " "))) {
+		item.Score += 20
+	}
+	item.Score += 2 * len(item.Matches)
+
+	// show where the keyword was found: the description first, otherwise the first matching file page
+	item.TitleHighlight = utils.Highlight(item.Title, terms)
+	if !inBody && len(item.Matches) > 0 {
+		item.Snippet = item.Matches[0].Snippet
+	} else {
+		item.Snippet = utils.Snippet(utils.PlainText(desc), terms, searchSnippetLength)
+	}
+	return item
+}
+
+func globalSearchTypeFilter(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return globalSearchTypes, nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, t := range requested {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			wanted[t] = true
+		}
+	}
+
+	types := make([]string, 0, len(wanted))
+	for _, t := range globalSearchTypes {
+		if wanted[t] {
+			types = append(types, t)
+			delete(wanted, t)
+		}
+	}
+	for t := range wanted {
+		return nil, errs.NewError("unknown search type: " + t)
+	}
+	if len(types) == 0 {
+		return globalSearchTypes, nil
+	}
+	return types, nil
+}
+
+// globalSearchFacet counts the values among the returned hits, most frequent first
+func globalSearchFacet(items []models.GlobalSearchItem, value func(models.GlobalSearchItem) string) []models.GlobalSearchFacet {
+	counts := make(map[string]int64)
+	for _, it := range items {
+		if v := value(it); v != "" {
+			counts[v]++
+		}
+	}
+
+	facets := make([]models.GlobalSearchFacet, 0, len(counts))
+	for v, n := range counts {
+		facets = append(facets, models.GlobalSearchFacet{Value: v, Count: n})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
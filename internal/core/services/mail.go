@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/core/domains"
+	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/mail"
+)
+
+// Outbox worker tuning
+const (
+	outboxPollInterval   = 30 * time.Second
+	outboxBatchSize      = 20
+	outboxSendTimeout    = 2 * time.Minute
+	outboxStaleAfter     = 10 * time.Minute // a row left in sending this long is assumed abandoned
+	outboxMaxAttempts    = 8
+	outboxBaseRetryDelay = time.Minute
+	outboxMaxRetryDelay  = 6 * time.Hour
+)
+
+type MailService struct {
+	outboxRepo ports.EmailOutboxRepository
+	sender     mail.Sender
+}
+
+func NewMailService(outboxRepo ports.EmailOutboxRepository, sender mail.Sender) *MailService {
+	return &MailService{outboxRepo: outboxRepo, sender: sender}
+}
+
+// Enqueue stores a ready-made message in the outbox; the worker sends it
+func (s *MailService) Enqueue(msg mail.Message) (*domains.EmailOutbox, error) {
+	return s.enqueue(msg, "", "")
+}
+
+// EnqueueTemplate renders templates/<name>.<lang>.* and stores the result in the outbox
+func (s *MailService) EnqueueTemplate(to, cc []string, name, lang string, data interface{}) (*domains.EmailOutbox, error) {
+	return s.EnqueueTemplateMessage(mail.Message{To: to, Cc: cc}, name, lang, data)
+}
+
+// EnqueueTemplateMessage renders the template into msg, keeping its recipients and unsubscribe URL
+func (s *MailService) EnqueueTemplateMessage(msg mail.Message, name, lang string, data interface{}) (*domains.EmailOutbox, error) {
+	rendered, err := mail.Render(name, lang, data)
+	if err != nil {
+		return nil, err
+	}
+	msg.Subject, msg.HTML, msg.Text = rendered.Subject, rendered.HTML, rendered.Text
+	return s.enqueue(msg, name, lang)
+}
+
+func (s *MailService) enqueue(msg mail.Message, template, lang string) (*domains.EmailOutbox, error) {
+	to, err := mail.ParseAddressList(strings.Join(cleanAddresses(msg.To), ","))
+	if err != nil {
+		return nil, err
+	}
+	if len(to) == 0 {
+		return nil, errors.New("mail: no recipients")
+	}
+	cc, err := mail.ParseAddressList(strings.Join(cleanAddresses(msg.Cc), ","))
+	if err != nil {
+		return nil, err
+	}
+
+	email := &domains.EmailOutbox{
+		ToAddresses:    strings.Join(to, ","),
+		CcAddresses:    strings.Join(cc, ","),
+		Subject:        msg.Subject,
+		HTMLBody:       msg.HTML,
+		TextBody:       msg.Text,
+		UnsubscribeURL: msg.UnsubscribeURL,
+		Template:       template,
+		Lang:           lang,
+		Status:         domains.EmailPending,
+		MaxAttempts:    outboxMaxAttempts,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := s.outboxRepo.CreateEmail(email); err != nil {
+		return nil, err
+	}
+	return email, nil
+}
+
+// StartOutboxWorker polls the outbox until ctx is cancelled. Run it in its own goroutine.
+func (s *MailService) StartOutboxWorker(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	log.Println("[mail] outbox worker started")
+	for {
+		s.ProcessOutbox(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Println("[mail] outbox worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOutbox sends one batch of due messages
+func (s *MailService) ProcessOutbox(ctx context.Context) {
+	now := time.Now()
+	if n, err := s.outboxRepo.ResetStaleSending(now.Add(-outboxStaleAfter)); err == nil && n > 0 {
+		log.Printf("[mail] requeued %d abandoned message(s)", n)
+	}
+
+	emails, err := s.outboxRepo.GetDueEmails(now, outboxBatchSize)
+	if err != nil {
+		return
+	}
+
+	for _, email := range emails {
+		if ctx.Err() != nil {
+			return
+		}
+		claimed, err := s.outboxRepo.ClaimEmail(email.EmailOutboxID)
+		if err != nil || !claimed {
+			continue
+		}
+		s.deliver(ctx, email)
+	}
+}
+
+func (s *MailService) deliver(ctx context.Context, email domains.EmailOutbox) {
+	msg := mail.Message{
+		To:      splitAddresses(email.ToAddresses),
+		Cc:      splitAddresses(email.CcAddresses),
+		Subject: email.Subject,
+		HTML:    email.HTMLBody,
+		Text:    email.TextBody,
+
+		UnsubscribeURL: email.UnsubscribeURL,
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+	err := s.sender.Send(sendCtx, msg)
+	cancel()
+
+	if err == nil {
+		if err := s.outboxRepo.MarkEmailSent(email.EmailOutboxID, time.Now()); err != nil {
+			log.Printf("[mail] sent #%d but could not mark it: %v", email.EmailOutboxID, err)
+		}
+		return
+	}
+
+	attempts := email.Attempts + 1
+	maxAttempts := email.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = outboxMaxAttempts
+	}
+
+	status := domains.EmailPending
+	if attempts >= maxAttempts {
+		status = domains.EmailFailed
+	}
+	next := time.Now().Add(retryDelay(attempts))
+	log.Printf("[mail] send #%d failed (attempt %d/%d): %v", email.EmailOutboxID, attempts, maxAttempts, err)
+
+	if err := s.outboxRepo.MarkEmailFailed(email.EmailOutboxID, attempts, status, next, err.Error()); err != nil {
+		log.Printf("[mail] could not record failure of #%d: %v", email.EmailOutboxID, err)
+	}
+}
+
+// retryDelay doubles from one minute per failed attempt, capped at six hours
+func retryDelay(attempts int) time.Duration {
+	delay := outboxBaseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxRetryDelay {
+			return outboxMaxRetryDelay
+		}
+	}
+	return delay
+}
+
+func cleanAddresses(addrs []string) []string {
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func splitAddresses(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return cleanAddresses(strings.Split(s, ","))
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/mail"
+)
+
+// memoryOutbox keeps the outbox in memory
+type memoryOutbox struct {
+	emails map[int]*domains.EmailOutbox
+	nextID int
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{emails: map[int]*domains.EmailOutbox{}}
+}
+
+func (r *memoryOutbox) CreateEmail(email *domains.EmailOutbox) error {
+	r.nextID++
+	email.EmailOutboxID = r.nextID
+	copied := *email
+	r.emails[email.EmailOutboxID] = &copied
+	return nil
+}
+
+func (r *memoryOutbox) GetDueEmails(now time.Time, limit int) ([]domains.EmailOutbox, error) {
+	var due []domains.EmailOutbox
+	for id := 1; id <= r.nextID && len(due) < limit; id++ {
+		if e, ok := r.emails[id]; ok && e.Status == domains.EmailPending && !e.NextAttemptAt.After(now) {
+			due = append(due, *e)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryOutbox) ClaimEmail(id int) (bool, error) {
+	e, ok := r.emails[id]
+	if !ok || e.Status != domains.EmailPending {
+		return false, nil
+	}
+	e.Status = domains.EmailSending
+	return true, nil
+}
+
+func (r *memoryOutbox) MarkEmailSent(id int, sentAt time.Time) error {
+	e := r.emails[id]
+	e.Status, e.SentAt = domains.EmailSent, &sentAt
+	return nil
+}
+
+func (r *memoryOutbox) MarkEmailFailed(id int, attempts int, status string, nextAttemptAt time.Time, lastError string) error {
+	e := r.emails[id]
+	e.Attempts, e.Status, e.NextAttemptAt, e.LastError = attempts, status, nextAttemptAt, lastError
+	return nil
+}
+
+func (r *memoryOutbox) ResetStaleSending(olderThan time.Time) (int64, error) {
+	return 0, nil
+}
+
+// recordingSender remembers the messages it was given and fails with err when set
+type recordingSender struct {
+	sent []mail.Message
+	err  error
+}
+
+func (s *recordingSender) Send(_ context.Context, msg mail.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, outboxMaxRetryDelay},
+		{50, outboxMaxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestProcessOutboxMarksSent(t *testing.T) {
+	repo, sender := newMemoryOutbox(), &recordingSender{}
+	srv := NewMailService(repo, sender)
+
+	email, err := srv.EnqueueTemplateMessage(mail.Message{
+		To:             []string{"Somchai <somchai@example.com>", " "},
+		UnsubscribeURL: "https://portal.example.com/unsubscribe?token=abc",
+	}, "notification", mail.LangEN, mail.NotificationData{Title: "Hello"})
+	if err != nil {
+		t.Fatalf("EnqueueTemplateMessage: %v", err)
+	}
+	if email.ToAddresses != "somchai@example.com" || email.Template != "notification" || email.Lang != mail.LangEN {
+		t.Errorf("queued email = %+v", email)
+	}
+
+	srv.ProcessOutbox(context.Background())
+
+	stored := repo.emails[email.EmailOutboxID]
+	if stored.Status != domains.EmailSent || stored.SentAt == nil {
+		t.Fatalf("status = %s, sent_at = %v", stored.Status, stored.SentAt)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("sent %d message(s)", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.Subject != "[PSTH Info Portal] Hello" || msg.UnsubscribeURL != "https://portal.example.com/unsubscribe?token=abc" {
+		t.Errorf("delivered message = %+v", msg)
+	}
+}
+
+func TestProcessOutboxRetriesThenFails(t *testing.T) {
+	repo, sender := newMemoryOutbox(), &recordingSender{err: errors.New("421 try again later")}
+	srv := NewMailService(repo, sender)
+
+	email, err := srv.Enqueue(mail.Message{To: []string{"a@example.com"}, Subject: "x", Text: "x"})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	stored := repo.emails[email.EmailOutboxID]
+
+	before := time.Now()
+	srv.ProcessOutbox(context.Background())
+	if stored.Status != domains.EmailPending || stored.Attempts != 1 || !strings.Contains(stored.LastError, "421") {
+		t.Fatalf("after first failure: status %s, attempts %d, error %q", stored.Status, stored.Attempts, stored.LastError)
+	}
+	if stored.NextAttemptAt.Before(before.Add(retryDelay(1))) {
+		t.Errorf("next attempt %v is earlier than the retry delay", stored.NextAttemptAt)
+	}
+
+	// the last allowed attempt gives up
+	stored.Attempts, stored.NextAttemptAt = outboxMaxAttempts-1, time.Now()
+	srv.ProcessOutbox(context.Background())
+	if stored.Status != domains.EmailFailed || stored.Attempts != outboxMaxAttempts {
+		t.Fatalf("after last attempt: status %s, attempts %d", stored.Status, stored.Attempts)
+	}
+}
+
+func TestEnqueueRejectsBadRecipients(t *testing.T) {
+	srv := NewMailService(newMemoryOutbox(), &recordingSender{})
+	if _, err := srv.Enqueue(mail.Message{To: []string{" "}}); err == nil {
+		t.Error("Enqueue without recipients should fail")
+	}
+	if _, err := srv.Enqueue(mail.Message{To: []string{"not an address"}}); err == nil {
+		t.Error("Enqueue with an invalid address should fail")
+	}
+}
@@ -10,11 +10,12 @@ import (
 )
 
 type OrganizationDocService struct {
-	repo portRepositories.OrganizationDocRepository
+	repo    portRepositories.OrganizationDocRepository
+	textSrv portServices.DocumentTextService
 }
 
-func NewOrganizationDocService(repo portRepositories.OrganizationDocRepository) portServices.OrganizationDocService {
-	return &OrganizationDocService{repo: repo}
+func NewOrganizationDocService(repo portRepositories.OrganizationDocRepository, textSrv portServices.DocumentTextService) portServices.OrganizationDocService {
+	return &OrganizationDocService{repo: repo, textSrv: textSrv}
 }
 
 func (s *OrganizationDocService) CreateOrganizationDoc(req *models.CreateOrganizationDocRequest) (*models.OrganizationDocResponse, error) {
@@ -40,12 +41,15 @@ func (s *OrganizationDocService) CreateOrganizationDoc(req *models.CreateOrganiz
 		return nil, err
 	}
 
+	files := lookupStoredFiles(doc.FileName)
 	return &models.OrganizationDocResponse{
 		OrganizationDocID: doc.OrganizationDocID,
 		Name:              doc.Name,
 		Desc:              doc.Desc,
 		Department:        doc.Department,
 		FileName:          doc.FileName,
+		Thumbnails:        files.thumbnails(doc.FileName),
+		FileSHA256:        files.sha256(doc.FileName),
 		CreatedAt:         doc.CreatedAt,
 		UpdatedAt:         doc.UpdatedAt,
 	}, nil
@@ -57,12 +61,15 @@ func (s *OrganizationDocService) GetOrganizationDocByID(id int) (*models.Organiz
 		return nil, err
 	}
 
+	files := lookupStoredFiles(doc.FileName)
 	return &models.OrganizationDocResponse{
 		OrganizationDocID: doc.OrganizationDocID,
 		Name:              doc.Name,
 		Desc:              doc.Desc,
 		Department:        doc.Department,
 		FileName:          doc.FileName,
+		Thumbnails:        files.thumbnails(doc.FileName),
+		FileSHA256:        files.sha256(doc.FileName),
 		CreatedAt:         doc.CreatedAt,
 		UpdatedAt:         doc.UpdatedAt,
 	}, nil
@@ -82,6 +89,7 @@ func (s *OrganizationDocService) GetAllOrganizationDocs(limit, offset int) (*mod
 	}
 
 	var responses []models.OrganizationDocResponse
+	files := organizationDocFiles(docs...)
 	for _, doc := range docs {
 		responses = append(responses, models.OrganizationDocResponse{
 			OrganizationDocID: doc.OrganizationDocID,
@@ -89,6 +97,8 @@ func (s *OrganizationDocService) GetAllOrganizationDocs(limit, offset int) (*mod
 			Desc:              doc.Desc,
 			Department:        doc.Department,
 			FileName:          doc.FileName,
+			Thumbnails:        files.thumbnails(doc.FileName),
+			FileSHA256:        files.sha256(doc.FileName),
 			CreatedAt:         doc.CreatedAt,
 			UpdatedAt:         doc.UpdatedAt,
 		})
@@ -120,6 +130,7 @@ func (s *OrganizationDocService) GetOrganizationDocByDepartment(department strin
 	}
 
 	var responses []models.OrganizationDocResponse
+	files := organizationDocFiles(docs...)
 	for _, doc := range docs {
 		responses = append(responses, models.OrganizationDocResponse{
 			OrganizationDocID: doc.OrganizationDocID,
@@ -127,6 +138,8 @@ func (s *OrganizationDocService) GetOrganizationDocByDepartment(department strin
 			Desc:              doc.Desc,
 			Department:        doc.Department,
 			FileName:          doc.FileName,
+			Thumbnails:        files.thumbnails(doc.FileName),
+			FileSHA256:        files.sha256(doc.FileName),
 			CreatedAt:         doc.CreatedAt,
 			UpdatedAt:         doc.UpdatedAt,
 		})
@@ -178,20 +191,20 @@ func (s *OrganizationDocService) DeleteOrganizationDoc(id int) error {
 	return s.repo.DeleteOrganizationDoc(id)
 }
 
-func (s *OrganizationDocService) SearchOrganizationDocs(keyword string, limit, offset int) (*models.OrganizationDocListResponse, error) {
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100
-	}
-
-	docs, total, err := s.repo.SearchOrganizationDocs(keyword, limit, offset)
+func (s *OrganizationDocService) SearchOrganizationDocs(search domains.DocumentSearch, limit, offset int) (*models.OrganizationDocListResponse, error) {
+	docs, total, err := s.repo.SearchOrganizationDocs(search, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.OrganizationDocResponse
+	ids := make([]int, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.OrganizationDocID
+	}
+	matches := documentPageMatches(s.textSrv, domains.DocModuleOrganizationDocs, ids, search.Keyword)
+
+	responses := make([]models.OrganizationDocResponse, 0, len(docs))
+	files := organizationDocFiles(docs...)
 	for _, doc := range docs {
 		responses = append(responses, models.OrganizationDocResponse{
 			OrganizationDocID: doc.OrganizationDocID,
@@ -199,8 +212,11 @@ func (s *OrganizationDocService) SearchOrganizationDocs(keyword string, limit, o
 			Desc:              doc.Desc,
 			Department:        doc.Department,
 			FileName:          doc.FileName,
+			Thumbnails:        files.thumbnails(doc.FileName),
+			FileSHA256:        files.sha256(doc.FileName),
 			CreatedAt:         doc.CreatedAt,
 			UpdatedAt:         doc.UpdatedAt,
+			Matches:           matches[doc.OrganizationDocID],
 		})
 	}
 
@@ -259,10 +275,19 @@ func (s *OrganizationDocService) DeleteOrganizationDocService(id int) error {
 }
 
 // SearchOrganizationDocService implements the port interface
-func (s *OrganizationDocService) SearchOrganizationDocService(keyword string, page, pageSize int) (*models.OrganizationDocListResponse, error) {
-	if page < 1 {
-		page = 1
+func (s *OrganizationDocService) SearchOrganizationDocService(req models.DocumentSearchRequest) (*models.OrganizationDocListResponse, error) {
+	search, limit, offset, err := documentSearch(domains.DocModuleOrganizationDocs, req)
+	if err != nil {
+		return nil, err
 	}
-	offset := (page - 1) * pageSize
-	return s.SearchOrganizationDocs(keyword, pageSize, offset)
+	return s.SearchOrganizationDocs(search, limit, offset)
+}
+
+// organizationDocFiles looks up the stored files of a page of organization documents
+func organizationDocFiles(docs ...domains.OrganizationDoc) storedFiles {
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.FileName
+	}
+	return lookupStoredFiles(names...)
 }
@@ -7,15 +7,17 @@ import (
 
 	"backend/internal/core/domains"
 	"backend/internal/core/models"
+	portServices "backend/internal/core/ports/services"
 	"backend/internal/repositories"
 )
 
 type ProcedureManualService struct {
-	repo *repositories.ProcedureManualRepository
+	repo    *repositories.ProcedureManualRepository
+	textSrv portServices.DocumentTextService
 }
 
-func NewProcedureManualService(repo *repositories.ProcedureManualRepository) *ProcedureManualService {
-	return &ProcedureManualService{repo: repo}
+func NewProcedureManualService(repo *repositories.ProcedureManualRepository, textSrv portServices.DocumentTextService) *ProcedureManualService {
+	return &ProcedureManualService{repo: repo, textSrv: textSrv}
 }
 
 // CreateProcedureManual creates a new procedure manual
@@ -43,12 +45,15 @@ func (s *ProcedureManualService) CreateProcedureManual(req *models.CreateProcedu
 		return nil, err
 	}
 
+	files := lookupStoredFiles(manual.FileName)
 	return &models.ProcedureManualResponse{
 		ProcedureManualID:   manual.ProcedureManualID,
 		ProcedureManualName: manual.ProcedureManualName,
 		Desc:                manual.Desc,
 		Category:            manual.Category,
 		FileName:            manual.FileName,
+		Thumbnails:          files.thumbnails(manual.FileName),
+		FileSHA256:          files.sha256(manual.FileName),
 		CreatedAt:           manual.CreatedAt,
 		UpdatedAt:           manual.UpdatedAt,
 	}, nil
@@ -61,12 +66,15 @@ func (s *ProcedureManualService) GetProcedureManualByID(id int) (*models.Procedu
 		return nil, err
 	}
 
+	files := lookupStoredFiles(manual.FileName)
 	return &models.ProcedureManualResponse{
 		ProcedureManualID:   manual.ProcedureManualID,
 		ProcedureManualName: manual.ProcedureManualName,
 		Desc:                manual.Desc,
 		Category:            manual.Category,
 		FileName:            manual.FileName,
+		Thumbnails:          files.thumbnails(manual.FileName),
+		FileSHA256:          files.sha256(manual.FileName),
 		CreatedAt:           manual.CreatedAt,
 		UpdatedAt:           manual.UpdatedAt,
 	}, nil
@@ -94,6 +102,7 @@ func (s *ProcedureManualService) GetAllProcedureManuals(page, pageSize int) (*mo
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	files := procedureManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.ProcedureManualResponse{
 			ProcedureManualID:   manual.ProcedureManualID,
@@ -101,6 +110,8 @@ func (s *ProcedureManualService) GetAllProcedureManuals(page, pageSize int) (*mo
 			Desc:                manual.Desc,
 			Category:            manual.Category,
 			FileName:            manual.FileName,
+			Thumbnails:          files.thumbnails(manual.FileName),
+			FileSHA256:          files.sha256(manual.FileName),
 			CreatedAt:           manual.CreatedAt,
 			UpdatedAt:           manual.UpdatedAt,
 		}
@@ -131,6 +142,7 @@ func (s *ProcedureManualService) GetProcedureManualsByCategory(category string,
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	files := procedureManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.ProcedureManualResponse{
 			ProcedureManualID:   manual.ProcedureManualID,
@@ -138,6 +150,8 @@ func (s *ProcedureManualService) GetProcedureManualsByCategory(category string,
 			Desc:                manual.Desc,
 			Category:            manual.Category,
 			FileName:            manual.FileName,
+			Thumbnails:          files.thumbnails(manual.FileName),
+			FileSHA256:          files.sha256(manual.FileName),
 			CreatedAt:           manual.CreatedAt,
 			UpdatedAt:           manual.UpdatedAt,
 		}
@@ -187,12 +201,15 @@ func (s *ProcedureManualService) UpdateProcedureManual(id int, req *models.Updat
 		return nil, err
 	}
 
+	files := lookupStoredFiles(updatedManual.FileName)
 	return &models.ProcedureManualResponse{
 		ProcedureManualID:   updatedManual.ProcedureManualID,
 		ProcedureManualName: updatedManual.ProcedureManualName,
 		Desc:                updatedManual.Desc,
 		Category:            updatedManual.Category,
 		FileName:            updatedManual.FileName,
+		Thumbnails:          files.thumbnails(updatedManual.FileName),
+		FileSHA256:          files.sha256(updatedManual.FileName),
 		CreatedAt:           updatedManual.CreatedAt,
 		UpdatedAt:           updatedManual.UpdatedAt,
 	}, nil
@@ -204,15 +221,8 @@ func (s *ProcedureManualService) DeleteProcedureManual(id int) error {
 }
 
 // SearchProcedureManuals searches procedure manuals by keyword
-func (s *ProcedureManualService) SearchProcedureManuals(keyword string, page, pageSize int) (*models.ProcedureManualListResponse, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 50
-	}
-
-	manuals, total, err := s.repo.SearchProcedureManuals(keyword, page, pageSize)
+func (s *ProcedureManualService) SearchProcedureManuals(search domains.DocumentSearch, page, pageSize int) (*models.ProcedureManualListResponse, error) {
+	manuals, total, err := s.repo.SearchProcedureManuals(search, page, pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +235,13 @@ func (s *ProcedureManualService) SearchProcedureManuals(keyword string, page, pa
 		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
 	}
 
+	ids := make([]int, len(manuals))
+	for i, manual := range manuals {
+		ids[i] = manual.ProcedureManualID
+	}
+	matches := documentPageMatches(s.textSrv, domains.DocModuleProcedureManual, ids, search.Keyword)
+
+	files := procedureManualFiles(manuals...)
 	for i, manual := range manuals {
 		resp.Data[i] = models.ProcedureManualResponse{
 			ProcedureManualID:   manual.ProcedureManualID,
@@ -232,8 +249,11 @@ func (s *ProcedureManualService) SearchProcedureManuals(keyword string, page, pa
 			Desc:                manual.Desc,
 			Category:            manual.Category,
 			FileName:            manual.FileName,
+			Thumbnails:          files.thumbnails(manual.FileName),
+			FileSHA256:          files.sha256(manual.FileName),
 			CreatedAt:           manual.CreatedAt,
 			UpdatedAt:           manual.UpdatedAt,
+			Matches:             matches[manual.ProcedureManualID],
 		}
 	}
 
@@ -283,11 +303,23 @@ func (s *ProcedureManualService) DeleteProcedureManualService(id int) error {
 }
 
 // SearchProcedureManualService implements the port interface
-func (s *ProcedureManualService) SearchProcedureManualService(keyword string, limit, offset int) (models.ProcedureManualListResponse, error) {
-	page := offset/limit + 1
-	resp, err := s.SearchProcedureManuals(keyword, page, limit)
+func (s *ProcedureManualService) SearchProcedureManualService(req models.DocumentSearchRequest) (models.ProcedureManualListResponse, error) {
+	search, limit, offset, err := documentSearch(domains.DocModuleProcedureManual, req)
+	if err != nil {
+		return models.ProcedureManualListResponse{}, err
+	}
+	resp, err := s.SearchProcedureManuals(search, offset/limit+1, limit)
 	if err != nil {
 		return models.ProcedureManualListResponse{}, err
 	}
 	return *resp, nil
 }
+
+// procedureManualFiles looks up the stored files of a page of procedure manuals
+func procedureManualFiles(manuals ...domains.ProcedureManual) storedFiles {
+	names := make([]string, len(manuals))
+	for i, manual := range manuals {
+		names[i] = manual.FileName
+	}
+	return lookupStoredFiles(names...)
+}
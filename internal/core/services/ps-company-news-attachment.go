@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/logs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/utils"
+)
+
+func (s *CompanyNewsService) AddCompanyNewsAttachments(companyNewsID, usernameCreator string, uploads []models.CompanyNewsAttachmentUpload) ([]models.CompanyNewsAttachmentResp, error) {
+	if len(uploads) == 0 {
+		return nil, errs.NewError("at least one file is required")
+	}
+
+	parsedID, err := uuid.Parse(companyNewsID)
+	if err != nil {
+		return nil, errs.NewError("invalid company news ID")
+	}
+	companyNewsID = parsedID.String()
+
+	if _, err := s.companyNewsRepo.GetCompanyNewsByID(companyNewsID, domains.NewsViewer{SeeAll: true}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NewNotfoundError("company news not found")
+		}
+		return nil, err
+	}
+
+	attachments := make([]domains.CompanyNewsAttachment, 0, len(uploads))
+	for _, u := range uploads {
+		kind := domains.AttachmentDocument
+		if strings.HasPrefix(u.MimeType, "image/") {
+			kind = domains.AttachmentImage
+		}
+		attachments = append(attachments, domains.CompanyNewsAttachment{
+			CompanyNewsID:   companyNewsID,
+			Kind:            kind,
+			FilePath:        u.FilePath,
+			OriginalName:    u.OriginalName,
+			MimeType:        u.MimeType,
+			FileSize:        u.FileSize,
+			Caption:         strings.TrimSpace(u.Caption),
+			UsernameCreator: usernameCreator,
+		})
+	}
+
+	if err := s.companyNewsRepo.CreateCompanyNewsAttachments(attachments); err != nil {
+		logs.Error(err)
+		return nil, fmt.Errorf("failed to save company news attachments: %w", err)
+	}
+
+	return toCompanyNewsAttachmentModels(attachments), nil
+}
+
+func (s *CompanyNewsService) GetCompanyNewsAttachments(companyNewsID, empCode, role string) ([]models.CompanyNewsAttachmentResp, error) {
+	if _, err := s.companyNewsRepo.GetCompanyNewsByID(companyNewsID, s.newsViewer(empCode, role)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NewNotfoundError("company news not found")
+		}
+		return nil, err
+	}
+
+	attachments, err := s.companyNewsRepo.GetCompanyNewsAttachments(companyNewsID)
+	if err != nil {
+		return nil, err
+	}
+	return toCompanyNewsAttachmentModels(attachments), nil
+}
+
+func (s *CompanyNewsService) UpdateCompanyNewsAttachment(attachmentID int, req models.CompanyNewsAttachmentUpdateReq) error {
+	if req.Caption == nil {
+		return errs.NewError("no fields to update")
+	}
+
+	updates := map[string]interface{}{
+		"caption": strings.TrimSpace(*req.Caption),
+	}
+	if err := s.companyNewsRepo.UpdateCompanyNewsAttachmentWithMap(attachmentID, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("attachment not found")
+		}
+		logs.Error(err)
+		return fmt.Errorf("failed to update attachment: %w", err)
+	}
+	return nil
+}
+
+func (s *CompanyNewsService) ReorderCompanyNewsAttachments(companyNewsID string, attachmentIDs []int) error {
+	if len(attachmentIDs) == 0 {
+		return errs.NewError("attachment_ids is required")
+	}
+
+	seen := make(map[int]bool, len(attachmentIDs))
+	for _, id := range attachmentIDs {
+		if seen[id] {
+			return errs.NewError(fmt.Sprintf("duplicate attachment id %d", id))
+		}
+		seen[id] = true
+	}
+
+	if err := s.companyNewsRepo.ReorderCompanyNewsAttachments(companyNewsID, attachmentIDs); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("one or more attachments do not belong to this company news")
+		}
+		logs.Error(err)
+		return fmt.Errorf("failed to reorder attachments: %w", err)
+	}
+	return nil
+}
+
+func (s *CompanyNewsService) DeleteCompanyNewsAttachment(attachmentID int) error {
+	attachment, err := s.companyNewsRepo.GetCompanyNewsAttachmentByID(attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("attachment not found")
+		}
+		return err
+	}
+
+	if err := s.companyNewsRepo.DeleteCompanyNewsAttachment(attachmentID); err != nil {
+		logs.Error(err)
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	if err := storage.Default().Delete(context.Background(), attachment.FilePath); err != nil {
+		log.Printf("[DeleteCompanyNewsAttachment] Failed to remove file %s: %v\n", attachment.FilePath, err)
+	}
+	return nil
+}
+
+func toCompanyNewsAttachmentModels(attachments []domains.CompanyNewsAttachment) []models.CompanyNewsAttachmentResp {
+	out := make([]models.CompanyNewsAttachmentResp, 0, len(attachments))
+	files := companyNewsAttachmentFiles(attachments...)
+	for _, a := range attachments {
+		out = append(out, models.CompanyNewsAttachmentResp{
+			CompanyNewsAttachmentID: a.CompanyNewsAttachmentID,
+			CompanyNewsID:           a.CompanyNewsID,
+			Kind:                    a.Kind,
+			FilePath:                a.FilePath,
+			SHA256:                  files.sha256(a.FilePath),
+			URL:                     utils.FileURL("", a.FilePath),
+			OriginalName:            a.OriginalName,
+			MimeType:                a.MimeType,
+			FileSize:                a.FileSize,
+			Caption:                 a.Caption,
+			SortOrder:               a.SortOrder,
+			UsernameCreator:         a.UsernameCreator,
+			CreatedAt:               a.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return out
+}
+
+// companyNewsAttachmentFiles looks up the stored files of the attachments of a news item
+func companyNewsAttachmentFiles(attachments ...domains.CompanyNewsAttachment) storedFiles {
+	paths := make([]string, len(attachments))
+	for i, a := range attachments {
+		paths[i] = a.FilePath
+	}
+	return lookupStoredFiles(paths...)
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	servicesports "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/logs"
+	"backend/internal/pkgs/mail"
+	"backend/internal/pkgs/utils"
+)
+
+const (
+	digestTemplate          = "company-news-digest"
+	digestDefaultPeriod     = 7 * 24 * time.Hour
+	digestMaxPeriod         = 31 * 24 * time.Hour
+	digestItemLimit         = 50
+	digestSummaryLength     = 180
+	digestSchedulerInterval = 15 * time.Minute
+	digestImageURLTTL       = 30 * 24 * time.Hour // mail clients cannot send a token, so cover images use signed URLs
+)
+
+type CompanyNewsDigestService struct {
+	companyNewsRepo ports.CompanyNewsRepository
+	digestRepo      ports.CompanyNewsDigestRepository
+	userRepo        ports.UserRepository
+	mailSrv         servicesports.MailService
+}
+
+func NewCompanyNewsDigestService(companyNewsRepo ports.CompanyNewsRepository, digestRepo ports.CompanyNewsDigestRepository, userRepo ports.UserRepository, mailSrv servicesports.MailService) *CompanyNewsDigestService {
+	return &CompanyNewsDigestService{
+		companyNewsRepo: companyNewsRepo,
+		digestRepo:      digestRepo,
+		userRepo:        userRepo,
+		mailSrv:         mailSrv,
+	}
+}
+
+// digestData feeds templates/company-news-digest.<lang>.*
+type digestData struct {
+	Name           string
+	PeriodFrom     string
+	PeriodTo       string
+	Total          int
+	Categories     []digestCategory
+	PortalURL      string
+	UnsubscribeURL string
+}
+
+type digestCategory struct {
+	Name  string
+	Items []digestItem
+}
+
+type digestItem struct {
+	Title    string
+	Summary  string
+	URL      string
+	ImageURL string
+	Date     string
+}
+
+// ====================== Subscription ===================================
+
+func (s *CompanyNewsDigestService) GetDigestSubscription(empCode string) (models.CompanyNewsDigestSubscriptionResp, error) {
+	emp, err := s.userRepo.GetEmployeeByEmpCode(empCode)
+	if err != nil || emp == nil {
+		return models.CompanyNewsDigestSubscriptionResp{}, errs.NewNotfoundError("employee not found")
+	}
+
+	resp := models.CompanyNewsDigestSubscriptionResp{EmpCode: emp.UHR_EmpCode, Email: emp.AD_Mail, Lang: mail.LangTH}
+	sub, err := s.digestRepo.GetDigestSubscription(emp.UHR_EmpCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return resp, nil
+		}
+		return models.CompanyNewsDigestSubscriptionResp{}, err
+	}
+
+	resp.Subscribed = sub.Subscribed
+	resp.Lang = sub.Lang
+	return resp, nil
+}
+
+func (s *CompanyNewsDigestService) UpdateDigestSubscription(empCode string, req models.CompanyNewsDigestSubscriptionReq) (models.CompanyNewsDigestSubscriptionResp, error) {
+	lang := strings.ToLower(strings.TrimSpace(req.Lang))
+	if lang != "" && lang != mail.LangTH && lang != mail.LangEN {
+		return models.CompanyNewsDigestSubscriptionResp{}, errs.NewError("lang must be th or en")
+	}
+
+	emp, err := s.userRepo.GetEmployeeByEmpCode(empCode)
+	if err != nil || emp == nil {
+		return models.CompanyNewsDigestSubscriptionResp{}, errs.NewNotfoundError("employee not found")
+	}
+
+	sub, err := s.digestRepo.GetDigestSubscription(emp.UHR_EmpCode)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CompanyNewsDigestSubscriptionResp{}, err
+		}
+		token, err := newUnsubscribeToken()
+		if err != nil {
+			return models.CompanyNewsDigestSubscriptionResp{}, err
+		}
+		sub = domains.CompanyNewsDigestSubscription{EmpCode: emp.UHR_EmpCode, Subscribed: true, Lang: mail.LangTH, UnsubscribeToken: token}
+	}
+
+	if req.Subscribed != nil {
+		sub.Subscribed = *req.Subscribed
+	}
+	if lang != "" {
+		sub.Lang = lang
+	}
+	if err := s.digestRepo.SaveDigestSubscription(&sub); err != nil {
+		return models.CompanyNewsDigestSubscriptionResp{}, err
+	}
+
+	if sub.Subscribed && strings.TrimSpace(emp.AD_Mail) == "" {
+		log.Printf("[CompanyNewsDigest] %s subscribed but has no AD_Mail, no digest will be sent\n", emp.UHR_EmpCode)
+	}
+
+	return models.CompanyNewsDigestSubscriptionResp{
+		EmpCode:    emp.UHR_EmpCode,
+		Email:      emp.AD_Mail,
+		Subscribed: sub.Subscribed,
+		Lang:       sub.Lang,
+	}, nil
+}
+
+// UnsubscribeDigest handles the confirmation posted from the digest's unsubscribe page and the
+// one-click POST mail clients send for its List-Unsubscribe header
+func (s *CompanyNewsDigestService) UnsubscribeDigest(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errs.NewError("token is required")
+	}
+	ok, err := s.digestRepo.UnsubscribeDigestByToken(token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.NewNotfoundError("subscription not found")
+	}
+	return nil
+}
+
+// ====================== Preview & Send ===================================
+
+// PreviewDigest renders the digest of the last days as empCode would receive it.
+// Without empCode the preview shows every article, as seen by an editor.
+func (s *CompanyNewsDigestService) PreviewDigest(empCode, lang string, days int, baseURL string) (mail.Rendered, error) {
+	to := time.Now()
+	from := to.Add(-digestPeriod(days))
+
+	viewer := domains.NewsViewer{SeeAll: true}
+	name := ""
+	if empCode != "" {
+		emp, err := s.userRepo.GetEmployeeByEmpCode(empCode)
+		if err != nil || emp == nil {
+			return mail.Rendered{}, errs.NewNotfoundError("employee not found")
+		}
+		viewer = employeeNewsViewer(*emp, "")
+		name = digestRecipientName(*emp, lang)
+	}
+
+	news, err := s.periodNews(viewer, from, to)
+	if err != nil {
+		return mail.Rendered{}, err
+	}
+
+	data := buildDigestData(news, from, to, lang, baseURL)
+	data.Name = name
+	data.UnsubscribeURL = digestUnsubscribeURL(baseURL, "preview")
+	return mail.Render(digestTemplate, lang, data)
+}
+
+// SendDigestNow queues the digest of the last days for every subscriber
+func (s *CompanyNewsDigestService) SendDigestNow(days int, baseURL, actor string) (models.CompanyNewsDigestSendResp, error) {
+	to := time.Now()
+	return s.sendDigest(to.Add(-digestPeriod(days)), to, baseURL, domains.DigestTriggerManual, actor)
+}
+
+func (s *CompanyNewsDigestService) sendDigest(from, to time.Time, baseURL, trigger, actor string) (models.CompanyNewsDigestSendResp, error) {
+	recipients, err := s.digestRepo.GetDigestRecipients()
+	if err != nil {
+		logs.Error(err)
+		return models.CompanyNewsDigestSendResp{}, fmt.Errorf("failed to load digest recipients: %w", err)
+	}
+
+	// Recipients with the same audience attributes see the same articles
+	cache := make(map[domains.NewsViewer][]domains.CompanyNewsSearchHit)
+	sent, skipped := 0, 0
+	for _, rcpt := range recipients {
+		viewer := employeeNewsViewer(rcpt.PSEmployee, "")
+		viewer.EmpCode = ""
+
+		news, ok := cache[viewer]
+		if !ok {
+			news, err = s.periodNews(viewer, from, to)
+			if err != nil {
+				return models.CompanyNewsDigestSendResp{}, err
+			}
+			cache[viewer] = news
+		}
+		if len(news) == 0 {
+			skipped++
+			continue
+		}
+
+		data := buildDigestData(news, from, to, rcpt.Lang, baseURL)
+		data.Name = digestRecipientName(rcpt.PSEmployee, rcpt.Lang)
+		data.UnsubscribeURL = digestUnsubscribeURL(baseURL, rcpt.UnsubscribeToken)
+
+		msg := mail.Message{To: []string{rcpt.AD_Mail}, UnsubscribeURL: data.UnsubscribeURL}
+		if _, err := s.mailSrv.EnqueueTemplateMessage(msg, digestTemplate, rcpt.Lang, data); err != nil {
+			log.Printf("[CompanyNewsDigest] Failed to queue digest for %s: %v\n", rcpt.UHR_EmpCode, err)
+			skipped++
+			continue
+		}
+		sent++
+	}
+
+	run := &domains.CompanyNewsDigestRun{
+		PeriodFrom: from,
+		PeriodTo:   to,
+		Recipients: sent,
+		Skipped:    skipped,
+		Trigger:    trigger,
+		Username:   actor,
+	}
+	if err := s.digestRepo.CreateDigestRun(run); err != nil {
+		logs.Error(err)
+	}
+
+	log.Printf("[CompanyNewsDigest] %s digest %s - %s queued for %d recipient(s), %d skipped\n",
+		trigger, from.Format(time.RFC3339), to.Format(time.RFC3339), sent, skipped)
+
+	return models.CompanyNewsDigestSendResp{
+		PeriodFrom: from.Format(time.RFC3339),
+		PeriodTo:   to.Format(time.RFC3339),
+		Recipients: sent,
+		Skipped:    skipped,
+	}, nil
+}
+
+func (s *CompanyNewsDigestService) periodNews(viewer domains.NewsViewer, from, to time.Time) ([]domains.CompanyNewsSearchHit, error) {
+	hits, _, err := s.companyNewsRepo.SearchCompanyNews(domains.CompanyNewsSearch{
+		DateFrom: &from,
+		DateTo:   &to,
+		Viewer:   viewer,
+		Limit:    digestItemLimit,
+	})
+	if err != nil {
+		logs.Error(err)
+		return nil, fmt.Errorf("failed to load company news for digest: %w", err)
+	}
+	return hits, nil
+}
+
+// ====================== Schedule ===================================
+
+// StartDigestScheduler sends the digest once a week, at COMPANY_NEWS_DIGEST_WEEKDAY (0 = Sunday, default 1)
+// and COMPANY_NEWS_DIGEST_HOUR (server local time, default 8). Set COMPANY_NEWS_DIGEST_ENABLED=false to turn it off.
+func (s *CompanyNewsDigestService) StartDigestScheduler(ctx context.Context) {
+	if os.Getenv("COMPANY_NEWS_DIGEST_ENABLED") == "false" {
+		log.Println("[CompanyNewsDigest] scheduler disabled")
+		return
+	}
+
+	weekday := envInt("COMPANY_NEWS_DIGEST_WEEKDAY", int(time.Monday), 0, 6)
+	hour := envInt("COMPANY_NEWS_DIGEST_HOUR", 8, 0, 23)
+	baseURL := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if baseURL == "" {
+		log.Println("[CompanyNewsDigest] PUBLIC_BASE_URL is not set, digest images and links will be relative")
+	}
+
+	ticker := time.NewTicker(digestSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runScheduledDigest(time.Now(), time.Weekday(weekday), hour, baseURL)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *CompanyNewsDigestService) runScheduledDigest(now time.Time, weekday time.Weekday, hour int, baseURL string) {
+	slot := lastDigestSlot(now, weekday, hour)
+
+	last, err := s.digestRepo.GetLastDigestRun()
+	if err != nil {
+		return
+	}
+	if last != nil && !last.CreatedAt.Before(slot) {
+		return
+	}
+
+	// Continue from the previous digest so nothing is missed or repeated
+	from := now.Add(-digestDefaultPeriod)
+	if last != nil && last.PeriodTo.After(now.Add(-digestMaxPeriod)) {
+		from = last.PeriodTo
+	}
+
+	if _, err := s.sendDigest(from, now, baseURL, domains.DigestTriggerSchedule, ""); err != nil {
+		log.Printf("[CompanyNewsDigest] Scheduled digest failed: %v\n", err)
+	}
+}
+
+// lastDigestSlot returns the most recent weekday/hour at or before now
+func lastDigestSlot(now time.Time, weekday time.Weekday, hour int) time.Time {
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	slot = slot.AddDate(0, 0, -int((now.Weekday()-weekday+7)%7))
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -7)
+	}
+	return slot
+}
+
+// ====================== Helpers ===================================
+
+func buildDigestData(news []domains.CompanyNewsSearchHit, from, to time.Time, lang, baseURL string) digestData {
+	linkBase := companyNewsLinkBase(baseURL)
+	data := digestData{
+		PeriodFrom: from.Format("02/01/2006"),
+		PeriodTo:   to.Format("02/01/2006"),
+		Total:      len(news),
+		PortalURL:  strings.TrimSuffix(linkBase, "/"),
+	}
+
+	index := make(map[string]int)
+	for _, hit := range news {
+		category := strings.TrimSpace(hit.Category)
+		if category == "" {
+			category = "อื่น ๆ"
+			if lang == mail.LangEN {
+				category = "Other"
+			}
+		}
+
+		i, ok := index[category]
+		if !ok {
+			i = len(data.Categories)
+			index[category] = i
+			data.Categories = append(data.Categories, digestCategory{Name: category})
+		}
+
+		summary := []rune(utils.PlainText(hit.Content))
+		if len(summary) > digestSummaryLength {
+			summary = append(summary[:digestSummaryLength], '…')
+		}
+
+		data.Categories[i].Items = append(data.Categories[i].Items, digestItem{
+			Title:    hit.Title,
+			Summary:  string(summary),
+			URL:      linkBase + hit.CompanyNewsID.String(),
+			ImageURL: signedUploadURL(baseURL, hit.CompanyNewsPhoto, digestImageURLTTL),
+			Date:     hit.CreatedAt.Format("02/01/2006"),
+		})
+	}
+	return data
+}
+
+func digestRecipientName(emp domains.PSEmployee, lang string) string {
+	if lang == mail.LangEN && strings.TrimSpace(emp.UHR_FullNameEn) != "" {
+		return emp.UHR_FullNameEn
+	}
+	if strings.TrimSpace(emp.UHR_FullNameTh) != "" {
+		return emp.UHR_FullNameTh
+	}
+	return emp.UHR_FullNameEn
+}
+
+func digestUnsubscribeURL(baseURL, token string) string {
+	return strings.TrimRight(baseURL, "/") + "/api/company-news/digest/unsubscribe?token=" + token
+}
+
+func digestPeriod(days int) time.Duration {
+	if days <= 0 {
+		return digestDefaultPeriod
+	}
+	period := time.Duration(days) * 24 * time.Hour
+	if period > digestMaxPeriod {
+		return digestMaxPeriod
+	}
+	return period
+}
+
+func newUnsubscribeToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func envInt(key string, def, min, max int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v < min || v > max {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/logs"
+)
+
+func (s *CompanyNewsService) GetCompanyNewsRevisions(companyNewsID string) ([]models.CompanyNewsRevisionResp, error) {
+	id, err := normalizeCompanyNewsID(companyNewsID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.companyNewsRepo.GetCompanyNewsRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.CompanyNewsRevisionResp, 0, len(revisions))
+	for _, rev := range revisions {
+		out = append(out, toCompanyNewsRevisionModel(rev, false))
+	}
+	return out, nil
+}
+
+func (s *CompanyNewsService) GetCompanyNewsRevision(companyNewsID string, revisionNo int) (models.CompanyNewsRevisionResp, error) {
+	rev, err := s.findRevision(companyNewsID, revisionNo)
+	if err != nil {
+		return models.CompanyNewsRevisionResp{}, err
+	}
+	return toCompanyNewsRevisionModel(rev, true), nil
+}
+
+// DiffCompanyNewsRevisions lists the fields that differ between two revisions of an article
+func (s *CompanyNewsService) DiffCompanyNewsRevisions(companyNewsID string, from, to int) (models.CompanyNewsRevisionDiffResp, error) {
+	fromRev, err := s.findRevision(companyNewsID, from)
+	if err != nil {
+		return models.CompanyNewsRevisionDiffResp{}, err
+	}
+	toRev, err := s.findRevision(companyNewsID, to)
+	if err != nil {
+		return models.CompanyNewsRevisionDiffResp{}, err
+	}
+
+	fromSnap, err := parseCompanyNewsSnapshot(fromRev.Snapshot)
+	if err != nil {
+		return models.CompanyNewsRevisionDiffResp{}, err
+	}
+	toSnap, err := parseCompanyNewsSnapshot(toRev.Snapshot)
+	if err != nil {
+		return models.CompanyNewsRevisionDiffResp{}, err
+	}
+
+	fromFields, toFields := snapshotFields(fromSnap), snapshotFields(toSnap)
+	changes := make([]models.CompanyNewsRevisionFieldChange, 0)
+	for _, field := range diffCompanyNewsSnapshots(fromSnap, toSnap) {
+		changes = append(changes, models.CompanyNewsRevisionFieldChange{
+			Field: field,
+			From:  fromFields[field],
+			To:    toFields[field],
+		})
+	}
+
+	return models.CompanyNewsRevisionDiffResp{
+		CompanyNewsID: fromRev.CompanyNewsID,
+		From:          from,
+		To:            to,
+		Changes:       changes,
+	}, nil
+}
+
+// RestoreCompanyNewsRevision writes the snapshot of an older revision back to the article as a new revision
+func (s *CompanyNewsService) RestoreCompanyNewsRevision(companyNewsID string, revisionNo int, actor string) error {
+	rev, err := s.findRevision(companyNewsID, revisionNo)
+	if err != nil {
+		return err
+	}
+	target, err := parseCompanyNewsSnapshot(rev.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	id := rev.CompanyNewsID
+	before, err := s.currentSnapshot(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewError("company news is deleted; undelete it before restoring a revision")
+		}
+		return err
+	}
+	s.ensureBaselineRevision(id, before)
+
+	changed := diffCompanyNewsSnapshots(before, target)
+	if len(changed) == 0 {
+		return errs.NewError("revision is identical to the current version")
+	}
+
+	updates := map[string]interface{}{
+		"title":              target.Title,
+		"content":            target.Content,
+		"category":           target.Category,
+		"company_news_photo": target.CompanyNewsPhoto,
+		"username_creator":   target.UsernameCreator,
+	}
+	audiences := make([]domains.CompanyNewsAudience, 0, len(target.Audiences))
+	for _, a := range target.Audiences {
+		audiences = append(audiences, domains.CompanyNewsAudience{TargetType: a.TargetType, TargetValue: a.TargetValue})
+	}
+	if err := s.companyNewsRepo.UpdateCompanyNewsWithAudiences(id, updates, audiences); err != nil {
+		logs.Error(err)
+		return fmt.Errorf("failed to restore company news: %w", err)
+	}
+
+	restoredFrom := revisionNo
+	s.recordRevision(id, domains.RevisionRestore, changed, target, actor, &restoredFrom)
+	return nil
+}
+
+// UndeleteCompanyNews brings back a soft-deleted article
+func (s *CompanyNewsService) UndeleteCompanyNews(companyNewsID, actor string) error {
+	id, err := normalizeCompanyNewsID(companyNewsID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.companyNewsRepo.UndeleteCompanyNews(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("deleted company news not found")
+		}
+		logs.Error(err)
+		return fmt.Errorf("failed to undelete company news: %w", err)
+	}
+
+	after, err := s.currentSnapshot(id)
+	if err != nil {
+		logs.Error(err)
+		return nil
+	}
+	s.recordRevision(id, domains.RevisionUndelete, nil, after, actor, nil)
+	return nil
+}
+
+func (s *CompanyNewsService) findRevision(companyNewsID string, revisionNo int) (domains.CompanyNewsRevision, error) {
+	id, err := normalizeCompanyNewsID(companyNewsID)
+	if err != nil {
+		return domains.CompanyNewsRevision{}, err
+	}
+
+	rev, err := s.companyNewsRepo.GetCompanyNewsRevision(id, revisionNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domains.CompanyNewsRevision{}, errs.NewNotfoundError(fmt.Sprintf("revision %d not found", revisionNo))
+		}
+		return domains.CompanyNewsRevision{}, err
+	}
+	return rev, nil
+}
+
+// currentSnapshot reads the live (not deleted) state of an article
+func (s *CompanyNewsService) currentSnapshot(companyNewsID string) (domains.CompanyNewsSnapshot, error) {
+	news, err := s.companyNewsRepo.GetCompanyNewsByID(companyNewsID, domains.NewsViewer{SeeAll: true})
+	if err != nil {
+		return domains.CompanyNewsSnapshot{}, err
+	}
+	audiences, err := s.companyNewsRepo.GetCompanyNewsAudiences(companyNewsID)
+	if err != nil {
+		return domains.CompanyNewsSnapshot{}, err
+	}
+	return companyNewsSnapshot(news, audiences), nil
+}
+
+// recordUpdateRevision compares the article with its state before an update and records the changed fields
+func (s *CompanyNewsService) recordUpdateRevision(companyNewsID string, before domains.CompanyNewsSnapshot, actor string) {
+	after, err := s.currentSnapshot(companyNewsID)
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+
+	changed := diffCompanyNewsSnapshots(before, after)
+	if len(changed) == 0 {
+		return
+	}
+	s.ensureBaselineRevision(companyNewsID, before)
+	s.recordRevision(companyNewsID, domains.RevisionUpdate, changed, after, actor, nil)
+}
+
+// ensureBaselineRevision records the pre-existing state of articles created before revisions were tracked
+func (s *CompanyNewsService) ensureBaselineRevision(companyNewsID string, before domains.CompanyNewsSnapshot) {
+	count, err := s.companyNewsRepo.CountCompanyNewsRevisions(companyNewsID)
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+	if count == 0 {
+		s.recordRevision(companyNewsID, domains.RevisionBaseline, nil, before, "", nil)
+	}
+}
+
+// recordRevision stores a revision. A failure is logged but does not undo the change it describes.
+func (s *CompanyNewsService) recordRevision(companyNewsID, action string, changed []string, snap domains.CompanyNewsSnapshot, actor string, restoredFrom *int) {
+	if changed == nil {
+		changed = []string{}
+	}
+	changedJSON, _ := json.Marshal(changed)
+	snapJSON, err := json.Marshal(snap)
+	if err != nil {
+		logs.Error(err)
+		return
+	}
+
+	rev := domains.CompanyNewsRevision{
+		CompanyNewsID: companyNewsID,
+		Action:        action,
+		ChangedFields: string(changedJSON),
+		Snapshot:      string(snapJSON),
+		RestoredFrom:  restoredFrom,
+		Username:      actor,
+	}
+	if err := s.companyNewsRepo.CreateCompanyNewsRevision(&rev); err != nil {
+		log.Printf("[recordRevision] Failed to record %s revision for %s: %v\n", action, companyNewsID, err)
+		logs.Error(err)
+	}
+}
+
+func companyNewsSnapshot(news domains.CompanyNews, audiences []domains.CompanyNewsAudience) domains.CompanyNewsSnapshot {
+	snap := domains.CompanyNewsSnapshot{
+		Title:            news.Title,
+		Content:          news.Content,
+		Category:         news.Category,
+		CompanyNewsPhoto: news.CompanyNewsPhoto,
+		UsernameCreator:  news.UsernameCreator,
+		Audiences:        make([]domains.CompanyNewsSnapshotAudience, 0, len(audiences)),
+	}
+	for _, a := range audiences {
+		snap.Audiences = append(snap.Audiences, domains.CompanyNewsSnapshotAudience{TargetType: a.TargetType, TargetValue: a.TargetValue})
+	}
+	return snap
+}
+
+func snapshotFields(snap domains.CompanyNewsSnapshot) map[string]interface{} {
+	return map[string]interface{}{
+		"title":              snap.Title,
+		"content":            snap.Content,
+		"category":           snap.Category,
+		"company_news_photo": snap.CompanyNewsPhoto,
+		"username_creator":   snap.UsernameCreator,
+		"audiences":          snap.Audiences,
+	}
+}
+
+// diffCompanyNewsSnapshots returns the names of the fields that differ, in a stable order
+func diffCompanyNewsSnapshots(a, b domains.CompanyNewsSnapshot) []string {
+	order := []string{"title", "content", "category", "company_news_photo", "username_creator", "audiences"}
+	fa, fb := snapshotFields(a), snapshotFields(b)
+
+	changed := make([]string, 0)
+	for _, field := range order {
+		if field == "audiences" {
+			if !sameAudiences(a.Audiences, b.Audiences) {
+				changed = append(changed, field)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(fa[field], fb[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+func sameAudiences(a, b []domains.CompanyNewsSnapshotAudience) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[domains.CompanyNewsSnapshotAudience]int, len(a))
+	for _, x := range a {
+		set[x]++
+	}
+	for _, x := range b {
+		if set[x] == 0 {
+			return false
+		}
+		set[x]--
+	}
+	return true
+}
+
+func parseCompanyNewsSnapshot(raw string) (domains.CompanyNewsSnapshot, error) {
+	var snap domains.CompanyNewsSnapshot
+	if err := json.Unmarshal([]byte(raw), &snap); err != nil {
+		return domains.CompanyNewsSnapshot{}, fmt.Errorf("invalid revision snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+func normalizeCompanyNewsID(companyNewsID string) (string, error) {
+	id, err := uuid.Parse(companyNewsID)
+	if err != nil {
+		return "", errs.NewError("invalid company news ID")
+	}
+	return id.String(), nil
+}
+
+func toCompanyNewsRevisionModel(rev domains.CompanyNewsRevision, withSnapshot bool) models.CompanyNewsRevisionResp {
+	changed := []string{}
+	if rev.ChangedFields != "" {
+		_ = json.Unmarshal([]byte(rev.ChangedFields), &changed)
+	}
+
+	out := models.CompanyNewsRevisionResp{
+		CompanyNewsID: rev.CompanyNewsID,
+		RevisionNo:    rev.RevisionNo,
+		Action:        rev.Action,
+		ChangedFields: changed,
+		RestoredFrom:  rev.RestoredFrom,
+		Username:      rev.Username,
+		CreatedAt:     rev.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if withSnapshot && rev.Snapshot != "" {
+		out.Snapshot = json.RawMessage(rev.Snapshot)
+	}
+	return out
+}
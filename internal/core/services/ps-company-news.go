@@ -1,28 +1,56 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	ports "backend/internal/core/ports/repositories"
 	servicesports "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/feeds"
 	"backend/internal/pkgs/logs"
+	"backend/internal/pkgs/utils"
 )
 
+// companyNewsEditorRoles see every article regardless of its audience
+var companyNewsEditorRoles = []string{"SU", "HR"}
+
+// searchSnippetLength is the approximate number of characters shown around a search match
+const searchSnippetLength = 200
+
+// feedItemLimit is the number of latest articles published in each feed
+const feedItemLimit = 50
+
+// audiencePreviewSampleSize caps the employee list returned by the audience preview
+const audiencePreviewSampleSize = 50
+
 type CompanyNewsService struct {
 	companyNewsRepo ports.CompanyNewsRepository
+	userRepo        ports.UserRepository
 }
 
-func NewCompanyNewsService(companyNewsRepo ports.CompanyNewsRepository) servicesports.CompanyNewsService {
-	return &CompanyNewsService{companyNewsRepo: companyNewsRepo}
+func NewCompanyNewsService(companyNewsRepo ports.CompanyNewsRepository, userRepo ports.UserRepository) servicesports.CompanyNewsService {
+	return &CompanyNewsService{companyNewsRepo: companyNewsRepo, userRepo: userRepo}
 }
 
-func (s *CompanyNewsService) CreateCompanyNewsService(req models.CompanyNewsResp) error {
+func (s *CompanyNewsService) CreateCompanyNewsService(req models.CompanyNewsResp, actor string) error {
+	var audiences []domains.CompanyNewsAudience
+	if req.Audiences != nil {
+		var err error
+		if audiences, err = toCompanyNewsAudiences(*req.Audiences); err != nil {
+			return err
+		}
+	}
+
 	newID := uuid.New()
 
 	domainISR := domains.CompanyNews{
@@ -41,16 +69,20 @@ func (s *CompanyNewsService) CreateCompanyNewsService(req models.CompanyNewsResp
 		return fmt.Errorf("user repository is not initialized")
 	}
 
-	err := s.companyNewsRepo.CreateCompanyNews(&domainISR)
+	err := s.companyNewsRepo.CreateCompanyNews(&domainISR, audiences)
 	if err != nil {
 		logs.Error(err)
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
+	s.recordRevision(domainISR.CompanyNewsID.String(), domains.RevisionCreate,
+		[]string{"title", "content", "category", "company_news_photo", "username_creator", "audiences"},
+		companyNewsSnapshot(domainISR, audiences), actor, nil)
+
 	return nil
 }
 
-func (s *CompanyNewsService) GetCompanyNews(limit, offset int) (models.CompanyNewsListResp, error) {
+func (s *CompanyNewsService) GetCompanyNews(empCode, role string, limit, offset int) (models.CompanyNewsListResp, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -58,16 +90,19 @@ func (s *CompanyNewsService) GetCompanyNews(limit, offset int) (models.CompanyNe
 		offset = 0
 	}
 
-	query, total, err := s.companyNewsRepo.GetCompanyNews(limit, offset)
+	query, total, err := s.companyNewsRepo.GetCompanyNews(s.newsViewer(empCode, role), limit, offset)
 	if err != nil {
 		return models.CompanyNewsListResp{}, err
 	}
 
 	jobs := make([]models.CompanyNewsReq, 0, len(query))
+	files := companyNewsFiles(query...)
 	for _, job := range query {
 		jobs = append(jobs, models.CompanyNewsReq{
 			CompanyNewsID:    job.CompanyNewsID,
 			CompanyNewsPhoto: job.CompanyNewsPhoto,
+			Thumbnails:       files.thumbnails(job.CompanyNewsPhoto),
+			PhotoSHA256:      files.sha256(job.CompanyNewsPhoto),
 			Title:            job.Title,
 			Content:          job.Content,
 			Category:         job.Category,
@@ -93,15 +128,21 @@ func (s *CompanyNewsService) GetCompanyNews(limit, offset int) (models.CompanyNe
 	}, nil
 }
 
-func (s *CompanyNewsService) GetCompanyNewsByTitle(title string) (models.CompanyNewsReq, error) {
-	job, err := s.companyNewsRepo.GetCompanyNewsByTitle(title)
+func (s *CompanyNewsService) GetCompanyNewsByTitle(title, empCode, role string) (models.CompanyNewsReq, error) {
+	job, err := s.companyNewsRepo.GetCompanyNewsByTitle(title, s.newsViewer(empCode, role))
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CompanyNewsReq{}, errs.NewNotfoundError("company news not found")
+		}
 		return models.CompanyNewsReq{}, err
 	}
 
+	files := lookupStoredFiles(job.CompanyNewsPhoto)
 	jobReq := models.CompanyNewsReq{
 		CompanyNewsID:    job.CompanyNewsID,
 		CompanyNewsPhoto: job.CompanyNewsPhoto,
+		Thumbnails:       files.thumbnails(job.CompanyNewsPhoto),
+		PhotoSHA256:      files.sha256(job.CompanyNewsPhoto),
 		Title:            job.Title,
 		Content:          job.Content,
 		Category:         job.Category,
@@ -113,27 +154,45 @@ func (s *CompanyNewsService) GetCompanyNewsByTitle(title string) (models.Company
 	return jobReq, nil
 }
 
-func (s *CompanyNewsService) GetCompanyNewsByID(id string) (models.CompanyNewsReq, error) {
-	job, err := s.companyNewsRepo.GetCompanyNewsByID(id)
+func (s *CompanyNewsService) GetCompanyNewsByID(id, empCode, role string) (models.CompanyNewsReq, error) {
+	job, err := s.companyNewsRepo.GetCompanyNewsByID(id, s.newsViewer(empCode, role))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CompanyNewsReq{}, errs.NewNotfoundError("company news not found")
+		}
+		return models.CompanyNewsReq{}, err
+	}
+
+	audiences, err := s.companyNewsRepo.GetCompanyNewsAudiences(id)
+	if err != nil {
+		return models.CompanyNewsReq{}, err
+	}
+
+	attachments, err := s.companyNewsRepo.GetCompanyNewsAttachments(id)
 	if err != nil {
 		return models.CompanyNewsReq{}, err
 	}
 
+	files := lookupStoredFiles(job.CompanyNewsPhoto)
 	jobReq := models.CompanyNewsReq{
 		CompanyNewsID:    job.CompanyNewsID,
 		CompanyNewsPhoto: job.CompanyNewsPhoto,
+		Thumbnails:       files.thumbnails(job.CompanyNewsPhoto),
+		PhotoSHA256:      files.sha256(job.CompanyNewsPhoto),
 		Title:            job.Title,
 		Content:          job.Content,
 		Category:         job.Category,
 		UsernameCreator:  job.UsernameCreator,
 		CreatedAt:        job.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:        job.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Audiences:        toCompanyNewsAudienceModels(audiences),
+		Attachments:      toCompanyNewsAttachmentModels(attachments),
 	}
 
 	return jobReq, nil
 }
 
-func (s *CompanyNewsService) UpdateCompanyNewsService(companyNewsID string, req models.CompanyNewsResp) error {
+func (s *CompanyNewsService) UpdateCompanyNewsService(companyNewsID string, req models.CompanyNewsResp, actor string) error {
 	log.Printf("[UpdateCompanyNewsService] Starting update for ID: %s\n", companyNewsID)
 	log.Printf("[UpdateCompanyNewsService] Request data: %+v\n", req)
 
@@ -147,6 +206,22 @@ func (s *CompanyNewsService) UpdateCompanyNewsService(companyNewsID string, req
 		return fmt.Errorf("company news repository is not initialized")
 	}
 
+	var audiences []domains.CompanyNewsAudience
+	if req.Audiences != nil {
+		var err error
+		if audiences, err = toCompanyNewsAudiences(*req.Audiences); err != nil {
+			return err
+		}
+	}
+
+	before, err := s.currentSnapshot(companyNewsID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("company news not found")
+		}
+		return err
+	}
+
 	updates := make(map[string]interface{})
 
 	if req.CompanyNewsPhoto != "" {
@@ -170,7 +245,7 @@ func (s *CompanyNewsService) UpdateCompanyNewsService(companyNewsID string, req
 		updates["username_creator"] = req.UsernameCreator
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && req.Audiences == nil {
 		log.Println("[UpdateCompanyNewsService] No fields to update - all provided values are empty!")
 		return fmt.Errorf("no fields to update")
 	}
@@ -178,18 +253,26 @@ func (s *CompanyNewsService) UpdateCompanyNewsService(companyNewsID string, req
 	log.Printf("[UpdateCompanyNewsService] Total fields to update: %d\n", len(updates))
 	log.Printf("[UpdateCompanyNewsService] Updates map: %+v\n", updates)
 
-	err := s.companyNewsRepo.UpdateCompanyNewsWithMap(companyNewsID, updates)
+	if req.Audiences != nil {
+		// the fields and the audiences change together or not at all
+		log.Printf("[UpdateCompanyNewsService] Replacing audiences: %d target(s)\n", len(audiences))
+		err = s.companyNewsRepo.UpdateCompanyNewsWithAudiences(companyNewsID, updates, audiences)
+	} else {
+		err = s.companyNewsRepo.UpdateCompanyNewsWithMap(companyNewsID, updates)
+	}
 	if err != nil {
 		log.Printf("[UpdateCompanyNewsService] Repository error: %v\n", err)
 		logs.Error(err)
 		return fmt.Errorf("failed to update company news: %w", err)
 	}
 
+	s.recordUpdateRevision(companyNewsID, before, actor)
+
 	log.Println("[UpdateCompanyNewsService] Update completed successfully")
 	return nil
 }
 
-func (s *CompanyNewsService) DeleteCompanyNewsService(companyNewsID string) error {
+func (s *CompanyNewsService) DeleteCompanyNewsService(companyNewsID, actor string) error {
 	log.Printf("[DeleteCompanyNewsService] Starting delete for ID: %s\n", companyNewsID)
 
 	if companyNewsID == "" {
@@ -202,13 +285,320 @@ func (s *CompanyNewsService) DeleteCompanyNewsService(companyNewsID string) erro
 		return fmt.Errorf("company news repository is not initialized")
 	}
 
-	err := s.companyNewsRepo.DeleteCompanyNews(companyNewsID)
+	before, err := s.currentSnapshot(companyNewsID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errs.NewNotfoundError("company news not found")
+		}
+		return err
+	}
+
+	err = s.companyNewsRepo.DeleteCompanyNews(companyNewsID)
 	if err != nil {
 		log.Printf("[DeleteCompanyNewsService] Repository error: %v\n", err)
 		logs.Error(err)
 		return fmt.Errorf("failed to delete company news: %w", err)
 	}
 
+	s.ensureBaselineRevision(companyNewsID, before)
+	s.recordRevision(companyNewsID, domains.RevisionDelete, nil, before, actor, nil)
+
 	log.Println("[DeleteCompanyNewsService] Delete completed successfully")
 	return nil
 }
+
+func (s *CompanyNewsService) SearchCompanyNews(req models.CompanyNewsSearchReq) (models.CompanyNewsSearchResp, error) {
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	search := domains.CompanyNewsSearch{
+		Terms:    utils.SearchTerms(req.Keyword),
+		Category: strings.TrimSpace(req.Category),
+		Creator:  strings.TrimSpace(req.Creator),
+		Viewer:   s.newsViewer(req.EmpCode, req.Role),
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	}
+
+	if req.DateFrom != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.DateFrom, time.Local)
+		if err != nil {
+			return models.CompanyNewsSearchResp{}, errs.NewError("date_from must be YYYY-MM-DD")
+		}
+		search.DateFrom = &from
+	}
+	if req.DateTo != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.DateTo, time.Local)
+		if err != nil {
+			return models.CompanyNewsSearchResp{}, errs.NewError("date_to must be YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+		search.DateTo = &to
+	}
+
+	hits, total, err := s.companyNewsRepo.SearchCompanyNews(search)
+	if err != nil {
+		logs.Error(err)
+		return models.CompanyNewsSearchResp{}, fmt.Errorf("failed to search company news: %w", err)
+	}
+
+	items := make([]models.CompanyNewsSearchItem, 0, len(hits))
+	photos := make([]string, len(hits))
+	for i, hit := range hits {
+		photos[i] = hit.CompanyNewsPhoto
+	}
+	files := lookupStoredFiles(photos...)
+	for _, hit := range hits {
+		items = append(items, models.CompanyNewsSearchItem{
+			CompanyNewsReq: models.CompanyNewsReq{
+				CompanyNewsID:    hit.CompanyNewsID,
+				CompanyNewsPhoto: hit.CompanyNewsPhoto,
+				Thumbnails:       files.thumbnails(hit.CompanyNewsPhoto),
+				PhotoSHA256:      files.sha256(hit.CompanyNewsPhoto),
+				Title:            hit.Title,
+				Content:          hit.Content,
+				Category:         hit.Category,
+				UsernameCreator:  hit.UsernameCreator,
+				CreatedAt:        hit.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdatedAt:        hit.UpdatedAt.Format("2006-01-02 15:04:05"),
+			},
+			Score:          hit.Score,
+			TitleHighlight: utils.Highlight(hit.Title, search.Terms),
+			Snippet:        utils.Snippet(utils.PlainText(hit.Content), search.Terms, searchSnippetLength),
+		})
+	}
+
+	totalPages := 0
+	if total > 0 {
+		totalPages = int((total + int64(req.Limit) - 1) / int64(req.Limit))
+	}
+
+	return models.CompanyNewsSearchResp{
+		Data:       items,
+		Total:      total,
+		TotalPages: totalPages,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	}, nil
+}
+
+// GetCompanyNewsFeed returns the latest company-wide news for RSS/Atom/JSON Feed output.
+// Feeds are read anonymously, so audience-restricted articles are never included.
+func (s *CompanyNewsService) GetCompanyNewsFeed(category, baseURL string) (feeds.Feed, error) {
+	hits, _, err := s.companyNewsRepo.SearchCompanyNews(domains.CompanyNewsSearch{
+		Category: strings.TrimSpace(category),
+		Limit:    feedItemLimit,
+	})
+	if err != nil {
+		logs.Error(err)
+		return feeds.Feed{}, fmt.Errorf("failed to load company news feed: %w", err)
+	}
+
+	linkBase := companyNewsLinkBase(baseURL)
+
+	title := "PSTH Company News"
+	if category != "" {
+		title += " - " + category
+	}
+
+	feed := feeds.Feed{
+		Title:       title,
+		Link:        strings.TrimSuffix(linkBase, "/"),
+		Description: "Latest company news and announcements",
+		Language:    "th",
+		Items:       make([]feeds.Item, 0, len(hits)),
+	}
+
+	for _, hit := range hits {
+		id := hit.CompanyNewsID.String()
+		updated := hit.UpdatedAt
+		if updated.Before(hit.CreatedAt) {
+			updated = hit.CreatedAt
+		}
+		if updated.After(feed.Updated) {
+			feed.Updated = updated
+		}
+
+		summary := []rune(utils.PlainText(hit.Content))
+		if len(summary) > searchSnippetLength {
+			summary = append(summary[:searchSnippetLength], '…')
+		}
+
+		feed.Items = append(feed.Items, feeds.Item{
+			ID:          id,
+			Title:       hit.Title,
+			Link:        linkBase + id,
+			Summary:     string(summary),
+			ContentHTML: hit.Content,
+			ImageURL:    utils.FileURL(baseURL, hit.CompanyNewsPhoto),
+			Category:    hit.Category,
+			Author:      hit.UsernameCreator,
+			Published:   hit.CreatedAt,
+			Updated:     updated,
+		})
+	}
+
+	return feed, nil
+}
+
+// companyNewsLinkBase points article links at the portal when PORTAL_BASE_URL is set, otherwise at the detail API
+func companyNewsLinkBase(baseURL string) string {
+	if portal := os.Getenv("PORTAL_BASE_URL"); portal != "" {
+		return strings.TrimRight(portal, "/") + "/company-news/"
+	}
+	return strings.TrimRight(baseURL, "/") + "/api/company-news/get-company-news/"
+}
+
+func (s *CompanyNewsService) PreviewCompanyNewsAudience(reqs []models.CompanyNewsAudienceReq) (models.CompanyNewsAudiencePreviewResp, error) {
+	audiences, err := toCompanyNewsAudiences(reqs)
+	if err != nil {
+		return models.CompanyNewsAudiencePreviewResp{}, err
+	}
+	return s.previewAudience(audiences)
+}
+
+func (s *CompanyNewsService) PreviewCompanyNewsAudienceByID(companyNewsID string) (models.CompanyNewsAudiencePreviewResp, error) {
+	if _, err := s.companyNewsRepo.GetCompanyNewsByID(companyNewsID, domains.NewsViewer{SeeAll: true}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.CompanyNewsAudiencePreviewResp{}, errs.NewNotfoundError("company news not found")
+		}
+		return models.CompanyNewsAudiencePreviewResp{}, err
+	}
+
+	audiences, err := s.companyNewsRepo.GetCompanyNewsAudiences(companyNewsID)
+	if err != nil {
+		return models.CompanyNewsAudiencePreviewResp{}, err
+	}
+	return s.previewAudience(audiences)
+}
+
+func (s *CompanyNewsService) previewAudience(audiences []domains.CompanyNewsAudience) (models.CompanyNewsAudiencePreviewResp, error) {
+	departments, err := s.companyNewsRepo.CountAudienceEmployees(audiences)
+	if err != nil {
+		logs.Error(err)
+		return models.CompanyNewsAudiencePreviewResp{}, fmt.Errorf("failed to count audience: %w", err)
+	}
+
+	var total int64
+	for _, d := range departments {
+		total += d.Total
+	}
+
+	emps, err := s.companyNewsRepo.GetAudienceEmployees(audiences, audiencePreviewSampleSize)
+	if err != nil {
+		logs.Error(err)
+		return models.CompanyNewsAudiencePreviewResp{}, fmt.Errorf("failed to list audience: %w", err)
+	}
+
+	sample := make([]models.CompanyNewsAudienceEmployee, 0, len(emps))
+	for _, e := range emps {
+		sample = append(sample, models.CompanyNewsAudienceEmployee{
+			EmpCode:    e.UHR_EmpCode,
+			FullNameEn: e.UHR_FullNameEn,
+			FullNameTh: e.UHR_FullNameTh,
+			Department: e.UHR_Department,
+			Position:   e.UHR_Position,
+		})
+	}
+
+	return models.CompanyNewsAudiencePreviewResp{
+		CompanyWide:     len(audiences) == 0,
+		Audiences:       toCompanyNewsAudienceModels(audiences),
+		Total:           total,
+		Departments:     departments,
+		SampleEmployees: sample,
+	}, nil
+}
+
+// newsViewer resolves the employee attributes used to match news audiences
+func (s *CompanyNewsService) newsViewer(empCode, role string) domains.NewsViewer {
+	viewer := domains.NewsViewer{EmpCode: empCode, Role: role}
+	if utils.HasAnyRole(role, companyNewsEditorRoles...) {
+		viewer.SeeAll = true
+		return viewer
+	}
+	if empCode == "" || s.userRepo == nil {
+		return viewer
+	}
+
+	emp, err := s.userRepo.GetEmployeeByEmpCode(empCode)
+	if err != nil {
+		log.Printf("[CompanyNewsService] Employee %s not found, matching by role only: %v\n", empCode, err)
+		return viewer
+	}
+	return employeeNewsViewer(*emp, role)
+}
+
+// employeeNewsViewer builds the audience attributes of an employee; role overrides the stored role when set
+func employeeNewsViewer(emp domains.PSEmployee, role string) domains.NewsViewer {
+	viewer := domains.NewsViewer{
+		EmpCode:         emp.UHR_EmpCode,
+		Department:      emp.UHR_Department,
+		GroupDepartment: emp.UHR_GroupDepartment,
+		OrgGroup:        emp.UHR_OrgGroup,
+		Role:            role,
+	}
+	if viewer.Role == "" {
+		viewer.Role = strings.ToUpper(emp.Role)
+	}
+	viewer.SeeAll = utils.HasAnyRole(viewer.Role, companyNewsEditorRoles...)
+	return viewer
+}
+
+func toCompanyNewsAudiences(reqs []models.CompanyNewsAudienceReq) ([]domains.CompanyNewsAudience, error) {
+	seen := make(map[string]bool, len(reqs))
+	audiences := make([]domains.CompanyNewsAudience, 0, len(reqs))
+	for _, r := range reqs {
+		targetType := strings.ToLower(strings.TrimSpace(r.TargetType))
+		targetValue := strings.TrimSpace(r.TargetValue)
+
+		if !domains.IsValidAudienceType(targetType) {
+			return nil, errs.NewError(fmt.Sprintf("invalid audience target_type: %q", r.TargetType))
+		}
+		if targetValue == "" {
+			return nil, errs.NewError("audience target_value is required")
+		}
+		if targetType == domains.AudienceRole {
+			targetValue = strings.ToUpper(targetValue)
+		}
+
+		key := targetType + "|" + targetValue
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		audiences = append(audiences, domains.CompanyNewsAudience{
+			TargetType:  targetType,
+			TargetValue: targetValue,
+		})
+	}
+	return audiences, nil
+}
+
+func toCompanyNewsAudienceModels(audiences []domains.CompanyNewsAudience) []models.CompanyNewsAudienceReq {
+	out := make([]models.CompanyNewsAudienceReq, 0, len(audiences))
+	for _, a := range audiences {
+		out = append(out, models.CompanyNewsAudienceReq{
+			TargetType:  a.TargetType,
+			TargetValue: a.TargetValue,
+		})
+	}
+	return out
+}
+
+// companyNewsFiles looks up the stored cover photos of a page of company news
+func companyNewsFiles(news ...domains.CompanyNews) storedFiles {
+	photos := make([]string, len(news))
+	for i, n := range news {
+		photos[i] = n.CompanyNewsPhoto
+	}
+	return lookupStoredFiles(photos...)
+}
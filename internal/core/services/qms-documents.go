@@ -5,22 +5,53 @@ import (
 	"strings"
 	"time"
 
+	"gorm.io/gorm"
+
 	"backend/internal/core/domains"
 	"backend/internal/core/models"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
 	"backend/internal/repositories"
 )
 
+// qmsDocumentAdminRoles manage every QMS document; everyone else only sees effective ones
+var qmsDocumentAdminRoles = []string{"SU", "QMS"}
+
+// qmsDefaultReviewPeriod is used when a document is released without a review-due date
+const qmsDefaultReviewPeriod = 1 // years
+
 type QmsDocumentsService struct {
-	repo *repositories.QmsDocumentsRepository
+	repo    *repositories.QmsDocumentsRepository
+	textSrv portServices.DocumentTextService
 }
 
-func NewQmsDocumentsService(repo *repositories.QmsDocumentsRepository) *QmsDocumentsService {
-	return &QmsDocumentsService{repo: repo}
+func NewQmsDocumentsService(repo *repositories.QmsDocumentsRepository, textSrv portServices.DocumentTextService) *QmsDocumentsService {
+	return &QmsDocumentsService{repo: repo, textSrv: textSrv}
 }
 
 func (s *QmsDocumentsService) CreateQmsDocument(req *models.CreateQmsDocumentRequest) (*models.QmsDocumentResponse, error) {
 	if req.QmsDocumentsName == "" || req.DQmsDocumentsDesc == "" || req.Category == "" {
-		return nil, errors.New("qms_documents_name, dqms_documents_desc, and category are required")
+		return nil, errs.NewError("qms_documents_name, dqms_documents_desc, and category are required")
+	}
+
+	reviewDue, err := parseQmsDate(req.ReviewDueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// New documents are owned by whoever uploads them; only QMS admins may file one for someone else
+	owner := strings.TrimSpace(req.OwnerEmpCode)
+	if owner == "" {
+		owner = req.EmpCode
+	} else if !strings.EqualFold(owner, req.EmpCode) && !utils.HasAnyRole(req.Role, qmsDocumentAdminRoles...) {
+		return nil, errs.NewError("only a QMS admin can create a document for another owner")
+	}
+	if owner == "" {
+		return nil, errs.NewError("owner_emp_code is required")
+	}
+	if err := checkQmsSeparationOfDuties(owner, req.ReviewerEmpCode, req.ApproverEmpCode); err != nil {
+		return nil, err
 	}
 
 	// optional: validate categories
@@ -29,21 +60,21 @@ func (s *QmsDocumentsService) CreateQmsDocument(req *models.CreateQmsDocumentReq
 		DQmsDocumentsDesc: strings.TrimSpace(req.DQmsDocumentsDesc),
 		Category:          strings.ToLower(req.Category),
 		FileName:          strings.TrimSpace(req.FileName),
+		DocumentNo:        strings.TrimSpace(req.DocumentNo),
+		Revision:          strings.TrimSpace(req.Revision),
+		Status:            domains.QmsStatusDraft,
+		OwnerEmpCode:      owner,
+		ReviewerEmpCode:   strings.TrimSpace(req.ReviewerEmpCode),
+		ApproverEmpCode:   strings.TrimSpace(req.ApproverEmpCode),
+		ReviewDueDate:     reviewDue,
 	}
 
 	if err := s.repo.CreateQmsDocument(doc); err != nil {
 		return nil, err
 	}
 
-	return &models.QmsDocumentResponse{
-		QmsDocumentsID:    doc.QmsDocumentsID,
-		QmsDocumentsName:  doc.QmsDocumentsName,
-		DQmsDocumentsDesc: doc.DQmsDocumentsDesc,
-		Category:          doc.Category,
-		FileName:          doc.FileName,
-		CreatedAt:         doc.CreatedAt,
-		UpdatedAt:         doc.UpdatedAt,
-	}, nil
+	resp := toQmsDocumentResponse(*doc, qmsDocumentFiles(*doc))
+	return &resp, nil
 }
 
 func (s *QmsDocumentsService) GetQmsDocumentByID(id int) (*models.QmsDocumentResponse, error) {
@@ -51,18 +82,11 @@ func (s *QmsDocumentsService) GetQmsDocumentByID(id int) (*models.QmsDocumentRes
 	if err != nil {
 		return nil, err
 	}
-	return &models.QmsDocumentResponse{
-		QmsDocumentsID:    doc.QmsDocumentsID,
-		QmsDocumentsName:  doc.QmsDocumentsName,
-		DQmsDocumentsDesc: doc.DQmsDocumentsDesc,
-		Category:          doc.Category,
-		FileName:          doc.FileName,
-		CreatedAt:         doc.CreatedAt,
-		UpdatedAt:         doc.UpdatedAt,
-	}, nil
+	resp := toQmsDocumentResponse(*doc, qmsDocumentFiles(*doc))
+	return &resp, nil
 }
 
-func (s *QmsDocumentsService) GetAllQmsDocuments(page, pageSize int) (*models.QmsDocumentListResponse, error) {
+func (s *QmsDocumentsService) GetAllQmsDocuments(page, pageSize int, filter domains.QmsDocumentFilter) (*models.QmsDocumentListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -70,37 +94,40 @@ func (s *QmsDocumentsService) GetAllQmsDocuments(page, pageSize int) (*models.Qm
 		pageSize = 50
 	}
 
-	docs, total, err := s.repo.GetAllQmsDocuments(page, pageSize)
+	docs, total, err := s.repo.GetAllQmsDocuments(page, pageSize, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	resp := &models.QmsDocumentListResponse{
-		Data:       make([]models.QmsDocumentResponse, len(docs)),
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
-	}
-
-	for i, d := range docs {
-		resp.Data[i] = models.QmsDocumentResponse{
-			QmsDocumentsID:    d.QmsDocumentsID,
-			QmsDocumentsName:  d.QmsDocumentsName,
-			DQmsDocumentsDesc: d.DQmsDocumentsDesc,
-			Category:          d.Category,
-			FileName:          d.FileName,
-			CreatedAt:         d.CreatedAt,
-			UpdatedAt:         d.UpdatedAt,
-		}
-	}
-
-	return resp, nil
+	return toQmsDocumentListResponse(docs, total, page, pageSize), nil
 }
 
 func (s *QmsDocumentsService) UpdateQmsDocument(id int, req *models.UpdateQmsDocumentRequest) (*models.QmsDocumentResponse, error) {
-	_, err := s.repo.GetQmsDocumentByID(id)
+	doc, err := s.repo.GetQmsDocumentByID(id)
 	if err != nil {
+		return nil, errs.NewNotfoundError(err.Error())
+	}
+
+	admin := utils.HasAnyRole(req.Role, qmsDocumentAdminRoles...)
+	if !admin && (req.EmpCode == "" || !strings.EqualFold(doc.OwnerEmpCode, req.EmpCode)) {
+		return nil, errs.NewError("only the document owner or a QMS admin can edit this document")
+	}
+	// Approved and effective documents are changed by releasing a new revision with the same document number
+	if !admin && doc.Status != domains.QmsStatusDraft && doc.Status != domains.QmsStatusUnderReview {
+		return nil, errs.NewError("qms document is " + doc.Status + "; create a new revision to change it")
+	}
+
+	owner, reviewer, approver := doc.OwnerEmpCode, doc.ReviewerEmpCode, doc.ApproverEmpCode
+	if v := strings.TrimSpace(req.OwnerEmpCode); v != "" {
+		owner = v
+	}
+	if v := strings.TrimSpace(req.ReviewerEmpCode); v != "" {
+		reviewer = v
+	}
+	if v := strings.TrimSpace(req.ApproverEmpCode); v != "" {
+		approver = v
+	}
+	if err := checkQmsSeparationOfDuties(owner, reviewer, approver); err != nil {
 		return nil, err
 	}
 
@@ -118,6 +145,30 @@ func (s *QmsDocumentsService) UpdateQmsDocument(id int, req *models.UpdateQmsDoc
 	if req.FileName != "" {
 		updates["file_name"] = strings.TrimSpace(req.FileName)
 	}
+	for key, value := range map[string]string{
+		"document_no":       req.DocumentNo,
+		"revision":          req.Revision,
+		"owner_emp_code":    req.OwnerEmpCode,
+		"reviewer_emp_code": req.ReviewerEmpCode,
+		"approver_emp_code": req.ApproverEmpCode,
+	} {
+		if value != "" {
+			updates[key] = strings.TrimSpace(value)
+		}
+	}
+	if req.ReviewDueDate != "" {
+		reviewDue, err := parseQmsDate(req.ReviewDueDate)
+		if err != nil {
+			return nil, err
+		}
+		updates["review_due_date"] = reviewDue
+	}
+
+	// a review covers one file and one reviewer; changing either during review asks for it again
+	if doc.Status == domains.QmsStatusUnderReview && doc.ReviewedAt != nil &&
+		(req.FileName != "" || !strings.EqualFold(reviewer, doc.ReviewerEmpCode)) {
+		updates["reviewed_at"] = nil
+	}
 
 	updates["updated_at"] = time.Now()
 
@@ -130,55 +181,297 @@ func (s *QmsDocumentsService) UpdateQmsDocument(id int, req *models.UpdateQmsDoc
 		return nil, err
 	}
 
-	return &models.QmsDocumentResponse{
-		QmsDocumentsID:    updated.QmsDocumentsID,
-		QmsDocumentsName:  updated.QmsDocumentsName,
-		DQmsDocumentsDesc: updated.DQmsDocumentsDesc,
-		Category:          updated.Category,
-		FileName:          updated.FileName,
-		CreatedAt:         updated.CreatedAt,
-		UpdatedAt:         updated.UpdatedAt,
-	}, nil
+	resp := toQmsDocumentResponse(*updated, qmsDocumentFiles(*updated))
+	return &resp, nil
 }
 
 func (s *QmsDocumentsService) DeleteQmsDocument(id int) error {
 	return s.repo.DeleteQmsDocument(id)
 }
 
-func (s *QmsDocumentsService) SearchQmsDocuments(keyword string, page, pageSize int) (*models.QmsDocumentListResponse, error) {
-	if page < 1 {
-		page = 1
+func (s *QmsDocumentsService) SearchQmsDocuments(search domains.DocumentSearch, page, pageSize int, filter domains.QmsDocumentFilter) (*models.QmsDocumentListResponse, error) {
+	docs, total, err := s.repo.SearchQmsDocuments(search, page, pageSize, filter)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 50
+
+	ids := make([]int, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.QmsDocumentsID
+	}
+	matches := documentPageMatches(s.textSrv, domains.DocModuleQms, ids, search.Keyword)
+
+	resp := toQmsDocumentListResponse(docs, total, page, pageSize)
+	for i := range resp.Data {
+		resp.Data[i].Matches = matches[resp.Data[i].QmsDocumentsID]
 	}
+	return resp, nil
+}
 
-	docs, total, err := s.repo.SearchQmsDocuments(keyword, page, pageSize)
+// ====================== Document control ===================================
+
+// TransitionQmsDocument runs one workflow action on behalf of empCode
+func (s *QmsDocumentsService) TransitionQmsDocument(id int, action, empCode, role string, req models.QmsDocumentWorkflowRequest) (*models.QmsDocumentResponse, error) {
+	doc, err := s.repo.GetQmsDocumentByID(id)
 	if err != nil {
+		return nil, errs.NewNotfoundError(err.Error())
+	}
+
+	admin := utils.HasAnyRole(role, qmsDocumentAdminRoles...)
+	is := func(code string) bool { return empCode != "" && strings.EqualFold(code, empCode) }
+	now := time.Now()
+	updates := map[string]interface{}{}
+	var to string
+
+	switch action {
+	case domains.QmsActionSubmit:
+		if doc.Status != domains.QmsStatusDraft {
+			return nil, errs.NewError("only draft documents can be submitted")
+		}
+		if !admin && !is(doc.OwnerEmpCode) {
+			return nil, errs.NewError("only the document owner can submit for review")
+		}
+		if doc.DocumentNo == "" || doc.ReviewerEmpCode == "" || doc.ApproverEmpCode == "" || documentFilePath(doc.FileName) == "" {
+			return nil, errs.NewError("document_no, reviewer, approver and a file are required before review")
+		}
+		to = domains.QmsStatusUnderReview
+		updates["submitted_at"] = now
+
+	case domains.QmsActionReview:
+		if doc.Status != domains.QmsStatusUnderReview {
+			return nil, errs.NewError("document is not under review")
+		}
+		if doc.ReviewedAt != nil {
+			return nil, errs.NewError("document has already been reviewed")
+		}
+		if !admin && !is(doc.ReviewerEmpCode) {
+			return nil, errs.NewError("only the reviewer can review this document")
+		}
+		to = domains.QmsStatusUnderReview
+		updates["reviewed_at"] = now
+
+	case domains.QmsActionApprove:
+		if doc.Status != domains.QmsStatusUnderReview {
+			return nil, errs.NewError("document is not under review")
+		}
+		if doc.ReviewedAt == nil {
+			return nil, errs.NewError("document must be reviewed before approval")
+		}
+		if !admin && !is(doc.ApproverEmpCode) {
+			return nil, errs.NewError("only the approver can approve this document")
+		}
+		to = domains.QmsStatusApproved
+		updates["approved_at"] = now
+
+	case domains.QmsActionReject:
+		if doc.Status != domains.QmsStatusUnderReview && doc.Status != domains.QmsStatusApproved {
+			return nil, errs.NewError("only documents under review or approved can be rejected")
+		}
+		if !admin && !is(doc.ReviewerEmpCode) && !is(doc.ApproverEmpCode) {
+			return nil, errs.NewError("only the reviewer or approver can reject this document")
+		}
+		if strings.TrimSpace(req.Comment) == "" {
+			return nil, errs.NewError("comment is required when rejecting")
+		}
+		to = domains.QmsStatusDraft
+		updates["submitted_at"] = nil
+		updates["reviewed_at"] = nil
+		updates["approved_at"] = nil
+
+	case domains.QmsActionRelease:
+		if doc.Status != domains.QmsStatusApproved {
+			return nil, errs.NewError("only approved documents can be released")
+		}
+		if !admin && !is(doc.ApproverEmpCode) && !is(doc.OwnerEmpCode) {
+			return nil, errs.NewError("only the owner or approver can release this document")
+		}
+		reviewDue, err := parseQmsDate(req.ReviewDueDate)
+		if err != nil {
+			return nil, err
+		}
+		if reviewDue == nil {
+			reviewDue = doc.ReviewDueDate
+		}
+		if reviewDue == nil || reviewDue.Before(now) {
+			d := time.Date(now.Year()+qmsDefaultReviewPeriod, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			reviewDue = &d
+		}
+		to = domains.QmsStatusEffective
+		updates["effective_at"] = now
+		updates["review_due_date"] = reviewDue
+
+	case domains.QmsActionObsolete:
+		if doc.Status != domains.QmsStatusEffective {
+			return nil, errs.NewError("only effective documents can be made obsolete")
+		}
+		if !admin && !is(doc.ApproverEmpCode) && !is(doc.OwnerEmpCode) {
+			return nil, errs.NewError("only the owner or approver can make this document obsolete")
+		}
+		to = domains.QmsStatusObsolete
+		updates["obsoleted_at"] = now
+
+	default:
+		return nil, errs.NewError("unknown workflow action")
+	}
+
+	updates["status"] = to
+	approval := &domains.QmsDocumentApproval{
+		Action:     action,
+		FromStatus: doc.Status,
+		ToStatus:   to,
+		EmpCode:    empCode,
+		Comment:    strings.TrimSpace(req.Comment),
+	}
+	if err := s.repo.TransitionQmsDocument(id, doc.Status, updates, approval); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errs.NewError("qms document status has changed, reload and try again")
+		}
 		return nil, err
 	}
 
-	resp := &models.QmsDocumentListResponse{
-		Data:       make([]models.QmsDocumentResponse, len(docs)),
-		Total:      total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	return s.GetQmsDocumentByID(id)
+}
+
+func (s *QmsDocumentsService) GetQmsDocumentApprovals(id int) ([]models.QmsDocumentApprovalResponse, error) {
+	if _, err := s.repo.GetQmsDocumentByID(id); err != nil {
+		return nil, errs.NewNotfoundError(err.Error())
 	}
 
-	for i, d := range docs {
-		resp.Data[i] = models.QmsDocumentResponse{
-			QmsDocumentsID:    d.QmsDocumentsID,
-			QmsDocumentsName:  d.QmsDocumentsName,
-			DQmsDocumentsDesc: d.DQmsDocumentsDesc,
-			Category:          d.Category,
-			FileName:          d.FileName,
-			CreatedAt:         d.CreatedAt,
-			UpdatedAt:         d.UpdatedAt,
+	approvals, err := s.repo.GetQmsDocumentApprovals(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.QmsDocumentApprovalResponse, 0, len(approvals))
+	for _, a := range approvals {
+		out = append(out, models.QmsDocumentApprovalResponse{
+			Action:     a.Action,
+			FromStatus: a.FromStatus,
+			ToStatus:   a.ToStatus,
+			EmpCode:    a.EmpCode,
+			Comment:    a.Comment,
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// GetQmsDocumentTasks lists documents waiting on the employee as owner, reviewer or approver
+func (s *QmsDocumentsService) GetQmsDocumentTasks(empCode string) ([]models.QmsDocumentResponse, error) {
+	docs, err := s.repo.GetQmsDocumentTasks(empCode)
+	if err != nil {
+		return nil, err
+	}
+	return toQmsDocumentResponses(docs), nil
+}
+
+// GetQmsDocumentsReviewDue lists effective documents whose periodic review falls within the next days
+func (s *QmsDocumentsService) GetQmsDocumentsReviewDue(days int) ([]models.QmsDocumentResponse, error) {
+	if days < 0 {
+		days = 0
+	}
+	docs, err := s.repo.GetQmsDocumentsReviewDue(time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	return toQmsDocumentResponses(docs), nil
+}
+
+// qmsDocumentFilter limits general users to effective documents; admins may pick statuses (comma separated)
+func qmsDocumentFilter(role, status string) domains.QmsDocumentFilter {
+	if !utils.HasAnyRole(role, qmsDocumentAdminRoles...) {
+		return domains.QmsDocumentFilter{Statuses: []string{domains.QmsStatusEffective}}
+	}
+
+	var filter domains.QmsDocumentFilter
+	for _, st := range strings.Split(status, ",") {
+		if st = strings.ToLower(strings.TrimSpace(st)); st != "" {
+			filter.Statuses = append(filter.Statuses, st)
 		}
 	}
+	return filter
+}
 
-	return resp, nil
+// checkQmsSeparationOfDuties keeps the owner from reviewing or approving their own document
+func checkQmsSeparationOfDuties(owner, reviewer, approver string) error {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil
+	}
+	if strings.EqualFold(owner, strings.TrimSpace(reviewer)) || strings.EqualFold(owner, strings.TrimSpace(approver)) {
+		return errs.NewError("the reviewer and approver must be someone other than the document owner")
+	}
+	return nil
+}
+
+func parseQmsDate(v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	d, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, errs.NewError("review_due_date must be YYYY-MM-DD")
+	}
+	return &d, nil
+}
+
+// qmsDocumentFiles looks up the stored files of a page of QMS documents at once
+func qmsDocumentFiles(docs ...domains.QmsDocuments) storedFiles {
+	names := make([]string, len(docs))
+	for i, d := range docs {
+		names[i] = d.FileName
+	}
+	return lookupStoredFiles(names...)
+}
+
+func toQmsDocumentResponse(d domains.QmsDocuments, files storedFiles) models.QmsDocumentResponse {
+	resp := models.QmsDocumentResponse{
+		QmsDocumentsID:    d.QmsDocumentsID,
+		QmsDocumentsName:  d.QmsDocumentsName,
+		DQmsDocumentsDesc: d.DQmsDocumentsDesc,
+		Category:          d.Category,
+		FileName:          d.FileName,
+		Thumbnails:        files.thumbnails(d.FileName),
+		FileSHA256:        files.sha256(d.FileName),
+		DocumentNo:        d.DocumentNo,
+		Revision:          d.Revision,
+		Status:            d.Status,
+		OwnerEmpCode:      d.OwnerEmpCode,
+		ReviewerEmpCode:   d.ReviewerEmpCode,
+		ApproverEmpCode:   d.ApproverEmpCode,
+		SubmittedAt:       d.SubmittedAt,
+		ReviewedAt:        d.ReviewedAt,
+		ApprovedAt:        d.ApprovedAt,
+		EffectiveAt:       d.EffectiveAt,
+		ObsoletedAt:       d.ObsoletedAt,
+		CreatedAt:         d.CreatedAt,
+		UpdatedAt:         d.UpdatedAt,
+	}
+	if d.ReviewDueDate != nil {
+		due := d.ReviewDueDate.Format("2006-01-02")
+		resp.ReviewDueDate = &due
+	}
+	return resp
+}
+
+func toQmsDocumentResponses(docs []domains.QmsDocuments) []models.QmsDocumentResponse {
+	out := make([]models.QmsDocumentResponse, 0, len(docs))
+	files := qmsDocumentFiles(docs...)
+	for _, d := range docs {
+		out = append(out, toQmsDocumentResponse(d, files))
+	}
+	return out
+}
+
+func toQmsDocumentListResponse(docs []domains.QmsDocuments, total int64, page, pageSize int) *models.QmsDocumentListResponse {
+	return &models.QmsDocumentListResponse{
+		Data:       toQmsDocumentResponses(docs),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}
 }
 
 // Implement port interface wrappers if needed
@@ -187,9 +480,12 @@ func (s *QmsDocumentsService) CreateQmsDocumentService(req models.CreateQmsDocum
 	return err
 }
 
-func (s *QmsDocumentsService) GetAllQmsDocumentService(limit, offset int) (models.QmsDocumentListResponse, error) {
+func (s *QmsDocumentsService) GetAllQmsDocumentService(limit, offset int, role, status string) (models.QmsDocumentListResponse, error) {
+	if limit <= 0 {
+		limit = 50
+	}
 	page := offset/limit + 1
-	resp, err := s.GetAllQmsDocuments(page, limit)
+	resp, err := s.GetAllQmsDocuments(page, limit, qmsDocumentFilter(role, status))
 	if err != nil {
 		return models.QmsDocumentListResponse{}, err
 	}
@@ -209,11 +505,40 @@ func (s *QmsDocumentsService) DeleteQmsDocumentService(id int) error {
 	return s.DeleteQmsDocument(id)
 }
 
-func (s *QmsDocumentsService) SearchQmsDocumentService(keyword string, limit, offset int) (models.QmsDocumentListResponse, error) {
-	page := offset/limit + 1
-	resp, err := s.SearchQmsDocuments(keyword, page, limit)
+func (s *QmsDocumentsService) SearchQmsDocumentService(req models.DocumentSearchRequest, role, status string) (models.QmsDocumentListResponse, error) {
+	search, limit, offset, err := documentSearch(domains.DocModuleQms, req)
+	if err != nil {
+		return models.QmsDocumentListResponse{}, err
+	}
+	resp, err := s.SearchQmsDocuments(search, offset/limit+1, limit, qmsDocumentFilter(role, status))
 	if err != nil {
 		return models.QmsDocumentListResponse{}, err
 	}
 	return *resp, nil
 }
+
+func (s *QmsDocumentsService) TransitionQmsDocumentService(id int, action, empCode, role string, req models.QmsDocumentWorkflowRequest) (*models.QmsDocumentResponse, error) {
+	return s.TransitionQmsDocument(id, strings.ToLower(action), empCode, role, req)
+}
+
+// GetQmsDocumentApprovalsService shows the approval history to whoever may see the document itself
+func (s *QmsDocumentsService) GetQmsDocumentApprovalsService(id int, empCode, role string) ([]models.QmsDocumentApprovalResponse, error) {
+	if !utils.HasAnyRole(role, qmsDocumentAdminRoles...) {
+		doc, err := s.repo.GetQmsDocumentByID(id)
+		if err != nil || !qmsDocumentVisible(doc.Status, empCode, doc.OwnerEmpCode, doc.ReviewerEmpCode, doc.ApproverEmpCode) {
+			return nil, errs.NewNotfoundError("qms document not found")
+		}
+	}
+	return s.GetQmsDocumentApprovals(id)
+}
+
+func (s *QmsDocumentsService) GetQmsDocumentTasksService(empCode string) ([]models.QmsDocumentResponse, error) {
+	if empCode == "" {
+		return nil, errors.New("emp code is required")
+	}
+	return s.GetQmsDocumentTasks(empCode)
+}
+
+func (s *QmsDocumentsService) GetQmsDocumentsReviewDueService(days int) ([]models.QmsDocumentResponse, error) {
+	return s.GetQmsDocumentsReviewDue(days)
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	portRepositories "backend/internal/core/ports/repositories"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/logs"
+	"backend/internal/pkgs/utils"
+)
+
+type SafetyAcknowledgementService struct {
+	ackRepo     portRepositories.SafetyAcknowledgementRepository
+	safetyRepo  portRepositories.SafetyDocumentRepository
+	revisionSrv portServices.DocumentRevisionService
+}
+
+func NewSafetyAcknowledgementService(ackRepo portRepositories.SafetyAcknowledgementRepository, safetyRepo portRepositories.SafetyDocumentRepository, revisionSrv portServices.DocumentRevisionService) *SafetyAcknowledgementService {
+	return &SafetyAcknowledgementService{ackRepo: ackRepo, safetyRepo: safetyRepo, revisionSrv: revisionSrv}
+}
+
+// SetAckRequirement starts a new acknowledgement round for a document revision. Earlier
+// acknowledgements stay on record but no longer count for the new round.
+func (s *SafetyAcknowledgementService) SetAckRequirement(safetyDocumentID int, req models.SafetyAckRequirementReq, actor string) (models.SafetyAckRequirementResp, error) {
+	if _, err := s.safetyRepo.GetSafetyDocumentByID(safetyDocumentID); err != nil {
+		return models.SafetyAckRequirementResp{}, errs.NewNotfoundError(err.Error())
+	}
+
+	var targets []domains.SafetyAckTarget
+	if req.Everyone {
+		targets = append(targets, domains.SafetyAckTarget{TargetType: domains.AckTargetAll, TargetValue: "*"})
+	} else {
+		seen := map[string]bool{}
+		for _, t := range req.Targets {
+			targetType := strings.ToLower(strings.TrimSpace(t.TargetType))
+			value := strings.TrimSpace(t.TargetValue)
+			if !domains.IsValidAckTargetType(targetType) || targetType == domains.AckTargetAll || value == "" {
+				return models.SafetyAckRequirementResp{}, errs.NewError("targets must be department or position with a value")
+			}
+			if key := targetType + "\x00" + value; !seen[key] {
+				seen[key] = true
+				targets = append(targets, domains.SafetyAckTarget{TargetType: targetType, TargetValue: value})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return models.SafetyAckRequirementResp{}, errs.NewError("set everyone or at least one department or position")
+	}
+
+	dueDate, err := parseSafetyDate("due_date", req.DueDate)
+	if err != nil {
+		return models.SafetyAckRequirementResp{}, err
+	}
+
+	revisionNo, err := s.ackRevision(safetyDocumentID, req.RevisionNo)
+	if err != nil {
+		return models.SafetyAckRequirementResp{}, err
+	}
+
+	requirement := &domains.SafetyAckRequirement{
+		SafetyDocumentID: safetyDocumentID,
+		RevisionNo:       revisionNo,
+		DueDate:          dueDate,
+		RequiredBy:       actor,
+	}
+	if err := s.ackRepo.CreateAckRequirement(requirement, targets); err != nil {
+		logs.Error(err)
+		return models.SafetyAckRequirementResp{}, err
+	}
+
+	return toSafetyAckRequirementModel(*requirement, targets), nil
+}
+
+// ackRevision checks the requested revision, or picks the current one, of the document
+func (s *SafetyAcknowledgementService) ackRevision(safetyDocumentID, revisionNo int) (int, error) {
+	revisions, err := s.revisionSrv.GetRevisions(domains.DocModuleSafety, safetyDocumentID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, rev := range revisions {
+		if (revisionNo == 0 && rev.IsCurrent) || (revisionNo != 0 && rev.RevisionNo == revisionNo) {
+			return rev.RevisionNo, nil
+		}
+	}
+	if revisionNo != 0 {
+		return 0, errs.NewNotfoundError("revision not found")
+	}
+	return 0, nil
+}
+
+// GetAckRequirement returns nil when the document does not require acknowledgement
+func (s *SafetyAcknowledgementService) GetAckRequirement(safetyDocumentID int) (*models.SafetyAckRequirementResp, error) {
+	requirement, targets, err := s.ackRepo.GetActiveAckRequirement(safetyDocumentID)
+	if err != nil || requirement == nil {
+		return nil, err
+	}
+	resp := toSafetyAckRequirementModel(*requirement, targets)
+	return &resp, nil
+}
+
+func (s *SafetyAcknowledgementService) CloseAckRequirement(safetyDocumentID int) error {
+	return s.ackRepo.CloseAckRequirement(safetyDocumentID)
+}
+
+// Acknowledge records that the employee has read the current revision. Acknowledging twice keeps the first time.
+func (s *SafetyAcknowledgementService) Acknowledge(safetyDocumentID int, empCode string) (models.SafetyAcknowledgementResp, error) {
+	if empCode == "" {
+		return models.SafetyAcknowledgementResp{}, errs.NewError("emp code is required")
+	}
+
+	requirement, _, err := s.ackRepo.GetActiveAckRequirement(safetyDocumentID)
+	if err != nil {
+		return models.SafetyAcknowledgementResp{}, err
+	}
+	if requirement == nil {
+		return models.SafetyAcknowledgementResp{}, errs.NewNotfoundError("document does not require acknowledgement")
+	}
+
+	targeted, err := s.ackRepo.IsAckTargeted(requirement.SafetyAckRequirementID, empCode)
+	if err != nil {
+		return models.SafetyAcknowledgementResp{}, err
+	}
+	if !targeted {
+		return models.SafetyAcknowledgementResp{}, errs.NewError("document does not require your acknowledgement")
+	}
+
+	ack, err := s.ackRepo.GetAcknowledgement(requirement.SafetyAckRequirementID, empCode)
+	if err != nil {
+		return models.SafetyAcknowledgementResp{}, err
+	}
+	if ack == nil {
+		ack = &domains.SafetyAcknowledgement{
+			SafetyAckRequirementID: requirement.SafetyAckRequirementID,
+			SafetyDocumentID:       safetyDocumentID,
+			RevisionNo:             requirement.RevisionNo,
+			EmpCode:                empCode,
+		}
+		if err := s.ackRepo.CreateAcknowledgement(ack); err != nil {
+			logs.Error(err)
+			return models.SafetyAcknowledgementResp{}, err
+		}
+	}
+
+	return models.SafetyAcknowledgementResp{
+		SafetyDocumentID: ack.SafetyDocumentID,
+		RevisionNo:       ack.RevisionNo,
+		EmpCode:          ack.EmpCode,
+		AcknowledgedAt:   ack.AcknowledgedAt,
+	}, nil
+}
+
+// GetPendingAcknowledgements lists the documents the employee still has to read and acknowledge
+func (s *SafetyAcknowledgementService) GetPendingAcknowledgements(empCode, baseURL string) ([]models.SafetyAckPendingResp, error) {
+	pending, err := s.ackRepo.GetPendingAcknowledgements(empCode)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.SafetyAckPendingResp, 0, len(pending))
+	for _, p := range pending {
+		item := models.SafetyAckPendingResp{
+			SafetyDocumentID:   p.SafetyDocumentID,
+			SafetyDocumentName: p.SafetyDocumentName,
+			Category:           p.Category,
+			Department:         p.Department,
+			RevisionNo:         p.RevisionNo,
+			FileURL:            utils.FileURL(baseURL, p.FileName),
+		}
+		if p.DueDate != nil {
+			d := p.DueDate.Format("2006-01-02")
+			item.DueDate = &d
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// GetAckReport counts acknowledged and pending employees of the active requirement, per department and per employee
+func (s *SafetyAcknowledgementService) GetAckReport(safetyDocumentID int, department string) (models.SafetyAckReportResp, error) {
+	doc, err := s.safetyRepo.GetSafetyDocumentByID(safetyDocumentID)
+	if err != nil {
+		return models.SafetyAckReportResp{}, errs.NewNotfoundError(err.Error())
+	}
+
+	requirement, targets, err := s.ackRepo.GetActiveAckRequirement(safetyDocumentID)
+	if err != nil {
+		return models.SafetyAckReportResp{}, err
+	}
+	if requirement == nil {
+		return models.SafetyAckReportResp{}, errs.NewNotfoundError("document does not require acknowledgement")
+	}
+
+	statuses, err := s.ackRepo.GetAckEmployeeStatuses(requirement.SafetyAckRequirementID, strings.TrimSpace(department))
+	if err != nil {
+		return models.SafetyAckReportResp{}, err
+	}
+
+	report := models.SafetyAckReportResp{
+		SafetyDocumentID:   doc.SafetyDocumentID,
+		SafetyDocumentName: doc.SafetyDocumentName,
+		Requirement:        toSafetyAckRequirementModel(*requirement, targets),
+		Departments:        []models.SafetyAckDepartmentResp{},
+		Employees:          make([]models.SafetyAckEmployeeResp, 0, len(statuses)),
+	}
+	byDepartment := map[string]int{}
+
+	for _, st := range statuses {
+		idx, ok := byDepartment[st.Department]
+		if !ok {
+			idx = len(report.Departments)
+			byDepartment[st.Department] = idx
+			report.Departments = append(report.Departments, models.SafetyAckDepartmentResp{Department: st.Department})
+		}
+
+		dept := &report.Departments[idx]
+		dept.Total++
+		report.Total++
+		if st.AcknowledgedAt != nil {
+			dept.Acknowledged++
+			report.Acknowledged++
+		} else {
+			dept.Pending++
+			report.Pending++
+		}
+
+		report.Employees = append(report.Employees, models.SafetyAckEmployeeResp{
+			EmpCode:        st.EmpCode,
+			FullNameTh:     st.FullNameTh,
+			FullNameEn:     st.FullNameEn,
+			Department:     st.Department,
+			Position:       st.Position,
+			Acknowledged:   st.AcknowledgedAt != nil,
+			AcknowledgedAt: st.AcknowledgedAt,
+		})
+	}
+
+	for i := range report.Departments {
+		d := &report.Departments[i]
+		d.Percent = math.Round(float64(d.Acknowledged)*1000/float64(d.Total)) / 10
+	}
+	return report, nil
+}
+
+func toSafetyAckRequirementModel(requirement domains.SafetyAckRequirement, targets []domains.SafetyAckTarget) models.SafetyAckRequirementResp {
+	resp := models.SafetyAckRequirementResp{
+		SafetyAckRequirementID: requirement.SafetyAckRequirementID,
+		SafetyDocumentID:       requirement.SafetyDocumentID,
+		RevisionNo:             requirement.RevisionNo,
+		Targets:                []models.SafetyAckTargetReq{},
+		RequiredBy:             requirement.RequiredBy,
+		CreatedAt:              requirement.CreatedAt,
+	}
+	for _, t := range targets {
+		if t.TargetType == domains.AckTargetAll {
+			resp.Everyone = true
+			continue
+		}
+		resp.Targets = append(resp.Targets, models.SafetyAckTargetReq{TargetType: t.TargetType, TargetValue: t.TargetValue})
+	}
+	if requirement.DueDate != nil {
+		d := requirement.DueDate.Format("2006-01-02")
+		resp.DueDate = &d
+	}
+	return resp
+}
+
+// GetAckReportCSV exports the report per employee, or per department when view is "department".
+// The file starts with a UTF-8 BOM so Excel shows Thai names correctly.
+func (s *SafetyAcknowledgementService) GetAckReportCSV(safetyDocumentID int, department, view string) ([]byte, string, error) {
+	report, err := s.GetAckReport(safetyDocumentID, department)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF")
+	w := csv.NewWriter(&buf)
+
+	revision := "-"
+	if report.Requirement.RevisionNo > 0 {
+		revision = fmt.Sprintf("Rev.%d", report.Requirement.RevisionNo)
+	}
+
+	if view == "department" {
+		_ = w.Write([]string{"department", "total", "acknowledged", "pending", "percent"})
+		for _, d := range report.Departments {
+			_ = w.Write([]string{
+				d.Department,
+				strconv.Itoa(d.Total),
+				strconv.Itoa(d.Acknowledged),
+				strconv.Itoa(d.Pending),
+				strconv.FormatFloat(d.Percent, 'f', 1, 64),
+			})
+		}
+	} else {
+		view = "employee"
+		_ = w.Write([]string{"emp_code", "full_name_th", "full_name_en", "department", "position", "revision", "status", "acknowledged_at"})
+		for _, e := range report.Employees {
+			status, at := "pending", ""
+			if e.AcknowledgedAt != nil {
+				status, at = "acknowledged", e.AcknowledgedAt.Format("2006-01-02 15:04:05")
+			}
+			_ = w.Write([]string{e.EmpCode, e.FullNameTh, e.FullNameEn, e.Department, e.Position, revision, status, at})
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, "", err
+	}
+
+	name := fmt.Sprintf("safety-document-%d-acknowledgements-%s.csv", safetyDocumentID, view)
+	return buf.Bytes(), name, nil
+}
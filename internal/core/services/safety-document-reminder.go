@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"backend/internal/core/domains"
+	portRepositories "backend/internal/core/ports/repositories"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/mail"
+	"backend/internal/pkgs/utils"
+)
+
+const (
+	// safetyReminderRole receives every reminder in addition to the document owner
+	safetyReminderRole = "SAFETY"
+
+	safetyReminderInterval = time.Hour
+)
+
+type SafetyDocumentReminderService struct {
+	repo    portRepositories.SafetyDocumentRepository
+	mailSrv portServices.MailService
+}
+
+func NewSafetyDocumentReminderService(repo portRepositories.SafetyDocumentRepository, mailSrv portServices.MailService) *SafetyDocumentReminderService {
+	return &SafetyDocumentReminderService{repo: repo, mailSrv: mailSrv}
+}
+
+// StartReminderScheduler checks review and expiry dates every hour from SAFETY_REMINDER_HOUR (server local
+// time, default 8) and e-mails the owner and the SAFETY role SAFETY_REMINDER_DAYS (default 30) ahead of a
+// due date and again once it has passed. Set SAFETY_REMINDER_ENABLED=false to turn it off.
+func (s *SafetyDocumentReminderService) StartReminderScheduler(ctx context.Context) {
+	if os.Getenv("SAFETY_REMINDER_ENABLED") == "false" {
+		log.Println("[SafetyReminder] scheduler disabled")
+		return
+	}
+
+	leadDays := envInt("SAFETY_REMINDER_DAYS", 30, 1, 365)
+	hour := envInt("SAFETY_REMINDER_HOUR", 8, 0, 23)
+	baseURL := strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/")
+
+	ticker := time.NewTicker(safetyReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		if now := time.Now(); now.Hour() >= hour {
+			if sent, err := s.SendDueReminders(now, leadDays, baseURL); err != nil {
+				log.Printf("[SafetyReminder] Failed: %v\n", err)
+			} else if sent > 0 {
+				log.Printf("[SafetyReminder] %d reminder(s) queued\n", sent)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDueReminders queues one e-mail per document and due date that has not been reminded yet
+func (s *SafetyDocumentReminderService) SendDueReminders(now time.Time, leadDays int, baseURL string) (int, error) {
+	today := safetyToday(now)
+
+	docs, err := s.repo.GetSafetyDocumentsDue(today.AddDate(0, 0, leadDays), "")
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, doc := range docs {
+		for _, due := range safetyDueDates(doc) {
+			if due.date.After(today.AddDate(0, 0, leadDays)) {
+				continue
+			}
+
+			kind := safetyReminderKind(due, today)
+			done, err := s.repo.HasSafetyDocumentReminder(doc.SafetyDocumentID, kind, due.date)
+			if err != nil {
+				return sent, err
+			}
+			if done {
+				continue
+			}
+
+			recipients, err := s.repo.GetSafetyReminderRecipients(doc.OwnerEmpCode, safetyReminderRole)
+			if err != nil {
+				return sent, err
+			}
+
+			to := make([]string, 0, len(recipients))
+			for _, emp := range recipients {
+				to = append(to, emp.AD_Mail)
+			}
+			if len(to) > 0 {
+				data := safetyReminderData(doc, kind, due, today, baseURL)
+				if _, err := s.mailSrv.EnqueueTemplate(to, nil, "notification", mail.LangTH, data); err != nil {
+					return sent, err
+				}
+				sent++
+			} else {
+				log.Printf("[SafetyReminder] No recipients for safety document #%d (%s)\n", doc.SafetyDocumentID, kind)
+			}
+
+			if err := s.repo.CreateSafetyDocumentReminder(&domains.SafetyDocumentReminder{
+				SafetyDocumentID: doc.SafetyDocumentID,
+				Kind:             kind,
+				DueDate:          due.date,
+				Recipients:       len(to),
+			}); err != nil {
+				return sent, err
+			}
+		}
+	}
+	return sent, nil
+}
+
+func safetyReminderKind(due safetyDueDate, today time.Time) string {
+	overdue := due.date.Before(today)
+	switch {
+	case due.dueType == "review" && overdue:
+		return domains.SafetyReminderReviewOverdue
+	case due.dueType == "review":
+		return domains.SafetyReminderReviewUpcoming
+	case overdue:
+		return domains.SafetyReminderExpired
+	default:
+		return domains.SafetyReminderExpiryUpcoming
+	}
+}
+
+func safetyReminderData(doc domains.SafetyDocument, kind string, due safetyDueDate, today time.Time, baseURL string) mail.NotificationData {
+	date := due.date.Format("02/01/2006")
+	days := int(due.date.Sub(today).Hours() / 24)
+
+	var title string
+	var lines []string
+	switch kind {
+	case domains.SafetyReminderReviewOverdue:
+		title = "เอกสารความปลอดภัยเลยกำหนดทบทวน / Safety document review overdue"
+		lines = []string{
+			fmt.Sprintf("เอกสาร \"%s\" เลยกำหนดทบทวนเมื่อ %s (%d วัน)", doc.SafetyDocumentName, date, -days),
+			fmt.Sprintf("The review of \"%s\" was due on %s (%d days ago).", doc.SafetyDocumentName, date, -days),
+		}
+	case domains.SafetyReminderReviewUpcoming:
+		title = "เอกสารความปลอดภัยใกล้ถึงกำหนดทบทวน / Safety document review due soon"
+		lines = []string{
+			fmt.Sprintf("เอกสาร \"%s\" ถึงกำหนดทบทวนวันที่ %s (อีก %d วัน)", doc.SafetyDocumentName, date, days),
+			fmt.Sprintf("\"%s\" must be reviewed by %s (in %d days).", doc.SafetyDocumentName, date, days),
+		}
+	case domains.SafetyReminderExpired:
+		title = "เอกสารความปลอดภัยหมดอายุ / Safety document expired"
+		lines = []string{
+			fmt.Sprintf("เอกสาร \"%s\" หมดอายุเมื่อ %s", doc.SafetyDocumentName, date),
+			fmt.Sprintf("\"%s\" expired on %s.", doc.SafetyDocumentName, date),
+		}
+	default:
+		title = "เอกสารความปลอดภัยใกล้หมดอายุ / Safety document expiring soon"
+		lines = []string{
+			fmt.Sprintf("เอกสาร \"%s\" จะหมดอายุวันที่ %s (อีก %d วัน)", doc.SafetyDocumentName, date, days),
+			fmt.Sprintf("\"%s\" expires on %s (in %d days).", doc.SafetyDocumentName, date, days),
+		}
+	}
+	lines = append(lines, fmt.Sprintf("หน่วยงาน / Department: %s", doc.Department))
+	if doc.OwnerEmpCode != "" {
+		lines = append(lines, fmt.Sprintf("ผู้รับผิดชอบ / Owner: %s", doc.OwnerEmpCode))
+	}
+
+	return mail.NotificationData{
+		Title:      title,
+		Lines:      lines,
+		ActionURL:  utils.FileURL(baseURL, doc.FileName),
+		ActionText: "เปิดเอกสาร / Open document",
+	}
+}
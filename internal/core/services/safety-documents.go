@@ -2,19 +2,24 @@ package services
 
 import (
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	portRepositories "backend/internal/core/ports/repositories"
 	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 )
 
 type SafetyDocumentService struct {
-	repo portRepositories.SafetyDocumentRepository
+	repo    portRepositories.SafetyDocumentRepository
+	textSrv portServices.DocumentTextService
 }
 
-func NewSafetyDocumentService(repo portRepositories.SafetyDocumentRepository) portServices.SafetyDocumentService {
-	return &SafetyDocumentService{repo: repo}
+func NewSafetyDocumentService(repo portRepositories.SafetyDocumentRepository, textSrv portServices.DocumentTextService) portServices.SafetyDocumentService {
+	return &SafetyDocumentService{repo: repo, textSrv: textSrv}
 }
 
 func (s *SafetyDocumentService) CreateSafetyDocument(req *models.CreateSafetyDocumentRequest) (*models.SafetyDocumentResponse, error) {
@@ -32,28 +37,32 @@ func (s *SafetyDocumentService) CreateSafetyDocument(req *models.CreateSafetyDoc
 		return nil, errors.New("department is required")
 	}
 
+	nextReview, err := parseSafetyDate("next_review_date", req.NextReviewDate)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := parseSafetyDate("expiry_date", req.ExpiryDate)
+	if err != nil {
+		return nil, err
+	}
+
 	doc := domains.SafetyDocument{
 		SafetyDocumentName: req.SafetyDocumentName,
 		SafetyDocumentDesc: req.SafetyDocumentDesc,
 		Category:           req.Category,
 		Department:         req.Department,
 		FileName:           req.FileName,
+		OwnerEmpCode:       strings.TrimSpace(req.OwnerEmpCode),
+		NextReviewDate:     nextReview,
+		ExpiryDate:         expiry,
 	}
 
 	if err := s.repo.CreateSafetyDocument(&doc); err != nil {
 		return nil, err
 	}
 
-	return &models.SafetyDocumentResponse{
-		SafetyDocumentID:   doc.SafetyDocumentID,
-		SafetyDocumentName: doc.SafetyDocumentName,
-		SafetyDocumentDesc: doc.SafetyDocumentDesc,
-		Category:           doc.Category,
-		Department:         doc.Department,
-		FileName:           doc.FileName,
-		CreatedAt:          doc.CreatedAt,
-		UpdatedAt:          doc.UpdatedAt,
-	}, nil
+	resp := toSafetyDocumentResponse(doc, safetyDocumentFiles(doc))
+	return &resp, nil
 }
 
 func (s *SafetyDocumentService) GetSafetyDocumentByID(id int) (*models.SafetyDocumentResponse, error) {
@@ -62,16 +71,8 @@ func (s *SafetyDocumentService) GetSafetyDocumentByID(id int) (*models.SafetyDoc
 		return nil, err
 	}
 
-	return &models.SafetyDocumentResponse{
-		SafetyDocumentID:   doc.SafetyDocumentID,
-		SafetyDocumentName: doc.SafetyDocumentName,
-		SafetyDocumentDesc: doc.SafetyDocumentDesc,
-		Category:           doc.Category,
-		Department:         doc.Department,
-		FileName:           doc.FileName,
-		CreatedAt:          doc.CreatedAt,
-		UpdatedAt:          doc.UpdatedAt,
-	}, nil
+	resp := toSafetyDocumentResponse(*doc, safetyDocumentFiles(*doc))
+	return &resp, nil
 }
 
 func (s *SafetyDocumentService) GetAllSafetyDocuments(limit, offset int) (*models.SafetyDocumentListResponse, error) {
@@ -88,17 +89,9 @@ func (s *SafetyDocumentService) GetAllSafetyDocuments(limit, offset int) (*model
 	}
 
 	var responses []models.SafetyDocumentResponse
+	files := safetyDocumentFiles(docs...)
 	for _, doc := range docs {
-		responses = append(responses, models.SafetyDocumentResponse{
-			SafetyDocumentID:   doc.SafetyDocumentID,
-			SafetyDocumentName: doc.SafetyDocumentName,
-			SafetyDocumentDesc: doc.SafetyDocumentDesc,
-			Category:           doc.Category,
-			Department:         doc.Department,
-			FileName:           doc.FileName,
-			CreatedAt:          doc.CreatedAt,
-			UpdatedAt:          doc.UpdatedAt,
-		})
+		responses = append(responses, toSafetyDocumentResponse(doc, files))
 	}
 
 	page := offset/limit + 1
@@ -127,17 +120,9 @@ func (s *SafetyDocumentService) GetSafetyDocumentByCategory(category string, lim
 	}
 
 	var responses []models.SafetyDocumentResponse
+	files := safetyDocumentFiles(docs...)
 	for _, doc := range docs {
-		responses = append(responses, models.SafetyDocumentResponse{
-			SafetyDocumentID:   doc.SafetyDocumentID,
-			SafetyDocumentName: doc.SafetyDocumentName,
-			SafetyDocumentDesc: doc.SafetyDocumentDesc,
-			Category:           doc.Category,
-			Department:         doc.Department,
-			FileName:           doc.FileName,
-			CreatedAt:          doc.CreatedAt,
-			UpdatedAt:          doc.UpdatedAt,
-		})
+		responses = append(responses, toSafetyDocumentResponse(doc, files))
 	}
 
 	page := offset/limit + 1
@@ -166,17 +151,9 @@ func (s *SafetyDocumentService) GetSafetyDocumentByDepartment(department string,
 	}
 
 	var responses []models.SafetyDocumentResponse
+	files := safetyDocumentFiles(docs...)
 	for _, doc := range docs {
-		responses = append(responses, models.SafetyDocumentResponse{
-			SafetyDocumentID:   doc.SafetyDocumentID,
-			SafetyDocumentName: doc.SafetyDocumentName,
-			SafetyDocumentDesc: doc.SafetyDocumentDesc,
-			Category:           doc.Category,
-			Department:         doc.Department,
-			FileName:           doc.FileName,
-			CreatedAt:          doc.CreatedAt,
-			UpdatedAt:          doc.UpdatedAt,
-		})
+		responses = append(responses, toSafetyDocumentResponse(doc, files))
 	}
 
 	page := offset/limit + 1
@@ -216,6 +193,24 @@ func (s *SafetyDocumentService) UpdateSafetyDocument(id int, req *models.UpdateS
 	if req.FileName != "" {
 		updates["file_name"] = req.FileName
 	}
+	if req.OwnerEmpCode != "" {
+		updates["owner_emp_code"] = strings.TrimSpace(req.OwnerEmpCode)
+	}
+	// A new review date re-arms the reminders for the document
+	if req.NextReviewDate != "" {
+		nextReview, err := parseSafetyDate("next_review_date", req.NextReviewDate)
+		if err != nil {
+			return nil, err
+		}
+		updates["next_review_date"] = nextReview
+	}
+	if req.ExpiryDate != "" {
+		expiry, err := parseSafetyDate("expiry_date", req.ExpiryDate)
+		if err != nil {
+			return nil, err
+		}
+		updates["expiry_date"] = expiry
+	}
 
 	if err := s.repo.UpdateSafetyDocument(id, updates); err != nil {
 		return nil, err
@@ -229,31 +224,24 @@ func (s *SafetyDocumentService) DeleteSafetyDocument(id int) error {
 	return s.repo.DeleteSafetyDocument(id)
 }
 
-func (s *SafetyDocumentService) SearchSafetyDocuments(keyword string, limit, offset int) (*models.SafetyDocumentListResponse, error) {
-	if limit <= 0 {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100
-	}
-
-	docs, total, err := s.repo.SearchSafetyDocuments(keyword, limit, offset)
+func (s *SafetyDocumentService) SearchSafetyDocuments(search domains.DocumentSearch, limit, offset int) (*models.SafetyDocumentListResponse, error) {
+	docs, total, err := s.repo.SearchSafetyDocuments(search, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []models.SafetyDocumentResponse
+	ids := make([]int, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.SafetyDocumentID
+	}
+	matches := documentPageMatches(s.textSrv, domains.DocModuleSafety, ids, search.Keyword)
+
+	responses := make([]models.SafetyDocumentResponse, 0, len(docs))
+	files := safetyDocumentFiles(docs...)
 	for _, doc := range docs {
-		responses = append(responses, models.SafetyDocumentResponse{
-			SafetyDocumentID:   doc.SafetyDocumentID,
-			SafetyDocumentName: doc.SafetyDocumentName,
-			SafetyDocumentDesc: doc.SafetyDocumentDesc,
-			Category:           doc.Category,
-			Department:         doc.Department,
-			FileName:           doc.FileName,
-			CreatedAt:          doc.CreatedAt,
-			UpdatedAt:          doc.UpdatedAt,
-		})
+		resp := toSafetyDocumentResponse(doc, files)
+		resp.Matches = matches[doc.SafetyDocumentID]
+		responses = append(responses, resp)
 	}
 
 	page := offset/limit + 1
@@ -268,6 +256,142 @@ func (s *SafetyDocumentService) SearchSafetyDocuments(keyword string, limit, off
 	}, nil
 }
 
+// GetSafetyReviewStatus lists overdue reviews/expiries and those due within the next days, by department
+func (s *SafetyDocumentService) GetSafetyReviewStatus(days int, department string) (*models.SafetyReviewStatusResp, error) {
+	if days < 0 {
+		days = 0
+	}
+	today := safetyToday(time.Now())
+	horizon := today.AddDate(0, 0, days)
+
+	docs, err := s.repo.GetSafetyDocumentsDue(horizon, strings.TrimSpace(department))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.SafetyReviewStatusResp{
+		AsOf:        today.Format("2006-01-02"),
+		Days:        days,
+		Departments: []models.SafetyReviewDepartmentResp{},
+	}
+	byDepartment := map[string]int{}
+
+	for _, doc := range docs {
+		for _, due := range safetyDueDates(doc) {
+			if due.date.After(horizon) {
+				continue
+			}
+
+			idx, ok := byDepartment[doc.Department]
+			if !ok {
+				idx = len(resp.Departments)
+				byDepartment[doc.Department] = idx
+				resp.Departments = append(resp.Departments, models.SafetyReviewDepartmentResp{
+					Department: doc.Department,
+					Overdue:    []models.SafetyReviewItemResp{},
+					Upcoming:   []models.SafetyReviewItemResp{},
+				})
+			}
+
+			item := models.SafetyReviewItemResp{
+				SafetyDocumentID:   doc.SafetyDocumentID,
+				SafetyDocumentName: doc.SafetyDocumentName,
+				Category:           doc.Category,
+				OwnerEmpCode:       doc.OwnerEmpCode,
+				DueType:            due.dueType,
+				DueDate:            due.date.Format("2006-01-02"),
+				DaysLeft:           int(due.date.Sub(today).Hours() / 24),
+			}
+			dept := &resp.Departments[idx]
+			if due.date.Before(today) {
+				dept.Overdue = append(dept.Overdue, item)
+				resp.TotalOverdue++
+			} else {
+				dept.Upcoming = append(dept.Upcoming, item)
+				resp.TotalUpcoming++
+			}
+		}
+	}
+
+	for i := range resp.Departments {
+		sortSafetyReviewItems(resp.Departments[i].Overdue)
+		sortSafetyReviewItems(resp.Departments[i].Upcoming)
+	}
+	return resp, nil
+}
+
+type safetyDueDate struct {
+	dueType string
+	date    time.Time
+}
+
+// safetyDueDates returns the review and expiry dates set on a document
+func safetyDueDates(doc domains.SafetyDocument) []safetyDueDate {
+	var out []safetyDueDate
+	if doc.NextReviewDate != nil {
+		out = append(out, safetyDueDate{dueType: "review", date: safetyToday(*doc.NextReviewDate)})
+	}
+	if doc.ExpiryDate != nil {
+		out = append(out, safetyDueDate{dueType: "expiry", date: safetyToday(*doc.ExpiryDate)})
+	}
+	return out
+}
+
+func sortSafetyReviewItems(items []models.SafetyReviewItemResp) {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DueDate < items[j].DueDate })
+}
+
+// safetyToday truncates t to a calendar date, so DATE columns compare by day
+func safetyToday(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseSafetyDate(field, v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	d, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, errs.NewError(field + " must be YYYY-MM-DD")
+	}
+	return &d, nil
+}
+
+// safetyDocumentFiles looks up the stored files of a page of safety documents at once
+func safetyDocumentFiles(docs ...domains.SafetyDocument) storedFiles {
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.FileName
+	}
+	return lookupStoredFiles(names...)
+}
+
+func toSafetyDocumentResponse(doc domains.SafetyDocument, files storedFiles) models.SafetyDocumentResponse {
+	resp := models.SafetyDocumentResponse{
+		SafetyDocumentID:   doc.SafetyDocumentID,
+		SafetyDocumentName: doc.SafetyDocumentName,
+		SafetyDocumentDesc: doc.SafetyDocumentDesc,
+		Category:           doc.Category,
+		Department:         doc.Department,
+		FileName:           doc.FileName,
+		Thumbnails:         files.thumbnails(doc.FileName),
+		FileSHA256:         files.sha256(doc.FileName),
+		OwnerEmpCode:       doc.OwnerEmpCode,
+		CreatedAt:          doc.CreatedAt,
+		UpdatedAt:          doc.UpdatedAt,
+	}
+	if doc.NextReviewDate != nil {
+		d := doc.NextReviewDate.Format("2006-01-02")
+		resp.NextReviewDate = &d
+	}
+	if doc.ExpiryDate != nil {
+		d := doc.ExpiryDate.Format("2006-01-02")
+		resp.ExpiryDate = &d
+	}
+	return resp
+}
+
 // ===== Port Interface Implementation =====
 
 // CreateSafetyDocumentService implements the port interface
@@ -320,10 +444,15 @@ func (s *SafetyDocumentService) DeleteSafetyDocumentService(id int) error {
 }
 
 // SearchSafetyDocumentService implements the port interface
-func (s *SafetyDocumentService) SearchSafetyDocumentService(keyword string, page, pageSize int) (*models.SafetyDocumentListResponse, error) {
-	if page < 1 {
-		page = 1
+func (s *SafetyDocumentService) SearchSafetyDocumentService(req models.DocumentSearchRequest) (*models.SafetyDocumentListResponse, error) {
+	search, limit, offset, err := documentSearch(domains.DocModuleSafety, req)
+	if err != nil {
+		return nil, err
 	}
-	offset := (page - 1) * pageSize
-	return s.SearchSafetyDocuments(keyword, pageSize, offset)
+	return s.SearchSafetyDocuments(search, limit, offset)
+}
+
+// GetSafetyReviewStatusService implements the port interface
+func (s *SafetyDocumentService) GetSafetyReviewStatusService(days int, department string) (*models.SafetyReviewStatusResp, error) {
+	return s.GetSafetyReviewStatus(days, department)
 }
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/thumbnail"
+)
+
+const (
+	uploadGCInterval    = time.Hour
+	uploadGCBlobBatch   = 200
+	maxUploadGCOrphans  = 500 // listed in the report; the counts cover every orphan
+	uploadGCDefaultDays = 7
+)
+
+// UploadGCService removes uploads no record refers to any more: files replaced by an update, files of
+// soft-deleted documents and files left behind by a create that failed after the upload. A file is only
+// reported the first time it is found unreferenced and deleted once it stayed so for the grace period.
+type UploadGCService struct {
+	repo      ports.UploadGCRepository
+	blobRepo  ports.FileBlobRepository
+	graceDays int
+	running   sync.Mutex
+}
+
+// NewUploadGCService reads the grace period from UPLOAD_GC_GRACE_DAYS (default 7)
+func NewUploadGCService(repo ports.UploadGCRepository, blobRepo ports.FileBlobRepository) *UploadGCService {
+	return &UploadGCService{
+		repo:      repo,
+		blobRepo:  blobRepo,
+		graceDays: envInt("UPLOAD_GC_GRACE_DAYS", uploadGCDefaultDays, 1, 365),
+	}
+}
+
+// StartScheduler collects orphans once a day from UPLOAD_GC_HOUR (server local time, default 2).
+// UPLOAD_GC_DRY_RUN=true only reports; UPLOAD_GC_ENABLED=false turns the job off, e.g. on all replicas but one.
+func (s *UploadGCService) StartScheduler(ctx context.Context) {
+	if os.Getenv("UPLOAD_GC_ENABLED") == "false" {
+		log.Println("[UploadGC] scheduler disabled")
+		return
+	}
+
+	hour := envInt("UPLOAD_GC_HOUR", 2, 0, 23)
+	dryRun := os.Getenv("UPLOAD_GC_DRY_RUN") == "true"
+
+	ticker := time.NewTicker(uploadGCInterval)
+	defer ticker.Stop()
+
+	lastRun := ""
+	for {
+		if now := time.Now(); now.Hour() >= hour && now.Format("2006-01-02") != lastRun {
+			lastRun = now.Format("2006-01-02")
+			report, err := s.CollectOrphanUploads(ctx, dryRun)
+			if err != nil {
+				log.Printf("[UploadGC] Failed: %v\n", err)
+			} else {
+				logUploadGCReport(report)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectOrphanUploads cross-references every file under uploads/ with the tables storing file paths and
+// reports disk usage per folder. Unless dryRun, newly found orphans start their grace period, orphans past it
+// are deleted together with their thumbnails, and unreferenced content-addressed blobs are removed.
+func (s *UploadGCService) CollectOrphanUploads(ctx context.Context, dryRun bool) (models.UploadGCReport, error) {
+	if !s.running.TryLock() {
+		return models.UploadGCReport{}, errs.NewError("the upload garbage collector is already running")
+	}
+	defer s.running.Unlock()
+
+	now := time.Now()
+	grace := time.Duration(s.graceDays) * 24 * time.Hour
+	report := models.UploadGCReport{
+		DryRun:    dryRun,
+		GraceDays: s.graceDays,
+		Orphans:   []models.UploadGCOrphan{},
+		StartedAt: now.Format(time.RFC3339),
+	}
+
+	referenced, err := s.referencedKeys()
+	if err != nil {
+		return models.UploadGCReport{}, err
+	}
+	tracked, err := s.repo.GetOrphanUploads()
+	if err != nil {
+		return models.UploadGCReport{}, err
+	}
+	trackedByKey := make(map[string]domains.OrphanUpload, len(tracked))
+	for _, o := range tracked {
+		trackedByKey[o.StorageKey] = o
+	}
+
+	store := storage.Default()
+	managed := managedUploadFolders()
+	folders := make(map[string]*models.UploadGCFolderStats)
+	inContent := make(map[string]bool)
+	var orphans []storage.Info
+
+	err = store.Walk(ctx, storage.Root+"/", func(info storage.Info) error {
+		folder := uploadFolder(info.Key)
+		st, ok := folders[folder]
+		if !ok {
+			st = &models.UploadGCFolderStats{Folder: folder, Managed: managed[folder]}
+			folders[folder] = st
+		}
+		st.Files++
+		st.Bytes += info.Size
+		if !st.Managed {
+			return nil
+		}
+
+		// a thumbnail lives and dies with the upload it was generated from
+		owner := info.Key
+		if original, ok := thumbnail.Original(path.Base(info.Key)); ok {
+			owner = path.Join(path.Dir(info.Key), original)
+		}
+		isReferenced, err := s.isReferenced(owner, folder, referenced, inContent)
+		if err != nil {
+			return err
+		}
+		if isReferenced {
+			st.ReferencedFiles++
+			st.ReferencedBytes += info.Size
+			return nil
+		}
+		st.OrphanFiles++
+		st.OrphanBytes += info.Size
+		orphans = append(orphans, info)
+		return nil
+	})
+	if err != nil {
+		return models.UploadGCReport{}, err
+	}
+
+	var untrack []string
+	for _, info := range orphans {
+		st := folders[uploadFolder(info.Key)]
+		orphan, isTracked := trackedByKey[info.Key]
+		delete(trackedByKey, info.Key)
+		if !isTracked {
+			orphan = domains.OrphanUpload{StorageKey: info.Key, Folder: st.Folder, FileSize: info.Size, FirstSeenAt: now}
+		}
+
+		item := models.UploadGCOrphan{
+			Key:         info.Key,
+			Folder:      st.Folder,
+			Size:        info.Size,
+			FirstSeenAt: orphan.FirstSeenAt.Format(time.RFC3339),
+			DeleteAfter: orphan.FirstSeenAt.Add(grace).Format(time.RFC3339),
+			Due:         !orphan.FirstSeenAt.Add(grace).After(now),
+		}
+
+		switch {
+		case dryRun:
+		case item.Due:
+			if err := store.Delete(ctx, info.Key); err != nil {
+				log.Printf("[UploadGC] Failed to delete %s: %v\n", info.Key, err)
+				report.FailedFiles++
+				break
+			}
+			item.Deleted = true
+			untrack = append(untrack, info.Key)
+			st.DeletedFiles++
+			st.DeletedBytes += info.Size
+			report.DeletedFiles++
+			report.DeletedBytes += info.Size
+		case isTracked:
+			if err := s.repo.UpdateOrphanUploadSeen(info.Key, info.Size, now); err != nil {
+				return models.UploadGCReport{}, err
+			}
+		default:
+			orphan.LastSeenAt = now
+			if err := s.repo.CreateOrphanUpload(&orphan); err != nil {
+				return models.UploadGCReport{}, err
+			}
+		}
+
+		if len(report.Orphans) < maxUploadGCOrphans {
+			report.Orphans = append(report.Orphans, item)
+		} else {
+			report.OrphansTruncated = true
+		}
+	}
+
+	if !dryRun {
+		// files referenced again or removed by someone else are no longer tracked
+		for key := range trackedByKey {
+			untrack = append(untrack, key)
+		}
+		if err := s.repo.DeleteOrphanUploads(untrack); err != nil {
+			return models.UploadGCReport{}, err
+		}
+	}
+
+	if cas, ok := store.(*storage.ContentAddressed); ok {
+		blobs, err := s.collectBlobs(ctx, cas.Inner(), now.Add(-grace), dryRun)
+		if err != nil {
+			return models.UploadGCReport{}, err
+		}
+		report.Blobs = &blobs
+	}
+
+	report.Folders = make([]models.UploadGCFolderStats, 0, len(folders))
+	for _, st := range folders {
+		report.Folders = append(report.Folders, *st)
+	}
+	sort.Slice(report.Folders, func(i, j int) bool { return report.Folders[i].Folder < report.Folders[j].Folder })
+	report.FinishedAt = time.Now().Format(time.RFC3339)
+	return report, nil
+}
+
+// collectBlobs removes blobs that lost their last link before cutoff, and blob objects without a row
+// (left by an upload whose link could not be saved)
+func (s *UploadGCService) collectBlobs(ctx context.Context, inner storage.Storage, cutoff time.Time, dryRun bool) (models.UploadGCBlobStats, error) {
+	var stats models.UploadGCBlobStats
+	if !dryRun {
+		for {
+			blobs, err := s.blobRepo.GetUnreferencedFileBlobs(cutoff, uploadGCBlobBatch)
+			if err != nil {
+				return stats, err
+			}
+			for _, blob := range blobs {
+				deleted, err := s.blobRepo.DeleteUnreferencedFileBlob(blob.SHA256, cutoff)
+				if err != nil {
+					return stats, err
+				}
+				if !deleted {
+					continue
+				}
+				if err := inner.Delete(ctx, storage.BlobKey(blob.SHA256)); err != nil {
+					// the object is picked up as a blob without a row on the next run
+					log.Printf("[UploadGC] Failed to delete blob %s: %v\n", blob.SHA256, err)
+					continue
+				}
+				stats.DeletedBlobs++
+				stats.DeletedBytes += blob.Size
+			}
+			if len(blobs) < uploadGCBlobBatch {
+				break
+			}
+		}
+	}
+
+	stray := make(map[string]storage.Info)
+	err := inner.Walk(ctx, storage.BlobPrefix, func(info storage.Info) error {
+		if info.ModTime.Before(cutoff) {
+			stray[path.Base(info.Key)] = info
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	sums := make([]string, 0, len(stray))
+	for sum := range stray {
+		sums = append(sums, sum)
+	}
+	known, err := s.blobRepo.FindFileBlobs(sums)
+	if err != nil {
+		return stats, err
+	}
+	for _, sum := range known {
+		delete(stray, sum)
+	}
+	for _, info := range stray {
+		if dryRun {
+			continue
+		}
+		if err := inner.Delete(ctx, info.Key); err != nil {
+			log.Printf("[UploadGC] Failed to delete blob %s: %v\n", info.Key, err)
+			continue
+		}
+		stats.DeletedBlobs++
+		stats.DeletedBytes += info.Size
+	}
+
+	totals, err := s.blobRepo.GetFileBlobStats()
+	if err != nil {
+		return stats, err
+	}
+	stats.Blobs, stats.Bytes = totals.Blobs, totals.Bytes
+	stats.UnreferencedBlobs, stats.UnreferencedBytes = totals.UnreferencedBlobs, totals.UnreferencedBytes
+	return stats, nil
+}
+
+// referencedKeys loads every stored path as a storage key
+func (s *UploadGCService) referencedKeys() (map[string]bool, error) {
+	values, err := s.repo.GetUploadReferences()
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(values))
+	for _, v := range values {
+		for _, key := range uploadReferenceKeys(v) {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
+
+// isReferenced also looks for company news images embedded in article content; results are cached per file
+func (s *UploadGCService) isReferenced(key, folder string, referenced, inContent map[string]bool) (bool, error) {
+	if referenced[key] {
+		return true, nil
+	}
+	if folder != domains.CompanyNewsUploadFolder {
+		return false, nil
+	}
+	if found, ok := inContent[key]; ok {
+		return found, nil
+	}
+	found, err := s.repo.IsCompanyNewsContentFile(path.Base(key))
+	if err != nil {
+		return false, err
+	}
+	inContent[key] = found
+	return found, nil
+}
+
+// managedUploadFolders are the folders whose every reference the collector knows. Product images are not
+// among them: this service has no product table to check them against.
+func managedUploadFolders() map[string]bool {
+	folders := map[string]bool{
+		domains.CompanyNewsUploadFolder:    true,
+		domains.WelfareBenefitUploadFolder: true,
+	}
+	for _, mod := range domains.DocumentModules {
+		folders[path.Base(mod.UploadDir)] = true
+	}
+	return folders
+}
+
+// uploadFolder is the first folder below uploads/, or "" for files stored directly in it
+func uploadFolder(key string) string {
+	rest := strings.TrimPrefix(key, storage.Root+"/")
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[:i]
+	}
+	return ""
+}
+
+// uploadReferenceKeys turns a stored value into storage keys: a plain path, a JSON array of paths, or a
+// file service URL (?folder=&filename=). Other values yield no key.
+func uploadReferenceKeys(value string) []string {
+	value = strings.TrimSpace(value)
+	values := []string{value}
+	if strings.HasPrefix(value, "[") {
+		var paths []string
+		if err := json.Unmarshal([]byte(value), &paths); err == nil {
+			values = paths
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for _, v := range values {
+		if u, err := url.Parse(v); err == nil && (u.Scheme != "" || u.RawQuery != "") {
+			if folder, name := u.Query().Get("folder"), u.Query().Get("filename"); folder != "" && name != "" {
+				v = path.Join(storage.Root, folder, name)
+			} else {
+				v = u.Path
+			}
+		}
+		if key := storage.CleanKey(v); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func logUploadGCReport(report models.UploadGCReport) {
+	orphans := 0
+	for _, st := range report.Folders {
+		orphans += st.OrphanFiles
+		log.Printf("[UploadGC] %s: %d file(s), %d bytes, %d orphan(s), %d bytes\n", st.Folder, st.Files, st.Bytes, st.OrphanFiles, st.OrphanBytes)
+	}
+	log.Printf("[UploadGC] %d orphan file(s), %d deleted (%d bytes), %d failed (dry run: %t)\n",
+		orphans, report.DeletedFiles, report.DeletedBytes, report.FailedFiles, report.DryRun)
+}
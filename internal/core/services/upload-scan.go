@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"backend/internal/core/domains"
+	ports "backend/internal/core/ports/repositories"
+	portServices "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/mail"
+	"backend/internal/pkgs/scanner"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/utils"
+)
+
+// uploadScanAlertRole receives the malware notifications, on top of UPLOAD_SCAN_ALERT_EMAILS
+const uploadScanAlertRole = "SU"
+
+// UploadScanService screens uploads for malware before the uploader stores them and registers each
+// upload with its verdict. It is installed with utils.SetUploadGuard.
+type UploadScanService struct {
+	repo     ports.UploadedFileRepository
+	scanner  scanner.Scanner
+	mailSrv  portServices.MailService
+	action   string
+	failOpen bool
+}
+
+// NewUploadScanService takes a nil scanner when scanning is disabled; uploads are then registered as skipped
+func NewUploadScanService(repo ports.UploadedFileRepository, sc scanner.Scanner, mailSrv portServices.MailService) *UploadScanService {
+	action := strings.ToLower(os.Getenv("UPLOAD_SCAN_ACTION"))
+	if action != domains.UploadScanActionReject {
+		action = domains.UploadScanActionQuarantine
+	}
+
+	return &UploadScanService{
+		repo:    repo,
+		scanner: sc,
+		mailSrv: mailSrv,
+		action:  action,
+		// by default an unreachable scanner blocks uploads instead of letting them through unchecked
+		failOpen: os.Getenv("UPLOAD_SCAN_FAIL_OPEN") == "true",
+	}
+}
+
+// Screen implements utils.UploadGuard
+func (s *UploadScanService) Screen(ctx context.Context, upload utils.UploadInfo, open func() (io.ReadCloser, error)) error {
+	record := domains.UploadedFile{
+		StorageKey:   upload.Key,
+		OriginalName: upload.OriginalName,
+		MimeType:     upload.MimeType,
+		FileSize:     upload.Size,
+		ScanStatus:   domains.UploadScanSkipped,
+		UploadedBy:   upload.UploadedBy,
+	}
+	if s.scanner == nil {
+		s.register(&record)
+		return nil
+	}
+
+	result, err := s.scan(ctx, open)
+	now := time.Now()
+	record.ScanEngine = s.scanner.Engine()
+	record.ScannedAt = &now
+
+	switch {
+	case err != nil:
+		log.Printf("[UploadScan] Failed to scan %s: %v\n", upload.OriginalName, err)
+		record.ScanStatus = domains.UploadScanError
+		record.ScanError = truncateRunes(err.Error(), 500)
+		if s.failOpen {
+			s.register(&record)
+			return nil
+		}
+		record.StorageKey = ""
+		s.register(&record)
+		return errs.NewError("the file could not be checked for malware, please try again later")
+
+	case result.Infected:
+		log.Printf("[UploadScan] %s uploaded by %s is infected with %s\n", upload.OriginalName, upload.UploadedBy, result.Signature)
+		record.ScanStatus = domains.UploadScanInfected
+		record.ScanSignature = result.Signature
+		record.StorageKey = ""
+		if s.action == domains.UploadScanActionQuarantine {
+			key := quarantineKey(upload.Key)
+			if err := s.quarantine(ctx, key, upload, open); err != nil {
+				log.Printf("[UploadScan] Failed to quarantine %s: %v\n", upload.OriginalName, err)
+			} else {
+				record.StorageKey = key
+				record.Quarantined = true
+			}
+		}
+		s.register(&record)
+		s.notifyInfected(record)
+		return errs.NewError("the file was rejected because malware was detected")
+	}
+
+	record.ScanStatus = domains.UploadScanClean
+	s.register(&record)
+	return nil
+}
+
+func (s *UploadScanService) scan(ctx context.Context, open func() (io.ReadCloser, error)) (scanner.Result, error) {
+	src, err := open()
+	if err != nil {
+		return scanner.Result{}, err
+	}
+	defer src.Close()
+	return s.scanner.Scan(ctx, src)
+}
+
+func (s *UploadScanService) quarantine(ctx context.Context, key string, upload utils.UploadInfo, open func() (io.ReadCloser, error)) error {
+	if key == "" {
+		return fmt.Errorf("invalid upload key %q", upload.Key)
+	}
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return storage.Default().Put(ctx, key, src, upload.Size, "application/octet-stream")
+}
+
+// register failures are logged only: the verdict has already been decided
+func (s *UploadScanService) register(record *domains.UploadedFile) {
+	if err := s.repo.CreateUploadedFile(record); err != nil {
+		log.Printf("[UploadScan] Failed to register upload %s: %v\n", record.OriginalName, err)
+	}
+}
+
+// notifyInfected e-mails the system administrators and UPLOAD_SCAN_ALERT_EMAILS
+func (s *UploadScanService) notifyInfected(record domains.UploadedFile) {
+	if s.mailSrv == nil {
+		return
+	}
+
+	to := splitAddresses(os.Getenv("UPLOAD_SCAN_ALERT_EMAILS"))
+	recipients, err := s.repo.GetUploadScanAlertRecipients(uploadScanAlertRole)
+	if err != nil {
+		log.Printf("[UploadScan] Failed to load alert recipients: %v\n", err)
+	}
+	for _, emp := range recipients {
+		to = append(to, emp.AD_Mail)
+	}
+	if len(to) == 0 {
+		log.Printf("[UploadScan] No recipients for the malware alert on upload #%d\n", record.UploadedFileID)
+		return
+	}
+
+	outcome := "ไฟล์ถูกปฏิเสธและไม่ได้จัดเก็บ / The file was rejected and not stored."
+	if record.Quarantined {
+		outcome = fmt.Sprintf("ไฟล์ถูกกักกันไว้ที่ / The file was quarantined at: %s", record.StorageKey)
+	}
+	data := mail.NotificationData{
+		Title: "ตรวจพบมัลแวร์ในไฟล์ที่อัปโหลด / Malware detected in an upload",
+		Lines: []string{
+			fmt.Sprintf("ไฟล์ / File: %s (%s, %d bytes)", record.OriginalName, record.MimeType, record.FileSize),
+			fmt.Sprintf("ผลการตรวจ / Detection: %s (%s)", record.ScanSignature, record.ScanEngine),
+			fmt.Sprintf("ผู้อัปโหลด / Uploaded by: %s", record.UploadedBy),
+			outcome,
+		},
+	}
+	if _, err := s.mailSrv.EnqueueTemplate(to, nil, "notification", mail.LangTH, data); err != nil {
+		log.Printf("[UploadScan] Failed to queue the malware alert: %v\n", err)
+	}
+}
+
+// quarantineKey moves uploads/<folder>/<name> to uploads/quarantine/<folder>/<name>
+func quarantineKey(key string) string {
+	key = storage.CleanKey(key)
+	if key == "" {
+		return ""
+	}
+	return path.Join(storage.Root, domains.QuarantineUploadFolder, strings.TrimPrefix(key, storage.Root+"/"))
+}
+
+func truncateRunes(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
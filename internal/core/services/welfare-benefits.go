@@ -41,10 +41,14 @@ func (s *WelfareBenefitService) CreateWelfareBenefit(req *models.CreateWelfareBe
 		return nil, err
 	}
 
+	files := lookupStoredFiles(benefit.ImageURL, benefit.FileName)
 	return &models.WelfareBenefitResponse{
 		WelfareBenefitID: benefit.WelfareBenefitID,
 		Title:            benefit.Title,
 		ImageURL:         benefit.ImageURL,
+		Thumbnails:       files.thumbnails(benefit.ImageURL),
+		FileSHA256:       files.sha256(benefit.FileName),
+		ImageSHA256:      files.sha256(benefit.ImageURL),
 		Description:      benefit.Description,
 		Category:         benefit.Category,
 		FileName:         benefit.FileName,
@@ -59,10 +63,14 @@ func (s *WelfareBenefitService) GetWelfareBenefitByID(id int) (*models.WelfareBe
 		return nil, err
 	}
 
+	files := lookupStoredFiles(benefit.ImageURL, benefit.FileName)
 	return &models.WelfareBenefitResponse{
 		WelfareBenefitID: benefit.WelfareBenefitID,
 		Title:            benefit.Title,
 		ImageURL:         benefit.ImageURL,
+		Thumbnails:       files.thumbnails(benefit.ImageURL),
+		FileSHA256:       files.sha256(benefit.FileName),
+		ImageSHA256:      files.sha256(benefit.ImageURL),
 		Description:      benefit.Description,
 		Category:         benefit.Category,
 		FileName:         benefit.FileName,
@@ -85,11 +93,15 @@ func (s *WelfareBenefitService) GetAllWelfareBenefits(limit, offset int) (*model
 	}
 
 	var responses []models.WelfareBenefitResponse
+	files := welfareBenefitFiles(benefits...)
 	for _, benefit := range benefits {
 		responses = append(responses, models.WelfareBenefitResponse{
 			WelfareBenefitID: benefit.WelfareBenefitID,
 			Title:            benefit.Title,
 			ImageURL:         benefit.ImageURL,
+			Thumbnails:       files.thumbnails(benefit.ImageURL),
+			FileSHA256:       files.sha256(benefit.FileName),
+			ImageSHA256:      files.sha256(benefit.ImageURL),
 			Description:      benefit.Description,
 			Category:         benefit.Category,
 			FileName:         benefit.FileName,
@@ -124,11 +136,15 @@ func (s *WelfareBenefitService) GetWelfareBenefitByCategory(category string, lim
 	}
 
 	var responses []models.WelfareBenefitResponse
+	files := welfareBenefitFiles(benefits...)
 	for _, benefit := range benefits {
 		responses = append(responses, models.WelfareBenefitResponse{
 			WelfareBenefitID: benefit.WelfareBenefitID,
 			Title:            benefit.Title,
 			ImageURL:         benefit.ImageURL,
+			Thumbnails:       files.thumbnails(benefit.ImageURL),
+			FileSHA256:       files.sha256(benefit.FileName),
+			ImageSHA256:      files.sha256(benefit.ImageURL),
 			Description:      benefit.Description,
 			Category:         benefit.Category,
 			FileName:         benefit.FileName,
@@ -233,11 +249,15 @@ func (s *WelfareBenefitService) GetAllWelfareBenefitsNoPagination() (*models.Wel
 	}
 
 	var responses []models.WelfareBenefitResponse
+	files := welfareBenefitFiles(benefits...)
 	for _, benefit := range benefits {
 		responses = append(responses, models.WelfareBenefitResponse{
 			WelfareBenefitID: benefit.WelfareBenefitID,
 			Title:            benefit.Title,
 			ImageURL:         benefit.ImageURL,
+			Thumbnails:       files.thumbnails(benefit.ImageURL),
+			FileSHA256:       files.sha256(benefit.FileName),
+			ImageSHA256:      files.sha256(benefit.ImageURL),
 			Description:      benefit.Description,
 			Category:         benefit.Category,
 			FileName:         benefit.FileName,
@@ -320,3 +340,12 @@ func (s *WelfareBenefitService) SearchWelfareBenefitService(keyword string, page
 func (s *WelfareBenefitService) GetWelfareBenefitsCountService() (int64, error) {
 	return s.GetWelfareBenefitsCount()
 }
+
+// welfareBenefitFiles looks up the stored files and images of a page of welfare benefits
+func welfareBenefitFiles(benefits ...domains.WelfareBenefit) storedFiles {
+	names := make([]string, 0, 2*len(benefits))
+	for _, benefit := range benefits {
+		names = append(names, benefit.FileName, benefit.ImageURL)
+	}
+	return lookupStoredFiles(names...)
+}
@@ -8,17 +8,21 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 	uploader "backend/internal/pkgs/utils"
 )
 
 type CustomerManualHandler struct {
 	CustomerManualSrv services.CustomerManualService
+	RevisionSrv       services.DocumentRevisionService
+	TextSrv           services.DocumentTextService
 }
 
-func NewCustomerManualHandler(insSrv services.CustomerManualService) *CustomerManualHandler {
-	return &CustomerManualHandler{CustomerManualSrv: insSrv}
+func NewCustomerManualHandler(insSrv services.CustomerManualService, revisionSrv services.DocumentRevisionService, textSrv services.DocumentTextService) *CustomerManualHandler {
+	return &CustomerManualHandler{CustomerManualSrv: insSrv, RevisionSrv: revisionSrv, TextSrv: textSrv}
 }
 
 func (h *CustomerManualHandler) CreateCustomerManualNestedHandler(c *fiber.Ctx) error {
@@ -67,6 +71,10 @@ func (h *CustomerManualHandler) CreateCustomerManualNestedHandler(c *fiber.Ctx)
 			return nil, err
 		}
 
+		if req.FileName != "" {
+			indexDocumentText(h.TextSrv, domains.DocModuleCustomerManual, resp.CustomerManualID)
+		}
+
 		created := &CreatedManual{
 			ID:               resp.CustomerManualID,
 			CustomerManualID: resp.CustomerManualID,
@@ -127,6 +135,7 @@ func (h *CustomerManualHandler) CreateCustomerManualHandler(c *fiber.Ctx) error
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create customer manual"})
 	}
 
+	indexUploadedText(h.TextSrv, domains.DocModuleCustomerManual, req.FileName)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Customer manual created successfully",
 		"file":    relPath,
@@ -148,6 +157,21 @@ func (h *CustomerManualHandler) GetAllCustomerManualHandler(c *fiber.Ctx) error
 	return c.Status(http.StatusOK).JSON(manuals)
 }
 
+// Search customer manuals
+func (h *CustomerManualHandler) SearchCustomerManualHandler(c *fiber.Ctx) error {
+	manuals, err := h.CustomerManualSrv.SearchCustomerManualService(documentSearchRequest(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search customer manuals",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(manuals)
+}
+
 // Get customer manuals as tree structure
 func (h *CustomerManualHandler) GetCustomerManualTreeHandler(c *fiber.Ctx) error {
 	tree, err := h.CustomerManualSrv.GetAllCustomerManualsTree()
@@ -222,12 +246,27 @@ func (h *CustomerManualHandler) UpdateCustomerManualHandler(c *fiber.Ctx) error
 		req.FileName = fmt.Sprintf("[\"%s\"]", relPath)
 	}
 
+	revisionReq, err := parseDocumentRevisionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if relPath != "" {
+		if err := h.RevisionSrv.EnsureBaselineRevision(domains.DocModuleCustomerManual, customerManualID); err != nil {
+			log.Printf("[UpdateCustomerManualHandler] Baseline revision error: %v\n", err)
+		}
+	}
+
 	log.Printf("[UpdateCustomerManualHandler] Calling UpdateCustomerManualService with ID: %d\n", customerManualID)
 	if err := h.CustomerManualSrv.UpdateCustomerManualService(customerManualID, req); err != nil {
 		log.Printf("[UpdateCustomerManualHandler] Service error: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update customer manual"})
 	}
 
+	recordDocumentRevision(h.RevisionSrv, domains.DocModuleCustomerManual, customerManualID, req.FileName, revisionReq)
+	if req.FileName != "" {
+		indexDocumentText(h.TextSrv, domains.DocModuleCustomerManual, customerManualID)
+	}
+
 	log.Println("[UpdateCustomerManualHandler] Update completed successfully")
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "Customer manual updated successfully",
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+)
+
+type DocumentAccessHandler struct {
+	AccessSrv services.DocumentAccessService
+	Module    string
+}
+
+func NewDocumentAccessHandler(insSrv services.DocumentAccessService, module string) *DocumentAccessHandler {
+	return &DocumentAccessHandler{AccessSrv: insSrv, Module: module}
+}
+
+// View and download totals of one document
+func (h *DocumentAccessHandler) GetDocumentAccessStatHandler(c *fiber.Ctx) error {
+	documentID, err := c.ParamsInt("document_id")
+	if err != nil || documentID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid document ID"})
+	}
+
+	stat, err := h.AccessSrv.GetDocumentAccessStat(h.Module, documentID)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch document access stats"})
+	}
+
+	return c.JSON(fiber.Map{"data": stat})
+}
+
+// Most viewed documents of the current month; ?limit= defaults to 10
+func (h *DocumentAccessHandler) GetMostViewedHandler(c *fiber.Ctx) error {
+	report, err := h.AccessSrv.GetMostViewedThisMonth(h.Module, c.QueryInt("limit", 0))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch most viewed documents"})
+	}
+
+	return c.JSON(report)
+}
+
+// Documents nobody opened in the last ?months= (default 6); ?format=csv exports it
+func (h *DocumentAccessHandler) GetUnopenedDocumentsHandler(c *fiber.Ctx) error {
+	months := c.QueryInt("months", 0)
+
+	if c.Query("format") == "csv" {
+		data, fileName, err := h.AccessSrv.GetUnopenedDocumentsCSV(h.Module, months)
+		if err != nil {
+			if appErr, ok := err.(errs.AppError); ok {
+				return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to export unopened documents"})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+		return c.Send(data)
+	}
+
+	report, err := h.AccessSrv.GetUnopenedDocuments(h.Module, months)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch unopened documents"})
+	}
+
+	return c.JSON(report)
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/resumable"
+	"backend/internal/pkgs/spreadsheet"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/thumbnail"
+	uploader "backend/internal/pkgs/utils"
+)
+
+const maxImportManifestSize = 5 << 20
+
+// documentImportMIMEs are the file types each module's create endpoint accepts
+var documentImportMIMEs = map[string][]string{
+	domains.DocModuleSafety:           searchableDocumentMIMEs,
+	domains.DocModuleQms:              searchableDocumentMIMEs,
+	domains.DocModuleProcedureManual:  searchableDocumentMIMEs,
+	domains.DocModuleCustomerManual:   {"application/pdf"},
+	domains.DocModuleOrganizationDocs: {"application/pdf"},
+}
+
+// DocumentImportHandler serves the bulk import of one document module
+type DocumentImportHandler struct {
+	ImportSrv services.DocumentImportService
+	TextSrv   services.DocumentTextService
+	Module    string
+}
+
+func NewDocumentImportHandler(importSrv services.DocumentImportService, textSrv services.DocumentTextService, module string) *DocumentImportHandler {
+	return &DocumentImportHandler{ImportSrv: importSrv, TextSrv: textSrv, Module: module}
+}
+
+// ImportDocumentsHandler creates documents from a ZIP ("archive", or "archive_upload_token" for a completed
+// resumable upload) and a CSV/XLSX "manifest" with one row per document; without a manifest field the
+// archive's manifest.csv or manifest.xlsx is used and its file paths are relative to it. Every row is
+// validated first and nothing is imported unless all are valid; ?dry_run=true only validates.
+func (h *DocumentImportHandler) ImportDocumentsHandler(c *fiber.Ctx) error {
+	zr, closeArchive, token, err := openImportArchive(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer closeArchive()
+
+	manifestName, manifest, baseDir, err := readImportManifest(c, zr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	rows, err := spreadsheet.Read(manifestName, manifest)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid manifest: " + err.Error()})
+	}
+
+	files := newImportArchive(zr, baseDir)
+	opt := uploader.Options{
+		Dir:          domains.DocumentModules[h.Module].UploadDir,
+		AllowedMIMEs: documentImportMIMEs[h.Module],
+		MaxSize:      50 << 20,
+		Required:     true,
+	}
+	checkFile := func(name string) error {
+		f, err := files.find(name)
+		if err != nil {
+			return err
+		}
+		if err := uploader.CheckZipFile(f, opt); err != nil {
+			return fmt.Errorf("file %q: %v", name, err)
+		}
+		return nil
+	}
+
+	report, err := h.ImportSrv.PrepareDocumentImport(h.Module, rows, uploader.AuthEmpCode(c), checkFile)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		log.Printf("[ImportDocuments] %s: %v\n", h.Module, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to import documents"})
+	}
+	report.DryRun = c.QueryBool("dry_run")
+	if report.InvalidRows > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "The manifest has invalid rows; nothing was imported", "data": report})
+	}
+	if report.DryRun {
+		return c.JSON(fiber.Map{"data": report})
+	}
+
+	// store every file first so the rows can be inserted in one transaction
+	var saved []string
+	removeSaved := func() {
+		for _, p := range saved {
+			thumbnail.Remove(p)
+			_ = storage.Default().Delete(context.Background(), p)
+		}
+	}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.File == "" {
+			continue
+		}
+		f, err := files.find(row.File)
+		var relPath string
+		if err == nil {
+			relPath, _, err = uploader.SaveZipFile(c, f, opt)
+		}
+		if err != nil {
+			removeSaved()
+			row.Status, row.Errors = models.DocumentImportFailed, append(row.Errors, err.Error())
+			report.InvalidRows++
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "A file could not be stored; nothing was imported", "data": report})
+		}
+		saved = append(saved, relPath)
+		row.FileName = fmt.Sprintf("[\"%s\"]", relPath)
+	}
+
+	if err := h.ImportSrv.ImportDocuments(h.Module, &report); err != nil {
+		removeSaved()
+		log.Printf("[ImportDocuments] %s: %v\n", h.Module, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to import documents"})
+	}
+
+	if token != "" {
+		if err := resumable.Default().Remove(token); err != nil {
+			log.Printf("[ImportDocuments] Failed to remove upload session %s: %v\n", token, err)
+		}
+	}
+	indexImportedText(h.TextSrv, h.Module, report.Rows)
+	log.Printf("[ImportDocuments] %s: %d document(s) imported by %s\n", h.Module, report.ImportedRows, uploader.AuthUsername(c))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Documents imported successfully", "data": report})
+}
+
+// openImportArchive opens the uploaded ZIP; token is set when it came from a resumable upload
+func openImportArchive(c *fiber.Ctx) (*zip.Reader, func(), string, error) {
+	if fh, err := c.FormFile("archive"); err == nil && fh != nil {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		zr, err := zip.NewReader(f, fh.Size)
+		if err != nil {
+			f.Close()
+			return nil, nil, "", errors.New("archive is not a valid ZIP file")
+		}
+		return zr, func() { f.Close() }, "", nil
+	}
+
+	token := c.FormValue("archive" + uploader.UploadTokenSuffix)
+	if token == "" {
+		return nil, nil, "", errors.New("archive is required")
+	}
+	f, session, err := uploader.OpenUploadSession(c, token)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	zr, err := zip.NewReader(f, session.Size)
+	if err != nil {
+		f.Close()
+		return nil, nil, "", errors.New("archive is not a valid ZIP file")
+	}
+	return zr, func() { f.Close() }, token, nil
+}
+
+// readImportManifest returns the "manifest" form file, or the single manifest.csv/.xlsx inside the archive
+// together with its folder, which the file paths of its rows are relative to
+func readImportManifest(c *fiber.Ctx, zr *zip.Reader) (string, []byte, string, error) {
+	if fh, err := c.FormFile("manifest"); err == nil && fh != nil {
+		f, err := fh.Open()
+		if err != nil {
+			return "", nil, "", err
+		}
+		defer f.Close()
+		data, err := readImportManifestData(f)
+		return fh.Filename, data, "", err
+	}
+
+	var found *zip.File
+	for _, f := range zr.File {
+		switch strings.ToLower(path.Base(f.Name)) {
+		case "manifest.csv", "manifest.xlsx":
+			if found != nil {
+				return "", nil, "", errors.New("the archive has more than one manifest; send it as the manifest field")
+			}
+			found = f
+		}
+	}
+	if found == nil {
+		return "", nil, "", errors.New("manifest is required")
+	}
+	rc, err := found.Open()
+	if err != nil {
+		return "", nil, "", err
+	}
+	defer rc.Close()
+	data, err := readImportManifestData(rc)
+	return found.Name, data, path.Dir(found.Name), err
+}
+
+func readImportManifestData(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImportManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportManifestSize {
+		return nil, errors.New("manifest is too large")
+	}
+	return data, nil
+}
+
+// importArchive looks up the files a manifest names: by path from the manifest's folder or the archive root,
+// or by bare file name when it is unique in the archive. Names are matched ignoring case.
+type importArchive struct {
+	baseDir string
+	byPath  map[string]*zip.File
+	byName  map[string][]*zip.File
+}
+
+func newImportArchive(zr *zip.Reader, baseDir string) *importArchive {
+	a := &importArchive{baseDir: baseDir, byPath: map[string]*zip.File{}, byName: map[string][]*zip.File{}}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		key := strings.ToLower(importPath(f.Name))
+		a.byPath[key] = f
+		a.byName[path.Base(key)] = append(a.byName[path.Base(key)], f)
+	}
+	return a
+}
+
+func (a *importArchive) find(name string) (*zip.File, error) {
+	p := strings.ToLower(importPath(name))
+	if f, ok := a.byPath[strings.ToLower(importPath(path.Join(a.baseDir, p)))]; ok {
+		return f, nil
+	}
+	if f, ok := a.byPath[p]; ok {
+		return f, nil
+	}
+	if !strings.Contains(p, "/") {
+		switch matches := a.byName[p]; len(matches) {
+		case 1:
+			return matches[0], nil
+		case 0:
+		default:
+			return nil, fmt.Errorf("file %q matches several files in the archive; give its folder", name)
+		}
+	}
+	return nil, fmt.Errorf("file %q is not in the archive", name)
+}
+
+// importPath normalises a path inside an archive or manifest: forward slashes, no leading "./" or "/"
+func importPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(strings.TrimSpace(name), "\\", "/")), "/")
+}
+
+// indexImportedText indexes the imported documents one after another in the background
+func indexImportedText(srv services.DocumentTextService, module string, rows []models.DocumentImportRow) {
+	if srv == nil {
+		return
+	}
+	go func() {
+		for _, row := range rows {
+			if row.FileName == "" || row.DocumentID == 0 {
+				continue
+			}
+			if err := srv.IndexDocument(module, row.DocumentID); err != nil {
+				log.Printf("[DocumentText] Failed to index %s #%d: %v\n", module, row.DocumentID, err)
+			}
+		}
+	}()
+}
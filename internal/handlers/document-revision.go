@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+// DocumentRevisionHandler serves the revision APIs of one document module
+type DocumentRevisionHandler struct {
+	RevisionSrv services.DocumentRevisionService
+	TextSrv     services.DocumentTextService
+	FileSrv     services.FileService
+	AccessSrv   services.DocumentAccessService
+	Module      string
+}
+
+func NewDocumentRevisionHandler(insSrv services.DocumentRevisionService, textSrv services.DocumentTextService, fileSrv services.FileService, accessSrv services.DocumentAccessService, module string) *DocumentRevisionHandler {
+	return &DocumentRevisionHandler{RevisionSrv: insSrv, TextSrv: textSrv, FileSrv: fileSrv, AccessSrv: accessSrv, Module: module}
+}
+
+func (h *DocumentRevisionHandler) GetRevisionsHandler(c *fiber.Ctx) error {
+	documentID, err := c.ParamsInt("document_id")
+	if err != nil || documentID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid document ID"})
+	}
+	if !h.canReadDocument(c, documentID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "document not found"})
+	}
+
+	revisions, err := h.RevisionSrv.GetRevisions(h.Module, documentID)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve revisions"})
+	}
+
+	return c.JSON(fiber.Map{"data": revisions})
+}
+
+func (h *DocumentRevisionHandler) DownloadRevisionHandler(c *fiber.Ctx) error {
+	documentID, revisionNo, err := revisionParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !h.canReadDocument(c, documentID) {
+		return fileNotFound(c)
+	}
+
+	filePath, name, err := h.RevisionSrv.GetRevisionFile(h.Module, documentID, revisionNo)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve revision file"})
+	}
+
+	download := !c.QueryBool("inline")
+	if err := utils.ServeFile(c, filePath, name, download); err != nil {
+		log.Printf("[DownloadRevisionHandler] Error sending %s: %v\n", filePath, err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "revision file not found"})
+	}
+
+	action := domains.DocAccessView
+	if download {
+		action = domains.DocAccessDownload
+	}
+	logDocumentAccess(c, h.AccessSrv, h.Module, documentID, action)
+	return nil
+}
+
+func (h *DocumentRevisionHandler) SetCurrentRevisionHandler(c *fiber.Ctx) error {
+	documentID, revisionNo, err := revisionParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.RevisionSrv.SetCurrentRevision(h.Module, documentID, revisionNo, utils.AuthRole(c)); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to set current revision"})
+	}
+
+	indexDocumentText(h.TextSrv, h.Module, documentID)
+
+	log.Printf("[SetCurrentRevision] %s #%d -> revision %d by %s\n", h.Module, documentID, revisionNo, utils.AuthUsername(c))
+	return c.JSON(fiber.Map{"message": "Current revision updated successfully"})
+}
+
+// canReadDocument applies the file service's access rules, so revisions of a QMS document that is not
+// effective stay hidden from everyone but QMS admins and the people working on it
+func (h *DocumentRevisionHandler) canReadDocument(c *fiber.Ctx, documentID int) bool {
+	err := h.FileSrv.CheckDocumentAccess(h.Module, documentID, fileViewer(c))
+	if err != nil {
+		if _, ok := err.(errs.AppError); !ok {
+			log.Printf("[DocumentRevision] Access check for %s #%d: %v\n", h.Module, documentID, err)
+		}
+		return false
+	}
+	return true
+}
+
+func revisionParams(c *fiber.Ctx) (int, int, error) {
+	documentID, err := c.ParamsInt("document_id")
+	if err != nil || documentID <= 0 {
+		return 0, 0, errors.New("Invalid document ID")
+	}
+	revisionNo, err := c.ParamsInt("revision_no")
+	if err != nil || revisionNo <= 0 {
+		return 0, 0, errors.New("Invalid revision number")
+	}
+	return documentID, revisionNo, nil
+}
+
+// parseDocumentRevisionForm reads revision_label, change_note and effective_date (YYYY-MM-DD); the uploader
+// is always the signed-in user, never a form field
+func parseDocumentRevisionForm(c *fiber.Ctx) (models.DocumentRevisionReq, error) {
+	req := models.DocumentRevisionReq{
+		RevisionLabel: c.FormValue("revision_label"),
+		ChangeNote:    c.FormValue("change_note"),
+		UploadedBy:    utils.AuthUsername(c),
+	}
+
+	if v := strings.TrimSpace(c.FormValue("effective_date")); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return req, errors.New("effective_date must be YYYY-MM-DD")
+		}
+		req.EffectiveDate = &d
+	}
+	return req, nil
+}
+
+// recordDocumentRevision keeps the previous file and stores the new upload as the current revision.
+// The document update itself has already succeeded, so failures are only logged.
+func recordDocumentRevision(srv services.DocumentRevisionService, module string, documentID int, fileName string, req models.DocumentRevisionReq) {
+	if srv == nil || fileName == "" {
+		return
+	}
+	if _, err := srv.RecordRevision(module, documentID, fileName, req); err != nil {
+		log.Printf("[DocumentRevision] Failed to record %s #%d: %v\n", module, documentID, err)
+	}
+}
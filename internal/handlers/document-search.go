@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+)
+
+// documentSearchRequest reads keyword, category, department, limit and offset from the query string
+func documentSearchRequest(c *fiber.Ctx) models.DocumentSearchRequest {
+	return models.DocumentSearchRequest{
+		Keyword:    c.Query("keyword"),
+		Category:   c.Query("category"),
+		Department: c.Query("department"),
+		Limit:      c.QueryInt("limit", 50),
+		Offset:     c.QueryInt("offset", 0),
+	}
+}
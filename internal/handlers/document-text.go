@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+// searchableDocumentMIMEs are the upload types whose text can be extracted for search
+var searchableDocumentMIMEs = []string{
+	"application/pdf",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// DocumentTextHandler serves the text index APIs of one document module
+type DocumentTextHandler struct {
+	TextSrv services.DocumentTextService
+	Module  string
+}
+
+func NewDocumentTextHandler(insSrv services.DocumentTextService, module string) *DocumentTextHandler {
+	return &DocumentTextHandler{TextSrv: insSrv, Module: module}
+}
+
+// RebuildTextIndexHandler re-extracts the text of every document in the module, e.g. for files uploaded before indexing existed
+func (h *DocumentTextHandler) RebuildTextIndexHandler(c *fiber.Ctx) error {
+	indexed, err := h.TextSrv.RebuildIndex(h.Module)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to rebuild text index"})
+	}
+
+	log.Printf("[RebuildTextIndex] %s: %d document(s) indexed by %s\n", h.Module, indexed, utils.AuthUsername(c))
+	return c.JSON(fiber.Map{"message": "Text index rebuilt", "indexed": indexed})
+}
+
+// indexDocumentText re-extracts the document text in the background; the upload has already succeeded
+func indexDocumentText(srv services.DocumentTextService, module string, documentID int) {
+	if srv == nil {
+		return
+	}
+	go func() {
+		if err := srv.IndexDocument(module, documentID); err != nil {
+			log.Printf("[DocumentText] Failed to index %s #%d: %v\n", module, documentID, err)
+		}
+	}()
+}
+
+// indexUploadedText indexes a newly created document, found by its stored file name
+func indexUploadedText(srv services.DocumentTextService, module, fileName string) {
+	if srv == nil || fileName == "" {
+		return
+	}
+	go func() {
+		if err := srv.IndexUploadedFile(module, fileName); err != nil {
+			log.Printf("[DocumentText] Failed to index new %s file %s: %v\n", module, fileName, err)
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+type GlobalSearchHandler struct {
+	GlobalSearchSrv services.GlobalSearchService
+}
+
+func NewGlobalSearchHandler(insSrv services.GlobalSearchService) *GlobalSearchHandler {
+	return &GlobalSearchHandler{GlobalSearchSrv: insSrv}
+}
+
+// GlobalSearchHandler searches every module at once; type takes a comma-separated list of result types
+func (h *GlobalSearchHandler) GlobalSearchHandler(c *fiber.Ctx) error {
+	req := models.GlobalSearchReq{
+		Keyword:    c.Query("keyword"),
+		Category:   c.Query("category"),
+		Department: c.Query("department"),
+		EmpCode:    utils.AuthEmpCode(c),
+		Role:       utils.AuthRole(c),
+		BaseURL:    utils.PublicBaseURL(c),
+		Limit:      c.QueryInt("limit", 20),
+	}
+	if types := c.Query("type"); types != "" {
+		req.Types = strings.Split(types, ",")
+	}
+
+	result, err := h.GlobalSearchSrv.Search(req)
+	if err != nil {
+		log.Printf("[GlobalSearchHandler] Error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to search"})
+	}
+
+	return c.JSON(result)
+}
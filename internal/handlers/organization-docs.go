@@ -9,17 +9,21 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 	uploader "backend/internal/pkgs/utils"
 )
 
 type OrganizationDocHandler struct {
 	OrganizationDocSrv services.OrganizationDocService
+	RevisionSrv        services.DocumentRevisionService
+	TextSrv            services.DocumentTextService
 }
 
-func NewOrganizationDocHandler(insSrv services.OrganizationDocService) *OrganizationDocHandler {
-	return &OrganizationDocHandler{OrganizationDocSrv: insSrv}
+func NewOrganizationDocHandler(insSrv services.OrganizationDocService, revisionSrv services.DocumentRevisionService, textSrv services.DocumentTextService) *OrganizationDocHandler {
+	return &OrganizationDocHandler{OrganizationDocSrv: insSrv, RevisionSrv: revisionSrv, TextSrv: textSrv}
 }
 
 // Create organization doc with single file upload
@@ -47,6 +51,7 @@ func (h *OrganizationDocHandler) CreateOrganizationDocHandler(c *fiber.Ctx) erro
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create organization doc"})
 	}
 
+	indexUploadedText(h.TextSrv, domains.DocModuleOrganizationDocs, req.FileName)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Organization doc created successfully",
 		"file":    relPath,
@@ -68,6 +73,21 @@ func (h *OrganizationDocHandler) GetAllOrganizationDocHandler(c *fiber.Ctx) erro
 	return c.Status(http.StatusOK).JSON(docs)
 }
 
+// Search organization docs
+func (h *OrganizationDocHandler) SearchOrganizationDocHandler(c *fiber.Ctx) error {
+	docs, err := h.OrganizationDocSrv.SearchOrganizationDocService(documentSearchRequest(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search organization docs",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(docs)
+}
+
 // Get organization docs by department
 func (h *OrganizationDocHandler) GetOrganizationDocByDepartmentHandler(c *fiber.Ctx) error {
 	department := c.Params("department")
@@ -131,12 +151,27 @@ func (h *OrganizationDocHandler) UpdateOrganizationDocHandler(c *fiber.Ctx) erro
 		req.FileName = fmt.Sprintf("[\"%s\"]", relPath)
 	}
 
+	revisionReq, err := parseDocumentRevisionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if relPath != "" {
+		if err := h.RevisionSrv.EnsureBaselineRevision(domains.DocModuleOrganizationDocs, docID); err != nil {
+			log.Printf("[UpdateOrganizationDocHandler] Baseline revision error: %v\n", err)
+		}
+	}
+
 	log.Printf("[UpdateOrganizationDocHandler] Calling UpdateOrganizationDocService with ID: %d\n", docID)
 	if err := h.OrganizationDocSrv.UpdateOrganizationDocService(docID, req); err != nil {
 		log.Printf("[UpdateOrganizationDocHandler] Service error: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update organization doc"})
 	}
 
+	recordDocumentRevision(h.RevisionSrv, domains.DocModuleOrganizationDocs, docID, req.FileName, revisionReq)
+	if req.FileName != "" {
+		indexDocumentText(h.TextSrv, domains.DocModuleOrganizationDocs, docID)
+	}
+
 	log.Println("[UpdateOrganizationDocHandler] Update completed successfully")
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "Organization doc updated successfully",
@@ -8,23 +8,27 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 	uploader "backend/internal/pkgs/utils"
 )
 
 type ProcedureManualHandler struct {
 	ProcedureManualSrv services.ProcedureManualService
+	RevisionSrv        services.DocumentRevisionService
+	TextSrv            services.DocumentTextService
 }
 
-func NewProcedureManualHandler(insSrv services.ProcedureManualService) *ProcedureManualHandler {
-	return &ProcedureManualHandler{ProcedureManualSrv: insSrv}
+func NewProcedureManualHandler(insSrv services.ProcedureManualService, revisionSrv services.DocumentRevisionService, textSrv services.DocumentTextService) *ProcedureManualHandler {
+	return &ProcedureManualHandler{ProcedureManualSrv: insSrv, RevisionSrv: revisionSrv, TextSrv: textSrv}
 }
 
 func (h *ProcedureManualHandler) CreateProcedureManualHandler(c *fiber.Ctx) error {
 	relPath, _, err := uploader.UploadFromForm(c, "file", uploader.Options{
 		Dir:          "./uploads/procedure_manual",
-		AllowedMIMEs: []string{"application/pdf"},
+		AllowedMIMEs: searchableDocumentMIMEs,
 		MaxSize:      50 << 20, // 50MB
 		BaseURL:      "",
 		Required:     true,
@@ -45,6 +49,7 @@ func (h *ProcedureManualHandler) CreateProcedureManualHandler(c *fiber.Ctx) erro
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create procedure manual"})
 	}
 
+	indexUploadedText(h.TextSrv, domains.DocModuleProcedureManual, req.FileName)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Procedure manual created successfully",
 		"file":    relPath,
@@ -66,6 +71,21 @@ func (h *ProcedureManualHandler) GetAllProcedureManualHandler(c *fiber.Ctx) erro
 	return c.Status(http.StatusOK).JSON(manuals)
 }
 
+// Search procedure manuals
+func (h *ProcedureManualHandler) SearchProcedureManualHandler(c *fiber.Ctx) error {
+	manuals, err := h.ProcedureManualSrv.SearchProcedureManualService(documentSearchRequest(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search procedure manuals",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(manuals)
+}
+
 // Update procedure manual
 func (h *ProcedureManualHandler) UpdateProcedureManualHandler(c *fiber.Ctx) error {
 	procedureManualIDStr := c.Params("procedure_manual_id")
@@ -84,7 +104,7 @@ func (h *ProcedureManualHandler) UpdateProcedureManualHandler(c *fiber.Ctx) erro
 		log.Println("[UpdateProcedureManualHandler] Processing file upload...")
 		relPath, _, err = uploader.UploadFromForm(c, "file", uploader.Options{
 			Dir:          "./uploads/procedure_manual",
-			AllowedMIMEs: []string{"application/pdf"},
+			AllowedMIMEs: searchableDocumentMIMEs,
 			MaxSize:      50 << 20,
 			BaseURL:      "",
 			Required:     false,
@@ -107,12 +127,27 @@ func (h *ProcedureManualHandler) UpdateProcedureManualHandler(c *fiber.Ctx) erro
 		req.FileName = fmt.Sprintf("[\"%s\"]", relPath)
 	}
 
+	revisionReq, err := parseDocumentRevisionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if relPath != "" {
+		if err := h.RevisionSrv.EnsureBaselineRevision(domains.DocModuleProcedureManual, procedureManualID); err != nil {
+			log.Printf("[UpdateProcedureManualHandler] Baseline revision error: %v\n", err)
+		}
+	}
+
 	log.Printf("[UpdateProcedureManualHandler] Calling UpdateProcedureManualService with ID: %d\n", procedureManualID)
 	if err := h.ProcedureManualSrv.UpdateProcedureManualService(procedureManualID, req); err != nil {
 		log.Printf("[UpdateProcedureManualHandler] Service error: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update procedure manual"})
 	}
 
+	recordDocumentRevision(h.RevisionSrv, domains.DocModuleProcedureManual, procedureManualID, req.FileName, revisionReq)
+	if req.FileName != "" {
+		indexDocumentText(h.TextSrv, domains.DocModuleProcedureManual, procedureManualID)
+	}
+
 	log.Println("[UpdateProcedureManualHandler] Update completed successfully")
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "Procedure manual updated successfully",
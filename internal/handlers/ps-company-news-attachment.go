@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/storage"
+	uploader "backend/internal/pkgs/utils"
+)
+
+// maxAttachmentsPerRequest limits how many files one add-attachments call may carry
+const maxAttachmentsPerRequest = 30
+
+var companyNewsImageMIMEs = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+
+var companyNewsDocumentMIMEs = []string{
+	"application/pdf",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.ms-powerpoint",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// AddCompanyNewsAttachmentsHandler accepts one or more "files" with optional "captions" in the same order.
+// Images join the gallery, other files become document attachments.
+func (h *CompanyNewsHandler) AddCompanyNewsAttachmentsHandler(c *fiber.Ctx) error {
+	companyNewsID := c.Params("company_news_id")
+	if companyNewsID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company news ID is required",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid multipart form"})
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "files is required"})
+	}
+	if len(files) > maxAttachmentsPerRequest {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too many files in one request"})
+	}
+	captions := form.Value["captions"]
+
+	uploads := make([]models.CompanyNewsAttachmentUpload, 0, len(files))
+	removeSaved := func() {
+		for _, u := range uploads {
+			_ = storage.Default().Delete(c.UserContext(), u.FilePath)
+		}
+	}
+
+	for i, fh := range files {
+		mimeType, err := uploader.DetectFormFileMIME(fh)
+		if err != nil {
+			removeSaved()
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		opt := uploader.Options{
+			Dir:          "./uploads/company_news",
+			AllowedMIMEs: companyNewsDocumentMIMEs,
+			MaxSize:      50 << 20,
+		}
+		if strings.HasPrefix(mimeType, "image/") {
+			opt.AllowedMIMEs = companyNewsImageMIMEs
+			opt.MaxSize = 10 << 20
+		}
+
+		relPath, _, err := uploader.SaveFormFile(c, fh, opt)
+		if err != nil {
+			log.Printf("[AddCompanyNewsAttachmentsHandler] Upload error for %s: %v\n", fh.Filename, err)
+			removeSaved()
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fh.Filename + ": " + err.Error()})
+		}
+
+		caption := ""
+		if i < len(captions) {
+			caption = captions[i]
+		}
+		uploads = append(uploads, models.CompanyNewsAttachmentUpload{
+			FilePath:     relPath,
+			OriginalName: fh.Filename,
+			MimeType:     mimeType,
+			FileSize:     fh.Size,
+			Caption:      caption,
+		})
+	}
+
+	attachments, err := h.CompanyNewsSrv.AddCompanyNewsAttachments(companyNewsID, uploader.AuthUsername(c), uploads)
+	if err != nil {
+		log.Printf("[AddCompanyNewsAttachmentsHandler] Service error: %v\n", err)
+		removeSaved()
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to add attachments"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Attachments added successfully",
+		"data":    attachments,
+	})
+}
+
+func (h *CompanyNewsHandler) GetCompanyNewsAttachmentsHandler(c *fiber.Ctx) error {
+	companyNewsID := c.Params("company_news_id")
+	if companyNewsID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company news ID is required",
+		})
+	}
+
+	attachments, err := h.CompanyNewsSrv.GetCompanyNewsAttachments(companyNewsID, uploader.AuthEmpCode(c), uploader.AuthRole(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve attachments"})
+	}
+
+	return c.JSON(fiber.Map{"data": attachments})
+}
+
+func (h *CompanyNewsHandler) UpdateCompanyNewsAttachmentHandler(c *fiber.Ctx) error {
+	attachmentID, err := c.ParamsInt("attachment_id")
+	if err != nil || attachmentID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid attachment ID"})
+	}
+
+	var req models.CompanyNewsAttachmentUpdateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.CompanyNewsSrv.UpdateCompanyNewsAttachment(attachmentID, req); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update attachment"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Attachment updated successfully"})
+}
+
+func (h *CompanyNewsHandler) ReorderCompanyNewsAttachmentsHandler(c *fiber.Ctx) error {
+	companyNewsID := c.Params("company_news_id")
+	if companyNewsID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company news ID is required",
+		})
+	}
+
+	var req models.CompanyNewsAttachmentReorderReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := h.CompanyNewsSrv.ReorderCompanyNewsAttachments(companyNewsID, req.AttachmentIDs); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reorder attachments"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Attachments reordered successfully"})
+}
+
+func (h *CompanyNewsHandler) DeleteCompanyNewsAttachmentHandler(c *fiber.Ctx) error {
+	attachmentID, err := c.ParamsInt("attachment_id")
+	if err != nil || attachmentID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid attachment ID"})
+	}
+
+	if err := h.CompanyNewsSrv.DeleteCompanyNewsAttachment(attachmentID); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete attachment"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Attachment deleted successfully"})
+}
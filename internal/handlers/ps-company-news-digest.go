@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"html"
+	"log"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+type CompanyNewsDigestHandler struct {
+	DigestSrv services.CompanyNewsDigestService
+}
+
+func NewCompanyNewsDigestHandler(insSrv services.CompanyNewsDigestService) *CompanyNewsDigestHandler {
+	return &CompanyNewsDigestHandler{DigestSrv: insSrv}
+}
+
+func (h *CompanyNewsDigestHandler) GetDigestSubscriptionHandler(c *fiber.Ctx) error {
+	sub, err := h.DigestSrv.GetDigestSubscription(utils.AuthEmpCode(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve digest subscription"})
+	}
+
+	return c.JSON(sub)
+}
+
+func (h *CompanyNewsDigestHandler) UpdateDigestSubscriptionHandler(c *fiber.Ctx) error {
+	var req models.CompanyNewsDigestSubscriptionReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	sub, err := h.DigestSrv.UpdateDigestSubscription(utils.AuthEmpCode(c), req)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update digest subscription"})
+	}
+
+	return c.JSON(sub)
+}
+
+// UnsubscribeDigestPageHandler is opened from the link in the email. It only asks for confirmation,
+// because mail scanners prefetch links; the button posts to UnsubscribeDigestHandler.
+func (h *CompanyNewsDigestHandler) UnsubscribeDigestPageHandler(c *fiber.Ctx) error {
+	if c.Query("token") == "" {
+		return digestPage(c, fiber.StatusBadRequest, digestInvalidLink)
+	}
+	action := html.EscapeString("unsubscribe?token=" + url.QueryEscape(c.Query("token")))
+	return digestPage(c, fiber.StatusOK, "ต้องการยกเลิกการรับสรุปข่าวสารบริษัทหรือไม่<br>Do you want to unsubscribe from the company news digest?"+
+		`<form method="POST" action="`+action+`" style="margin-top:24px;">`+
+		`<button type="submit" style="padding:8px 24px;font-size:16px;">ยกเลิกการรับข่าว / Unsubscribe</button></form>`)
+}
+
+// UnsubscribeDigestHandler unsubscribes ?token=; it serves the confirmation form and the RFC 8058
+// one-click POST mail clients send for the List-Unsubscribe header
+func (h *CompanyNewsDigestHandler) UnsubscribeDigestHandler(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		token = c.FormValue("token")
+	}
+
+	status, message := fiber.StatusOK, "ยกเลิกการรับสรุปข่าวสารบริษัทเรียบร้อยแล้ว<br>You have been unsubscribed from the company news digest."
+	if err := h.DigestSrv.UnsubscribeDigest(token); err != nil {
+		log.Printf("[UnsubscribeDigest] %v\n", err)
+		status, message = fiber.StatusInternalServerError, "เกิดข้อผิดพลาด กรุณาลองใหม่อีกครั้ง<br>Something went wrong, please try again."
+		if appErr, ok := err.(errs.AppError); ok {
+			status = appErr.Code
+			switch appErr.Code {
+			case fiber.StatusNotFound:
+				message = "ไม่พบการสมัครรับข่าว หรือยกเลิกไปแล้ว<br>Subscription not found or already removed."
+			default:
+				message = digestInvalidLink
+			}
+		}
+	}
+	return digestPage(c, status, message)
+}
+
+const digestInvalidLink = "ลิงก์ยกเลิกการรับข่าวไม่ถูกต้อง<br>This unsubscribe link is invalid."
+
+func digestPage(c *fiber.Ctx, status int, body string) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).SendString(`<!DOCTYPE html><html><head><meta charset="UTF-8"><title>PSTH Info Portal</title></head>` +
+		`<body style="font-family:Tahoma,Arial,sans-serif;text-align:center;padding:48px;">` + body + `</body></html>`)
+}
+
+// PreviewDigestHandler renders the digest: ?lang=th|en&days=7&emp_code=&format=html|text
+func (h *CompanyNewsDigestHandler) PreviewDigestHandler(c *fiber.Ctx) error {
+	rendered, err := h.DigestSrv.PreviewDigest(c.Query("emp_code"), c.Query("lang"), c.QueryInt("days", 7), utils.PublicBaseURL(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to render digest preview"})
+	}
+
+	c.Set("X-Digest-Subject", html.EscapeString(rendered.Subject))
+	if c.Query("format") == "text" {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(rendered.Text)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(rendered.HTML)
+}
+
+// SendDigestNowHandler queues the digest of the last ?days=7 for every subscriber
+func (h *CompanyNewsDigestHandler) SendDigestNowHandler(c *fiber.Ctx) error {
+	result, err := h.DigestSrv.SendDigestNow(c.QueryInt("days", 7), utils.PublicBaseURL(c), utils.AuthUsername(c))
+	if err != nil {
+		log.Printf("[SendDigestNow] %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send digest"})
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/pkgs/errs"
+)
+
+func (h *CompanyNewsHandler) GetCompanyNewsRevisionsHandler(c *fiber.Ctx) error {
+	revisions, err := h.CompanyNewsSrv.GetCompanyNewsRevisions(c.Params("company_news_id"))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve revisions"})
+	}
+
+	return c.JSON(fiber.Map{"data": revisions})
+}
+
+func (h *CompanyNewsHandler) GetCompanyNewsRevisionHandler(c *fiber.Ctx) error {
+	revisionNo, err := c.ParamsInt("revision_no")
+	if err != nil || revisionNo <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision number"})
+	}
+
+	revision, err := h.CompanyNewsSrv.GetCompanyNewsRevision(c.Params("company_news_id"), revisionNo)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve revision"})
+	}
+
+	return c.JSON(revision)
+}
+
+// DiffCompanyNewsRevisionsHandler compares ?from=<revision_no>&to=<revision_no>
+func (h *CompanyNewsHandler) DiffCompanyNewsRevisionsHandler(c *fiber.Ctx) error {
+	from, to := c.QueryInt("from", 0), c.QueryInt("to", 0)
+	if from <= 0 || to <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to revision numbers are required"})
+	}
+
+	diff, err := h.CompanyNewsSrv.DiffCompanyNewsRevisions(c.Params("company_news_id"), from, to)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to compare revisions"})
+	}
+
+	return c.JSON(diff)
+}
+
+func (h *CompanyNewsHandler) RestoreCompanyNewsRevisionHandler(c *fiber.Ctx) error {
+	revisionNo, err := c.ParamsInt("revision_no")
+	if err != nil || revisionNo <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid revision number"})
+	}
+
+	if err := h.CompanyNewsSrv.RestoreCompanyNewsRevision(c.Params("company_news_id"), revisionNo, newsActor(c)); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore revision"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Company news restored successfully"})
+}
+
+func (h *CompanyNewsHandler) UndeleteCompanyNewsHandler(c *fiber.Ctx) error {
+	if err := h.CompanyNewsSrv.UndeleteCompanyNews(c.Params("company_news_id"), newsActor(c)); err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to undelete company news"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Company news undeleted successfully"})
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
 	"log"
+	"net/http"
 
 	"github.com/gofiber/fiber/v2"
 
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/feeds"
 	uploader "backend/internal/pkgs/utils"
 )
 
@@ -21,7 +25,7 @@ func NewCompanyNewsHandler(insSrv services.CompanyNewsService) *CompanyNewsHandl
 func (h *CompanyNewsHandler) GetCompanyNewsHandler(c *fiber.Ctx) error {
 	limit, offset := c.QueryInt("limit", 10), c.QueryInt("offset", 0)
 
-	jobs, err := h.CompanyNewsSrv.GetCompanyNews(limit, offset)
+	jobs, err := h.CompanyNewsSrv.GetCompanyNews(uploader.AuthEmpCode(c), uploader.AuthRole(c), limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve company news",
@@ -32,6 +36,11 @@ func (h *CompanyNewsHandler) GetCompanyNewsHandler(c *fiber.Ctx) error {
 }
 
 func (h *CompanyNewsHandler) CreateCompanyNewsFormHandler(c *fiber.Ctx) error {
+	audiences, err := parseAudiencesForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid audiences format"})
+	}
+
 	relPath, publicURL, err := uploader.UploadFromForm(c, "image", uploader.Options{
 		Dir:          "./uploads/company_news",
 		AllowedMIMEs: []string{"image/jpeg", "image/png", "image/webp"},
@@ -49,10 +58,14 @@ func (h *CompanyNewsHandler) CreateCompanyNewsFormHandler(c *fiber.Ctx) error {
 		Category:         c.FormValue("category"),
 		CompanyNewsPhoto: relPath,
 		UsernameCreator:  c.FormValue("username_creator"),
+		Audiences:        audiences,
 	}
 
-	if err := h.CompanyNewsSrv.CreateCompanyNewsService(req); err != nil {
+	if err := h.CompanyNewsSrv.CreateCompanyNewsService(req, newsActor(c)); err != nil {
 		log.Println("Error creating company news:", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create company news"})
 	}
 
@@ -60,6 +73,7 @@ func (h *CompanyNewsHandler) CreateCompanyNewsFormHandler(c *fiber.Ctx) error {
 		"message": "Company news created successfully",
 		"file":    relPath,
 		"url":     publicURL,
+		"sha256":  uploader.StoredSHA256(c.UserContext(), relPath),
 	})
 }
 
@@ -87,8 +101,9 @@ func (h *CompanyNewsHandler) UploadImageHandler(c *fiber.Ctx) error {
 	log.Printf("[UploadImageHandler] Upload successful - Path: %s, URL: %s\n", relPath, publicURL)
 	return c.JSON(fiber.Map{
 		"result": fiber.Map{
-			"url":  publicURL,
-			"path": relPath,
+			"url":    publicURL,
+			"path":   relPath,
+			"sha256": uploader.StoredSHA256(c.UserContext(), relPath),
 		},
 	})
 }
@@ -96,8 +111,11 @@ func (h *CompanyNewsHandler) UploadImageHandler(c *fiber.Ctx) error {
 func (h *CompanyNewsHandler) GetCompanyNewsByTitleHandler(c *fiber.Ctx) error {
 	title := c.Query("title")
 
-	job, err := h.CompanyNewsSrv.GetCompanyNewsByTitle(title)
+	job, err := h.CompanyNewsSrv.GetCompanyNewsByTitle(title, uploader.AuthEmpCode(c), uploader.AuthRole(c))
 	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve company news",
 		})
@@ -116,9 +134,12 @@ func (h *CompanyNewsHandler) GetCompanyNewsByIDHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	job, err := h.CompanyNewsSrv.GetCompanyNewsByID(companyNewsID)
+	job, err := h.CompanyNewsSrv.GetCompanyNewsByID(companyNewsID, uploader.AuthEmpCode(c), uploader.AuthRole(c))
 	if err != nil {
 		log.Printf("[GetCompanyNewsByIDHandler] Error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve company news",
 		})
@@ -149,6 +170,12 @@ func (h *CompanyNewsHandler) UpdateCompanyNewsFormHandler(c *fiber.Ctx) error {
 
 	log.Printf("[UpdateCompanyNewsFormHandler] Received ID: %s (length: %d)\n", companyNewsID, len(companyNewsID))
 
+	audiences, err := parseAudiencesForm(c)
+	if err != nil {
+		log.Printf("[UpdateCompanyNewsFormHandler] Invalid audiences: %v\n", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid audiences format"})
+	}
+
 	// Handle optional image upload
 	var relPath string
 	if c.Request().Header.Peek("Content-Type") != nil {
@@ -181,11 +208,15 @@ func (h *CompanyNewsHandler) UpdateCompanyNewsFormHandler(c *fiber.Ctx) error {
 		Category:         category,
 		CompanyNewsPhoto: relPath,
 		UsernameCreator:  usernameCreator,
+		Audiences:        audiences,
 	}
 
 	log.Printf("[UpdateCompanyNewsFormHandler] Calling UpdateCompanyNewsService with ID: %s\n", companyNewsID)
-	if err := h.CompanyNewsSrv.UpdateCompanyNewsService(companyNewsID, req); err != nil {
+	if err := h.CompanyNewsSrv.UpdateCompanyNewsService(companyNewsID, req, newsActor(c)); err != nil {
 		log.Printf("[UpdateCompanyNewsFormHandler] Service error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update company news"})
 	}
 
@@ -215,8 +246,11 @@ func (h *CompanyNewsHandler) DeleteCompanyNewsHandler(c *fiber.Ctx) error {
 	}
 
 	log.Printf("[DeleteCompanyNewsHandler] Calling DeleteCompanyNewsService with ID: %s\n", companyNewsID)
-	if err := h.CompanyNewsSrv.DeleteCompanyNewsService(companyNewsID); err != nil {
+	if err := h.CompanyNewsSrv.DeleteCompanyNewsService(companyNewsID, newsActor(c)); err != nil {
 		log.Printf("[DeleteCompanyNewsHandler] Service error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete company news"})
 	}
 
@@ -225,3 +259,145 @@ func (h *CompanyNewsHandler) DeleteCompanyNewsHandler(c *fiber.Ctx) error {
 		"message": "Company news deleted successfully",
 	})
 }
+
+// Search company news by keyword with category, creator and date-range filters
+func (h *CompanyNewsHandler) SearchCompanyNewsHandler(c *fiber.Ctx) error {
+	req := models.CompanyNewsSearchReq{
+		Keyword:  c.Query("keyword"),
+		Category: c.Query("category"),
+		Creator:  c.Query("username_creator"),
+		DateFrom: c.Query("date_from"),
+		DateTo:   c.Query("date_to"),
+		EmpCode:  uploader.AuthEmpCode(c),
+		Role:     uploader.AuthRole(c),
+		Limit:    c.QueryInt("limit", 10),
+		Offset:   c.QueryInt("offset", 0),
+	}
+
+	result, err := h.CompanyNewsSrv.SearchCompanyNews(req)
+	if err != nil {
+		log.Printf("[SearchCompanyNewsHandler] Error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search company news",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+func (h *CompanyNewsHandler) GetCompanyNewsRSSHandler(c *fiber.Ctx) error {
+	return h.serveCompanyNewsFeed(c, "rss")
+}
+
+func (h *CompanyNewsHandler) GetCompanyNewsAtomHandler(c *fiber.Ctx) error {
+	return h.serveCompanyNewsFeed(c, "atom")
+}
+
+func (h *CompanyNewsHandler) GetCompanyNewsJSONFeedHandler(c *fiber.Ctx) error {
+	return h.serveCompanyNewsFeed(c, "json")
+}
+
+// serveCompanyNewsFeed renders the feed in the given format and answers 304 when the poller's copy is current
+func (h *CompanyNewsHandler) serveCompanyNewsFeed(c *fiber.Ctx, format string) error {
+	baseURL := uploader.PublicBaseURL(c)
+
+	feed, err := h.CompanyNewsSrv.GetCompanyNewsFeed(c.Query("category"), baseURL)
+	if err != nil {
+		log.Printf("[serveCompanyNewsFeed] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve company news feed",
+		})
+	}
+	feed.FeedURL = baseURL + c.OriginalURL()
+
+	c.Set(fiber.HeaderETag, feed.ETag(format))
+	if !feed.Updated.IsZero() {
+		c.Set(fiber.HeaderLastModified, feed.Updated.UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	if uploader.NotModified(c, feed.ETag(format), feed.Updated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	var body []byte
+	switch format {
+	case "atom":
+		c.Set(fiber.HeaderContentType, feeds.AtomContentType)
+		body, err = feed.Atom()
+	case "json":
+		c.Set(fiber.HeaderContentType, feeds.JSONContentType)
+		body, err = feed.JSONFeed()
+	default:
+		c.Set(fiber.HeaderContentType, feeds.RSSContentType)
+		body, err = feed.RSS()
+	}
+	if err != nil {
+		log.Printf("[serveCompanyNewsFeed] Render error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render company news feed",
+		})
+	}
+
+	return c.Send(body)
+}
+
+func (h *CompanyNewsHandler) PreviewCompanyNewsAudienceHandler(c *fiber.Ctx) error {
+	var req models.CompanyNewsAudiencePreviewReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	preview, err := h.CompanyNewsSrv.PreviewCompanyNewsAudience(req.Audiences)
+	if err != nil {
+		log.Printf("[PreviewCompanyNewsAudienceHandler] Error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to preview audience"})
+	}
+
+	return c.JSON(preview)
+}
+
+func (h *CompanyNewsHandler) PreviewCompanyNewsAudienceByIDHandler(c *fiber.Ctx) error {
+	companyNewsID := c.Params("company_news_id")
+	if companyNewsID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Company news ID is required",
+		})
+	}
+
+	preview, err := h.CompanyNewsSrv.PreviewCompanyNewsAudienceByID(companyNewsID)
+	if err != nil {
+		log.Printf("[PreviewCompanyNewsAudienceByIDHandler] Error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to preview audience"})
+	}
+
+	return c.JSON(preview)
+}
+
+// newsActor names who made a change for the revision history; it comes from the token only
+func newsActor(c *fiber.Ctx) string {
+	return uploader.AuthUsername(c)
+}
+
+// parseAudiencesForm reads the optional "audiences" form field, a JSON array of
+// {"target_type","target_value"}. A missing field returns nil; "[]" means company-wide.
+func parseAudiencesForm(c *fiber.Ctx) (*[]models.CompanyNewsAudienceReq, error) {
+	raw := c.FormValue("audiences")
+	if raw == "" {
+		return nil, nil
+	}
+
+	audiences := []models.CompanyNewsAudienceReq{}
+	if err := json.Unmarshal([]byte(raw), &audiences); err != nil {
+		return nil, err
+	}
+	return &audiences, nil
+}
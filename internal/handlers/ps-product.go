@@ -7,7 +7,6 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,6 +15,8 @@ import (
 
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/thumbnail"
+	"backend/internal/pkgs/utils"
 )
 
 type ProductHandler struct {
@@ -92,8 +93,6 @@ func (h *ProductHandler) CreateProductFormHandler(c *fiber.Ctx) error {
 
 		file.Seek(0, 0)
 
-		os.MkdirAll(uploadDir, 0755)
-
 		randomStr := make([]byte, 6)
 		rand.Read(randomStr)
 		randomHex := hex.EncodeToString(randomStr)
@@ -101,20 +100,13 @@ func (h *ProductHandler) CreateProductFormHandler(c *fiber.Ctx) error {
 		filename := time.Now().Format("20060102_150405") + "_" + randomHex + ext
 
 		filePath := filepath.Join(uploadDir, filename)
-		dst, err := os.Create(filePath)
-		if err != nil {
+		if err := utils.StoreUpload(c, filePath, mainImageHeader, detected); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to save main image",
 			})
 		}
-		defer dst.Close()
-
-		if _, err := io.Copy(dst, file); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to write main image",
-			})
-		}
 
+		generateThumbnails(filePath)
 		mainImage = filePath
 	}
 
@@ -163,8 +155,6 @@ func (h *ProductHandler) CreateProductFormHandler(c *fiber.Ctx) error {
 		}
 		file.Seek(0, 0)
 
-		os.MkdirAll(uploadDir, 0755)
-
 		randomStr := make([]byte, 6)
 		rand.Read(randomStr)
 		randomHex := hex.EncodeToString(randomStr)
@@ -172,20 +162,13 @@ func (h *ProductHandler) CreateProductFormHandler(c *fiber.Ctx) error {
 		filename := time.Now().Format("20060102_150405") + "_" + randomHex + ext
 
 		filePath := filepath.Join(uploadDir, filename)
-		dst, err := os.Create(filePath)
-		if err != nil {
+		if err := utils.StoreUpload(c, filePath, fileHeader, detected); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to save file",
 			})
 		}
-		defer dst.Close()
-
-		if _, err := io.Copy(dst, file); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to write file",
-			})
-		}
 
+		generateThumbnails(filePath)
 		imagePaths = append(imagePaths, filePath)
 	}
 
@@ -326,8 +309,6 @@ func (h *ProductHandler) UpdateProductHandler(c *fiber.Ctx) error {
 
 		file.Seek(0, 0)
 
-		os.MkdirAll(uploadDir, 0755)
-
 		randomStr := make([]byte, 6)
 		rand.Read(randomStr)
 		randomHex := hex.EncodeToString(randomStr)
@@ -335,20 +316,13 @@ func (h *ProductHandler) UpdateProductHandler(c *fiber.Ctx) error {
 		filename := time.Now().Format("20060102_150405") + "_" + randomHex + ext
 
 		filePath := filepath.Join(uploadDir, filename)
-		dst, err := os.Create(filePath)
-		if err != nil {
+		if err := utils.StoreUpload(c, filePath, mainImageHeader, detected); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to save main image",
 			})
 		}
-		defer dst.Close()
-
-		if _, err := io.Copy(dst, file); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to write main image",
-			})
-		}
 
+		generateThumbnails(filePath)
 		mainImage = filePath
 	}
 
@@ -393,8 +367,6 @@ func (h *ProductHandler) UpdateProductHandler(c *fiber.Ctx) error {
 			}
 			file.Seek(0, 0)
 
-			os.MkdirAll(uploadDir, 0755)
-
 			randomStr := make([]byte, 6)
 			rand.Read(randomStr)
 			randomHex := hex.EncodeToString(randomStr)
@@ -402,20 +374,13 @@ func (h *ProductHandler) UpdateProductHandler(c *fiber.Ctx) error {
 			filename := time.Now().Format("20060102_150405") + "_" + randomHex + ext
 
 			filePath := filepath.Join(uploadDir, filename)
-			dst, err := os.Create(filePath)
-			if err != nil {
+			if err := utils.StoreUpload(c, filePath, fileHeader, detected); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"error": "Failed to save file",
 				})
 			}
-			defer dst.Close()
-
-			if _, err := io.Copy(dst, file); err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Failed to write file",
-				})
-			}
 
+			generateThumbnails(filePath)
 			imagePaths = append(imagePaths, filePath)
 		}
 
@@ -602,3 +567,10 @@ func (h *ProductHandler) SearchProductsByNameHandler(c *fiber.Ctx) error {
 		"data":    products,
 	})
 }
+
+// generateThumbnails renders the list previews of a saved product image; failures only cost the preview
+func generateThumbnails(filePath string) {
+	if err := thumbnail.Generate(filePath); err != nil {
+		log.Printf("[ProductHandler] Thumbnail error for %s: %v\n", filePath, err)
+	}
+}
@@ -1,44 +1,246 @@
 package handlers
 
 import (
-	"fmt"
+	"bufio"
+	"context"
 	"log"
-	"os"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+
+	"backend/internal/core/domains"
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
 )
 
-func ServeUploadFile(c *fiber.Ctx) error {
+type FileHandler struct {
+	FileSrv     services.FileService
+	AccessSrv   services.DocumentAccessService
+	UploadGCSrv services.UploadGCService
+}
+
+func NewFileHandler(fileSrv services.FileService, accessSrv services.DocumentAccessService, uploadGCSrv services.UploadGCService) *FileHandler {
+	return &FileHandler{FileSrv: fileSrv, AccessSrv: accessSrv, UploadGCSrv: uploadGCSrv}
+}
+
+// Serve a file from an allow-listed uploads folder (?folder=&filename=)
+func (h *FileHandler) ServeUploadFile(c *fiber.Ctx) error {
+	return h.serveFile(c, domains.FileRef{
+		Source:   domains.FileSourceFolder,
+		Folder:   c.Query("folder"),
+		FileName: c.Query("filename"),
+	})
+}
+
+// Serve the current file of a document module record
+func (h *FileHandler) ServeDocumentFileHandler(c *fiber.Ctx) error {
+	documentID, _ := c.ParamsInt("document_id")
+	return h.serveFile(c, domains.FileRef{
+		Source:     domains.FileSourceDocument,
+		Module:     c.Params("module"),
+		DocumentID: documentID,
+	})
+}
+
+// Serve a company news attachment
+func (h *FileHandler) ServeNewsAttachmentHandler(c *fiber.Ctx) error {
+	attachmentID, _ := c.ParamsInt("attachment_id")
+	return h.serveFile(c, domains.FileRef{
+		Source:       domains.FileSourceNewsAttachment,
+		AttachmentID: attachmentID,
+	})
+}
+
+// Issue a short-lived signed URL for embedding a file the caller may read
+func (h *FileHandler) SignFileURLHandler(c *fiber.Ctx) error {
+	var req models.SignFileURLReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	ref := domains.FileRef{Source: domains.FileSourceFolder, Folder: req.Folder, FileName: req.FileName}
+	switch {
+	case req.Module != "" && req.DocumentID > 0:
+		ref = domains.FileRef{Source: domains.FileSourceDocument, Module: req.Module, DocumentID: req.DocumentID}
+	case req.AttachmentID > 0:
+		ref = domains.FileRef{Source: domains.FileSourceNewsAttachment, AttachmentID: req.AttachmentID}
+	case req.Folder == "" || req.FileName == "":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "module and document_id, attachment_id, or folder and filename are required"})
+	}
+
+	signed, err := h.FileSrv.SignFileURL(ref, fileViewer(c), utils.PublicBaseURL(c), time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": "File not found"})
+		}
+		log.Printf("[SignFileURLHandler] Error resolving %s: %v\n", ref.Key(), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to sign file URL"})
+	}
 
-	folder := c.Query("folder")
-	filename := c.Query("filename")
+	return c.JSON(fiber.Map{"data": signed})
+}
 
-	if folder == "" || filename == "" {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid path")
+// RebuildThumbnailsHandler generates the missing previews of every upload
+func (h *FileHandler) RebuildThumbnailsHandler(c *fiber.Ctx) error {
+	generated, err := h.FileSrv.RebuildThumbnails()
+	if err != nil {
+		log.Printf("[RebuildThumbnails] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to rebuild thumbnails"})
 	}
-	filePath := filepath.Join("uploads", folder, filename)
 
-	log.Printf("[ServeUploadFile] Attempting to serve file: %s\n", filePath)
+	log.Printf("[RebuildThumbnails] %d file(s) processed by %s\n", generated, utils.AuthUsername(c))
+	return c.JSON(fiber.Map{"message": "Thumbnails rebuilt", "generated": generated})
+}
 
-	fileInfo, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		log.Printf("[ServeUploadFile] File not found: %s\n", filePath)
-		dirPath := filepath.Join("uploads", folder)
-		files, _ := os.ReadDir(dirPath)
-		log.Printf("[ServeUploadFile] Files in %s:\n", dirPath)
-		for _, f := range files {
-			log.Printf("  - %s\n", f.Name())
+// CheckFileIntegrityHandler re-hashes the stored files and reports missing or damaged ones
+func (h *FileHandler) CheckFileIntegrityHandler(c *fiber.Ctx) error {
+	report, err := h.FileSrv.CheckFileIntegrity()
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
 		}
-		return c.Status(fiber.StatusNotFound).SendString(fmt.Sprintf("File not found: %s", filePath))
+		log.Printf("[CheckFileIntegrity] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check file integrity"})
 	}
 
+	log.Printf("[CheckFileIntegrity] %d blob(s) checked by %s: %d missing, %d mismatched, %d unreadable\n",
+		report.CheckedBlobs, utils.AuthUsername(c), report.MissingBlobs, report.MismatchedBlobs, report.UnreadableBlobs)
+	return c.JSON(fiber.Map{"data": report})
+}
+
+// CollectOrphanUploadsHandler runs the upload garbage collector; it only reports unless ?dry_run=false
+func (h *FileHandler) CollectOrphanUploadsHandler(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", true)
+	report, err := h.UploadGCSrv.CollectOrphanUploads(c.UserContext(), dryRun)
 	if err != nil {
-		log.Printf("[ServeUploadFile] Error checking file: %v\n", err)
-		return c.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Error accessing file: %v", err))
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		log.Printf("[CollectOrphanUploads] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to collect orphaned uploads"})
 	}
 
-	log.Printf("[ServeUploadFile] File found, size: %d bytes\n", fileInfo.Size())
+	log.Printf("[CollectOrphanUploads] Run by %s: %d file(s) deleted, %d bytes (dry run: %t)\n",
+		utils.AuthUsername(c), report.DeletedFiles, report.DeletedBytes, dryRun)
+	return c.JSON(fiber.Map{"data": report})
+}
+
+// ExportDocumentsHandler streams a ZIP of a module's current documents with a manifest,
+// e.g. /export/safety_documents?department=Production; ?all=true also exports the non-current ones
+func (h *FileHandler) ExportDocumentsHandler(c *fiber.Ctx) error {
+	req := models.DocumentExportRequest{
+		Category:   c.Query("category"),
+		Department: c.Query("department"),
+		All:        c.QueryBool("all"),
+	}
+	export, err := h.FileSrv.ExportDocuments(c.Params("module"), req, fileViewer(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		log.Printf("[ExportDocuments] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to export documents"})
+	}
 
-	return c.SendFile(filePath)
+	log.Printf("[ExportDocuments] %s: %d document(s) exported by %s\n", export.FileName, len(export.Entries), utils.AuthUsername(c))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, utils.ContentDisposition(export.FileName, true))
+	// the writer runs after the handler returns, so it must not touch c; files are read and sent one at a time
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.FileSrv.WriteDocumentExport(context.Background(), w, export); err != nil {
+			log.Printf("[ExportDocuments] Error streaming %s: %v\n", export.FileName, err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("[ExportDocuments] Error streaming %s: %v\n", export.FileName, err)
+		}
+	})
+	return nil
+}
+
+// GetUploadedFilesHandler lists the upload registry for administrators, e.g. ?scan_status=infected
+func (h *FileHandler) GetUploadedFilesHandler(c *fiber.Ctx) error {
+	resp, err := h.FileSrv.GetUploadedFiles(c.Query("scan_status"), c.QueryInt("page", 1), c.QueryInt("page_size", 20))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		log.Printf("[GetUploadedFiles] Error: %v\n", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to get uploaded files"})
+	}
+	return c.JSON(resp)
+}
+
+// serveFile answers every failure with the same 404 so callers cannot probe for files or permissions
+func (h *FileHandler) serveFile(c *fiber.Ctx, ref domains.FileRef) error {
+	viewer := fileViewer(c)
+	if signature := c.Query("signature"); signature != "" {
+		expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+		if !utils.VerifyFileSignature(ref.Key(), expires, signature) {
+			return fileNotFound(c)
+		}
+		viewer.Signed = true
+	}
+
+	file, err := h.FileSrv.ResolveFile(ref, viewer)
+	if err != nil {
+		if _, ok := err.(errs.AppError); !ok {
+			log.Printf("[ServeFile] Error resolving %s: %v\n", ref.Key(), err)
+		}
+		return fileNotFound(c)
+	}
+
+	if err := utils.ServeFile(c, file.Path, file.Name, c.QueryBool("download")); err != nil {
+		log.Printf("[ServeFile] Error sending %s: %v\n", ref.Key(), err)
+		return fileNotFound(c)
+	}
+	h.logFileAccess(c, file)
+	return nil
+}
+
+func fileViewer(c *fiber.Ctx) domains.FileViewer {
+	return domains.FileViewer{EmpCode: utils.AuthEmpCode(c), Role: utils.AuthRole(c)}
+}
+
+func fileNotFound(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+}
+
+// logFileAccess records the view or download in the background so a slow insert never delays the file
+func (h *FileHandler) logFileAccess(c *fiber.Ctx, file domains.ServedFile) {
+	if file.Preview {
+		return
+	}
+	action := domains.DocAccessView
+	if c.QueryBool("download") {
+		action = domains.DocAccessDownload
+	}
+	logDocumentAccess(c, h.AccessSrv, file.Module, file.DocumentID, action)
+}
+
+// logDocumentAccess records a served document file in the background. c.IP() is the client address
+// nginx forwards, see the proxy settings in main.go.
+func logDocumentAccess(c *fiber.Ctx, accessSrv services.DocumentAccessService, module string, documentID int, action string) {
+	if accessSrv == nil || documentID == 0 {
+		return
+	}
+	// PDF viewers fetch a document in many ranges; count only the request for its first bytes
+	if status := c.Response().StatusCode(); status == fiber.StatusPartialContent && !strings.HasPrefix(c.Get(fiber.HeaderRange), "bytes=0-") ||
+		status == fiber.StatusRequestedRangeNotSatisfiable {
+		return
+	}
+
+	// fiber reuses the context after the handler returns, so copy everything the goroutine needs
+	empCode, ip := fiberutils.CopyString(utils.AuthEmpCode(c)), fiberutils.CopyString(c.IP())
+
+	go func() {
+		if err := accessSrv.LogDocumentAccess(module, documentID, action, empCode, ip); err != nil {
+			log.Printf("[ServeFile] Access log error for %s #%d: %v\n", module, documentID, err)
+		}
+	}()
 }
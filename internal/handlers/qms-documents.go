@@ -8,23 +8,27 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 	uploader "backend/internal/pkgs/utils"
 )
 
 type QmsDocumentsHandler struct {
 	QmsDocumentsSrv services.QmsDocumentsService
+	RevisionSrv     services.DocumentRevisionService
+	TextSrv         services.DocumentTextService
 }
 
-func NewQmsDocumentsHandler(insSrv services.QmsDocumentsService) *QmsDocumentsHandler {
-	return &QmsDocumentsHandler{QmsDocumentsSrv: insSrv}
+func NewQmsDocumentsHandler(insSrv services.QmsDocumentsService, revisionSrv services.DocumentRevisionService, textSrv services.DocumentTextService) *QmsDocumentsHandler {
+	return &QmsDocumentsHandler{QmsDocumentsSrv: insSrv, RevisionSrv: revisionSrv, TextSrv: textSrv}
 }
 
 func (h *QmsDocumentsHandler) CreateQmsDocumentHandler(c *fiber.Ctx) error {
 	relPath, _, err := uploader.UploadFromForm(c, "file", uploader.Options{
 		Dir:          "./uploads/qms_documents",
-		AllowedMIMEs: []string{"application/pdf"},
+		AllowedMIMEs: searchableDocumentMIMEs,
 		MaxSize:      50 << 20,
 		BaseURL:      "",
 		Required:     true,
@@ -38,13 +42,25 @@ func (h *QmsDocumentsHandler) CreateQmsDocumentHandler(c *fiber.Ctx) error {
 		DQmsDocumentsDesc: c.FormValue("dqms_documents_desc"),
 		Category:          c.FormValue("category"),
 		FileName:          fmt.Sprintf("[\"%s\"]", relPath),
+		DocumentNo:        c.FormValue("document_no"),
+		Revision:          c.FormValue("revision"),
+		OwnerEmpCode:      c.FormValue("owner_emp_code"),
+		ReviewerEmpCode:   c.FormValue("reviewer_emp_code"),
+		ApproverEmpCode:   c.FormValue("approver_emp_code"),
+		ReviewDueDate:     c.FormValue("review_due_date"),
+		EmpCode:           uploader.AuthEmpCode(c),
+		Role:              uploader.AuthRole(c),
 	}
 
 	if err := h.QmsDocumentsSrv.CreateQmsDocumentService(req); err != nil {
 		log.Println("Error creating qms document:", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create qms document"})
 	}
 
+	indexUploadedText(h.TextSrv, domains.DocModuleQms, req.FileName)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "QMS document created successfully", "file": relPath})
 }
 
@@ -52,7 +68,7 @@ func (h *QmsDocumentsHandler) GetAllQmsDocumentsHandler(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 50)
 	offset := c.QueryInt("offset", 0)
 
-	docs, err := h.QmsDocumentsSrv.GetAllQmsDocumentService(limit, offset)
+	docs, err := h.QmsDocumentsSrv.GetAllQmsDocumentService(limit, offset, uploader.AuthRole(c), c.Query("status"))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch qms documents"})
 	}
@@ -60,6 +76,21 @@ func (h *QmsDocumentsHandler) GetAllQmsDocumentsHandler(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(docs)
 }
 
+// Search QMS documents; non-admins only find effective documents
+func (h *QmsDocumentsHandler) SearchQmsDocumentsHandler(c *fiber.Ctx) error {
+	docs, err := h.QmsDocumentsSrv.SearchQmsDocumentService(documentSearchRequest(c), uploader.AuthRole(c), c.Query("status"))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search qms documents",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(docs)
+}
+
 func (h *QmsDocumentsHandler) UpdateQmsDocumentHandler(c *fiber.Ctx) error {
 	idStr := c.Params("qms_documents_id")
 	id, err := strconv.Atoi(idStr)
@@ -71,7 +102,7 @@ func (h *QmsDocumentsHandler) UpdateQmsDocumentHandler(c *fiber.Ctx) error {
 	if c.Request().Header.Peek("Content-Type") != nil {
 		relPath, _, err = uploader.UploadFromForm(c, "file", uploader.Options{
 			Dir:          "./uploads/qms_documents",
-			AllowedMIMEs: []string{"application/pdf"},
+			AllowedMIMEs: searchableDocumentMIMEs,
 			MaxSize:      50 << 20,
 			BaseURL:      "",
 			Required:     false,
@@ -85,16 +116,42 @@ func (h *QmsDocumentsHandler) UpdateQmsDocumentHandler(c *fiber.Ctx) error {
 		QmsDocumentsName:  c.FormValue("qms_documents_name"),
 		DQmsDocumentsDesc: c.FormValue("dqms_documents_desc"),
 		Category:          c.FormValue("category"),
+		DocumentNo:        c.FormValue("document_no"),
+		Revision:          c.FormValue("revision"),
+		OwnerEmpCode:      c.FormValue("owner_emp_code"),
+		ReviewerEmpCode:   c.FormValue("reviewer_emp_code"),
+		ApproverEmpCode:   c.FormValue("approver_emp_code"),
+		ReviewDueDate:     c.FormValue("review_due_date"),
+		EmpCode:           uploader.AuthEmpCode(c),
+		Role:              uploader.AuthRole(c),
 	}
 	if relPath != "" {
 		req.FileName = fmt.Sprintf("[\"%s\"]", relPath)
 	}
 
+	revisionReq, err := parseDocumentRevisionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if relPath != "" {
+		if err := h.RevisionSrv.EnsureBaselineRevision(domains.DocModuleQms, id); err != nil {
+			log.Printf("[UpdateQmsDocumentHandler] Baseline revision error: %v\n", err)
+		}
+	}
+
 	if err := h.QmsDocumentsSrv.UpdateQmsDocumentService(id, req); err != nil {
 		log.Printf("Error updating qms document: %v", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update qms document"})
 	}
 
+	recordDocumentRevision(h.RevisionSrv, domains.DocModuleQms, id, req.FileName, revisionReq)
+	if req.FileName != "" {
+		indexDocumentText(h.TextSrv, domains.DocModuleQms, id)
+	}
+
 	return c.Status(http.StatusOK).JSON(fiber.Map{"message": "QMS document updated successfully"})
 }
 
@@ -112,3 +169,73 @@ func (h *QmsDocumentsHandler) DeleteQmsDocumentHandler(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{"message": "QMS document deleted successfully"})
 }
+
+// ====================== Document control ===================================
+
+// WorkflowQmsDocumentHandler runs submit, review, approve, reject, release or obsolete on a document
+func (h *QmsDocumentsHandler) WorkflowQmsDocumentHandler(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("qms_documents_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid qms document ID"})
+	}
+
+	var req models.QmsDocumentWorkflowRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+
+	action := c.Params("action")
+	doc, err := h.QmsDocumentsSrv.TransitionQmsDocumentService(id, action, uploader.AuthEmpCode(c), uploader.AuthRole(c), req)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		log.Printf("[WorkflowQmsDocumentHandler] %s #%d: %v\n", action, id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update qms document status"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{"message": "QMS document " + doc.Status, "data": doc})
+}
+
+func (h *QmsDocumentsHandler) GetQmsDocumentApprovalsHandler(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("qms_documents_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid qms document ID"})
+	}
+
+	approvals, err := h.QmsDocumentsSrv.GetQmsDocumentApprovalsService(id, uploader.AuthEmpCode(c), uploader.AuthRole(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch approval history"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{"data": approvals})
+}
+
+// GetMyQmsDocumentTasksHandler lists documents waiting on the signed-in employee
+func (h *QmsDocumentsHandler) GetMyQmsDocumentTasksHandler(c *fiber.Ctx) error {
+	empCode := uploader.AuthEmpCode(c)
+	if empCode == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	docs, err := h.QmsDocumentsSrv.GetQmsDocumentTasksService(empCode)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch qms document tasks"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{"data": docs})
+}
+
+func (h *QmsDocumentsHandler) GetQmsDocumentsReviewDueHandler(c *fiber.Ctx) error {
+	docs, err := h.QmsDocumentsSrv.GetQmsDocumentsReviewDueService(c.QueryInt("days", 30))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch qms documents due for review"})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{"data": docs})
+}
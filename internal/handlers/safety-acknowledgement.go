@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
+	"backend/internal/pkgs/utils"
+)
+
+type SafetyAcknowledgementHandler struct {
+	AckSrv services.SafetyAcknowledgementService
+}
+
+func NewSafetyAcknowledgementHandler(insSrv services.SafetyAcknowledgementService) *SafetyAcknowledgementHandler {
+	return &SafetyAcknowledgementHandler{AckSrv: insSrv}
+}
+
+// Mark a document (revision) as requiring acknowledgement by departments, positions or everyone
+func (h *SafetyAcknowledgementHandler) SetAckRequirementHandler(c *fiber.Ctx) error {
+	docID, err := c.ParamsInt("safety_document_id")
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid safety document ID"})
+	}
+
+	var req models.SafetyAckRequirementReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	requirement, err := h.AckSrv.SetAckRequirement(docID, req, utils.AuthUsername(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to set acknowledgement requirement"})
+	}
+
+	log.Printf("[SetAckRequirementHandler] safety document #%d requires acknowledgement (set by %s)\n", docID, utils.AuthUsername(c))
+	return c.JSON(fiber.Map{"message": "Acknowledgement requirement saved", "data": requirement})
+}
+
+func (h *SafetyAcknowledgementHandler) GetAckRequirementHandler(c *fiber.Ctx) error {
+	docID, err := c.ParamsInt("safety_document_id")
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid safety document ID"})
+	}
+
+	requirement, err := h.AckSrv.GetAckRequirement(docID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch acknowledgement requirement"})
+	}
+
+	return c.JSON(fiber.Map{"data": requirement})
+}
+
+func (h *SafetyAcknowledgementHandler) CloseAckRequirementHandler(c *fiber.Ctx) error {
+	docID, err := c.ParamsInt("safety_document_id")
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid safety document ID"})
+	}
+
+	if err := h.AckSrv.CloseAckRequirement(docID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to close acknowledgement requirement"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Acknowledgement requirement closed"})
+}
+
+// Acknowledge records that the signed-in employee has read the document
+func (h *SafetyAcknowledgementHandler) AcknowledgeHandler(c *fiber.Ctx) error {
+	docID, err := c.ParamsInt("safety_document_id")
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid safety document ID"})
+	}
+
+	ack, err := h.AckSrv.Acknowledge(docID, utils.AuthEmpCode(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to acknowledge safety document"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Safety document acknowledged", "data": ack})
+}
+
+// Documents the signed-in employee still has to acknowledge
+func (h *SafetyAcknowledgementHandler) GetPendingAcknowledgementsHandler(c *fiber.Ctx) error {
+	pending, err := h.AckSrv.GetPendingAcknowledgements(utils.AuthEmpCode(c), utils.PublicBaseURL(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch pending acknowledgements"})
+	}
+
+	return c.JSON(fiber.Map{"data": pending})
+}
+
+// Acknowledged vs pending per department and employee; ?format=csv&view=employee|department exports it
+func (h *SafetyAcknowledgementHandler) GetAckReportHandler(c *fiber.Ctx) error {
+	docID, err := c.ParamsInt("safety_document_id")
+	if err != nil || docID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid safety document ID"})
+	}
+	department := c.Query("department")
+
+	if c.Query("format") == "csv" {
+		data, fileName, err := h.AckSrv.GetAckReportCSV(docID, department, c.Query("view"))
+		if err != nil {
+			if appErr, ok := err.(errs.AppError); ok {
+				return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to export acknowledgement report"})
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+		return c.Send(data)
+	}
+
+	report, err := h.AckSrv.GetAckReport(docID, department)
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch acknowledgement report"})
+	}
+
+	return c.JSON(report)
+}
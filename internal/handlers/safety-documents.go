@@ -8,24 +8,28 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"backend/internal/core/domains"
 	"backend/internal/core/models"
 	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/errs"
 	uploader "backend/internal/pkgs/utils"
 )
 
 type SafetyDocumentHandler struct {
 	SafetyDocumentSrv services.SafetyDocumentService
+	RevisionSrv       services.DocumentRevisionService
+	TextSrv           services.DocumentTextService
 }
 
-func NewSafetyDocumentHandler(insSrv services.SafetyDocumentService) *SafetyDocumentHandler {
-	return &SafetyDocumentHandler{SafetyDocumentSrv: insSrv}
+func NewSafetyDocumentHandler(insSrv services.SafetyDocumentService, revisionSrv services.DocumentRevisionService, textSrv services.DocumentTextService) *SafetyDocumentHandler {
+	return &SafetyDocumentHandler{SafetyDocumentSrv: insSrv, RevisionSrv: revisionSrv, TextSrv: textSrv}
 }
 
 // Create safety document with single file upload
 func (h *SafetyDocumentHandler) CreateSafetyDocumentHandler(c *fiber.Ctx) error {
 	relPath, _, err := uploader.UploadFromForm(c, "file", uploader.Options{
 		Dir:          "./uploads/safety_documents",
-		AllowedMIMEs: []string{"application/pdf"},
+		AllowedMIMEs: searchableDocumentMIMEs,
 		MaxSize:      50 << 20,
 		BaseURL:      "",
 		Required:     true,
@@ -40,13 +44,20 @@ func (h *SafetyDocumentHandler) CreateSafetyDocumentHandler(c *fiber.Ctx) error
 		Category:           c.FormValue("category"),
 		Department:         c.FormValue("department"),
 		FileName:           fmt.Sprintf("[\"%s\"]", relPath),
+		OwnerEmpCode:       c.FormValue("owner_emp_code"),
+		NextReviewDate:     c.FormValue("next_review_date"),
+		ExpiryDate:         c.FormValue("expiry_date"),
 	}
 
 	if err := h.SafetyDocumentSrv.CreateSafetyDocumentService(req); err != nil {
 		log.Println("Error creating safety document:", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create safety document"})
 	}
 
+	indexUploadedText(h.TextSrv, domains.DocModuleSafety, req.FileName)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Safety document created successfully",
 		"file":    relPath,
@@ -118,7 +129,7 @@ func (h *SafetyDocumentHandler) UpdateSafetyDocumentHandler(c *fiber.Ctx) error
 		log.Println("[UpdateSafetyDocumentHandler] Processing file upload...")
 		relPath, _, err = uploader.UploadFromForm(c, "file", uploader.Options{
 			Dir:          "./uploads/safety_documents",
-			AllowedMIMEs: []string{"application/pdf"},
+			AllowedMIMEs: searchableDocumentMIMEs,
 			MaxSize:      50 << 20,
 			BaseURL:      "",
 			Required:     false,
@@ -135,6 +146,9 @@ func (h *SafetyDocumentHandler) UpdateSafetyDocumentHandler(c *fiber.Ctx) error
 		SafetyDocumentDesc: c.FormValue("safety_document_desc"),
 		Category:           c.FormValue("category"),
 		Department:         c.FormValue("department"),
+		OwnerEmpCode:       c.FormValue("owner_emp_code"),
+		NextReviewDate:     c.FormValue("next_review_date"),
+		ExpiryDate:         c.FormValue("expiry_date"),
 	}
 
 	// Only set FileName if a new file was uploaded
@@ -142,12 +156,30 @@ func (h *SafetyDocumentHandler) UpdateSafetyDocumentHandler(c *fiber.Ctx) error
 		req.FileName = fmt.Sprintf("[\"%s\"]", relPath)
 	}
 
+	revisionReq, err := parseDocumentRevisionForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if relPath != "" {
+		if err := h.RevisionSrv.EnsureBaselineRevision(domains.DocModuleSafety, docID); err != nil {
+			log.Printf("[UpdateSafetyDocumentHandler] Baseline revision error: %v\n", err)
+		}
+	}
+
 	log.Printf("[UpdateSafetyDocumentHandler] Calling UpdateSafetyDocumentService with ID: %d\n", docID)
 	if err := h.SafetyDocumentSrv.UpdateSafetyDocumentService(docID, req); err != nil {
 		log.Printf("[UpdateSafetyDocumentHandler] Service error: %v\n", err)
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update safety document"})
 	}
 
+	recordDocumentRevision(h.RevisionSrv, domains.DocModuleSafety, docID, req.FileName, revisionReq)
+	if req.FileName != "" {
+		indexDocumentText(h.TextSrv, domains.DocModuleSafety, docID)
+	}
+
 	log.Println("[UpdateSafetyDocumentHandler] Update completed successfully")
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "Safety document updated successfully",
@@ -179,22 +211,30 @@ func (h *SafetyDocumentHandler) DeleteSafetyDocumentHandler(c *fiber.Ctx) error
 
 // Search safety documents
 func (h *SafetyDocumentHandler) SearchSafetyDocumentHandler(c *fiber.Ctx) error {
-	keyword := c.Query("keyword")
-	limit := c.QueryInt("limit", 50)
-	offset := c.QueryInt("offset", 0)
-
-	if keyword == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "keyword is required",
+	docs, err := h.SafetyDocumentSrv.SearchSafetyDocumentService(documentSearchRequest(c))
+	if err != nil {
+		if appErr, ok := err.(errs.AppError); ok {
+			return c.Status(appErr.Code).JSON(fiber.Map{"error": appErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search safety documents",
 		})
 	}
 
-	docs, err := h.SafetyDocumentSrv.SearchSafetyDocumentService(keyword, limit, offset)
+	return c.Status(http.StatusOK).JSON(docs)
+}
+
+// Get overdue and upcoming reviews/expiries grouped by department
+func (h *SafetyDocumentHandler) GetSafetyReviewStatusHandler(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 30)
+	department := c.Query("department")
+
+	status, err := h.SafetyDocumentSrv.GetSafetyReviewStatusService(days, department)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to search safety documents",
+			"error": "Failed to fetch safety document review status",
 		})
 	}
 
-	return c.Status(http.StatusOK).JSON(docs)
+	return c.Status(http.StatusOK).JSON(status)
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/core/models"
+	"backend/internal/pkgs/resumable"
+	"backend/internal/pkgs/utils"
+)
+
+// Headers of the chunked upload protocol, named after their tus counterparts
+const (
+	headerUploadOffset   = "Upload-Offset"
+	headerUploadLength   = "Upload-Length"
+	headerUploadChecksum = "Upload-Checksum"
+)
+
+// UploadSessionHandler implements resumable uploads: create a session, PATCH the parts at Upload-Offset,
+// then complete it with the file's SHA-256 and pass the token as file_upload_token to a document endpoint
+type UploadSessionHandler struct {
+	Store *resumable.Store
+}
+
+func NewUploadSessionHandler(store *resumable.Store) *UploadSessionHandler {
+	return &UploadSessionHandler{Store: store}
+}
+
+func (h *UploadSessionHandler) CreateUploadSessionHandler(c *fiber.Ctx) error {
+	var req models.CreateUploadSessionReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if req.FileName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file_name is required"})
+	}
+
+	session, err := h.Store.Create(req.FileName, req.Size, req.SHA256, utils.AuthUsername(c))
+	if err != nil {
+		return uploadSessionError(c, "CreateUploadSession", err)
+	}
+
+	log.Printf("[CreateUploadSession] %s started %s (%d bytes) as %s\n", session.Owner, session.FileName, session.Size, session.Token)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": h.sessionResp(c, session)})
+}
+
+// GetUploadSessionHandler tells a client resuming after a failure where to continue
+func (h *UploadSessionHandler) GetUploadSessionHandler(c *fiber.Ctx) error {
+	session, err := h.ownSession(c)
+	if err != nil {
+		return uploadSessionError(c, "GetUploadSession", err)
+	}
+	return c.JSON(fiber.Map{"data": h.sessionResp(c, session)})
+}
+
+// AppendUploadSessionHandler appends the request body at Upload-Offset; an optional Upload-Checksum
+// ("sha256 <base64>") rejects a damaged part so it can be sent again
+func (h *UploadSessionHandler) AppendUploadSessionHandler(c *fiber.Ctx) error {
+	offset, err := strconv.ParseInt(c.Get(headerUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Upload-Offset header is required"})
+	}
+	checksum := ""
+	if header := c.Get(headerUploadChecksum); header != "" {
+		if checksum, err = resumable.ParseChecksum(header); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	if _, err := h.ownSession(c); err != nil {
+		return uploadSessionError(c, "AppendUploadSession", err)
+	}
+
+	session, err := h.Store.Append(c.Params("token"), offset, bytes.NewReader(c.Body()), checksum)
+	if err != nil {
+		if session.Token != "" {
+			c.Set(headerUploadOffset, strconv.FormatInt(session.Offset, 10))
+		}
+		return uploadSessionError(c, "AppendUploadSession", err)
+	}
+	return c.JSON(fiber.Map{"data": h.sessionResp(c, session)})
+}
+
+// CompleteUploadSessionHandler verifies the assembled file against its SHA-256
+func (h *UploadSessionHandler) CompleteUploadSessionHandler(c *fiber.Ctx) error {
+	var req models.CompleteUploadSessionReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+	}
+	if _, err := h.ownSession(c); err != nil {
+		return uploadSessionError(c, "CompleteUploadSession", err)
+	}
+
+	session, err := h.Store.Complete(c.Params("token"), req.SHA256)
+	if err != nil {
+		return uploadSessionError(c, "CompleteUploadSession", err)
+	}
+
+	log.Printf("[CompleteUploadSession] %s completed %s (%d bytes, sha256 %s)\n", session.Owner, session.FileName, session.Size, session.SHA256)
+	return c.JSON(fiber.Map{"data": h.sessionResp(c, session)})
+}
+
+func (h *UploadSessionHandler) DeleteUploadSessionHandler(c *fiber.Ctx) error {
+	if _, err := h.ownSession(c); err != nil {
+		return uploadSessionError(c, "DeleteUploadSession", err)
+	}
+	if err := h.Store.Remove(c.Params("token")); err != nil {
+		return uploadSessionError(c, "DeleteUploadSession", err)
+	}
+	return c.JSON(fiber.Map{"message": "Upload cancelled"})
+}
+
+// ownSession hides sessions of other users behind ErrNotFound
+func (h *UploadSessionHandler) ownSession(c *fiber.Ctx) (resumable.Session, error) {
+	session, err := h.Store.Get(c.Params("token"))
+	if err != nil {
+		return resumable.Session{}, err
+	}
+	if session.Owner != utils.AuthUsername(c) {
+		return resumable.Session{}, resumable.ErrNotFound
+	}
+	return session, nil
+}
+
+func (h *UploadSessionHandler) sessionResp(c *fiber.Ctx, session resumable.Session) models.UploadSessionResp {
+	c.Set(headerUploadOffset, strconv.FormatInt(session.Offset, 10))
+	c.Set(headerUploadLength, strconv.FormatInt(session.Size, 10))
+	return models.UploadSessionResp{
+		UploadToken: session.Token,
+		FileName:    session.FileName,
+		Size:        session.Size,
+		Offset:      session.Offset,
+		SHA256:      session.SHA256,
+		Completed:   session.Completed,
+		ChunkSize:   resumable.MaxChunkSize,
+		ExpiresAt:   session.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func uploadSessionError(c *fiber.Ctx, name string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, resumable.ErrNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, resumable.ErrOffsetMismatch), errors.Is(err, resumable.ErrIncomplete), errors.Is(err, resumable.ErrCompleted):
+		status = fiber.StatusConflict
+	case errors.Is(err, resumable.ErrTooLarge), errors.Is(err, resumable.ErrChunkTooLarge):
+		status = fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, resumable.ErrChecksumMismatch):
+		status = fiber.StatusUnprocessableEntity
+	case errors.Is(err, resumable.ErrTooManySessions):
+		status = fiber.StatusTooManyRequests
+	case errors.Is(err, resumable.ErrInvalidSize), errors.Is(err, resumable.ErrInvalidChecksum), errors.Is(err, resumable.ErrChecksumRequired):
+		status = fiber.StatusBadRequest
+	default:
+		log.Printf("[%s] Error: %v\n", name, err)
+		return c.Status(status).JSON(fiber.Map{"error": "Failed to process the upload"})
+	}
+	return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+}
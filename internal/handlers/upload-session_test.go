@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+
+	"backend/app/middlewares"
+	"backend/internal/core/models"
+	services "backend/internal/core/ports/services"
+	"backend/internal/pkgs/resumable"
+	"backend/internal/pkgs/storage"
+)
+
+// recordingProcedureManuals keeps the procedure manuals it is asked to create
+type recordingProcedureManuals struct {
+	services.ProcedureManualService
+	created []models.CreateProcedureManualRequest
+}
+
+func (s *recordingProcedureManuals) CreateProcedureManualService(req models.CreateProcedureManualRequest) error {
+	s.created = append(s.created, req)
+	return nil
+}
+
+// samplePDF is just enough of a PDF for content sniffing
+var samplePDF = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n")
+
+func TestCreateDocumentWithUploadToken(t *testing.T) {
+	// the create endpoints read sessions from resumable.Default(), which is configured once from the environment
+	t.Setenv("UPLOAD_SESSION_DIR", t.TempDir())
+	t.Setenv("TOKEN_SECRET_KEY", "test-secret")
+	if err := storage.Init(storage.Config{Driver: storage.DriverLocal, LocalRoot: t.TempDir()}); err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	manuals := &recordingProcedureManuals{}
+	sessions := NewUploadSessionHandler(resumable.Default())
+	manualH := NewProcedureManualHandler(manuals, nil, nil)
+
+	app := fiber.New()
+	app.Post("/upload-sessions", middlewares.NewAuthMiddleware, sessions.CreateUploadSessionHandler)
+	app.Patch("/upload-sessions/:token", middlewares.NewAuthMiddleware, sessions.AppendUploadSessionHandler)
+	app.Post("/upload-sessions/:token/complete", middlewares.NewAuthMiddleware, sessions.CompleteUploadSessionHandler)
+	app.Post("/procedure-manual/create", middlewares.NewAuthMiddleware, manualH.CreateProcedureManualHandler)
+
+	owner := bearer(t, "100231", "somchai", "user")
+	token := completeUploadSession(t, app, owner, "manual.pdf", samplePDF)
+
+	t.Run("anonymous", func(t *testing.T) {
+		resp := createProcedureManual(t, app, "", token)
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", resp.StatusCode)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		resp := createProcedureManual(t, app, bearer(t, "100487", "somsak", "user"), token)
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("status = %d, want 400", resp.StatusCode)
+		}
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		resp := createProcedureManual(t, app, owner, token)
+		if resp.StatusCode != fiber.StatusCreated {
+			t.Fatalf("status = %d, want 201", resp.StatusCode)
+		}
+		if len(manuals.created) != 1 {
+			t.Fatalf("created %d manual(s)", len(manuals.created))
+		}
+		var fileName []string
+		if err := json.Unmarshal([]byte(manuals.created[0].FileName), &fileName); err != nil || len(fileName) != 1 {
+			t.Fatalf("file_name = %q (%v)", manuals.created[0].FileName, err)
+		}
+		if !strings.HasPrefix(fileName[0], "uploads/procedure_manual/") || !strings.HasSuffix(fileName[0], ".pdf") {
+			t.Errorf("stored as %q", fileName[0])
+		}
+		if _, err := resumable.Default().Get(token); err == nil {
+			t.Error("upload session was not removed after use")
+		}
+	})
+}
+
+// completeUploadSession uploads data in one part and returns the token of the completed session
+func completeUploadSession(t *testing.T, app *fiber.App, auth, fileName string, data []byte) string {
+	t.Helper()
+	sum := sha256.Sum256(data)
+
+	body, _ := json.Marshal(models.CreateUploadSessionReq{FileName: fileName, Size: int64(len(data))})
+	req := httptest.NewRequest(http.MethodPost, "/upload-sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	req.Header.Set("Authorization", auth)
+	var created struct {
+		Data models.UploadSessionResp `json:"data"`
+	}
+	doJSON(t, app, req, fiber.StatusCreated, &created)
+	token := created.Data.UploadToken
+
+	req = httptest.NewRequest(http.MethodPatch, "/upload-sessions/"+token, bytes.NewReader(data))
+	req.Header.Set(headerUploadOffset, "0")
+	req.Header.Set("Authorization", auth)
+	doJSON(t, app, req, fiber.StatusOK, nil)
+
+	body, _ = json.Marshal(models.CompleteUploadSessionReq{SHA256: hex.EncodeToString(sum[:])})
+	req = httptest.NewRequest(http.MethodPost, "/upload-sessions/"+token+"/complete", bytes.NewReader(body))
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	req.Header.Set("Authorization", auth)
+	doJSON(t, app, req, fiber.StatusOK, nil)
+	return token
+}
+
+func createProcedureManual(t *testing.T, app *fiber.App, auth, uploadToken string) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("procedure_manual_name", "Forklift handling")
+	form.WriteField("category", "warehouse")
+	form.WriteField("file_upload_token", uploadToken)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/procedure-manual/create", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return resp
+}
+
+func doJSON(t *testing.T, app *fiber.App, req *http.Request, want int, out interface{}) {
+	t.Helper()
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		var b bytes.Buffer
+		b.ReadFrom(resp.Body)
+		t.Fatalf("%s %s = %d, want %d: %s", req.Method, req.URL.Path, resp.StatusCode, want, b.String())
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", req.URL.Path, err)
+		}
+	}
+}
+
+// bearer signs an employee token the way SignInEmployee does
+func bearer(t *testing.T, empCode, username, role string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  empCode,
+		"username": username,
+		"role":     role,
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(os.Getenv("TOKEN_SECRET_KEY")))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return "Bearer " + signed
+}
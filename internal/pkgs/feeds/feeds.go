@@ -0,0 +1,314 @@
+package feeds
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"path"
+	"time"
+)
+
+// Content types for each feed format
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+type Feed struct {
+	Title       string
+	Link        string // HTML page of the feed
+	FeedURL     string // URL of the feed document itself
+	Description string
+	Language    string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Summary     string // plain text
+	ContentHTML string
+	ImageURL    string
+	Category    string
+	Author      string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// ====================== RSS 2.0 ===================================
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          rssSelf   `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	Category    string        `xml:"category,omitempty"`
+	Creator     string        `xml:"dc:creator,omitempty"` // RSS <author> must be an email address
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+// RSS renders the feed as RSS 2.0
+func (f Feed) RSS() ([]byte, error) {
+	ch := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Self:        rssSelf{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		Description: f.Description,
+		Language:    f.Language,
+	}
+	if !f.Updated.IsZero() {
+		ch.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, it := range f.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{Value: it.ID},
+			Description: it.ContentHTML,
+			Category:    it.Category,
+			Creator:     it.Author,
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+		}
+		if item.Description == "" {
+			item.Description = it.Summary
+		}
+		if it.ImageURL != "" {
+			item.Enclosure = &rssEnclosure{URL: it.ImageURL, Type: imageType(it.ImageURL)}
+		}
+		ch.Items = append(ch.Items, item)
+	}
+
+	return marshalXML(rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: ch,
+	})
+}
+
+// ====================== Atom ===================================
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []atomLink    `xml:"link"`
+	Author    *atomAuthor   `xml:"author,omitempty"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   *atomText     `xml:"summary,omitempty"`
+	Content   *atomText     `xml:"content,omitempty"`
+}
+
+// Atom renders the feed as Atom 1.0
+func (f Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:      f.FeedURL,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+
+	for _, it := range f.Items {
+		entry := atomEntry{
+			ID:        "urn:uuid:" + it.ID,
+			Title:     it.Title,
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: it.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if it.Author != "" {
+			entry.Author = &atomAuthor{Name: it.Author}
+		}
+		if it.Category != "" {
+			entry.Category = &atomCategory{Term: it.Category}
+		}
+		if it.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: it.Summary}
+		}
+		if it.ContentHTML != "" {
+			entry.Content = &atomText{Type: "html", Value: it.ContentHTML}
+		}
+		if it.ImageURL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: it.ImageURL, Rel: "enclosure", Type: imageType(it.ImageURL)})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// ====================== JSON Feed 1.1 ===================================
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+// JSONFeed renders the feed as JSON Feed 1.1
+func (f Feed) JSONFeed() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       make([]jsonFeedItem, 0, len(f.Items)),
+	}
+
+	for _, it := range f.Items {
+		item := jsonFeedItem{
+			ID:            it.ID,
+			URL:           it.Link,
+			Title:         it.Title,
+			ContentHTML:   it.ContentHTML,
+			Summary:       it.Summary,
+			Image:         it.ImageURL,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			DateModified:  it.Updated.UTC().Format(time.RFC3339),
+		}
+		if it.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: it.Author}}
+		}
+		if it.Category != "" {
+			item.Tags = []string{it.Category}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	return json.Marshal(doc)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// imageType guesses the MIME type from the file extension of an image URL
+func imageType(url string) string {
+	for _, candidate := range []string{stripQuery(url), url} {
+		if t := mime.TypeByExtension(path.Ext(candidate)); t != "" {
+			return t
+		}
+	}
+	return "image/jpeg"
+}
+
+func stripQuery(url string) string {
+	for i := 0; i < len(url); i++ {
+		if url[i] == '?' || url[i] == '#' {
+			return url[:i]
+		}
+	}
+	return url
+}
+
+// ETag returns a strong validator that changes whenever the set of items or their update times change
+func (f Feed) ETag(format string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%s|%s\n", format, f.FeedURL, f.Title)
+	for _, it := range f.Items {
+		fmt.Fprintf(h, "%s|%d\n", it.ID, it.Updated.UnixNano())
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
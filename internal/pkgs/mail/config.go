@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TLS modes for the SMTP connection
+const (
+	TLSStartTLS = "starttls" // upgrade when the server offers STARTTLS (default)
+	TLSImplicit = "tls"      // connect with TLS from the start (port 465)
+	TLSNone     = "none"     // never use TLS, e.g. the internal relay on port 25
+)
+
+type Config struct {
+	Host               string
+	Port               int
+	Username           string
+	Password           string
+	From               string
+	FromName           string
+	TLSMode            string
+	InsecureSkipVerify bool
+}
+
+// ConfigFromEnv reads the SMTP_* variables passed by docker-compose
+func ConfigFromEnv() Config {
+	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if port == 0 {
+		port = 25
+	}
+
+	from := os.Getenv("SMTP_SET_FROM")
+	if from == "" {
+		from = os.Getenv("SMTP_USERNAME")
+	}
+
+	mode := strings.ToLower(os.Getenv("SMTP_TLS"))
+	switch mode {
+	case TLSImplicit, TLSNone:
+	default:
+		mode = TLSStartTLS
+	}
+
+	return Config{
+		Host:               os.Getenv("SMTP_HOST"),
+		Port:               port,
+		Username:           os.Getenv("SMTP_USERNAME"),
+		Password:           os.Getenv("SMTP_PASSWORD"),
+		From:               from,
+		FromName:           os.Getenv("SMTP_FROM_NAME"),
+		TLSMode:            mode,
+		InsecureSkipVerify: os.Getenv("SMTP_INSECURE_SKIP_VERIFY") == "true",
+	}
+}
+
+// Enabled reports whether an SMTP host is configured
+func (c Config) Enabled() bool {
+	return c.Host != ""
+}
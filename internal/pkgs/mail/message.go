@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	From    string // defaults to the configured sender
+	To      []string
+	Cc      []string
+	Subject string
+	HTML    string
+	Text    string
+
+	// UnsubscribeURL adds RFC 8058 one-click unsubscribe headers; the URL must accept a POST
+	UnsubscribeURL string
+}
+
+// Recipients returns every envelope recipient (To and Cc)
+func (m Message) Recipients() []string {
+	return append(append([]string{}, m.To...), m.Cc...)
+}
+
+// Bytes builds an RFC 5322 message with UTF-8 (Thai-safe) headers and a text/HTML alternative body
+func (m Message) Bytes() []byte {
+	var buf bytes.Buffer
+	boundary := randomBoundary()
+
+	writeHeader(&buf, "From", m.From)
+	writeHeader(&buf, "To", strings.Join(m.To, ", "))
+	if len(m.Cc) > 0 {
+		writeHeader(&buf, "Cc", strings.Join(m.Cc, ", "))
+	}
+	writeHeader(&buf, "Subject", mime.BEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", randomBoundary(), messageDomain(m.From)))
+	if m.UnsubscribeURL != "" {
+		writeHeader(&buf, "List-Unsubscribe", "<"+m.UnsubscribeURL+">")
+		writeHeader(&buf, "List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	if m.Text != "" {
+		writePart(&buf, boundary, "text/plain; charset=UTF-8", m.Text)
+	}
+	if m.HTML != "" {
+		writePart(&buf, boundary, "text/html; charset=UTF-8", m.HTML)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes()
+}
+
+// FormatAddress renders "Name <addr>" with an RFC 2047 encoded name
+func FormatAddress(name, address string) string {
+	if name == "" {
+		return address
+	}
+	return (&mail.Address{Name: name, Address: address}).String()
+}
+
+// ParseAddressList splits and validates a comma-separated recipient list
+func ParseAddressList(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	addrs, err := mail.ParseAddressList(list)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		out = append(out, a.Address)
+	}
+	return out, nil
+}
+
+// writeHeader folds the value at spaces to keep lines near 78 octets; encoded subjects and names are
+// split into several encoded words, which gives those spaces
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	line := key + ":"
+	for i, word := range strings.Split(value, " ") {
+		if i > 0 && len(line)+1+len(word) > 78 {
+			buf.WriteString(line + "\r\n")
+			line = ""
+		}
+		line += " " + word
+	}
+	buf.WriteString(line + "\r\n")
+}
+
+func writePart(buf *bytes.Buffer, boundary, contentType, body string) {
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader(buf, "Content-Type", contentType)
+	writeHeader(buf, "Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func randomBoundary() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func messageDomain(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			return addr.Address[i+1:]
+		}
+	}
+	return "localhost"
+}
@@ -0,0 +1,113 @@
+package mail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytesThai(t *testing.T) {
+	msg := Message{
+		From:    FormatAddress("ระบบข่าวสาร", "portal@example.com"),
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: strings.Repeat("สรุปข่าวสารประจำสัปดาห์ ", 20),
+		Text:    strings.Repeat("สวัสดีครับ ยินดีต้อนรับสู่ระบบข่าวสารบริษัท\n", 10),
+		HTML:    "<p>สวัสดีครับ</p>",
+	}
+
+	// a long Thai subject is several kilobytes once encoded, so it must be folded
+	raw := string(msg.Bytes())
+	for _, line := range strings.Split(raw, "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line longer than 998 octets: %q", line)
+		}
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	from, err := parsed.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Name != "ระบบข่าวสาร" {
+		t.Errorf("From = %v (%v)", from, err)
+	}
+	if got := parsed.Header.Get("To"); got != "a@example.com, b@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if parsed.Header.Get("List-Unsubscribe") != "" {
+		t.Error("List-Unsubscribe set without an unsubscribe URL")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", mediaType, err)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "base64" {
+			t.Errorf("Content-Transfer-Encoding = %q", enc)
+		}
+		// multipart.Reader only decodes quoted-printable, so the base64 body is decoded here
+		body, _ := io.ReadAll(part)
+		decoded, err := decodeBase64Lines(string(body))
+		if err != nil {
+			t.Fatalf("decode %s: %v", part.Header.Get("Content-Type"), err)
+		}
+		parts[part.Header.Get("Content-Type")] = decoded
+	}
+	if parts["text/plain; charset=UTF-8"] != msg.Text {
+		t.Errorf("text part = %q", parts["text/plain; charset=UTF-8"])
+	}
+	if parts["text/html; charset=UTF-8"] != msg.HTML {
+		t.Errorf("html part = %q", parts["text/html; charset=UTF-8"])
+	}
+}
+
+func TestMessageBytesUnsubscribe(t *testing.T) {
+	msg := Message{To: []string{"a@example.com"}, Subject: "digest", Text: "x",
+		UnsubscribeURL: "https://portal.example.com/api/company-news/digest/unsubscribe?token=abc"}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(msg.Bytes())))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if got := parsed.Header.Get("List-Unsubscribe"); got != "<"+msg.UnsubscribeURL+">" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := parsed.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+}
+
+func TestParseAddressList(t *testing.T) {
+	got, err := ParseAddressList("Somchai <somchai@example.com>, hr@example.com")
+	if err != nil || strings.Join(got, ",") != "somchai@example.com,hr@example.com" {
+		t.Errorf("ParseAddressList = %v (%v)", got, err)
+	}
+	if _, err := ParseAddressList("not an address"); err == nil {
+		t.Error("ParseAddressList accepted an invalid address")
+	}
+}
+
+func decodeBase64Lines(body string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\r", "", "\n", "").Replace(body))
+	return string(b), err
+}
@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sender delivers a rendered message. SMTPSender is used in production; point SMTP_HOST
+// at a local stand-in (e.g. the mailpit service in docker-compose) to try it out.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender returns an SMTP sender, or a LogSender when no SMTP host is configured
+func NewSender(cfg Config) Sender {
+	if !cfg.Enabled() {
+		log.Println("[mail] SMTP_HOST is not set, emails will only be logged")
+		return LogSender{}
+	}
+	return NewSMTPSender(cfg)
+}
+
+type SMTPSender struct {
+	cfg Config
+}
+
+func NewSMTPSender(cfg Config) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	recipients := msg.Recipients()
+	if len(recipients) == 0 {
+		return errors.New("mail: no recipients")
+	}
+	if msg.From == "" {
+		msg.From = FormatAddress(s.cfg.FromName, s.cfg.From)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, InsecureSkipVerify: s.cfg.InsecureSkipVerify}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLSMode == TLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("mail: dial %s: %w", addr, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(2 * time.Minute)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if hostname, err := os.Hostname(); err == nil {
+		if err := client.Hello(hostname); err != nil {
+			return fmt.Errorf("mail: EHLO: %w", err)
+		}
+	}
+
+	encrypted := s.cfg.TLSMode == TLSImplicit
+	if s.cfg.TLSMode == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("mail: STARTTLS: %w", err)
+			}
+			encrypted = true
+		}
+	}
+
+	if s.cfg.Username != "" && s.cfg.Password != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			var auth smtp.Auth
+			if encrypted || s.cfg.TLSMode == TLSNone {
+				auth = plainAuth{username: s.cfg.Username, password: s.cfg.Password}
+			} else {
+				auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+			}
+			if err := client.Auth(auth); err != nil {
+				return fmt.Errorf("mail: AUTH: %w", err)
+			}
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("mail: MAIL FROM: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("mail: RCPT TO %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: DATA: %w", err)
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		w.Close()
+		return fmt.Errorf("mail: write body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: end DATA: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// plainAuth is AUTH PLAIN without net/smtp's TLS check. It is only used over TLS,
+// or when SMTP_TLS=none explicitly allows the internal relay without encryption.
+type plainAuth struct {
+	username string
+	password string
+}
+
+func (a plainAuth) Start(_ *smtp.ServerInfo) (string, []byte, error) {
+	return "PLAIN", []byte("\x00" + a.username + "\x00" + a.password), nil
+}
+
+func (a plainAuth) Next(_ []byte, more bool) ([]byte, error) {
+	if more {
+		return nil, errors.New("mail: unexpected server challenge")
+	}
+	return nil, nil
+}
+
+// LogSender prints messages instead of sending them, for development without SMTP
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("[mail] To: %s | Subject: %s\n%s\n", strings.Join(msg.Recipients(), ", "), msg.Subject, msg.Text)
+	return nil
+}
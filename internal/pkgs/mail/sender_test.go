@@ -0,0 +1,170 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpStub is a minimal in-process SMTP server that records what it receives
+type smtpStub struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpts    []string
+	data     string
+	rejectTo string // RCPT TO address answered with 550
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStub{ln: ln}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *smtpStub) config() Config {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return Config{Host: "127.0.0.1", Port: addr.Port, From: "portal@example.com", FromName: "PSTH Info Portal", TLSMode: TLSNone}
+}
+
+func (s *smtpStub) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStub) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 stub")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			rcpt := strings.Trim(line[len("RCPT TO:"):], "<> ")
+			if rcpt == s.rejectTo {
+				reply("550 no such user")
+				continue
+			}
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, rcpt)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	stub := newSMTPStub(t)
+	sender := NewSMTPSender(stub.config())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := sender.Send(ctx, Message{
+		To:      []string{"somchai@example.com"},
+		Cc:      []string{"hr@example.com"},
+		Subject: "ข่าวสารบริษัท",
+		Text:    "สวัสดีครับ",
+		HTML:    "<p>สวัสดีครับ</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	if stub.from != "portal@example.com" {
+		t.Errorf("MAIL FROM = %q", stub.from)
+	}
+	if strings.Join(stub.rcpts, ",") != "somchai@example.com,hr@example.com" {
+		t.Errorf("RCPT TO = %v", stub.rcpts)
+	}
+	if !strings.Contains(stub.data, "Subject: =?UTF-8?b?") {
+		t.Errorf("subject is not RFC 2047 encoded:\n%s", stub.data)
+	}
+	if !strings.Contains(stub.data, "From: \"PSTH Info Portal\" <portal@example.com>") {
+		t.Errorf("From header missing:\n%s", stub.data)
+	}
+}
+
+func TestSMTPSenderRejectedRecipient(t *testing.T) {
+	stub := newSMTPStub(t)
+	stub.rejectTo = "nobody@example.com"
+
+	err := NewSMTPSender(stub.config()).Send(context.Background(), Message{To: []string{"nobody@example.com"}, Subject: "x", Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "RCPT TO nobody@example.com") {
+		t.Fatalf("Send error = %v, want RCPT TO failure", err)
+	}
+}
+
+func TestSMTPSenderNoRecipients(t *testing.T) {
+	if err := NewSMTPSender(Config{Host: "127.0.0.1", Port: 1}).Send(context.Background(), Message{}); err == nil {
+		t.Fatal("Send without recipients should fail")
+	}
+}
+
+func TestSMTPSenderDialError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	cfg := Config{Host: "127.0.0.1", Port: port, From: "portal@example.com", TLSMode: TLSNone}
+	err = NewSMTPSender(cfg).Send(context.Background(), Message{To: []string{"a@example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "dial 127.0.0.1:"+strconv.Itoa(port)) {
+		t.Fatalf("Send error = %v, want dial failure", err)
+	}
+}
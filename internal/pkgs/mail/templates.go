@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Languages supported by the embedded templates
+const (
+	LangTH = "th"
+	LangEN = "en"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Rendered holds the parts of a message produced from a template
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Render executes templates/<name>.<lang>.html and .txt. The text template must define a "subject" block.
+// The HTML template defines a "content" block that is wrapped by templates/layout.html.
+// Unknown languages fall back to Thai.
+func Render(name, lang string, data interface{}) (Rendered, error) {
+	if lang != LangEN {
+		lang = LangTH
+	}
+
+	textName := fmt.Sprintf("templates/%s.%s.txt", name, lang)
+	htmlName := fmt.Sprintf("templates/%s.%s.html", name, lang)
+
+	textTmpl, err := texttemplate.New("").Funcs(texttemplate.FuncMap(templateFuncs)).ParseFS(templateFS, textName)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("mail: parse %s: %w", textName, err)
+	}
+	htmlTmpl, err := htmltemplate.New("").Funcs(htmltemplate.FuncMap(templateFuncs)).ParseFS(templateFS, "templates/layout.html", htmlName)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("mail: parse %s: %w", htmlName, err)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Rendered{}, fmt.Errorf("mail: render subject of %s: %w", textName, err)
+	}
+	if err := textTmpl.ExecuteTemplate(&text, "body", data); err != nil {
+		return Rendered{}, fmt.Errorf("mail: render %s: %w", textName, err)
+	}
+	if err := htmlTmpl.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Rendered{}, fmt.Errorf("mail: render %s: %w", htmlName, err)
+	}
+
+	return Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+var templateFuncs = map[string]interface{}{
+	"upper": strings.ToUpper,
+}
+
+// NotificationData feeds the generic "notification" template
+type NotificationData struct {
+	Title      string
+	Greeting   string
+	Lines      []string
+	ActionURL  string
+	ActionText string
+}
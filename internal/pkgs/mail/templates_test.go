@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderNotification(t *testing.T) {
+	data := NotificationData{
+		Title:     "เอกสารใกล้หมดอายุ",
+		Greeting:  "เรียน คุณสมชาย",
+		Lines:     []string{"บรรทัดแรก", "<b>ไม่ใช่ HTML</b>"},
+		ActionURL: "https://portal.example.com/safety",
+	}
+
+	tests := []struct {
+		lang       string
+		actionText string
+	}{
+		{LangTH, "เปิดดูรายละเอียด"},
+		{LangEN, "View details"},
+		{"jp", "เปิดดูรายละเอียด"}, // unknown languages fall back to Thai
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			rendered, err := Render("notification", tt.lang, data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if rendered.Subject != "[PSTH Info Portal] เอกสารใกล้หมดอายุ" {
+				t.Errorf("Subject = %q", rendered.Subject)
+			}
+			if !strings.Contains(rendered.Text, tt.actionText+": https://portal.example.com/safety") {
+				t.Errorf("text body lacks the action line:\n%s", rendered.Text)
+			}
+			if !strings.Contains(rendered.HTML, "เรียน คุณสมชาย") {
+				t.Errorf("HTML body lacks the greeting:\n%s", rendered.HTML)
+			}
+			if strings.Contains(rendered.HTML, "<b>ไม่ใช่ HTML</b>") {
+				t.Error("HTML body does not escape the lines")
+			}
+		})
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, err := Render("does-not-exist", LangTH, nil); err == nil {
+		t.Fatal("Render of a missing template should fail")
+	}
+}
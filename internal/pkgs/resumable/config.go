@@ -0,0 +1,61 @@
+package resumable
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDir is on the upload-sessions volume of docker-compose, so sessions survive restarts
+const DefaultDir = "./upload-sessions"
+
+// clamdStreamMaxMB is clamd's default StreamMaxLength
+const clamdStreamMaxMB = 25
+
+type Config struct {
+	Dir        string        // where the parts are kept; share it between replicas or route a session to one replica
+	TTL        time.Duration // sessions not completed and used within TTL are removed
+	MaxSize    int64         // largest file a session may announce
+	MaxPerUser int           // open sessions one user may have
+}
+
+// ConfigFromEnv reads UPLOAD_SESSION_DIR, UPLOAD_SESSION_TTL_HOURS (default 24), UPLOAD_SESSION_MAX_MB
+// (default 500) and UPLOAD_SESSION_MAX_PER_USER (default 5). When uploads are scanned by clamd
+// (UPLOAD_SCANNER=clamav) the size is capped at CLAMAV_STREAM_MAX_MB (default 25, clamd's own default):
+// clamd refuses larger streams and the fail-closed scan would only reject the file after a complete
+// upload. Raise StreamMaxLength in clamd.conf and CLAMAV_STREAM_MAX_MB together.
+func ConfigFromEnv() Config {
+	dir := os.Getenv("UPLOAD_SESSION_DIR")
+	if dir == "" {
+		dir = DefaultDir
+	}
+	ttl, _ := strconv.Atoi(os.Getenv("UPLOAD_SESSION_TTL_HOURS"))
+	if ttl <= 0 {
+		ttl = 24
+	}
+	maxMB, _ := strconv.ParseInt(os.Getenv("UPLOAD_SESSION_MAX_MB"), 10, 64)
+	if maxMB <= 0 {
+		maxMB = 500
+	}
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("UPLOAD_SCANNER")), "clamav") {
+		scanMB, _ := strconv.ParseInt(os.Getenv("CLAMAV_STREAM_MAX_MB"), 10, 64)
+		if scanMB <= 0 {
+			scanMB = clamdStreamMaxMB
+		}
+		if maxMB > scanMB {
+			maxMB = scanMB
+		}
+	}
+	perUser, _ := strconv.Atoi(os.Getenv("UPLOAD_SESSION_MAX_PER_USER"))
+	if perUser <= 0 {
+		perUser = 5
+	}
+
+	return Config{
+		Dir:        dir,
+		TTL:        time.Duration(ttl) * time.Hour,
+		MaxSize:    maxMB << 20,
+		MaxPerUser: perUser,
+	}
+}
@@ -0,0 +1,407 @@
+// Package resumable keeps chunked uploads on disk until they are complete, so files larger than the
+// proxy's request limit can be sent in parts and a broken connection only costs the current part.
+//
+// A session is created with the file's size (and optionally its SHA-256), receives parts appended at the
+// current offset, and is completed once every byte arrived and the checksum matches. The completed file is
+// then consumed once by its owner through the upload token, in place of a multipart file.
+package resumable
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxChunkSize is the largest part accepted by Append; it stays below the nginx body limit
+const MaxChunkSize = 16 << 20
+
+const (
+	infoFile = "info.json"
+	dataFile = "data"
+)
+
+var (
+	ErrNotFound         = errors.New("upload session not found")
+	ErrOffsetMismatch   = errors.New("upload offset does not match the bytes received")
+	ErrTooLarge         = errors.New("upload exceeds the announced size")
+	ErrChunkTooLarge    = errors.New("upload chunk is too large")
+	ErrChecksumMismatch = errors.New("upload checksum does not match")
+	ErrIncomplete       = errors.New("upload is not complete")
+	ErrCompleted        = errors.New("upload is already complete")
+	ErrInvalidSize      = errors.New("upload size is required")
+	ErrInvalidChecksum  = errors.New("sha256 must be 64 hexadecimal characters")
+	ErrChecksumRequired = errors.New("sha256 is required to complete the upload")
+	ErrTooManySessions  = errors.New("too many open upload sessions; complete or cancel one first")
+)
+
+type Session struct {
+	Token     string    `json:"token"`
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"-"` // the length of the data file, so it survives restarts
+	SHA256    string    `json:"sha256,omitempty"`
+	Owner     string    `json:"owner"`
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Store struct {
+	dir        string
+	ttl        time.Duration
+	maxSize    int64
+	maxPerUser int
+
+	createMu sync.Mutex // counts and creates a user's sessions as one step
+	mu       sync.Mutex
+	locks    map[string]*sessionLock
+}
+
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+func NewStore(cfg Config) *Store {
+	return &Store{dir: cfg.Dir, ttl: cfg.TTL, maxSize: cfg.MaxSize, maxPerUser: cfg.MaxPerUser, locks: make(map[string]*sessionLock)}
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultStore *Store
+)
+
+// Default returns the store configured from the environment
+func Default() *Store {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultStore == nil {
+		defaultStore = NewStore(ConfigFromEnv())
+	}
+	return defaultStore
+}
+
+// MaxSize is the largest file a session may announce
+func (s *Store) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create starts a session for a file of size bytes; sum is its hex SHA-256 when the client knows it up front
+func (s *Store) Create(fileName string, size int64, sum, owner string) (Session, error) {
+	if size <= 0 {
+		return Session{}, ErrInvalidSize
+	}
+	if size > s.maxSize {
+		return Session{}, ErrTooLarge
+	}
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if sum != "" && !validSHA256(sum) {
+		return Session{}, ErrInvalidChecksum
+	}
+	s.Cleanup(time.Now())
+
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+	if s.maxPerUser > 0 && s.openSessions(owner) >= s.maxPerUser {
+		return Session{}, ErrTooManySessions
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return Session{}, err
+	}
+	now := time.Now()
+	session := Session{
+		Token:     hex.EncodeToString(random),
+		FileName:  filepath.Base(strings.ReplaceAll(fileName, "\\", "/")),
+		Size:      size,
+		SHA256:    sum,
+		Owner:     owner,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+
+	if err := os.MkdirAll(s.path(session.Token), 0o700); err != nil {
+		return Session{}, err
+	}
+	f, err := os.OpenFile(filepath.Join(s.path(session.Token), dataFile), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return Session{}, err
+	}
+	f.Close()
+	if err := s.writeInfo(session); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// Get returns a session that has not expired
+func (s *Store) Get(token string) (Session, error) {
+	if !validToken(token) {
+		return Session{}, ErrNotFound
+	}
+	b, err := os.ReadFile(filepath.Join(s.path(token), infoFile))
+	if err != nil {
+		return Session{}, ErrNotFound
+	}
+	var session Session
+	if err := json.Unmarshal(b, &session); err != nil {
+		return Session{}, fmt.Errorf("upload session %s: %w", token, err)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return Session{}, ErrNotFound
+	}
+	fi, err := os.Stat(filepath.Join(s.path(token), dataFile))
+	if err != nil {
+		return Session{}, ErrNotFound
+	}
+	session.Offset = fi.Size()
+	return session, nil
+}
+
+// Append writes a part at offset, which must be the number of bytes received so far. When sum (hex SHA-256
+// of the part) is given a damaged part is discarded, so the client can send it again from the same offset.
+func (s *Store) Append(token string, offset int64, r io.Reader, sum string) (Session, error) {
+	unlock := s.lock(token)
+	defer unlock()
+
+	session, err := s.Get(token)
+	if err != nil {
+		return Session{}, err
+	}
+	if session.Completed {
+		return session, ErrCompleted
+	}
+	if offset != session.Offset {
+		return session, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.path(token), dataFile), os.O_WRONLY, 0o600)
+	if err != nil {
+		return Session{}, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return Session{}, err
+	}
+
+	limit := min(int64(MaxChunkSize), session.Size-offset)
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, limit+1))
+	switch {
+	case err != nil:
+	case n > limit && limit < MaxChunkSize:
+		err = ErrTooLarge
+	case n > limit:
+		err = ErrChunkTooLarge
+	case sum != "" && !strings.EqualFold(sum, hex.EncodeToString(h.Sum(nil))):
+		err = ErrChecksumMismatch
+	}
+	if err != nil {
+		// drop the partial or rejected part so the offset stays where the client expects it
+		_ = f.Truncate(offset)
+		return session, err
+	}
+
+	session.Offset = offset + n
+	session.ExpiresAt = time.Now().Add(s.ttl)
+	return session, s.writeInfo(session)
+}
+
+// Complete checks every byte arrived and the file hashes to the SHA-256 announced at creation or given here
+func (s *Store) Complete(token, sum string) (Session, error) {
+	unlock := s.lock(token)
+	defer unlock()
+
+	session, err := s.Get(token)
+	if err != nil {
+		return Session{}, err
+	}
+	if session.Completed {
+		return session, nil
+	}
+	if session.Offset != session.Size {
+		return session, ErrIncomplete
+	}
+
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if sum == "" {
+		sum = session.SHA256
+	}
+	if sum == "" {
+		return session, ErrChecksumRequired
+	}
+	if !validSHA256(sum) {
+		return session, ErrInvalidChecksum
+	}
+	if session.SHA256 != "" && sum != session.SHA256 {
+		return session, ErrChecksumMismatch
+	}
+
+	f, err := os.Open(filepath.Join(s.path(token), dataFile))
+	if err != nil {
+		return Session{}, err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return Session{}, err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != sum {
+		return session, ErrChecksumMismatch
+	}
+
+	session.SHA256 = sum
+	session.Completed = true
+	session.ExpiresAt = time.Now().Add(s.ttl)
+	return session, s.writeInfo(session)
+}
+
+// Open returns the file of a completed session; the caller closes it and removes the session once stored
+func (s *Store) Open(token string) (*os.File, Session, error) {
+	session, err := s.Get(token)
+	if err != nil {
+		return nil, Session{}, err
+	}
+	if !session.Completed {
+		return nil, session, ErrIncomplete
+	}
+	f, err := os.Open(filepath.Join(s.path(token), dataFile))
+	if err != nil {
+		return nil, Session{}, err
+	}
+	return f, session, nil
+}
+
+// Remove deletes a session and its parts
+func (s *Store) Remove(token string) error {
+	if !validToken(token) {
+		return ErrNotFound
+	}
+	unlock := s.lock(token)
+	defer unlock()
+	return os.RemoveAll(s.path(token))
+}
+
+// Cleanup removes expired sessions and returns how many were removed
+func (s *Store) Cleanup(now time.Time) int {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || !validToken(e.Name()) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(s.dir, e.Name(), infoFile))
+		var session Session
+		if err == nil && json.Unmarshal(b, &session) == nil && now.Before(session.ExpiresAt) {
+			continue
+		}
+		// a session without readable info is only removed once it is older than the TTL
+		if info, err := e.Info(); err == nil && session.Token == "" && now.Sub(info.ModTime()) < s.ttl {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(s.dir, e.Name())) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// openSessions counts the sessions of owner that have not expired, completed ones included
+func (s *Store) openSessions(owner string) int {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0
+	}
+	now, open := time.Now(), 0
+	for _, e := range entries {
+		if !e.IsDir() || !validToken(e.Name()) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(s.dir, e.Name(), infoFile))
+		var session Session
+		if err == nil && json.Unmarshal(b, &session) == nil && session.Owner == owner && now.Before(session.ExpiresAt) {
+			open++
+		}
+	}
+	return open
+}
+
+func (s *Store) path(token string) string {
+	return filepath.Join(s.dir, token)
+}
+
+// writeInfo replaces info.json through a temporary file so a crash never leaves it half written
+func (s *Store) writeInfo(session Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(s.path(session.Token), infoFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.path(session.Token), infoFile))
+}
+
+// lock serialises the writes of one session within this process
+func (s *Store) lock(token string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[token]
+	if !ok {
+		l = &sessionLock{}
+		s.locks[token] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.locks, token)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func validToken(token string) bool {
+	if len(token) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
+
+func validSHA256(sum string) bool {
+	b, err := hex.DecodeString(sum)
+	return err == nil && len(b) == sha256.Size
+}
+
+// ParseChecksum reads a tus Upload-Checksum header ("sha256 <base64 digest>") into a hex SHA-256
+func ParseChecksum(header string) (string, error) {
+	algo, value, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(algo, "sha256") {
+		return "", ErrInvalidChecksum
+	}
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(digest) != sha256.Size {
+		return "", ErrInvalidChecksum
+	}
+	return hex.EncodeToString(digest), nil
+}
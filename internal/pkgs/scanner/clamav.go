@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// chunkSize is the length of each INSTREAM chunk
+const chunkSize = 64 * 1024
+
+// ClamAV streams files to clamd with the INSTREAM command
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	network, addr := "tcp", address
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		addr = strings.TrimPrefix(address, "tcp://")
+	}
+	return &ClamAV{network: network, address: addr, timeout: timeout}
+}
+
+func (s *ClamAV) Engine() string {
+	return DriverClamAV
+}
+
+// Scan sends the stream as length-prefixed chunks and parses the single-line reply,
+// e.g. "stream: OK" or "stream: Win.Test.EICAR_HDB-1 FOUND"
+func (s *ClamAV) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return Result{}, fmt.Errorf("scanner: connect clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, fmt.Errorf("scanner: send command: %w", err)
+	}
+
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return Result{}, s.writeError(conn, err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return Result{}, s.writeError(conn, err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return Result{}, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Result{}, s.writeError(conn, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Result{}, fmt.Errorf("scanner: read reply: %w", err)
+	}
+	return parseReply(reply)
+}
+
+// writeError prefers clamd's own reply, which explains why it closed the connection (e.g. size limit exceeded)
+func (s *ClamAV) writeError(conn net.Conn, err error) error {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if reply, _ := bufio.NewReader(conn).ReadString(0); strings.TrimSpace(strings.TrimRight(reply, "\x00")) != "" {
+		return fmt.Errorf("scanner: clamd: %s", strings.TrimSpace(strings.TrimRight(reply, "\x00")))
+	}
+	return fmt.Errorf("scanner: send stream: %w", err)
+}
+
+func parseReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	case reply == "":
+		return Result{}, errors.New("scanner: empty reply from clamd")
+	}
+	return Result{}, fmt.Errorf("scanner: clamd: %s", reply)
+}
@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// EICAR is the industry-standard antivirus test string; every real engine reports it as infected
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+const eicarSignature = "Eicar-Test-Signature"
+
+// Fake reports files containing the EICAR test string as infected and everything else as clean,
+// so the reject and quarantine paths can be exercised without a ClamAV daemon
+type Fake struct{}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (s *Fake) Engine() string {
+	return DriverFake
+}
+
+func (s *Fake) Scan(_ context.Context, r io.Reader) (Result, error) {
+	marker := []byte(EICAR)
+	buf := make([]byte, 32*1024)
+	var tail []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			// keep the end of the previous chunk so a marker split across reads is still found
+			window := append(tail, buf[:n]...)
+			if bytes.Contains(window, marker) {
+				return Result{Infected: true, Signature: eicarSignature}, nil
+			}
+			if len(window) > len(marker) {
+				window = window[len(window)-len(marker):]
+			}
+			tail = append(tail[:0], window...)
+		}
+		if err == io.EOF {
+			return Result{}, nil
+		}
+		if err != nil {
+			return Result{}, err
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package scanner screens uploaded files for malware, either with a ClamAV daemon (clamd) reached over
+// TCP or a unix socket, or with a fake scanner that only flags the EICAR test file, for local runs and tests.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Drivers selectable with UPLOAD_SCANNER
+const (
+	DriverNone   = ""
+	DriverClamAV = "clamav"
+	DriverFake   = "fake"
+)
+
+// Result is the verdict for one file
+type Result struct {
+	Infected  bool
+	Signature string // name of the detected malware, e.g. "Win.Test.EICAR_HDB-1"
+}
+
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+	// Engine names the scanner in the upload registry
+	Engine() string
+}
+
+type Config struct {
+	Driver  string
+	Address string // tcp://host:3310, host:3310 or unix:///run/clamav/clamd.sock
+	Timeout time.Duration
+}
+
+// ConfigFromEnv reads UPLOAD_SCANNER, CLAMAV_ADDRESS and CLAMAV_TIMEOUT_SECONDS passed by docker-compose
+func ConfigFromEnv() Config {
+	timeout, _ := strconv.Atoi(os.Getenv("CLAMAV_TIMEOUT_SECONDS"))
+	if timeout <= 0 {
+		timeout = 60
+	}
+	address := os.Getenv("CLAMAV_ADDRESS")
+	if address == "" {
+		address = "tcp://clamav:3310"
+	}
+
+	return Config{
+		Driver:  strings.ToLower(strings.TrimSpace(os.Getenv("UPLOAD_SCANNER"))),
+		Address: address,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// New returns the configured scanner, or nil when scanning is disabled
+func New(cfg Config) (Scanner, error) {
+	switch cfg.Driver {
+	case DriverNone:
+		return nil, nil
+	case DriverClamAV:
+		return NewClamAV(cfg.Address, cfg.Timeout), nil
+	case DriverFake:
+		return NewFake(), nil
+	}
+	return nil, fmt.Errorf("scanner: unknown driver %q", cfg.Driver)
+}
@@ -0,0 +1,265 @@
+// Package spreadsheet reads the rows of a small CSV or XLSX file, such as an import manifest.
+// Only cell values are read: formulas give their cached result and dates stay Excel serial numbers.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupported = errors.New("manifest must be a .csv or .xlsx file")
+	ErrNoSheet     = errors.New("workbook has no worksheet")
+)
+
+// Read returns the rows of the file named name; rows[i] is spreadsheet row i+1, so empty rows are kept.
+// An XLSX is read from its first worksheet.
+func Read(name string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return readCSV(data)
+	case ".xlsx":
+		return readXLSX(data)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\uFEFF"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r.ReadAll()
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := firstSheet(zr)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := sharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+	return sheetRows(sheet, shared)
+}
+
+// firstSheet finds the worksheet of the first tab through the workbook and its relationships
+func firstSheet(zr *zip.Reader) (*zip.File, error) {
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeFile(zr, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if err := decodeFile(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, ErrNoSheet
+	}
+
+	for _, rel := range rels.Rels {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		if f := zipFile(zr, target); f != nil {
+			return f, nil
+		}
+	}
+	return nil, ErrNoSheet
+}
+
+func sharedStrings(zr *zip.Reader) ([]string, error) {
+	f := zipFile(zr, "xl/sharedStrings.xml")
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var out []string
+	var b strings.Builder
+	inText, inPhonetic := false, false
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				b.Reset()
+			case "rPh":
+				inPhonetic = true
+			case "t":
+				inText = !inPhonetic
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, b.String())
+			case "rPh":
+				inPhonetic = false
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+func sheetRows(f *zip.File, shared []string) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	var cellType, cellRef string
+	var value strings.Builder
+	rowNo, colNo := 0, 0
+	inValue := false
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				if n, err := strconv.Atoi(attr(t, "r")); err == nil && n > rowNo {
+					rowNo = n
+				} else {
+					rowNo++
+				}
+				colNo = 0
+				for len(rows) < rowNo {
+					rows = append(rows, nil)
+				}
+			case "c":
+				cellType, cellRef = attr(t, "t"), attr(t, "r")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				if col := columnIndex(cellRef); col > 0 {
+					colNo = col
+				} else {
+					colNo++
+				}
+				v := value.String()
+				if cellType == "s" {
+					if idx, err := strconv.Atoi(v); err == nil && idx >= 0 && idx < len(shared) {
+						v = shared[idx]
+					}
+				}
+				if rowNo > 0 && v != "" {
+					row := rows[rowNo-1]
+					for len(row) < colNo {
+						row = append(row, "")
+					}
+					row[colNo-1] = v
+					rows[rowNo-1] = row
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// columnIndex turns the letters of a cell reference ("C12") into its 1-based column, or 0 when absent
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col
+}
+
+func decodeFile(zr *zip.Reader, name string, v interface{}) error {
+	f := zipFile(zr, name)
+	if f == nil {
+		return ErrNoSheet
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func zipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// BlobPrefix holds the content-addressed objects, stored as uploads/objects/<first two hex digits>/<sha256>
+const BlobPrefix = Root + "/objects/"
+
+// Link ties a logical key (the path stored in the database) to the SHA-256 of its content
+type Link struct {
+	Key        string
+	SHA256     string
+	Size       int64
+	Thumbnails bool // previews of the file have been generated
+	CreatedAt  time.Time
+}
+
+// Index records links and counts how many keys share each blob; implemented on the database
+type Index interface {
+	// LookupLink returns ErrNotExist for keys stored before content addressing
+	LookupLink(ctx context.Context, key string) (Link, error)
+	// LookupLinks returns the links of the keys found, by key, in one round trip
+	LookupLinks(ctx context.Context, keys []string) (map[string]Link, error)
+	// SetLinkThumbnails records whether the previews of key exist; unlinked keys are ignored
+	SetLinkThumbnails(ctx context.Context, key string, thumbnails bool) error
+	// CreateLink links key, replacing an existing link, and adds a reference to the blob
+	CreateLink(ctx context.Context, key, sha256 string, size int64, contentType string) error
+	// DeleteLink removes the link and its reference; returns ErrNotExist when key is not linked
+	DeleteLink(ctx context.Context, key string) error
+	WalkLinks(ctx context.Context, prefix string, fn func(Link) error) error
+}
+
+// ContentAddressed stores each distinct content once under its SHA-256 and keeps the logical keys in an
+// index, so the same file uploaded to several modules takes the space of one. Keys stored before content
+// addressing was enabled are still read from their own path. Blobs losing their last reference stay
+// until the orphan collector removes them.
+type ContentAddressed struct {
+	inner Storage
+	index Index
+}
+
+func NewContentAddressed(inner Storage, index Index) *ContentAddressed {
+	return &ContentAddressed{inner: inner, index: index}
+}
+
+// EnableContentAddressing wraps the default storage with index
+func EnableContentAddressing(index Index) {
+	inner := Default()
+	defaultMu.Lock()
+	defaultStorage = NewContentAddressed(inner, index)
+	defaultMu.Unlock()
+}
+
+// BlobKey is where the content with the given SHA-256 is stored
+func BlobKey(sum string) string {
+	if len(sum) < 2 {
+		return ""
+	}
+	return BlobPrefix + sum[:2] + "/" + sum
+}
+
+func (c *ContentAddressed) Driver() string {
+	return c.inner.Driver()
+}
+
+// Put hashes the content into a temporary file, stores the blob unless an intact copy exists, then links key
+func (c *ContentAddressed) Put(ctx context.Context, key string, r io.Reader, _ int64, contentType string) error {
+	k := CleanKey(key)
+	if k == "" || strings.HasPrefix(k, BlobPrefix) {
+		return ErrNotExist
+	}
+
+	tmp, err := os.CreateTemp("", "cas-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		return err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	blob := BlobKey(sum)
+
+	existing, err := c.inner.Stat(ctx, blob)
+	if err != nil && !IsNotExist(err) {
+		return err
+	}
+	// a missing or truncated blob is (re)written; an intact one is shared
+	if err != nil || existing.Size != size {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := c.inner.Put(ctx, blob, tmp, size, contentType); err != nil {
+			return err
+		}
+	}
+
+	if err := c.index.CreateLink(ctx, k, sum, size, contentType); err != nil {
+		return err
+	}
+	// the garbage collector may have removed an unreferenced copy between the check and the link
+	if _, err := c.inner.Stat(ctx, blob); IsNotExist(err) {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := c.inner.Put(ctx, blob, tmp, size, contentType); err != nil {
+			return err
+		}
+	}
+	// a file stored at the key itself before content addressing is now shadowed by the link
+	_ = c.inner.Delete(ctx, k)
+	return nil
+}
+
+func (c *ContentAddressed) Open(ctx context.Context, key string) (Object, Info, error) {
+	link, err := c.index.LookupLink(ctx, CleanKey(key))
+	if IsNotExist(err) {
+		return c.inner.Open(ctx, key)
+	}
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	obj, _, err := c.inner.Open(ctx, BlobKey(link.SHA256))
+	if err != nil {
+		return nil, Info{}, err
+	}
+	return obj, linkInfo(link), nil
+}
+
+// Stat answers from the index for linked keys; the integrity check verifies that blobs really exist
+func (c *ContentAddressed) Stat(ctx context.Context, key string) (Info, error) {
+	link, err := c.index.LookupLink(ctx, CleanKey(key))
+	if IsNotExist(err) {
+		return c.inner.Stat(ctx, key)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	return linkInfo(link), nil
+}
+
+func (c *ContentAddressed) Delete(ctx context.Context, key string) error {
+	err := c.index.DeleteLink(ctx, CleanKey(key))
+	if IsNotExist(err) {
+		return c.inner.Delete(ctx, key)
+	}
+	return err
+}
+
+// Walk lists linked keys, then files still stored at their own path; blobs are not listed
+func (c *ContentAddressed) Walk(ctx context.Context, prefix string, fn func(Info) error) error {
+	linked := make(map[string]bool)
+	err := c.index.WalkLinks(ctx, prefix, func(link Link) error {
+		linked[link.Key] = true
+		return fn(linkInfo(link))
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.inner.Walk(ctx, prefix, func(info Info) error {
+		if strings.HasPrefix(info.Key, BlobPrefix) || linked[info.Key] {
+			return nil
+		}
+		return fn(info)
+	})
+}
+
+// LookupLinks returns the links of keys in the default storage with a single index query, so listings
+// get the SHA-256 and preview state of a whole page at once. Keys stored before content addressing,
+// or any key when it is disabled, are missing from the result.
+func LookupLinks(ctx context.Context, keys []string) (map[string]Link, error) {
+	c, ok := Default().(*ContentAddressed)
+	if !ok || len(keys) == 0 {
+		return map[string]Link{}, nil
+	}
+	clean := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k = CleanKey(k); k != "" {
+			clean = append(clean, k)
+		}
+	}
+	return c.index.LookupLinks(ctx, clean)
+}
+
+// SetThumbnails records in the index whether the previews of key exist
+func SetThumbnails(ctx context.Context, key string, thumbnails bool) error {
+	c, ok := Default().(*ContentAddressed)
+	if !ok {
+		return nil
+	}
+	return c.index.SetLinkThumbnails(ctx, CleanKey(key), thumbnails)
+}
+
+// Inner returns the storage holding the blobs
+func (c *ContentAddressed) Inner() Storage {
+	return c.inner
+}
+
+func linkInfo(link Link) Info {
+	return Info{Key: link.Key, Size: link.Size, ModTime: link.CreatedAt, SHA256: link.SHA256, Thumbnails: link.Thumbnails}
+}
+
+// HashObject re-reads an object and returns its SHA-256 and size
+func HashObject(ctx context.Context, s Storage, key string) (string, int64, error) {
+	obj, _, err := s.Open(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer obj.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, obj)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// ErrNotContentAddressed is returned by tools that need the content-addressed storage
+var ErrNotContentAddressed = errors.New("storage: content addressing is not enabled")
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"os"
+	"strings"
+)
+
+type Config struct {
+	Driver    string
+	LocalRoot string
+	S3        S3Config
+}
+
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// ConfigFromEnv reads STORAGE_DRIVER (local or s3), STORAGE_LOCAL_ROOT and the S3_* variables passed by docker-compose
+func ConfigFromEnv() Config {
+	root := os.Getenv("STORAGE_LOCAL_ROOT")
+	if root == "" {
+		root = "."
+	}
+
+	return Config{
+		Driver:    strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_DRIVER"))),
+		LocalRoot: root,
+		S3: S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			// TLS unless explicitly disabled, e.g. for a local MinIO container
+			UseSSL: os.Getenv("S3_USE_SSL") != "false",
+		},
+	}
+}
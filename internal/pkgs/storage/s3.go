@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	s3ConnectTimeout = 10 * time.Second
+	s3StreamPartSize = 16 << 20
+)
+
+// S3 stores objects in an S3-compatible bucket under the same keys as local storage
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 connects to the endpoint and creates the bucket when it does not exist yet
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: S3_ENDPOINT and S3_BUCKET are required for the s3 driver")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: S3 client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3ConnectTimeout)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("storage: S3 bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("storage: create S3 bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *S3) Driver() string {
+	return DriverS3
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	k := CleanKey(key)
+	if k == "" {
+		return ErrNotExist
+	}
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if size < 0 {
+		// without a size the client would buffer parts sized for a 5 TiB object
+		opts.PartSize = s3StreamPartSize
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, k, r, size, opts)
+	return err
+}
+
+// Open returns a lazily fetched object; each Seek followed by Read issues a ranged GET
+func (s *S3) Open(ctx context.Context, key string) (Object, Info, error) {
+	k := CleanKey(key)
+	if k == "" {
+		return nil, Info{}, ErrNotExist
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, k, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, Info{}, s3Error(err)
+	}
+	oi, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, Info{}, s3Error(err)
+	}
+	return obj, Info{Key: k, Size: oi.Size, ModTime: oi.LastModified}, nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (Info, error) {
+	k := CleanKey(key)
+	if k == "" {
+		return Info{}, ErrNotExist
+	}
+	oi, err := s.client.StatObject(ctx, s.bucket, k, minio.StatObjectOptions{})
+	if err != nil {
+		return Info{}, s3Error(err)
+	}
+	return Info{Key: k, Size: oi.Size, ModTime: oi.LastModified}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	k := CleanKey(key)
+	if k == "" {
+		return nil
+	}
+	return s3Error(s.client.RemoveObject(ctx, s.bucket, k, minio.RemoveObjectOptions{}))
+}
+
+func (s *S3) Walk(ctx context.Context, prefix string, fn func(Info) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for oi := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if oi.Err != nil {
+			return oi.Err
+		}
+		if err := fn(Info{Key: oi.Key, Size: oi.Size, ModTime: oi.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// s3Error maps missing keys to ErrNotExist so callers can treat both drivers alike
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NotFound":
+		return fmt.Errorf("%w: %v", ErrNotExist, err)
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+// Package storage keeps uploaded files behind one interface, so the API can store them on a local
+// volume or in S3-compatible object storage (MinIO, AWS S3) and run more than one replica.
+//
+// Keys are the slash-separated paths stored in the database, e.g. "uploads/company_news/x.jpg".
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Drivers selectable with STORAGE_DRIVER
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Root is the key prefix every uploaded file lives under
+const Root = "uploads"
+
+// ErrNotExist is returned for missing objects; it matches fs.ErrNotExist with errors.Is
+var ErrNotExist = fs.ErrNotExist
+
+// Info describes a stored object
+type Info struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+	SHA256  string // set for content-addressed keys
+	// Thumbnails tells that previews were generated; only known for content-addressed keys
+	Thumbnails bool
+}
+
+// Object is an open stored file; Seek lets callers serve byte ranges and sniff content types
+type Object interface {
+	io.ReadSeekCloser
+}
+
+type Storage interface {
+	// Put stores size bytes from r under key, replacing any existing object. size may be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (Object, Info, error)
+	Stat(ctx context.Context, key string) (Info, error)
+	// Delete removes key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// Walk calls fn for every object whose key starts with prefix
+	Walk(ctx context.Context, prefix string, fn func(Info) error) error
+	Driver() string
+}
+
+// CleanKey turns a stored path ("./uploads\x.pdf", "/uploads/x.pdf", "uploads/x.pdf") into its key.
+// Paths outside uploads/ or escaping it with ".." return "".
+func CleanKey(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+	p = path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))
+	if !strings.HasPrefix(p, "/"+Root+"/") {
+		return ""
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultStorage Storage
+)
+
+// Init selects the storage used by Default
+func Init(cfg Config) error {
+	s, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultStorage = s
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default returns the storage chosen by Init, or the local working directory when Init was not called
+func Default() Storage {
+	defaultMu.RLock()
+	s := defaultStorage
+	defaultMu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultStorage == nil {
+		defaultStorage = NewLocal(".")
+	}
+	return defaultStorage
+}
+
+// New builds the storage selected by cfg.Driver
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.LocalRoot), nil
+	case DriverS3:
+		return NewS3(cfg.S3)
+	}
+	return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+}
+
+// LocalPath returns a filesystem path holding the object, for tools that need one (pdftoppm,
+// zip.OpenReader). Local storage returns the file itself; other drivers download a temporary copy.
+// Always call cleanup when done.
+func LocalPath(ctx context.Context, s Storage, key string) (string, func(), error) {
+	if c, ok := s.(*ContentAddressed); ok {
+		link, err := c.index.LookupLink(ctx, CleanKey(key))
+		if err == nil {
+			return LocalPath(ctx, c.inner, BlobKey(link.SHA256))
+		}
+		if !IsNotExist(err) {
+			return "", func() {}, err
+		}
+		return LocalPath(ctx, c.inner, key)
+	}
+	if l, ok := s.(*Local); ok {
+		p, err := l.path(key)
+		if err != nil {
+			return "", func() {}, err
+		}
+		if _, err := os.Stat(p); err != nil {
+			return "", func() {}, err
+		}
+		return p, func() {}, nil
+	}
+
+	obj, _, err := s.Open(ctx, key)
+	if err != nil {
+		return "", func() {}, err
+	}
+	defer obj.Close()
+
+	tmp, err := os.CreateTemp("", "storage-*"+filepath.Ext(key))
+	if err != nil {
+		return "", func() {}, err
+	}
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+	if _, err := io.Copy(tmp, obj); err != nil {
+		tmp.Close()
+		cleanup()
+		return "", func() {}, err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+// Copy copies key from src to dst
+func Copy(ctx context.Context, src, dst Storage, key, contentType string) error {
+	obj, info, err := src.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	return dst.Put(ctx, key, obj, info.Size, contentType)
+}
+
+// IsNotExist reports whether err means the object is missing
+func IsNotExist(err error) bool {
+	return errors.Is(err, ErrNotExist)
+}
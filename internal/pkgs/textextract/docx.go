@@ -0,0 +1,98 @@
+package textextract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// extractDOCX reads word/document.xml. Word does not store page numbers, so pages are split at
+// explicit page breaks and at the page breaks Word recorded when the file was last saved.
+func extractDOCX(path string) ([]Page, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	f := zipFile(&zr.Reader, "word/document.xml")
+	if f == nil {
+		return nil, ErrUnsupported
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var pages []Page
+	var b strings.Builder
+	inText := false
+	newPage := func() {
+		pages = append(pages, Page{Number: len(pages) + 1, Text: b.String()})
+		b.Reset()
+	}
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString(" ")
+			case "br", "cr":
+				if attr(t, "type") == "page" {
+					newPage()
+				} else {
+					b.WriteString("\n")
+				}
+			case "lastRenderedPageBreak":
+				newPage()
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			case "tc":
+				b.WriteString(" ")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	newPage()
+	return pages, nil
+}
+
+func zipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func attr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
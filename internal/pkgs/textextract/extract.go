@@ -0,0 +1,96 @@
+// Package textextract pulls plain text out of uploaded PDF, DOCX and XLSX files, page by page,
+// without calling any external service.
+package textextract
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPageRunes caps the text stored per page; longer pages are cut
+const MaxPageRunes = 32000
+
+// maxPages stops extraction of very large files
+const maxPages = 2000
+
+// ErrUnsupported is returned for file types without an extractor
+var ErrUnsupported = errors.New("textextract: unsupported file type")
+
+// Page is the text of one PDF page, one DOCX page (split at page breaks) or one XLSX sheet
+type Page struct {
+	Number int
+	Text   string
+}
+
+// Supported reports whether the file extension has an extractor
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf", ".docx", ".xlsx":
+		return true
+	}
+	return false
+}
+
+// Extract returns the non-empty pages of the file. Malformed files return an error instead of panicking.
+func Extract(path string) ([]Page, error) {
+	return ExtractAs(path, filepath.Ext(path))
+}
+
+// ExtractAs reads path as a file of type ext (".pdf", ".docx", ".xlsx"), for copies stored without an extension
+func ExtractAs(path, ext string) (pages []Page, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pages, err = nil, fmt.Errorf("textextract: %s: %v", filepath.Base(path), r)
+		}
+	}()
+
+	switch strings.ToLower(ext) {
+	case ".pdf":
+		pages, err = extractPDF(path)
+	case ".docx":
+		pages, err = extractDOCX(path)
+	case ".xlsx":
+		pages, err = extractXLSX(path)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cleanPages(pages), nil
+}
+
+// cleanPages collapses whitespace, drops empty pages and cuts oversized ones
+func cleanPages(pages []Page) []Page {
+	out := make([]Page, 0, len(pages))
+	for _, p := range pages {
+		text := normalizeText(p.Text)
+		if text == "" {
+			continue
+		}
+		if utf8.RuneCountInString(text) > MaxPageRunes {
+			text = string([]rune(text)[:MaxPageRunes])
+		}
+		out = append(out, Page{Number: p.Number, Text: text})
+		if len(out) >= maxPages {
+			break
+		}
+	}
+	return out
+}
+
+// normalizeText keeps line breaks but collapses runs of spaces and blank lines
+func normalizeText(s string) string {
+	s = strings.ToValidUTF8(strings.ReplaceAll(s, "\x00", ""), "")
+	lines := strings.Split(strings.ReplaceAll(s, "\r", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
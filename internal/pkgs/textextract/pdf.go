@@ -0,0 +1,48 @@
+package textextract
+
+import (
+	"os"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func extractPDF(path string) ([]Page, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := pdf.NewReader(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []Page
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= r.NumPage() && i <= maxPages; i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		for _, name := range p.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := p.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := p.GetPlainText(fonts)
+		if err != nil {
+			// A broken page should not hide the rest of the document
+			continue
+		}
+		pages = append(pages, Page{Number: i, Text: text})
+	}
+	return pages, nil
+}
@@ -0,0 +1,154 @@
+package textextract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var sheetFilePattern = regexp.MustCompile(`^xl/worksheets/sheet(\d+)\.xml$`)
+
+// extractXLSX returns one page per worksheet, in sheet file order, with cells separated by tabs
+func extractXLSX(path string) ([]Page, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	shared, err := sharedStrings(&zr.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	type sheet struct {
+		no   int
+		file *zip.File
+	}
+	var sheets []sheet
+	for _, f := range zr.File {
+		if m := sheetFilePattern.FindStringSubmatch(f.Name); m != nil {
+			no, _ := strconv.Atoi(m[1])
+			sheets = append(sheets, sheet{no: no, file: f})
+		}
+	}
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].no < sheets[j].no })
+
+	pages := make([]Page, 0, len(sheets))
+	for i, s := range sheets {
+		text, err := sheetText(s.file, shared)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, Page{Number: i + 1, Text: text})
+	}
+	return pages, nil
+}
+
+func sharedStrings(zr *zip.Reader) ([]string, error) {
+	f := zipFile(zr, "xl/sharedStrings.xml")
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var out []string
+	var b strings.Builder
+	inText := false
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				b.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, b.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+func sheetText(f *zip.File, shared []string) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var b strings.Builder
+	var cellType string
+	var value strings.Builder
+	inValue := false
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "c":
+				cellType = attr(t, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := value.String()
+				if cellType == "s" {
+					if idx, err := strconv.Atoi(v); err == nil && idx >= 0 && idx < len(shared) {
+						v = shared[idx]
+					}
+				}
+				if v != "" {
+					b.WriteString(v)
+					b.WriteString("\t")
+				}
+			case "row":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Package thumbnail renders resized previews of uploaded images, and of the first page of PDFs when
+// poppler's pdftoppm is installed, and stores them next to the original as <original>.thumb-<width>.jpg
+// in the configured storage; paths are storage keys.
+// Previews are JPEG: x/image decodes WebP uploads but has no WebP encoder.
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"backend/internal/pkgs/storage"
+)
+
+// Size is one generated preview width; height follows the aspect ratio
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Sizes are generated for every supported upload
+var Sizes = []Size{
+	{Name: "small", Width: 160},
+	{Name: "medium", Width: 320},
+	{Name: "large", Width: 640},
+}
+
+const (
+	suffix      = ".thumb-"
+	ext         = ".jpg"
+	jpegQuality = 82
+
+	// maxSourcePixels refuses images that would need too much memory to decode
+	maxSourcePixels = 60_000_000
+	pdfTimeout      = 30 * time.Second
+)
+
+// ErrUnsupported is returned for files without a preview renderer
+var ErrUnsupported = errors.New("thumbnail: unsupported file type")
+
+// Supported reports whether previews can be generated for the file
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	case ".pdf":
+		return pdftoppmAvailable()
+	}
+	return false
+}
+
+// Path returns where the preview of original at width is stored
+func Path(original string, width int) string {
+	return original + suffix + strconv.Itoa(width) + ext
+}
+
+// Original returns the original file name of a preview name, or false when name is not a preview
+func Original(name string) (string, bool) {
+	if !strings.HasSuffix(name, ext) {
+		return "", false
+	}
+	i := strings.LastIndex(name, suffix)
+	if i <= 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(name[i+len(suffix) : len(name)-len(ext)]); err != nil {
+		return "", false
+	}
+	return name[:i], true
+}
+
+// Generate writes every size for the file, replacing existing previews.
+// Malformed files return an error instead of panicking.
+func Generate(path string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("thumbnail: %s: %v", filepath.Base(path), r)
+		}
+	}()
+
+	ctx := context.Background()
+	store := storage.Default()
+
+	var src image.Image
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		src, err = decodeStoredImage(ctx, store, path)
+	case ".pdf":
+		src, err = renderPDFPage(ctx, store, path)
+	default:
+		return ErrUnsupported
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, size := range Sizes {
+		if err := writeJPEG(ctx, store, Path(path, size.Width), resize(src, size.Width)); err != nil {
+			return err
+		}
+	}
+	// listings read this flag instead of looking up every preview
+	return storage.SetThumbnails(ctx, path, true)
+}
+
+// Exists reports whether every preview of the file has been generated
+func Exists(path string) bool {
+	store := storage.Default()
+	for _, size := range Sizes {
+		if _, err := store.Stat(context.Background(), Path(path, size.Width)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes the previews of the file
+func Remove(path string) {
+	store := storage.Default()
+	for _, size := range Sizes {
+		_ = store.Delete(context.Background(), Path(path, size.Width))
+	}
+	_ = storage.SetThumbnails(context.Background(), path, false)
+}
+
+func decodeStoredImage(ctx context.Context, store storage.Storage, path string) (image.Image, error) {
+	obj, _, err := store.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return decodeImage(obj, path)
+}
+
+func decodeFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decodeImage(f, path)
+}
+
+func decodeImage(f io.ReadSeeker, path string) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("thumbnail: %s is too large (%dx%d)", filepath.Base(path), cfg.Width, cfg.Height)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// renderPDFPage rasterises the first page with pdftoppm at the largest preview width
+func renderPDFPage(ctx context.Context, store storage.Storage, key string) (image.Image, error) {
+	if !pdftoppmAvailable() {
+		return nil, ErrUnsupported
+	}
+
+	path, cleanup, err := storage.LocalPath(ctx, store, key)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	dir, err := os.MkdirTemp("", "thumbnail-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+
+	maxWidth := Sizes[len(Sizes)-1].Width
+	out := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-f", "1", "-l", "1", "-singlefile", "-jpeg",
+		"-scale-to-x", strconv.Itoa(maxWidth), "-scale-to-y", "-1", path, out)
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("thumbnail: pdftoppm %s: %v: %s", filepath.Base(key), err, strings.TrimSpace(string(msg)))
+	}
+	return decodeFile(out + ".jpg")
+}
+
+func pdftoppmAvailable() bool {
+	_, err := exec.LookPath("pdftoppm")
+	return err == nil
+}
+
+// resize scales src to width (never enlarging) onto a white background, since JPEG has no transparency
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	if b.Dx() < width {
+		width = b.Dx()
+	}
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+// writeJPEG stores the encoded preview; local storage writes through a temporary file so readers
+// never see a half-written preview
+func writeJPEG(ctx context.Context, store storage.Storage, path string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return err
+	}
+	return store.Put(ctx, path, &buf, int64(buf.Len()), "image/jpeg")
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Keys used to expose the signed-in employee on fiber.Ctx locals
+const (
+	LocalEmpCode  = "emp_code"
+	LocalUsername = "username"
+	LocalRole     = "role"
+)
+
+// ParseBearerClaims validates the "Authorization: Bearer <jwt>" header issued by SignInEmployee.
+// It returns nil claims (and no error) when the header is absent.
+func ParseBearerClaims(c *fiber.Ctx) (jwt.MapClaims, error) {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		return nil, nil
+	}
+
+	if len(authHeader) <= 7 || authHeader[:7] != "Bearer " {
+		return nil, errors.New("invalid authorization header")
+	}
+
+	token, err := jwt.Parse(authHeader[7:], func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid token")
+		}
+		return []byte(os.Getenv("TOKEN_SECRET_KEY")), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// SetAuthLocals stores the employee claims on the request context
+func SetAuthLocals(c *fiber.Ctx, claims jwt.MapClaims) {
+	if v, ok := claims["user_id"].(string); ok {
+		c.Locals(LocalEmpCode, v)
+	}
+	if v, ok := claims["username"].(string); ok {
+		c.Locals(LocalUsername, v)
+	}
+	if v, ok := claims["role"].(string); ok {
+		c.Locals(LocalRole, strings.ToUpper(v))
+	}
+}
+
+// AuthEmpCode returns the employee code of the signed-in user, or "" for anonymous requests
+func AuthEmpCode(c *fiber.Ctx) string {
+	v, _ := c.Locals(LocalEmpCode).(string)
+	return v
+}
+
+// AuthUsername returns the AD logon of the signed-in user, or ""
+func AuthUsername(c *fiber.Ctx) string {
+	v, _ := c.Locals(LocalUsername).(string)
+	return v
+}
+
+// AuthRole returns the upper-cased role of the signed-in user, or ""
+func AuthRole(c *fiber.Ctx) string {
+	v, _ := c.Locals(LocalRole).(string)
+	return v
+}
+
+// HasAnyRole reports whether role is one of roles (case-insensitive)
+func HasAnyRole(role string, roles ...string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(role, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PublicBaseURL returns PUBLIC_BASE_URL when set (the address behind nginx), otherwise the request base URL
+func PublicBaseURL(c *fiber.Ctx) string {
+	if base := os.Getenv("PUBLIC_BASE_URL"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return c.BaseURL()
+}
+
+// FileURL builds the absolute /api/file/get-file URL for a path returned by UploadFromForm.
+// It also accepts the JSON array form (["uploads/..."]) stored by the document modules.
+func FileURL(baseURL, relPath string) string {
+	relPath = strings.TrimSpace(relPath)
+	if strings.HasPrefix(relPath, "[") {
+		var paths []string
+		if err := json.Unmarshal([]byte(relPath), &paths); err != nil || len(paths) == 0 {
+			return ""
+		}
+		relPath = paths[0]
+	}
+	if relPath == "" {
+		return ""
+	}
+	if strings.HasPrefix(relPath, "http://") || strings.HasPrefix(relPath, "https://") {
+		return relPath
+	}
+
+	folder, name := SplitUploadPath(relPath)
+
+	q := url.Values{}
+	q.Set("folder", folder)
+	q.Set("filename", name)
+	return strings.TrimRight(baseURL, "/") + "/api/file/get-file?" + q.Encode()
+}
+
+// SplitUploadPath splits a stored upload path (uploads/<folder>/<name>) into the get-file folder and file name
+func SplitUploadPath(relPath string) (folder, name string) {
+	p := strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(relPath), "\\", "/"), "./")
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "/"), "uploads/")
+	folder, name = path.Split(p)
+	return strings.TrimSuffix(folder, "/"), name
+}
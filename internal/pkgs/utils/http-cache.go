@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NotModified evaluates If-None-Match and If-Modified-Since against the current validators (RFC 7232).
+// If-None-Match takes precedence; fiber's Ctx.Fresh does not compare the If-Modified-Since date.
+func NotModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if strings.Contains(strings.ToLower(c.Get(fiber.HeaderCacheControl)), "no-cache") {
+		return false
+	}
+
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			return true
+		}
+	}
+	return false
+}
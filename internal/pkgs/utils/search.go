@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchCollation makes LIKE case- and accent-insensitive on SQL Server, including Thai text
+const SearchCollation = "Thai_100_CI_AI"
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// SearchTerms splits a keyword into distinct whitespace-separated terms.
+// Thai has no spaces between words, so a Thai phrase stays a single term.
+func SearchTerms(keyword string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	for _, t := range strings.Fields(keyword) {
+		key := strings.ToLower(t)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// LikeContains returns a SQL Server LIKE pattern matching s anywhere, with wildcards in s escaped
+func LikeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// LikePrefix returns a SQL Server LIKE pattern matching values starting with s
+func LikePrefix(s string) string {
+	return likeEscaper.Replace(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer("[", "[[]", "%", "[%]", "_", "[_]")
+
+// PlainText strips HTML tags and entities from rich-text content and collapses whitespace
+func PlainText(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Highlight HTML-escapes text and wraps every occurrence of terms in <mark>, ignoring case and
+// accents the same way SearchCollation does
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	marks := matchMask(runes, terms)
+
+	var b strings.Builder
+	open := false
+	for i, r := range runes {
+		if marks[i] && !open {
+			b.WriteString("<mark>")
+			open = true
+		} else if !marks[i] && open {
+			b.WriteString("</mark>")
+			open = false
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+// Snippet cuts a window of about width runes around the first matching term and highlights it
+func Snippet(text string, terms []string, width int) string {
+	runes := []rune(text)
+	if width <= 0 || len(runes) <= width {
+		return Highlight(text, terms)
+	}
+
+	first := -1
+	marks := matchMask(runes, terms)
+	for i, m := range marks {
+		if m {
+			first = i
+			break
+		}
+	}
+
+	start := 0
+	if first > width/3 {
+		start = first - width/3
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+		start = end - width
+	}
+
+	out := Highlight(string(runes[start:end]), terms)
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// matchMask flags the runes of text covered by any of terms, ignoring case and accents
+func matchMask(text []rune, terms []string) []bool {
+	marks := make([]bool, len(text))
+
+	// folded holds the base letters of text; origin maps each back to its rune in text
+	var folded []rune
+	var origin []int
+	for i, r := range text {
+		for _, f := range foldRune(r) {
+			folded = append(folded, f)
+			origin = append(origin, i)
+		}
+	}
+
+	for _, term := range terms {
+		var t []rune
+		for _, r := range term {
+			t = append(t, foldRune(r)...)
+		}
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(folded); i++ {
+			if !equalRunes(folded[i:i+len(t)], t) {
+				continue
+			}
+			start, end := origin[i], origin[i+len(t)-1]+1
+			// keep the tone marks and accents that follow the last matched letter
+			for end < len(text) && len(foldRune(text[end])) == 0 {
+				end++
+			}
+			for j := start; j < end; j++ {
+				marks[j] = true
+			}
+		}
+	}
+	return marks
+}
+
+// foldRune lower-cases r and drops its accents, so "É" becomes "e" and a Thai tone mark becomes
+// nothing. Thai vowels written above or below a consonant are letters, not accents, and are kept.
+func foldRune(r rune) []rune {
+	var out []rune
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) && !isThaiVowelMark(d) {
+			continue
+		}
+		out = append(out, unicode.ToLower(d))
+	}
+	return out
+}
+
+func isThaiVowelMark(r rune) bool {
+	return r == '\u0E31' || (r >= '\u0E34' && r <= '\u0E3A')
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/pkgs/storage"
+)
+
+// ServeFile streams a stored file with byte-range support (RFC 7233), a strong ETag and Last-Modified for
+// conditional GETs, and a Content-Disposition that keeps a non-ASCII download name (RFC 6266/5987).
+// key is the storage key (uploads/...); download selects "attachment" over inline preview; name defaults to the file's base name.
+func ServeFile(c *fiber.Ctx, key, name string, download bool) error {
+	f, info, err := storage.Default().Open(c.UserContext(), key)
+	if err != nil {
+		return err
+	}
+
+	size := info.Size
+	modTime := info.ModTime
+	etag := fileETag(info)
+	if name == "" {
+		name = path.Base(key)
+	}
+
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	// responses depend on the caller's permissions, so only the browser may keep a copy and it must revalidate
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	if NotModified(c, etag, modTime) {
+		f.Close()
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	contentType, err := fileContentType(f, key)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, ContentDisposition(name, download))
+
+	if c.Get(fiber.HeaderRange) != "" && ifRangeMatches(c, etag, modTime) {
+		ranges, err := c.Range(int(size))
+		switch {
+		case errors.Is(err, fiber.ErrRangeUnsatisfiable):
+			f.Close()
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", size))
+			return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+		// multiple ranges are answered with the whole file, which RFC 7233 allows
+		case err == nil && ranges.Type == "bytes" && len(ranges.Ranges) == 1:
+			start, end := int64(ranges.Ranges[0].Start), int64(ranges.Ranges[0].End)
+			if _, err := f.Seek(start, io.SeekStart); err != nil {
+				f.Close()
+				return err
+			}
+			length := end - start + 1
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+			c.Status(fiber.StatusPartialContent)
+			c.Context().SetBodyStream(&limitedFile{Reader: io.LimitReader(f, length), Closer: f}, int(length))
+			return nil
+		}
+	}
+
+	c.Status(fiber.StatusOK)
+	c.Context().SetBodyStream(f, int(size))
+	return nil
+}
+
+// ContentDisposition builds an inline or attachment header with an ASCII fallback and the UTF-8 filename*
+func ContentDisposition(name string, download bool) string {
+	disposition := "inline"
+	if download {
+		disposition = "attachment"
+	}
+
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '/' {
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback, rfc5987Escape(name))
+}
+
+// rfc5987Escape percent-encodes every byte outside attr-char
+func rfc5987Escape(s string) string {
+	const attrChar = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || strings.IndexByte(attrChar, ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+// fileETag is strong because uploads are never rewritten in place: a new upload gets a new name or mtime.
+// Content-addressed files use their SHA-256, which stays the same across replicas and re-uploads.
+func fileETag(info storage.Info) string {
+	if info.SHA256 != "" {
+		return `"` + info.SHA256 + `"`
+	}
+	return fmt.Sprintf(`"%x-%x"`, info.Size, info.ModTime.UnixNano())
+}
+
+// ifRangeMatches reports whether a Range request may be honoured: without If-Range, or when it names the
+// current strong ETag or the exact Last-Modified date
+func ifRangeMatches(c *fiber.Ctx, etag string, modTime time.Time) bool {
+	ifRange := strings.TrimSpace(c.Get(fiber.HeaderIfRange))
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == etag
+	}
+	t, err := http.ParseTime(ifRange)
+	return err == nil && modTime.UTC().Truncate(time.Second).Equal(t)
+}
+
+// fileContentType resolves the type by extension, sniffing the first bytes when the extension is unknown
+func fileContentType(f io.ReadSeeker, filePath string) (string, error) {
+	if ct := mime.TypeByExtension(strings.ToLower(filepath.Ext(filePath))); ct != "" {
+		return ct, nil
+	}
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// limitedFile closes the file once fasthttp has sent the range
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
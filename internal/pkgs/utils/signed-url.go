@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+)
+
+// fileURLSecret signs file URLs; FILE_URL_SECRET falls back to the JWT secret
+func fileURLSecret() []byte {
+	if secret := os.Getenv("FILE_URL_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte(os.Getenv("TOKEN_SECRET_KEY"))
+}
+
+// SignFileKey returns the hex HMAC-SHA256 signature of a file key valid until expires (unix seconds)
+func SignFileKey(key string, expires int64) string {
+	mac := hmac.New(sha256.New, fileURLSecret())
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyFileSignature reports whether signature was issued for key and has not expired yet
+func VerifyFileSignature(key string, expires int64, signature string) bool {
+	if signature == "" || time.Now().Unix() > expires {
+		return false
+	}
+	expected, err := hex.DecodeString(SignFileKey(key, expires))
+	if err != nil {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, got)
+}
@@ -1,18 +1,27 @@
 package utils
 
 import (
+	"archive/zip"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"io"
+	"log"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"backend/internal/pkgs/resumable"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/thumbnail"
 )
 
 type Options struct {
@@ -23,31 +32,125 @@ type Options struct {
 	Required     bool
 }
 
+// UploadTokenSuffix names the form value carrying a completed resumable upload in place of a file:
+// a request may send file_upload_token instead of the multipart field file
+const UploadTokenSuffix = "_upload_token"
+
 func UploadFromForm(c *fiber.Ctx, formField string, opt Options) (string, string, error) {
 	fileHeader, err := c.FormFile(formField)
 	if err != nil || fileHeader == nil {
+		if token := c.FormValue(formField + UploadTokenSuffix); token != "" {
+			return SaveUploadSession(c, token, opt)
+		}
 		if opt.Required {
 			return "", "", errors.New("file is required")
 		}
 		return "", "", nil
 	}
 
-	if opt.MaxSize > 0 && fileHeader.Size > opt.MaxSize {
-		return "", "", errors.New("file too large")
+	return SaveFormFile(c, fileHeader, opt)
+}
+
+// SaveFormFile validates and stores one multipart file with the same rules as UploadFromForm.
+// Use it for fields that carry several files.
+func SaveFormFile(c *fiber.Ctx, fileHeader *multipart.FileHeader, opt Options) (string, string, error) {
+	return saveUpload(c, formFileSource(fileHeader), opt)
+}
+
+// SaveUploadSession stores the file of a completed resumable upload with the same rules as UploadFromForm
+// and removes the session. Only the user who created the session may use its token.
+func SaveUploadSession(c *fiber.Ctx, token string, opt Options) (string, string, error) {
+	store := resumable.Default()
+	session, err := ownUploadSession(c, token)
+	if err != nil {
+		return "", "", err
 	}
 
-	src, err := fileHeader.Open()
+	src := uploadSource{
+		name: session.FileName,
+		size: session.Size,
+		open: func() (io.ReadCloser, error) {
+			f, _, err := store.Open(token)
+			return f, err
+		},
+	}
+	relPath, publicURL, err := saveUpload(c, src, opt)
 	if err != nil {
 		return "", "", err
 	}
-	defer src.Close()
+	if err := store.Remove(token); err != nil {
+		log.Printf("[SaveUploadSession] Failed to remove session %s: %v\n", token, err)
+	}
+	return relPath, publicURL, nil
+}
 
-	buf := make([]byte, 512)
-	n, _ := io.ReadFull(src, buf)
-	detected := http.DetectContentType(buf[:n])
+// OpenUploadSession opens the completed resumable upload of the caller in place, for files that are read
+// rather than stored, such as an archive to unpack. The caller closes the file and removes the session.
+func OpenUploadSession(c *fiber.Ctx, token string) (*os.File, resumable.Session, error) {
+	if _, err := ownUploadSession(c, token); err != nil {
+		return nil, resumable.Session{}, err
+	}
+	return resumable.Default().Open(token)
+}
 
-	if _, err := src.Seek(0, 0); err != nil {
-		return "", "", err
+// ownUploadSession returns the completed session behind token when the caller created it
+func ownUploadSession(c *fiber.Ctx, token string) (resumable.Session, error) {
+	if AuthUsername(c) == "" {
+		return resumable.Session{}, errors.New("sign in to use an upload token")
+	}
+	session, err := resumable.Default().Get(token)
+	if err != nil || session.Owner != AuthUsername(c) {
+		return resumable.Session{}, errors.New("invalid upload token")
+	}
+	if !session.Completed {
+		return resumable.Session{}, resumable.ErrIncomplete
+	}
+	return session, nil
+}
+
+// CheckZipFile applies the size and type rules of opt to a file inside an archive without storing it
+func CheckZipFile(f *zip.File, opt Options) error {
+	_, err := checkUpload(zipFileSource(f), opt)
+	return err
+}
+
+// SaveZipFile validates and stores a file inside an archive with the same rules as UploadFromForm
+func SaveZipFile(c *fiber.Ctx, f *zip.File, opt Options) (string, string, error) {
+	return saveUpload(c, zipFileSource(f), opt)
+}
+
+// uploadSource is a file to store: a multipart file, the file of a resumable upload or a file inside an archive
+type uploadSource struct {
+	name string
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+func formFileSource(fileHeader *multipart.FileHeader) uploadSource {
+	return uploadSource{
+		name: fileHeader.Filename,
+		size: fileHeader.Size,
+		open: func() (io.ReadCloser, error) { return fileHeader.Open() },
+	}
+}
+
+func zipFileSource(f *zip.File) uploadSource {
+	return uploadSource{
+		name: path.Base(f.Name),
+		size: int64(f.UncompressedSize64),
+		open: func() (io.ReadCloser, error) { return f.Open() },
+	}
+}
+
+// checkUpload enforces the size limit and allowed types, returning the detected content type
+func checkUpload(src uploadSource, opt Options) (string, error) {
+	if opt.MaxSize > 0 && src.size > opt.MaxSize {
+		return "", errors.New("file too large")
+	}
+
+	detected, err := detectMIME(src)
+	if err != nil {
+		return "", err
 	}
 
 	if len(opt.AllowedMIMEs) > 0 {
@@ -59,18 +162,23 @@ func UploadFromForm(c *fiber.Ctx, formField string, opt Options) (string, string
 			}
 		}
 		if !ok {
-			return "", "", errors.New("unsupported file type: " + detected)
+			return "", errors.New("unsupported file type: " + detected)
 		}
 	}
+	return detected, nil
+}
+
+func saveUpload(c *fiber.Ctx, src uploadSource, opt Options) (string, string, error) {
+	detected, err := checkUpload(src, opt)
+	if err != nil {
+		return "", "", err
+	}
 
 	if opt.Dir == "" {
 		opt.Dir = "./uploads"
 	}
-	if err := os.MkdirAll(opt.Dir, os.ModePerm); err != nil {
-		return "", "", err
-	}
 
-	ext := filepath.Ext(fileHeader.Filename)
+	ext := filepath.Ext(src.name)
 	if ext == "" {
 		if exts, _ := mime.ExtensionsByType(detected); len(exts) > 0 {
 			ext = exts[0]
@@ -85,11 +193,20 @@ func UploadFromForm(c *fiber.Ctx, formField string, opt Options) (string, string
 		hex.EncodeToString(random),
 	}, "_") + ext
 
-	relPath := filepath.Join(opt.Dir, newName)
+	relPath := path.Join(filepath.ToSlash(opt.Dir), newName)
+	if storage.CleanKey(relPath) == "" {
+		return "", "", errors.New("invalid upload directory")
+	}
 
-	if err := c.SaveFile(fileHeader, relPath); err != nil {
+	if err := storeUpload(c, relPath, src, detected); err != nil {
 		return "", "", err
 	}
+	// previews are best effort; the upload itself has succeeded
+	if thumbnail.Supported(relPath) {
+		if err := thumbnail.Generate(relPath); err != nil {
+			log.Printf("[SaveFormFile] Thumbnail error for %s: %v\n", relPath, err)
+		}
+	}
 
 	publicURL := ""
 	if opt.BaseURL != "" {
@@ -99,3 +216,101 @@ func UploadFromForm(c *fiber.Ctx, formField string, opt Options) (string, string
 
 	return relPath, publicURL, nil
 }
+
+// UploadInfo describes an upload about to be stored
+type UploadInfo struct {
+	Key          string
+	OriginalName string
+	MimeType     string
+	Size         int64
+	UploadedBy   string
+}
+
+// UploadGuard screens every upload before it is stored, e.g. for malware. It may read the file
+// through open as often as it needs; an error rejects the upload.
+type UploadGuard interface {
+	Screen(ctx context.Context, upload UploadInfo, open func() (io.ReadCloser, error)) error
+}
+
+var uploadGuard UploadGuard
+
+// SetUploadGuard installs the guard used by StoreUpload; call it once at startup
+func SetUploadGuard(g UploadGuard) {
+	uploadGuard = g
+}
+
+// StoreUpload screens one multipart file with the upload guard and stores it under key
+func StoreUpload(c *fiber.Ctx, key string, fileHeader *multipart.FileHeader, mimeType string) error {
+	return storeUpload(c, key, formFileSource(fileHeader), mimeType)
+}
+
+func storeUpload(c *fiber.Ctx, key string, src uploadSource, mimeType string) error {
+	if uploadGuard != nil {
+		upload := UploadInfo{
+			Key:          storage.CleanKey(key),
+			OriginalName: src.name,
+			MimeType:     mimeType,
+			Size:         src.size,
+			UploadedBy:   AuthUsername(c),
+		}
+		if err := uploadGuard.Screen(c.UserContext(), upload, src.open); err != nil {
+			return err
+		}
+	}
+
+	r, err := src.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return storage.Default().Put(c.UserContext(), key, r, src.size, mimeType)
+}
+
+// StoredSHA256 returns the SHA-256 recorded when key was stored, or "" when it was stored before
+// content addressing or is missing
+func StoredSHA256(ctx context.Context, key string) string {
+	key = storage.CleanKey(key)
+	if key == "" {
+		return ""
+	}
+	info, err := storage.Default().Stat(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return info.SHA256
+}
+
+// officeMIMEs maps extensions of container formats that http.DetectContentType
+// only reports as application/zip or application/octet-stream
+var officeMIMEs = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".ppt":  "application/vnd.ms-powerpoint",
+}
+
+// DetectFormFileMIME sniffs the content type of an uploaded file, resolving Office formats by extension
+func DetectFormFileMIME(fileHeader *multipart.FileHeader) (string, error) {
+	return detectMIME(formFileSource(fileHeader))
+}
+
+func detectMIME(src uploadSource) (string, error) {
+	r, err := src.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(r, buf)
+	detected := http.DetectContentType(buf[:n])
+
+	if detected == "application/zip" || detected == "application/octet-stream" {
+		if m, ok := officeMIMEs[strings.ToLower(filepath.Ext(src.name))]; ok {
+			return m, nil
+		}
+	}
+	return detected, nil
+}
@@ -97,12 +97,12 @@ func (r *CustomerManualRepository) DeleteCustomerManual(id int) error {
 	return result.Error
 }
 
-// SearchCustomerManuals searches customer manuals by keyword
-func (r *CustomerManualRepository) SearchCustomerManuals(keyword string, page, pageSize int) ([]domains.CustomerManual, int64, error) {
+// SearchCustomerManuals searches customer manuals by keyword, including the text of their files
+func (r *CustomerManualRepository) SearchCustomerManuals(search domains.DocumentSearch, page, pageSize int) ([]domains.CustomerManual, int64, error) {
 	var manuals []domains.CustomerManual
 	var total int64
 
-	query := r.db.Where("(customer_manual_name LIKE ? OR desc LIKE ?) AND deleted_at IS NULL", "%"+keyword+"%", "%"+keyword+"%")
+	query := r.db.Where("deleted_at IS NULL").Scopes(documentSearchScope(domains.DocModuleCustomerManual, search))
 
 	if err := query.Model(&domains.CustomerManual{}).Count(&total).Error; err != nil {
 		return nil, 0, err
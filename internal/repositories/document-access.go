@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type DocumentAccessRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewDocumentAccessRepositoryDB(db *gorm.DB) *DocumentAccessRepositoryDB {
+	if err := db.AutoMigrate(&domains.DocumentAccessLog{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &DocumentAccessRepositoryDB{db: db}
+}
+
+func (r *DocumentAccessRepositoryDB) CreateDocumentAccessLog(entry *domains.DocumentAccessLog) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		fmt.Printf("CreateDocumentAccessLog error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// documentAccessStatQuery selects live documents of a module with their access totals.
+// The totals subquery takes the module name followed by its own filter arguments.
+func documentAccessStatQuery(module domains.DocumentModule, logFilter, join string) string {
+	return fmt.Sprintf(`
+		SELECT d.%[1]s AS document_id, d.%[2]s AS name, d.file_name, d.created_at,
+			COALESCE(s.views, 0) AS views, COALESCE(s.downloads, 0) AS downloads,
+			COALESCE(s.unique_viewers, 0) AS unique_viewers, s.last_accessed_at
+		FROM %[3]s d
+		%[5]s JOIN (
+			SELECT document_id,
+				SUM(CASE WHEN action = 'view' THEN 1 ELSE 0 END) AS views,
+				SUM(CASE WHEN action = 'download' THEN 1 ELSE 0 END) AS downloads,
+				COUNT(DISTINCT NULLIF(emp_code, '')) AS unique_viewers,
+				MAX(accessed_at) AS last_accessed_at
+			FROM document_access_logs
+			WHERE module = ? %[4]s
+			GROUP BY document_id
+		) s ON s.document_id = d.%[1]s
+		WHERE d.deleted_at IS NULL`, module.IDColumn, module.NameColumn, module.Table, logFilter, join)
+}
+
+// GetDocumentAccessStat returns the all-time access totals of one document, or gorm.ErrRecordNotFound
+func (r *DocumentAccessRepositoryDB) GetDocumentAccessStat(module domains.DocumentModule, documentID int) (*domains.DocumentAccessStat, error) {
+	var stats []domains.DocumentAccessStat
+	q := documentAccessStatQuery(module, "", "LEFT") + fmt.Sprintf(" AND d.%s = ?", module.IDColumn)
+	if err := r.db.Raw(q, module.Name, documentID).Scan(&stats).Error; err != nil {
+		fmt.Printf("GetDocumentAccessStat error: %v\n", err)
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &stats[0], nil
+}
+
+// GetMostViewedDocuments returns the documents opened most often in [from, to)
+func (r *DocumentAccessRepositoryDB) GetMostViewedDocuments(module domains.DocumentModule, from, to time.Time, limit int) ([]domains.DocumentAccessStat, error) {
+	var stats []domains.DocumentAccessStat
+	q := "SELECT TOP (?) * FROM (" + documentAccessStatQuery(module, "AND accessed_at >= ? AND accessed_at < ?", "INNER") + ") t" +
+		" ORDER BY views + downloads DESC, unique_viewers DESC, last_accessed_at DESC"
+	if err := r.db.Raw(q, limit, module.Name, from, to).Scan(&stats).Error; err != nil {
+		fmt.Printf("GetMostViewedDocuments error: %v\n", err)
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetUnopenedDocuments returns documents created before since that nobody has opened since then,
+// with their all-time totals; never-opened documents come first, then the longest unused.
+func (r *DocumentAccessRepositoryDB) GetUnopenedDocuments(module domains.DocumentModule, since time.Time) ([]domains.DocumentAccessStat, error) {
+	var stats []domains.DocumentAccessStat
+	q := documentAccessStatQuery(module, "", "LEFT") +
+		" AND d.created_at < ? AND (s.last_accessed_at IS NULL OR s.last_accessed_at < ?)" +
+		" ORDER BY CASE WHEN s.last_accessed_at IS NULL THEN 0 ELSE 1 END, s.last_accessed_at, d.created_at"
+	if err := r.db.Raw(q, module.Name, since, since).Scan(&stats).Error; err != nil {
+		fmt.Printf("GetUnopenedDocuments error: %v\n", err)
+		return nil, err
+	}
+	return stats, nil
+}
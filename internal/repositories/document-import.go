@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type DocumentImportRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewDocumentImportRepositoryDB(db *gorm.DB) *DocumentImportRepositoryDB {
+	return &DocumentImportRepositoryDB{db: db}
+}
+
+// GetLiveDocumentIDs returns which of ids are live documents of the module
+func (r *DocumentImportRepositoryDB) GetLiveDocumentIDs(module domains.DocumentModule, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []int
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN ? AND deleted_at IS NULL", module.IDColumn, module.Table, module.IDColumn)
+	if err := r.db.Raw(q, ids).Scan(&found).Error; err != nil {
+		fmt.Printf("GetLiveDocumentIDs error: %v\n", err)
+		return nil, err
+	}
+	return found, nil
+}
+
+// CreateImportedDocuments inserts a batch into the module table in one transaction and returns the new IDs
+// in batch order; a row pointing at an earlier row of the batch gets that row's ID as parent.
+// QMS documents start as drafts, like documents created one by one.
+func (r *DocumentImportRepositoryDB) CreateImportedDocuments(module string, docs []domains.DocumentImport) ([]int, error) {
+	ids := make([]int, 0, len(docs))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, d := range docs {
+			var id int
+			switch module {
+			case domains.DocModuleSafety:
+				doc := domains.SafetyDocument{SafetyDocumentName: d.Name, SafetyDocumentDesc: d.Desc, Category: d.Category,
+					Department: d.Department, FileName: d.FileName, OwnerEmpCode: d.OwnerEmpCode}
+				if err := tx.Create(&doc).Error; err != nil {
+					return err
+				}
+				id = doc.SafetyDocumentID
+			case domains.DocModuleQms:
+				doc := domains.QmsDocuments{QmsDocumentsName: d.Name, DQmsDocumentsDesc: d.Desc, Category: d.Category,
+					FileName: d.FileName, DocumentNo: d.DocumentNo, Revision: d.Revision, Status: domains.QmsStatusDraft,
+					OwnerEmpCode: d.OwnerEmpCode}
+				if err := tx.Create(&doc).Error; err != nil {
+					return err
+				}
+				id = doc.QmsDocumentsID
+			case domains.DocModuleProcedureManual:
+				doc := domains.ProcedureManual{ProcedureManualName: d.Name, Desc: d.Desc, Category: d.Category, FileName: d.FileName}
+				if err := tx.Create(&doc).Error; err != nil {
+					return err
+				}
+				id = doc.ProcedureManualID
+			case domains.DocModuleCustomerManual:
+				parentID := d.ParentID
+				if d.ParentIndex >= 0 {
+					parent := ids[d.ParentIndex]
+					parentID = &parent
+				}
+				doc := domains.CustomerManual{CustomerManualName: d.Name, Desc: d.Desc, Category: d.Category,
+					FileName: d.FileName, ParentID: parentID, SortOrder: d.SortOrder}
+				if err := tx.Create(&doc).Error; err != nil {
+					return err
+				}
+				id = doc.CustomerManualID
+			case domains.DocModuleOrganizationDocs:
+				doc := domains.OrganizationDoc{Name: d.Name, Desc: d.Desc, Department: d.Department, FileName: d.FileName}
+				if err := tx.Create(&doc).Error; err != nil {
+					return err
+				}
+				id = doc.OrganizationDocID
+			default:
+				return fmt.Errorf("unknown document module %q", module)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("CreateImportedDocuments error: %v\n", err)
+		return nil, err
+	}
+	return ids, nil
+}
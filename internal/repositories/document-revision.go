@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type DocumentRevisionRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewDocumentRevisionRepositoryDB(db *gorm.DB) *DocumentRevisionRepositoryDB {
+	if err := db.AutoMigrate(&domains.DocumentRevision{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &DocumentRevisionRepositoryDB{db: db}
+}
+
+// GetDocumentFileName returns the current file of a live document, or gorm.ErrRecordNotFound
+func (r *DocumentRevisionRepositoryDB) GetDocumentFileName(module domains.DocumentModule, documentID int) (string, error) {
+	var fileName sql.NullString
+	q := fmt.Sprintf("SELECT file_name FROM %s WHERE %s = ? AND deleted_at IS NULL", module.Table, module.IDColumn)
+	rows, err := r.db.Raw(q, documentID).Rows()
+	if err != nil {
+		fmt.Printf("GetDocumentFileName error: %v\n", err)
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return "", gorm.ErrRecordNotFound
+	}
+	if err := rows.Scan(&fileName); err != nil {
+		fmt.Printf("GetDocumentFileName scan error: %v\n", err)
+		return "", err
+	}
+	return fileName.String, nil
+}
+
+// GetQmsDocumentStatus returns the workflow status of a live QMS document, or gorm.ErrRecordNotFound
+func (r *DocumentRevisionRepositoryDB) GetQmsDocumentStatus(documentID int) (string, error) {
+	var doc domains.QmsDocuments
+	if err := r.db.Select("status").Where("qms_documents_id = ?", documentID).Take(&doc).Error; err != nil {
+		fmt.Printf("GetQmsDocumentStatus error: %v\n", err)
+		return "", err
+	}
+	return doc.Status, nil
+}
+
+func (r *DocumentRevisionRepositoryDB) CountDocumentRevisions(module string, documentID int) (int64, error) {
+	var total int64
+	if err := r.db.Model(&domains.DocumentRevision{}).
+		Where("module = ? AND document_id = ?", module, documentID).
+		Count(&total).Error; err != nil {
+		fmt.Printf("CountDocumentRevisions error: %v\n", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// CreateDocumentRevision assigns the next revision number. A current revision clears the flag on the others.
+func (r *DocumentRevisionRepositoryDB) CreateDocumentRevision(revision *domains.DocumentRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxNo sql.NullInt64
+		if err := tx.Model(&domains.DocumentRevision{}).
+			Where("module = ? AND document_id = ?", revision.Module, revision.DocumentID).
+			Select("MAX(revision_no)").
+			Row().Scan(&maxNo); err != nil {
+			fmt.Printf("CreateDocumentRevision revision no error: %v\n", err)
+			return err
+		}
+		revision.RevisionNo = int(maxNo.Int64) + 1
+
+		if revision.IsCurrent {
+			if err := tx.Model(&domains.DocumentRevision{}).
+				Where("module = ? AND document_id = ?", revision.Module, revision.DocumentID).
+				Update("is_current", false).Error; err != nil {
+				fmt.Printf("CreateDocumentRevision clear current error: %v\n", err)
+				return err
+			}
+		}
+
+		if err := tx.Create(revision).Error; err != nil {
+			fmt.Printf("CreateDocumentRevision error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *DocumentRevisionRepositoryDB) GetDocumentRevisions(module string, documentID int) ([]domains.DocumentRevision, error) {
+	var revisions []domains.DocumentRevision
+	if err := r.db.
+		Where("module = ? AND document_id = ?", module, documentID).
+		Order("revision_no DESC").
+		Find(&revisions).Error; err != nil {
+		fmt.Printf("GetDocumentRevisions error: %v\n", err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *DocumentRevisionRepositoryDB) GetDocumentRevision(module string, documentID, revisionNo int) (domains.DocumentRevision, error) {
+	var revision domains.DocumentRevision
+	err := r.db.
+		Where("module = ? AND document_id = ? AND revision_no = ?", module, documentID, revisionNo).
+		First(&revision).Error
+	return revision, err
+}
+
+// SetCurrentDocumentRevision flags one revision as current and puts its file back on the document
+func (r *DocumentRevisionRepositoryDB) SetCurrentDocumentRevision(module domains.DocumentModule, documentID, revisionNo int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var revision domains.DocumentRevision
+		if err := tx.Where("module = ? AND document_id = ? AND revision_no = ?", module.Name, documentID, revisionNo).
+			First(&revision).Error; err != nil {
+			return err
+		}
+
+		q := fmt.Sprintf("UPDATE %s SET file_name = ?, updated_at = GETDATE() WHERE %s = ? AND deleted_at IS NULL", module.Table, module.IDColumn)
+		result := tx.Exec(q, revision.FileName, documentID)
+		if result.Error != nil {
+			fmt.Printf("SetCurrentDocumentRevision update document error: %v\n", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&domains.DocumentRevision{}).
+			Where("module = ? AND document_id = ?", module.Name, documentID).
+			Update("is_current", gorm.Expr("CASE WHEN revision_no = ? THEN 1 ELSE 0 END", revisionNo)).Error; err != nil {
+			fmt.Printf("SetCurrentDocumentRevision flag error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
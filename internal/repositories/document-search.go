@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/utils"
+)
+
+// documentSearchScope narrows a module query to documents where every keyword term matches one of the
+// module's text columns or its indexed file text (case- and accent-insensitive), then applies the
+// category and department filters.
+func documentSearchScope(module string, search domains.DocumentSearch) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		mod := domains.DocumentModules[module]
+		spec := domains.DocumentSearchSpecs[module]
+
+		for _, term := range utils.SearchTerms(search.Keyword) {
+			conds, args := termConditions(mod.Table, spec.Columns, term)
+			conds = append(conds, documentTextCondition(module))
+			args = append(args, module, utils.LikeContains(term))
+			db = db.Where("("+strings.Join(conds, " OR ")+")", args...)
+		}
+
+		if search.Category != "" && spec.HasCategory {
+			db = db.Where(mod.Table+".category COLLATE "+utils.SearchCollation+" = ?", search.Category)
+		}
+		if search.Department != "" && spec.HasDepartment {
+			db = db.Where(mod.Table+".department COLLATE "+utils.SearchCollation+" = ?", search.Department)
+		}
+		return db
+	}
+}
+
+// keywordScope narrows a query to rows where every keyword term matches one of the columns (case- and accent-insensitive)
+func keywordScope(table string, columns []string, keyword string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, term := range utils.SearchTerms(keyword) {
+			conds, args := termConditions(table, columns, term)
+			db = db.Where("("+strings.Join(conds, " OR ")+")", args...)
+		}
+		return db
+	}
+}
+
+// termConditions returns one collated LIKE condition per column for a single search term
+func termConditions(table string, columns []string, term string) ([]string, []interface{}) {
+	pattern := utils.LikeContains(term)
+	conds := make([]string, 0, len(columns)+1)
+	args := make([]interface{}, 0, len(columns)+2)
+	for _, col := range columns {
+		conds = append(conds, table+"."+col+" COLLATE "+utils.SearchCollation+" LIKE ?")
+		args = append(args, pattern)
+	}
+	return conds, args
+}
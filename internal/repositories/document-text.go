@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/utils"
+)
+
+type DocumentTextRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewDocumentTextRepositoryDB(db *gorm.DB) *DocumentTextRepositoryDB {
+	if err := db.AutoMigrate(&domains.DocumentTextPage{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &DocumentTextRepositoryDB{db: db}
+}
+
+// documentTextCondition matches rows of a module table whose extracted text contains a pattern.
+// Arguments: module name, LIKE pattern.
+func documentTextCondition(module string) string {
+	mod := domains.DocumentModules[module]
+	return `EXISTS (
+		SELECT 1 FROM document_text_pages tp
+		WHERE tp.module = ? AND tp.document_id = ` + mod.Table + `.` + mod.IDColumn + `
+		  AND tp.content COLLATE ` + utils.SearchCollation + ` LIKE ?
+	)`
+}
+
+// ReplaceDocumentText swaps the indexed pages of a document
+func (r *DocumentTextRepositoryDB) ReplaceDocumentText(module string, documentID int, pages []domains.DocumentTextPage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("module = ? AND document_id = ?", module, documentID).Delete(&domains.DocumentTextPage{}).Error; err != nil {
+			fmt.Printf("ReplaceDocumentText delete error: %v\n", err)
+			return err
+		}
+		if len(pages) == 0 {
+			return nil
+		}
+
+		for i := range pages {
+			pages[i].DocumentTextPageID = 0
+			pages[i].Module = module
+			pages[i].DocumentID = documentID
+		}
+		if err := tx.CreateInBatches(&pages, 100).Error; err != nil {
+			fmt.Printf("ReplaceDocumentText create error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// GetDocumentFiles returns the current file of one live document, or of all of them when documentID is 0
+func (r *DocumentTextRepositoryDB) GetDocumentFiles(module domains.DocumentModule, documentID int) ([]domains.DocumentFile, error) {
+	q := fmt.Sprintf("SELECT %s, file_name FROM %s WHERE deleted_at IS NULL", module.IDColumn, module.Table)
+	var args []interface{}
+	if documentID > 0 {
+		q += fmt.Sprintf(" AND %s = ?", module.IDColumn)
+		args = append(args, documentID)
+	}
+
+	rows, err := r.db.Raw(q, args...).Rows()
+	if err != nil {
+		fmt.Printf("GetDocumentFiles error: %v\n", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []domains.DocumentFile
+	for rows.Next() {
+		var id int
+		var fileName sql.NullString
+		if err := rows.Scan(&id, &fileName); err != nil {
+			fmt.Printf("GetDocumentFiles scan error: %v\n", err)
+			return nil, err
+		}
+		files = append(files, domains.DocumentFile{DocumentID: id, FileName: fileName.String})
+	}
+	return files, rows.Err()
+}
+
+// FindDocumentIDByFileName resolves a freshly uploaded file to its document, or gorm.ErrRecordNotFound
+func (r *DocumentTextRepositoryDB) FindDocumentIDByFileName(module domains.DocumentModule, fileName string) (int, error) {
+	var ids []int
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE file_name = ? AND deleted_at IS NULL ORDER BY %s DESC", module.IDColumn, module.Table, module.IDColumn)
+	if err := r.db.Raw(q, fileName).Scan(&ids).Error; err != nil {
+		fmt.Printf("FindDocumentIDByFileName error: %v\n", err)
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// GetMatchingTextPages returns the pages of the given documents whose text contains any keyword term
+func (r *DocumentTextRepositoryDB) GetMatchingTextPages(module string, documentIDs []int, keyword string) ([]domains.DocumentTextPage, error) {
+	var pages []domains.DocumentTextPage
+	terms := utils.SearchTerms(keyword)
+	if len(documentIDs) == 0 || len(terms) == 0 {
+		return pages, nil
+	}
+
+	conds := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		conds[i] = "content COLLATE " + utils.SearchCollation + " LIKE ?"
+		args[i] = utils.LikeContains(term)
+	}
+
+	if err := r.db.
+		Where("module = ? AND document_id IN ?", module, documentIDs).
+		Where("("+strings.Join(conds, " OR ")+")", args...).
+		Order("document_id, page_no").
+		Find(&pages).Error; err != nil {
+		fmt.Printf("GetMatchingTextPages error: %v\n", err)
+		return nil, err
+	}
+	return pages, nil
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type EmailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) *EmailOutboxRepository {
+	if err := db.AutoMigrate(&domains.EmailOutbox{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &EmailOutboxRepository{db: db}
+}
+
+// CreateEmail queues a message for the outbox worker
+func (r *EmailOutboxRepository) CreateEmail(email *domains.EmailOutbox) error {
+	if err := r.db.Create(email).Error; err != nil {
+		fmt.Printf("CreateEmailError: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// GetDueEmails returns pending messages whose next attempt time has passed, oldest first
+func (r *EmailOutboxRepository) GetDueEmails(now time.Time, limit int) ([]domains.EmailOutbox, error) {
+	var emails []domains.EmailOutbox
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", domains.EmailPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&emails).Error
+	if err != nil {
+		fmt.Printf("GetDueEmailsError: %v\n", err)
+		return nil, err
+	}
+	return emails, nil
+}
+
+// ClaimEmail moves a pending message to sending. It returns false when another worker got it first.
+func (r *EmailOutboxRepository) ClaimEmail(id int) (bool, error) {
+	result := r.db.Model(&domains.EmailOutbox{}).
+		Where("email_outbox_id = ? AND status = ?", id, domains.EmailPending).
+		Updates(map[string]interface{}{"status": domains.EmailSending, "updated_at": time.Now()})
+	if result.Error != nil {
+		fmt.Printf("ClaimEmailError: %v\n", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *EmailOutboxRepository) MarkEmailSent(id int, sentAt time.Time) error {
+	return r.db.Model(&domains.EmailOutbox{}).
+		Where("email_outbox_id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     domains.EmailSent,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"sent_at":    sentAt,
+			"last_error": "",
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// MarkEmailFailed records a failed attempt and either schedules the next one or gives up
+func (r *EmailOutboxRepository) MarkEmailFailed(id int, attempts int, status string, nextAttemptAt time.Time, lastError string) error {
+	return r.db.Model(&domains.EmailOutbox{}).
+		Where("email_outbox_id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// ResetStaleSending returns messages stuck in sending (e.g. the process died mid-send) to pending
+func (r *EmailOutboxRepository) ResetStaleSending(olderThan time.Time) (int64, error) {
+	result := r.db.Model(&domains.EmailOutbox{}).
+		Where("status = ? AND updated_at < ?", domains.EmailSending, olderThan).
+		Updates(map[string]interface{}{"status": domains.EmailPending, "updated_at": time.Now()})
+	if result.Error != nil {
+		fmt.Printf("ResetStaleSendingError: %v\n", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
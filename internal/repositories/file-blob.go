@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/storage"
+	"backend/internal/pkgs/utils"
+)
+
+const fileLinkBatchSize = 500
+
+type FileBlobRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewFileBlobRepositoryDB(db *gorm.DB) *FileBlobRepositoryDB {
+	if err := db.AutoMigrate(&domains.FileBlob{}, &domains.FileLink{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &FileBlobRepositoryDB{db: db}
+}
+
+type fileLinkRow struct {
+	StorageKey string    `gorm:"column:storage_key"`
+	SHA256     string    `gorm:"column:sha256"`
+	Size       int64     `gorm:"column:size"`
+	Thumbnails bool      `gorm:"column:thumbnails"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (row fileLinkRow) link() storage.Link {
+	return storage.Link{Key: row.StorageKey, SHA256: row.SHA256, Size: row.Size, Thumbnails: row.Thumbnails, CreatedAt: row.CreatedAt}
+}
+
+func (r *FileBlobRepositoryDB) fileLinks(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("file_links l").
+		Select("l.storage_key, l.sha256, b.size, l.thumbnails, l.created_at").
+		Joins("JOIN file_blobs b ON b.sha256 = l.sha256")
+}
+
+func (r *FileBlobRepositoryDB) LookupLink(ctx context.Context, key string) (storage.Link, error) {
+	var row fileLinkRow
+	if err := r.fileLinks(ctx).Where("l.storage_key = ?", key).Take(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return storage.Link{}, storage.ErrNotExist
+		}
+		fmt.Printf("LookupLink error: %v\n", err)
+		return storage.Link{}, err
+	}
+	return row.link(), nil
+}
+
+// LookupLinks reads the links of a page of files at once
+func (r *FileBlobRepositoryDB) LookupLinks(ctx context.Context, keys []string) (map[string]storage.Link, error) {
+	links := make(map[string]storage.Link, len(keys))
+	for start := 0; start < len(keys); start += fileLinkBatchSize {
+		end := min(start+fileLinkBatchSize, len(keys))
+		var rows []fileLinkRow
+		if err := r.fileLinks(ctx).Where("l.storage_key IN ?", keys[start:end]).Find(&rows).Error; err != nil {
+			fmt.Printf("LookupLinks error: %v\n", err)
+			return nil, err
+		}
+		for _, row := range rows {
+			links[row.StorageKey] = row.link()
+		}
+	}
+	return links, nil
+}
+
+func (r *FileBlobRepositoryDB) SetLinkThumbnails(ctx context.Context, key string, thumbnails bool) error {
+	return r.db.WithContext(ctx).Model(&domains.FileLink{}).Where("storage_key = ?", key).
+		UpdateColumn("thumbnails", thumbnails).Error
+}
+
+func (r *FileBlobRepositoryDB) CreateLink(ctx context.Context, key, sha256 string, size int64, contentType string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var link domains.FileLink
+		err := tx.Where("storage_key = ?", key).Take(&link).Error
+		switch {
+		case err == nil && link.SHA256 == sha256:
+			return nil
+		case err == nil:
+			if err := releaseFileBlob(tx, link.SHA256); err != nil {
+				return err
+			}
+			if err := tx.Model(&domains.FileLink{}).Where("storage_key = ?", key).
+				Updates(map[string]interface{}{"sha256": sha256, "thumbnails": false, "created_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(&domains.FileLink{StorageKey: key, SHA256: sha256}).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		return retainFileBlob(tx, sha256, size, contentType)
+	})
+}
+
+func (r *FileBlobRepositoryDB) DeleteLink(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var link domains.FileLink
+		if err := tx.Where("storage_key = ?", key).Take(&link).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrNotExist
+			}
+			return err
+		}
+		if err := tx.Where("storage_key = ?", key).Delete(&domains.FileLink{}).Error; err != nil {
+			return err
+		}
+		return releaseFileBlob(tx, link.SHA256)
+	})
+}
+
+// WalkLinks pages through the links in key order so large indexes are not loaded at once
+func (r *FileBlobRepositoryDB) WalkLinks(ctx context.Context, prefix string, fn func(storage.Link) error) error {
+	last := ""
+	for {
+		var rows []fileLinkRow
+		q := r.fileLinks(ctx).Where("l.storage_key > ?", last)
+		if prefix != "" {
+			q = q.Where("l.storage_key LIKE ?", utils.LikePrefix(prefix))
+		}
+		if err := q.Order("l.storage_key").Limit(fileLinkBatchSize).Find(&rows).Error; err != nil {
+			fmt.Printf("WalkLinks error: %v\n", err)
+			return err
+		}
+
+		for _, row := range rows {
+			if !strings.HasPrefix(row.StorageKey, prefix) {
+				continue
+			}
+			if err := fn(row.link()); err != nil {
+				return err
+			}
+		}
+		if len(rows) < fileLinkBatchSize {
+			return nil
+		}
+		last = rows[len(rows)-1].StorageKey
+	}
+}
+
+func (r *FileBlobRepositoryDB) GetFileBlobs(afterSHA256 string, limit int) ([]domains.FileBlob, error) {
+	var blobs []domains.FileBlob
+	if err := r.db.Where("sha256 > ?", afterSHA256).Order("sha256").Limit(limit).Find(&blobs).Error; err != nil {
+		fmt.Printf("GetFileBlobs error: %v\n", err)
+		return nil, err
+	}
+	return blobs, nil
+}
+
+func (r *FileBlobRepositoryDB) GetFileLinkKeys(sha256 string, limit int) ([]string, error) {
+	var keys []string
+	if err := r.db.Model(&domains.FileLink{}).Where("sha256 = ?", sha256).
+		Order("storage_key").Limit(limit).Pluck("storage_key", &keys).Error; err != nil {
+		fmt.Printf("GetFileLinkKeys error: %v\n", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *FileBlobRepositoryDB) MarkFileBlobVerified(sha256 string, at time.Time) error {
+	return r.db.Model(&domains.FileBlob{}).Where("sha256 = ?", sha256).UpdateColumn("verified_at", at).Error
+}
+
+func (r *FileBlobRepositoryDB) GetFileBlobStats() (domains.FileBlobStats, error) {
+	var stats domains.FileBlobStats
+	err := r.db.Model(&domains.FileBlob{}).Select(`COUNT(*) AS blobs, COALESCE(SUM(size), 0) AS bytes,
+		COALESCE(SUM(CASE WHEN ref_count = 0 THEN 1 ELSE 0 END), 0) AS unreferenced_blobs,
+		COALESCE(SUM(CASE WHEN ref_count = 0 THEN size ELSE 0 END), 0) AS unreferenced_bytes`).Scan(&stats).Error
+	if err != nil {
+		fmt.Printf("GetFileBlobStats error: %v\n", err)
+	}
+	return stats, err
+}
+
+// GetUnreferencedFileBlobs returns blobs that lost their last link before the given time
+func (r *FileBlobRepositoryDB) GetUnreferencedFileBlobs(before time.Time, limit int) ([]domains.FileBlob, error) {
+	var blobs []domains.FileBlob
+	if err := r.db.Where("ref_count = 0 AND updated_at < ?", before).Order("updated_at").Limit(limit).Find(&blobs).Error; err != nil {
+		fmt.Printf("GetUnreferencedFileBlobs error: %v\n", err)
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// DeleteUnreferencedFileBlob removes the blob row unless a link was added since it was listed;
+// deleted tells the caller it may remove the stored content
+func (r *FileBlobRepositoryDB) DeleteUnreferencedFileBlob(sha256 string, before time.Time) (bool, error) {
+	res := r.db.Where("sha256 = ? AND ref_count = 0 AND updated_at < ?", sha256, before).Delete(&domains.FileBlob{})
+	if res.Error != nil {
+		fmt.Printf("DeleteUnreferencedFileBlob error: %v\n", res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// FindFileBlobs returns which of the given hashes have a blob row
+func (r *FileBlobRepositoryDB) FindFileBlobs(sha256s []string) ([]string, error) {
+	var found []string
+	for start := 0; start < len(sha256s); start += fileLinkBatchSize {
+		end := min(start+fileLinkBatchSize, len(sha256s))
+		var batch []string
+		if err := r.db.Model(&domains.FileBlob{}).Where("sha256 IN ?", sha256s[start:end]).Pluck("sha256", &batch).Error; err != nil {
+			fmt.Printf("FindFileBlobs error: %v\n", err)
+			return nil, err
+		}
+		found = append(found, batch...)
+	}
+	return found, nil
+}
+
+// retainFileBlob adds a reference, creating the blob row on its first link
+func retainFileBlob(tx *gorm.DB, sha256 string, size int64, contentType string) error {
+	// one statement holding the key range lock, so two first uploads of the same content cannot both insert
+	now := time.Now()
+	return tx.Exec(`
+		MERGE file_blobs WITH (HOLDLOCK) AS b
+		USING (SELECT ? AS sha256) AS s ON b.sha256 = s.sha256
+		WHEN MATCHED THEN
+			UPDATE SET ref_count = b.ref_count + 1, updated_at = ?
+		WHEN NOT MATCHED THEN
+			INSERT (sha256, size, content_type, ref_count, created_at, updated_at)
+			VALUES (s.sha256, ?, ?, 1, ?, ?);`,
+		sha256, now, size, contentType, now, now).Error
+}
+
+// releaseFileBlob drops a reference; the blob itself is left to the orphan collector
+func releaseFileBlob(tx *gorm.DB, sha256 string) error {
+	return tx.Model(&domains.FileBlob{}).Where("sha256 = ? AND ref_count > 0", sha256).
+		Updates(map[string]interface{}{"ref_count": gorm.Expr("ref_count - 1"), "updated_at": time.Now()}).Error
+}
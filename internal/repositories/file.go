@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/utils"
+)
+
+type FileRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewFileRepositoryDB(db *gorm.DB) *FileRepositoryDB {
+	return &FileRepositoryDB{db: db}
+}
+
+// GetDocumentFile returns the current file of a live document, or gorm.ErrRecordNotFound
+func (r *FileRepositoryDB) GetDocumentFile(module domains.DocumentModule, documentID int) (domains.DocumentFile, error) {
+	var files []domains.DocumentFile
+	q := fmt.Sprintf("SELECT %s AS document_id, file_name, %s AS name FROM %s WHERE %s = ? AND deleted_at IS NULL",
+		module.IDColumn, module.NameColumn, module.Table, module.IDColumn)
+	if err := r.db.Raw(q, documentID).Scan(&files).Error; err != nil {
+		fmt.Printf("GetDocumentFile error: %v\n", err)
+		return domains.DocumentFile{}, err
+	}
+	if len(files) == 0 {
+		return domains.DocumentFile{}, gorm.ErrRecordNotFound
+	}
+	return files[0], nil
+}
+
+// FindDocumentIDByFile resolves a stored file to its live document, looking at the current file first and then
+// at older revisions. fileNames are the accepted spellings of the stored file_name value.
+func (r *FileRepositoryDB) FindDocumentIDByFile(module domains.DocumentModule, fileNames []string) (int, error) {
+	var ids []int
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE file_name IN ? AND deleted_at IS NULL ORDER BY %s DESC", module.IDColumn, module.Table, module.IDColumn)
+	if err := r.db.Raw(q, fileNames).Scan(&ids).Error; err != nil {
+		fmt.Printf("FindDocumentIDByFile error: %v\n", err)
+		return 0, err
+	}
+	if len(ids) > 0 {
+		return ids[0], nil
+	}
+
+	q = fmt.Sprintf(`
+		SELECT TOP 1 rv.document_id FROM document_revisions rv
+		INNER JOIN %s d ON d.%s = rv.document_id AND d.deleted_at IS NULL
+		WHERE rv.module = ? AND rv.file_name IN ?
+		ORDER BY rv.document_revision_id DESC`, module.Table, module.IDColumn)
+	if err := r.db.Raw(q, module.Name, fileNames).Scan(&ids).Error; err != nil {
+		fmt.Printf("FindDocumentIDByFile revision error: %v\n", err)
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// GetDocumentExportRows lists the live documents of a module matching the export filter, ordered by name.
+// Unless filter.All is set, QMS documents must be effective and safety documents not yet expired.
+func (r *FileRepositoryDB) GetDocumentExportRows(module domains.DocumentModule, filter domains.DocumentExportFilter) ([]domains.DocumentExportRow, error) {
+	spec := domains.DocumentSearchSpecs[module.Name]
+	columns := []string{
+		module.IDColumn + " AS document_id",
+		module.NameColumn + " AS name",
+		"file_name",
+		"updated_at",
+	}
+	conds := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if spec.HasCategory {
+		columns = append(columns, "category")
+		if filter.Category != "" {
+			conds = append(conds, "category COLLATE "+utils.SearchCollation+" = ?")
+			args = append(args, filter.Category)
+		}
+	}
+	if spec.HasDepartment {
+		columns = append(columns, "department")
+		if filter.Department != "" {
+			conds = append(conds, "department COLLATE "+utils.SearchCollation+" = ?")
+			args = append(args, filter.Department)
+		}
+	}
+
+	switch module.Name {
+	case domains.DocModuleQms:
+		columns = append(columns, "document_no", "revision", "status", "owner_emp_code", "reviewer_emp_code", "approver_emp_code")
+		if !filter.All {
+			conds = append(conds, "status = ?")
+			args = append(args, domains.QmsStatusEffective)
+		}
+	case domains.DocModuleSafety:
+		columns = append(columns, "owner_emp_code", "expiry_date")
+		if !filter.All {
+			conds = append(conds, "(expiry_date IS NULL OR expiry_date >= CAST(GETDATE() AS date))")
+		}
+	}
+
+	var rows []domains.DocumentExportRow
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s, %s",
+		strings.Join(columns, ", "), module.Table, strings.Join(conds, " AND "), module.NameColumn, module.IDColumn)
+	if err := r.db.Raw(q, args...).Scan(&rows).Error; err != nil {
+		fmt.Printf("GetDocumentExportRows error: %v\n", err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *FileRepositoryDB) GetCompanyNewsAttachment(attachmentID int) (domains.CompanyNewsAttachment, error) {
+	var attachment domains.CompanyNewsAttachment
+	if err := r.db.Where("company_news_attachment_id = ?", attachmentID).First(&attachment).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			fmt.Printf("GetCompanyNewsAttachment error: %v\n", err)
+		}
+		return domains.CompanyNewsAttachment{}, err
+	}
+	return attachment, nil
+}
+
+// FindCompanyNewsIDByFile resolves an uploaded news image or attachment to the live article using it:
+// as cover photo, as attachment, or embedded in the content. fileNames are the accepted stored spellings.
+func (r *FileRepositoryDB) FindCompanyNewsIDByFile(fileNames []string, fileName string) (string, error) {
+	var ids []string
+	q := `
+		SELECT TOP 1 CONVERT(NVARCHAR(36), n.company_news_id) FROM company_news n
+		WHERE n.deleted_at IS NULL AND (
+			n.company_news_photo IN ?
+			OR EXISTS (SELECT 1 FROM company_news_attachments a WHERE a.company_news_id = n.company_news_id AND a.file_path IN ?)
+			OR n.content LIKE ?
+		)
+		ORDER BY n.created_at DESC`
+	if err := r.db.Raw(q, fileNames, fileNames, utils.LikeContains(fileName)).Scan(&ids).Error; err != nil {
+		fmt.Printf("FindCompanyNewsIDByFile error: %v\n", err)
+		return "", err
+	}
+	if len(ids) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
@@ -86,13 +86,12 @@ func (r *OrganizationDocRepository) DeleteOrganizationDoc(id int) error {
 	return nil
 }
 
-// SearchOrganizationDocs searches organization docs by keyword
-func (r *OrganizationDocRepository) SearchOrganizationDocs(keyword string, limit, offset int) ([]domains.OrganizationDoc, int64, error) {
+// SearchOrganizationDocs searches organization docs by keyword, including the text of their files
+func (r *OrganizationDocRepository) SearchOrganizationDocs(search domains.DocumentSearch, limit, offset int) ([]domains.OrganizationDoc, int64, error) {
 	var docs []domains.OrganizationDoc
 	var total int64
 
-	query := r.db.Model(&domains.OrganizationDoc{}).
-		Where("name ILIKE ? OR desc ILIKE ? OR department ILIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	query := r.db.Model(&domains.OrganizationDoc{}).Scopes(documentSearchScope(domains.DocModuleOrganizationDocs, search))
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
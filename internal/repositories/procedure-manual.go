@@ -96,12 +96,12 @@ func (r *ProcedureManualRepository) DeleteProcedureManual(id int) error {
 	return result.Error
 }
 
-// SearchProcedureManuals searches procedure manuals by keyword
-func (r *ProcedureManualRepository) SearchProcedureManuals(keyword string, page, pageSize int) ([]domains.ProcedureManual, int64, error) {
+// SearchProcedureManuals searches procedure manuals by keyword, including the text of their files
+func (r *ProcedureManualRepository) SearchProcedureManuals(search domains.DocumentSearch, page, pageSize int) ([]domains.ProcedureManual, int64, error) {
 	var manuals []domains.ProcedureManual
 	var total int64
 
-	query := r.db.Where("(procedure_manual_name LIKE ? OR desc LIKE ?) AND deleted_at IS NULL", "%"+keyword+"%", "%"+keyword+"%")
+	query := r.db.Where("deleted_at IS NULL").Scopes(documentSearchScope(domains.DocModuleProcedureManual, search))
 
 	if err := query.Model(&domains.ProcedureManual{}).Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -121,17 +121,18 @@ func (r *AppSystemRepository) SearchAppSystems(keyword string, page, pageSize in
 	var AppSystems []domains.AppSystem
 	var total int64
 
+	query := r.db.Where("deleted_at IS NULL").
+		Scopes(keywordScope("ps_app_systems", []string{"name", "[desc]", "category"}, keyword))
+
 	// Get total count
-	countResult := r.db.Where("(name LIKE ? OR desc LIKE ?) AND deleted_at IS NULL", "%"+keyword+"%", "%"+keyword+"%").
-		Model(&domains.AppSystem{}).
-		Count(&total)
+	countResult := query.Model(&domains.AppSystem{}).Count(&total)
 	if countResult.Error != nil {
 		return nil, 0, countResult.Error
 	}
 
 	// Get paginated data
 	offset := (page - 1) * pageSize
-	result := r.db.Where("(name LIKE ? OR desc LIKE ?) AND deleted_at IS NULL", "%"+keyword+"%", "%"+keyword+"%").
+	result := query.
 		Offset(offset).
 		Limit(pageSize).
 		Order("created_at DESC").
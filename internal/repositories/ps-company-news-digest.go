@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type CompanyNewsDigestRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewCompanyNewsDigestRepositoryDB(db *gorm.DB) *CompanyNewsDigestRepositoryDB {
+	if err := db.AutoMigrate(&domains.CompanyNewsDigestSubscription{}, &domains.CompanyNewsDigestRun{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &CompanyNewsDigestRepositoryDB{db: db}
+}
+
+// GetDigestSubscription returns gorm.ErrRecordNotFound when the employee never chose
+func (r *CompanyNewsDigestRepositoryDB) GetDigestSubscription(empCode string) (domains.CompanyNewsDigestSubscription, error) {
+	var sub domains.CompanyNewsDigestSubscription
+	err := r.db.Where("emp_code = ?", empCode).First(&sub).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		fmt.Printf("GetDigestSubscription error: %v\n", err)
+	}
+	return sub, err
+}
+
+func (r *CompanyNewsDigestRepositoryDB) SaveDigestSubscription(subscription *domains.CompanyNewsDigestSubscription) error {
+	if err := r.db.Save(subscription).Error; err != nil {
+		fmt.Printf("SaveDigestSubscription error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// UnsubscribeDigestByToken reports false when the token is unknown
+func (r *CompanyNewsDigestRepositoryDB) UnsubscribeDigestByToken(token string) (bool, error) {
+	result := r.db.Model(&domains.CompanyNewsDigestSubscription{}).
+		Where("unsubscribe_token = ?", token).
+		Update("subscribed", false)
+	if result.Error != nil {
+		fmt.Printf("UnsubscribeDigestByToken error: %v\n", result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *CompanyNewsDigestRepositoryDB) digestRecipients() *gorm.DB {
+	return r.db.Table("company_news_digest_subscriptions s").
+		Select("e.*, s.lang, s.unsubscribe_token").
+		Joins("JOIN ps_employees e ON e.UHR_EmpCode = s.emp_code").
+		Where("s.subscribed = 1 AND e.status_login = ? AND e.AD_Mail IS NOT NULL AND e.AD_Mail <> ''", "ENABLE")
+}
+
+func (r *CompanyNewsDigestRepositoryDB) GetDigestRecipients() ([]domains.CompanyNewsDigestRecipient, error) {
+	var recipients []domains.CompanyNewsDigestRecipient
+	if err := r.digestRecipients().Order("e.UHR_EmpCode").Scan(&recipients).Error; err != nil {
+		fmt.Printf("GetDigestRecipients error: %v\n", err)
+		return nil, err
+	}
+	return recipients, nil
+}
+
+func (r *CompanyNewsDigestRepositoryDB) GetDigestRecipient(empCode string) (domains.CompanyNewsDigestRecipient, error) {
+	var recipients []domains.CompanyNewsDigestRecipient
+	if err := r.digestRecipients().Where("e.UHR_EmpCode = ?", empCode).Scan(&recipients).Error; err != nil {
+		fmt.Printf("GetDigestRecipient error: %v\n", err)
+		return domains.CompanyNewsDigestRecipient{}, err
+	}
+	if len(recipients) == 0 {
+		return domains.CompanyNewsDigestRecipient{}, gorm.ErrRecordNotFound
+	}
+	return recipients[0], nil
+}
+
+func (r *CompanyNewsDigestRepositoryDB) CreateDigestRun(run *domains.CompanyNewsDigestRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		fmt.Printf("CreateDigestRun error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// GetLastDigestRun returns nil when no digest has been sent yet
+func (r *CompanyNewsDigestRepositoryDB) GetLastDigestRun() (*domains.CompanyNewsDigestRun, error) {
+	var runs []domains.CompanyNewsDigestRun
+	if err := r.db.Order("period_to DESC").Limit(1).Find(&runs).Error; err != nil {
+		fmt.Printf("GetLastDigestRun error: %v\n", err)
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
@@ -1,14 +1,18 @@
 package repositories
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"backend/internal/core/domains"
+	"backend/internal/core/models"
 	ports "backend/internal/core/ports/repositories"
+	"backend/internal/pkgs/utils"
 )
 
 type CompanyNewsRepositoryDB struct {
@@ -19,10 +23,27 @@ func NewCompanyNewsRepositoryDB(db *gorm.DB) ports.CompanyNewsRepository {
 	// if err := db.AutoMigrate(&domains.CompanyNews{}); err != nil {
 	// 	fmt.Printf("failed to auto migrate: %v", err)
 	// }
+	if err := db.AutoMigrate(&domains.CompanyNewsAudience{}, &domains.CompanyNewsAttachment{}, &domains.CompanyNewsRevision{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
 	return &CompanyNewsRepositoryDB{db: db}
 }
 
-func (r *CompanyNewsRepositoryDB) CreateCompanyNews(n *domains.CompanyNews) error {
+// CreateCompanyNews inserts the article and its audiences together, so a failed audience insert
+// cannot leave restricted news visible to everyone
+func (r *CompanyNewsRepositoryDB) CreateCompanyNews(n *domains.CompanyNews, audiences []domains.CompanyNewsAudience) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := insertCompanyNews(tx, n); err != nil {
+			return err
+		}
+		if len(audiences) == 0 {
+			return nil
+		}
+		return replaceCompanyNewsAudiences(tx, n.CompanyNewsID.String(), audiences)
+	})
+}
+
+func insertCompanyNews(tx *gorm.DB, n *domains.CompanyNews) error {
 	now := time.Now()
 	if n.CreatedAt.IsZero() {
 		n.CreatedAt = now
@@ -33,10 +54,10 @@ func (r *CompanyNewsRepositoryDB) CreateCompanyNews(n *domains.CompanyNews) erro
 
 	const q = `
 	INSERT INTO company_news
-		(company_news_id, company_news_photo, title, content, category, username_creator, created_at, updated_at)
+		(company_news_id, company_news_photo, title, content, content_text, category, username_creator, created_at, updated_at)
 	OUTPUT CONVERT(NVARCHAR(36), INSERTED.company_news_id) AS company_news_id, INSERTED.created_at, INSERTED.updated_at
 	VALUES
-		(NEWID(), ?, ?, ?, ?, ?, ?, ?);
+		(NEWID(), ?, ?, ?, ?, ?, ?, ?, ?);
 `
 
 	type ret struct {
@@ -46,11 +67,12 @@ func (r *CompanyNewsRepositoryDB) CreateCompanyNews(n *domains.CompanyNews) erro
 	}
 
 	var out ret
-	if err := r.db.
+	if err := tx.
 		Raw(q,
 			n.CompanyNewsPhoto,
 			n.Title,
 			n.Content,
+			utils.PlainText(n.Content),
 			n.Category,
 			n.UsernameCreator,
 			n.CreatedAt,
@@ -74,8 +96,10 @@ func (r *CompanyNewsRepositoryDB) CreateCompanyNews(n *domains.CompanyNews) erro
 	return nil
 }
 
-func (r *CompanyNewsRepositoryDB) GetCompanyNewsByID(companyNewsID string) (domains.CompanyNews, error) {
-	const q = `
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsByID(companyNewsID string, viewer domains.NewsViewer) (domains.CompanyNews, error) {
+	audienceQ, audienceArgs := newsAudienceCondition(viewer)
+
+	q := `
 		SELECT TOP 1
 			CONVERT(NVARCHAR(36), company_news_id) AS company_news_id,
 			company_news_photo,
@@ -86,7 +110,7 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNewsByID(companyNewsID string) (doma
 			created_at,
 			updated_at
 		FROM company_news
-		WHERE CONVERT(NVARCHAR(36), company_news_id) = ?
+		WHERE CONVERT(NVARCHAR(36), company_news_id) = ? AND deleted_at IS NULL` + audienceQ + `
 		ORDER BY created_at DESC;
 	`
 
@@ -102,10 +126,14 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNewsByID(companyNewsID string) (doma
 	}
 
 	var row companyNewsRow
-	if err := r.db.Raw(q, companyNewsID).Scan(&row).Error; err != nil {
+	args := append([]interface{}{companyNewsID}, audienceArgs...)
+	if err := r.db.Raw(q, args...).Scan(&row).Error; err != nil {
 		fmt.Printf("GetCompanyNewsByID error: %v\n", err)
 		return domains.CompanyNews{}, err
 	}
+	if row.CompanyNewsID == "" {
+		return domains.CompanyNews{}, gorm.ErrRecordNotFound
+	}
 
 	id, err := uuid.Parse(row.CompanyNewsID)
 	if err != nil {
@@ -172,11 +200,13 @@ func (r *CompanyNewsRepositoryDB) GetAllCompanyNews() ([]domains.CompanyNews, er
 	return list, nil
 }
 
-func (r *CompanyNewsRepositoryDB) GetCompanyNews(limit, offset int) ([]domains.CompanyNews, int64, error) {
+func (r *CompanyNewsRepositoryDB) GetCompanyNews(viewer domains.NewsViewer, limit, offset int) ([]domains.CompanyNews, int64, error) {
 	var total int64
 
-	const countQ = `SELECT COUNT(*) FROM company_news WHERE deleted_at IS NULL;`
-	if err := r.db.Raw(countQ).Scan(&total).Error; err != nil {
+	audienceQ, audienceArgs := newsAudienceCondition(viewer)
+
+	countQ := `SELECT COUNT(*) FROM company_news WHERE deleted_at IS NULL` + audienceQ + `;`
+	if err := r.db.Raw(countQ, audienceArgs...).Scan(&total).Error; err != nil {
 		fmt.Printf("GetCompanyNews count error: %v\n", err)
 		return nil, 0, err
 	}
@@ -188,12 +218,12 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNews(limit, offset int) ([]domains.C
 		offset = 0
 	}
 
-	const q = `
+	q := `
     SELECT CONVERT(NVARCHAR(36), company_news_id) AS company_news_id, 
            company_news_photo, title, content, category,
            username_creator, created_at, updated_at
     FROM company_news
-    WHERE deleted_at IS NULL
+    WHERE deleted_at IS NULL` + audienceQ + `
     ORDER BY created_at DESC
     OFFSET ? ROWS
     FETCH NEXT ? ROWS ONLY;
@@ -211,7 +241,8 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNews(limit, offset int) ([]domains.C
 	}
 
 	var rows []companyNewsRow
-	if err := r.db.Raw(q, offset, limit).Scan(&rows).Error; err != nil {
+	args := append(audienceArgs, offset, limit)
+	if err := r.db.Raw(q, args...).Scan(&rows).Error; err != nil {
 		fmt.Printf("GetCompanyNews list error: %v\n", err)
 		return nil, 0, err
 	}
@@ -239,7 +270,26 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNews(limit, offset int) ([]domains.C
 }
 
 func (r *CompanyNewsRepositoryDB) UpdateCompanyNewsWithMap(companyNewsID string, updates map[string]interface{}) error {
-	if err := r.db.Model(&domains.CompanyNews{}).
+	return updateCompanyNews(r.db, companyNewsID, updates)
+}
+
+// UpdateCompanyNewsWithAudiences applies updates and replaces the audiences in one transaction
+func (r *CompanyNewsRepositoryDB) UpdateCompanyNewsWithAudiences(companyNewsID string, updates map[string]interface{}, audiences []domains.CompanyNewsAudience) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := updateCompanyNews(tx, companyNewsID, updates); err != nil {
+				return err
+			}
+		}
+		return replaceCompanyNewsAudiences(tx, companyNewsID, audiences)
+	})
+}
+
+func updateCompanyNews(tx *gorm.DB, companyNewsID string, updates map[string]interface{}) error {
+	if content, ok := updates["content"].(string); ok {
+		updates["content_text"] = utils.PlainText(content)
+	}
+	if err := tx.Model(&domains.CompanyNews{}).
 		Where("company_news_id = ?", companyNewsID).
 		Updates(updates).Error; err != nil {
 		fmt.Printf("UpdateMeetingRoomWithMap error: %v\n", err)
@@ -257,8 +307,10 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNewsCount() (int64, error) {
 	return cnt, nil
 }
 
-func (r *CompanyNewsRepositoryDB) GetCompanyNewsByTitle(title string) (domains.CompanyNews, error) {
-	const q = `
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsByTitle(title string, viewer domains.NewsViewer) (domains.CompanyNews, error) {
+	audienceQ, audienceArgs := newsAudienceCondition(viewer)
+
+	q := `
 		SELECT TOP 1
 			CONVERT(NVARCHAR(36), company_news_id) AS company_news_id,
 			company_news_photo,
@@ -269,7 +321,7 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNewsByTitle(title string) (domains.C
 			created_at,
 			updated_at
 		FROM company_news
-		WHERE title = ?
+		WHERE title = ? AND deleted_at IS NULL` + audienceQ + `
 		ORDER BY created_at DESC;
 	`
 
@@ -285,9 +337,13 @@ func (r *CompanyNewsRepositoryDB) GetCompanyNewsByTitle(title string) (domains.C
 	}
 
 	var row companyNewsRow
-	if err := r.db.Debug().Raw(q, title).Scan(&row).Error; err != nil {
+	args := append([]interface{}{title}, audienceArgs...)
+	if err := r.db.Debug().Raw(q, args...).Scan(&row).Error; err != nil {
 		return domains.CompanyNews{}, err
 	}
+	if row.CompanyNewsID == "" {
+		return domains.CompanyNews{}, gorm.ErrRecordNotFound
+	}
 
 	id, err := uuid.Parse(row.CompanyNewsID)
 	if err != nil {
@@ -317,3 +373,423 @@ func (r *CompanyNewsRepositoryDB) DeleteCompanyNews(companyNewsID string) error
 	}
 	return nil
 }
+
+// SearchCompanyNews matches every term against title or the tag-stripped content (case- and
+// accent-insensitive) and ranks title hits above content hits.
+func (r *CompanyNewsRepositoryDB) SearchCompanyNews(search domains.CompanyNewsSearch) ([]domains.CompanyNewsSearchHit, int64, error) {
+	if search.Limit <= 0 {
+		search.Limit = 10
+	}
+	if search.Offset < 0 {
+		search.Offset = 0
+	}
+
+	const titleLike = "title COLLATE " + utils.SearchCollation + " LIKE ?"
+	const contentLike = "content_text COLLATE " + utils.SearchCollation + " LIKE ?"
+
+	where := []string{"deleted_at IS NULL"}
+	var whereArgs []interface{}
+	score := []string{"0"}
+	var scoreArgs []interface{}
+
+	for _, term := range search.Terms {
+		pattern := utils.LikeContains(term)
+		where = append(where, "("+titleLike+" OR "+contentLike+")")
+		whereArgs = append(whereArgs, pattern, pattern)
+		score = append(score,
+			"CASE WHEN "+titleLike+" THEN 10 ELSE 0 END",
+			"CASE WHEN "+contentLike+" THEN 3 ELSE 0 END")
+		scoreArgs = append(scoreArgs, pattern, pattern)
+	}
+	if len(search.Terms) > 1 {
+		phrase := utils.LikeContains(strings.Join(search.Terms, " "))
+		score = append(score,
+			"CASE WHEN "+titleLike+" THEN 20 ELSE 0 END",
+			"CASE WHEN "+contentLike+" THEN 5 ELSE 0 END")
+		scoreArgs = append(scoreArgs, phrase, phrase)
+	}
+
+	if search.Category != "" {
+		where = append(where, "category = ?")
+		whereArgs = append(whereArgs, search.Category)
+	}
+	if search.Creator != "" {
+		where = append(where, "username_creator = ?")
+		whereArgs = append(whereArgs, search.Creator)
+	}
+	if search.DateFrom != nil {
+		where = append(where, "created_at >= ?")
+		whereArgs = append(whereArgs, *search.DateFrom)
+	}
+	if search.DateTo != nil {
+		where = append(where, "created_at < ?")
+		whereArgs = append(whereArgs, *search.DateTo)
+	}
+
+	audienceQ, audienceArgs := newsAudienceCondition(search.Viewer)
+	whereQ := strings.Join(where, " AND ") + audienceQ
+	whereArgs = append(whereArgs, audienceArgs...)
+
+	var total int64
+	countQ := `SELECT COUNT(*) FROM company_news WHERE ` + whereQ + `;`
+	if err := r.db.Raw(countQ, whereArgs...).Scan(&total).Error; err != nil {
+		fmt.Printf("SearchCompanyNews count error: %v\n", err)
+		return nil, 0, err
+	}
+
+	q := `
+    SELECT CONVERT(NVARCHAR(36), company_news_id) AS company_news_id,
+           company_news_photo, title, content, category,
+           username_creator, created_at, updated_at,
+           (` + strings.Join(score, " + ") + `) AS score
+    FROM company_news
+    WHERE ` + whereQ + `
+    ORDER BY score DESC, created_at DESC
+    OFFSET ? ROWS
+    FETCH NEXT ? ROWS ONLY;
+`
+
+	type companyNewsRow struct {
+		CompanyNewsID    string    `gorm:"column:company_news_id"`
+		CompanyNewsPhoto string    `gorm:"column:company_news_photo"`
+		Title            string    `gorm:"column:title"`
+		Content          string    `gorm:"column:content"`
+		Category         string    `gorm:"column:category"`
+		UsernameCreator  string    `gorm:"column:username_creator"`
+		CreatedAt        time.Time `gorm:"column:created_at"`
+		UpdatedAt        time.Time `gorm:"column:updated_at"`
+		Score            int       `gorm:"column:score"`
+	}
+
+	args := append(scoreArgs, whereArgs...)
+	args = append(args, search.Offset, search.Limit)
+
+	var rows []companyNewsRow
+	if err := r.db.Raw(q, args...).Scan(&rows).Error; err != nil {
+		fmt.Printf("SearchCompanyNews list error: %v\n", err)
+		return nil, 0, err
+	}
+
+	hits := make([]domains.CompanyNewsSearchHit, 0, len(rows))
+	for _, row := range rows {
+		id, err := uuid.Parse(row.CompanyNewsID)
+		if err != nil {
+			fmt.Printf("SearchCompanyNews parse UUID error: %v\n", err)
+			continue
+		}
+		hits = append(hits, domains.CompanyNewsSearchHit{
+			CompanyNews: domains.CompanyNews{
+				CompanyNewsID:    id,
+				CompanyNewsPhoto: row.CompanyNewsPhoto,
+				Title:            row.Title,
+				Content:          row.Content,
+				Category:         row.Category,
+				UsernameCreator:  row.UsernameCreator,
+				CreatedAt:        row.CreatedAt,
+				UpdatedAt:        row.UpdatedAt,
+			},
+			Score: row.Score,
+		})
+	}
+
+	return hits, total, nil
+}
+
+// newsAudienceCondition limits company_news rows to the ones targeted at the viewer.
+// News without any audience row is company-wide and always visible.
+func newsAudienceCondition(viewer domains.NewsViewer) (string, []interface{}) {
+	if viewer.SeeAll {
+		return "", nil
+	}
+
+	const companyWide = `
+		AND (NOT EXISTS (
+			SELECT 1 FROM company_news_audiences a
+			WHERE a.company_news_id = company_news.company_news_id
+		)`
+	if viewer.EmpCode == "" && viewer.Role == "" {
+		return companyWide + ")", nil
+	}
+
+	return companyWide + ` OR EXISTS (
+			SELECT 1 FROM company_news_audiences a
+			WHERE a.company_news_id = company_news.company_news_id
+			  AND ((a.target_type = 'department' AND a.target_value = ?)
+			    OR (a.target_type = 'group_department' AND a.target_value = ?)
+			    OR (a.target_type = 'org_group' AND a.target_value = ?)
+			    OR (a.target_type = 'role' AND UPPER(a.target_value) = UPPER(?)))
+		))`, []interface{}{viewer.Department, viewer.GroupDepartment, viewer.OrgGroup, viewer.Role}
+}
+
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsAudiences(companyNewsID string) ([]domains.CompanyNewsAudience, error) {
+	var audiences []domains.CompanyNewsAudience
+	if err := r.db.
+		Where("company_news_id = ?", companyNewsID).
+		Order("target_type, target_value").
+		Find(&audiences).Error; err != nil {
+		fmt.Printf("GetCompanyNewsAudiences error: %v\n", err)
+		return nil, err
+	}
+	return audiences, nil
+}
+
+// replaceCompanyNewsAudiences swaps the audiences of an article inside the caller's transaction
+func replaceCompanyNewsAudiences(tx *gorm.DB, companyNewsID string, audiences []domains.CompanyNewsAudience) error {
+	if err := tx.Where("company_news_id = ?", companyNewsID).Delete(&domains.CompanyNewsAudience{}).Error; err != nil {
+		fmt.Printf("replaceCompanyNewsAudiences delete error: %v\n", err)
+		return err
+	}
+	if len(audiences) == 0 {
+		return nil
+	}
+
+	for i := range audiences {
+		audiences[i].CompanyNewsAudienceID = 0
+		audiences[i].CompanyNewsID = companyNewsID
+	}
+	if err := tx.Create(&audiences).Error; err != nil {
+		fmt.Printf("replaceCompanyNewsAudiences create error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// audienceEmployees selects the enabled employees matched by at least one audience target
+func (r *CompanyNewsRepositoryDB) audienceEmployees(audiences []domains.CompanyNewsAudience) *gorm.DB {
+	q := r.db.Model(&domains.PSEmployee{}).Where("status_login = ?", "ENABLE")
+	if len(audiences) == 0 {
+		return q
+	}
+
+	match := r.db.Where("1 = 0")
+	for _, a := range audiences {
+		switch a.TargetType {
+		case domains.AudienceDepartment:
+			match = match.Or("UHR_Department = ?", a.TargetValue)
+		case domains.AudienceGroupDepartment:
+			match = match.Or("UHR_GroupDepartment = ?", a.TargetValue)
+		case domains.AudienceOrgGroup:
+			match = match.Or("UHR_OrgGroup = ?", a.TargetValue)
+		case domains.AudienceRole:
+			match = match.Or("UPPER(role) = UPPER(?)", a.TargetValue)
+		}
+	}
+	return q.Where(match)
+}
+
+func (r *CompanyNewsRepositoryDB) CountAudienceEmployees(audiences []domains.CompanyNewsAudience) ([]models.CompanyNewsAudienceDepartmentCount, error) {
+	var counts []models.CompanyNewsAudienceDepartmentCount
+	if err := r.audienceEmployees(audiences).
+		Select("UHR_Department AS department, COUNT(*) AS total").
+		Group("UHR_Department").
+		Order("total DESC").
+		Scan(&counts).Error; err != nil {
+		fmt.Printf("CountAudienceEmployees error: %v\n", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *CompanyNewsRepositoryDB) GetAudienceEmployees(audiences []domains.CompanyNewsAudience, limit int) ([]domains.PSEmployee, error) {
+	var emps []domains.PSEmployee
+	if err := r.audienceEmployees(audiences).
+		Order("UHR_EmpCode").
+		Limit(limit).
+		Find(&emps).Error; err != nil {
+		fmt.Printf("GetAudienceEmployees error: %v\n", err)
+		return nil, err
+	}
+	return emps, nil
+}
+
+// CreateCompanyNewsAttachments appends the attachments after the existing ones of the same kind
+func (r *CompanyNewsRepositoryDB) CreateCompanyNewsAttachments(attachments []domains.CompanyNewsAttachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		next := make(map[string]int)
+		for i := range attachments {
+			a := &attachments[i]
+			key := a.CompanyNewsID + "|" + a.Kind
+			if _, ok := next[key]; !ok {
+				var maxOrder sql.NullInt64
+				if err := tx.Model(&domains.CompanyNewsAttachment{}).
+					Where("company_news_id = ? AND kind = ?", a.CompanyNewsID, a.Kind).
+					Select("MAX(sort_order)").
+					Row().Scan(&maxOrder); err != nil {
+					fmt.Printf("CreateCompanyNewsAttachments sort order error: %v\n", err)
+					return err
+				}
+				if maxOrder.Valid {
+					next[key] = int(maxOrder.Int64) + 1
+				}
+			}
+			a.SortOrder = next[key]
+			next[key]++
+		}
+
+		if err := tx.Create(&attachments).Error; err != nil {
+			fmt.Printf("CreateCompanyNewsAttachments error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// companyNewsAttachmentColumns reads company_news_id as text; the driver returns uniqueidentifier as raw bytes
+const companyNewsAttachmentColumns = `company_news_attachment_id,
+	LOWER(CONVERT(NVARCHAR(36), company_news_id)) AS company_news_id,
+	kind, file_path, original_name, mime_type, file_size, caption, sort_order,
+	username_creator, created_at, updated_at`
+
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsAttachments(companyNewsID string) ([]domains.CompanyNewsAttachment, error) {
+	var attachments []domains.CompanyNewsAttachment
+	if err := r.db.
+		Select(companyNewsAttachmentColumns).
+		Where("company_news_id = ?", companyNewsID).
+		Order("kind DESC, sort_order ASC, company_news_attachment_id ASC").
+		Find(&attachments).Error; err != nil {
+		fmt.Printf("GetCompanyNewsAttachments error: %v\n", err)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsAttachmentByID(attachmentID int) (domains.CompanyNewsAttachment, error) {
+	var attachment domains.CompanyNewsAttachment
+	if err := r.db.
+		Select(companyNewsAttachmentColumns).
+		First(&attachment, "company_news_attachment_id = ?", attachmentID).Error; err != nil {
+		return domains.CompanyNewsAttachment{}, err
+	}
+	return attachment, nil
+}
+
+func (r *CompanyNewsRepositoryDB) UpdateCompanyNewsAttachmentWithMap(attachmentID int, updates map[string]interface{}) error {
+	result := r.db.Model(&domains.CompanyNewsAttachment{}).
+		Where("company_news_attachment_id = ?", attachmentID).
+		Updates(updates)
+	if result.Error != nil {
+		fmt.Printf("UpdateCompanyNewsAttachmentWithMap error: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReorderCompanyNewsAttachments sets sort_order to the position of each ID in attachmentIDs.
+// Every ID must belong to the article.
+func (r *CompanyNewsRepositoryDB) ReorderCompanyNewsAttachments(companyNewsID string, attachmentIDs []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&domains.CompanyNewsAttachment{}).
+			Where("company_news_id = ? AND company_news_attachment_id IN ?", companyNewsID, attachmentIDs).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(attachmentIDs)) {
+			return gorm.ErrRecordNotFound
+		}
+
+		for i, id := range attachmentIDs {
+			if err := tx.Model(&domains.CompanyNewsAttachment{}).
+				Where("company_news_attachment_id = ?", id).
+				Update("sort_order", i).Error; err != nil {
+				fmt.Printf("ReorderCompanyNewsAttachments error: %v\n", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *CompanyNewsRepositoryDB) DeleteCompanyNewsAttachment(attachmentID int) error {
+	result := r.db.Where("company_news_attachment_id = ?", attachmentID).Delete(&domains.CompanyNewsAttachment{})
+	if result.Error != nil {
+		fmt.Printf("DeleteCompanyNewsAttachment error: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateCompanyNewsRevision stores the revision with the next revision number of the article
+func (r *CompanyNewsRepositoryDB) CreateCompanyNewsRevision(revision *domains.CompanyNewsRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxNo sql.NullInt64
+		if err := tx.Model(&domains.CompanyNewsRevision{}).
+			Where("company_news_id = ?", revision.CompanyNewsID).
+			Select("MAX(revision_no)").
+			Row().Scan(&maxNo); err != nil {
+			fmt.Printf("CreateCompanyNewsRevision revision no error: %v\n", err)
+			return err
+		}
+		revision.RevisionNo = int(maxNo.Int64) + 1
+
+		if err := tx.Create(revision).Error; err != nil {
+			fmt.Printf("CreateCompanyNewsRevision error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *CompanyNewsRepositoryDB) CountCompanyNewsRevisions(companyNewsID string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domains.CompanyNewsRevision{}).
+		Where("company_news_id = ?", companyNewsID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// companyNewsRevisionColumns reads company_news_id as text; the driver returns uniqueidentifier as raw bytes
+const companyNewsRevisionColumns = `company_news_revision_id,
+	LOWER(CONVERT(NVARCHAR(36), company_news_id)) AS company_news_id,
+	revision_no, action, changed_fields, snapshot, restored_from, username, created_at`
+
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsRevisions(companyNewsID string) ([]domains.CompanyNewsRevision, error) {
+	var revisions []domains.CompanyNewsRevision
+	if err := r.db.
+		Select(companyNewsRevisionColumns).
+		Where("company_news_id = ?", companyNewsID).
+		Order("revision_no DESC").
+		Find(&revisions).Error; err != nil {
+		fmt.Printf("GetCompanyNewsRevisions error: %v\n", err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *CompanyNewsRepositoryDB) GetCompanyNewsRevision(companyNewsID string, revisionNo int) (domains.CompanyNewsRevision, error) {
+	var revision domains.CompanyNewsRevision
+	if err := r.db.
+		Select(companyNewsRevisionColumns).
+		Where("company_news_id = ? AND revision_no = ?", companyNewsID, revisionNo).
+		First(&revision).Error; err != nil {
+		return domains.CompanyNewsRevision{}, err
+	}
+	return revision, nil
+}
+
+// UndeleteCompanyNews clears deleted_at of a soft-deleted article
+func (r *CompanyNewsRepositoryDB) UndeleteCompanyNews(companyNewsID string) error {
+	result := r.db.Unscoped().Model(&domains.CompanyNews{}).
+		Where("company_news_id = ? AND deleted_at IS NOT NULL", companyNewsID).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now()})
+	if result.Error != nil {
+		fmt.Printf("UndeleteCompanyNews error: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
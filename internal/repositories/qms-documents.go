@@ -3,6 +3,7 @@ package repositories
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -14,7 +15,7 @@ type QmsDocumentsRepository struct {
 }
 
 func NewQmsDocumentsRepository(db *gorm.DB) *QmsDocumentsRepository {
-	if err := db.AutoMigrate(&domains.User{}, &domains.QmsDocuments{}); err != nil {
+	if err := db.AutoMigrate(&domains.User{}, &domains.QmsDocuments{}, &domains.QmsDocumentApproval{}); err != nil {
 		fmt.Printf("failed to auto migrate: %v", err)
 	}
 	return &QmsDocumentsRepository{db: db}
@@ -38,16 +39,17 @@ func (r *QmsDocumentsRepository) GetQmsDocumentByID(id int) (*domains.QmsDocumen
 	return &doc, nil
 }
 
-func (r *QmsDocumentsRepository) GetAllQmsDocuments(page, pageSize int) ([]domains.QmsDocuments, int64, error) {
+func (r *QmsDocumentsRepository) GetAllQmsDocuments(page, pageSize int, filter domains.QmsDocumentFilter) ([]domains.QmsDocuments, int64, error) {
 	var docs []domains.QmsDocuments
 	var total int64
 
-	if err := r.db.Model(&domains.QmsDocuments{}).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+	query := r.filteredQmsDocuments(filter)
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	if err := r.db.Where("deleted_at IS NULL").Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&docs).Error; err != nil {
+	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&docs).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -91,6 +93,24 @@ func (r *QmsDocumentsRepository) UpdateQmsDocument(id int, updates map[string]in
 			doc.FileName = s
 		}
 	}
+	for key, field := range map[string]*string{
+		"document_no":       &doc.DocumentNo,
+		"revision":          &doc.Revision,
+		"owner_emp_code":    &doc.OwnerEmpCode,
+		"reviewer_emp_code": &doc.ReviewerEmpCode,
+		"approver_emp_code": &doc.ApproverEmpCode,
+	} {
+		if v, ok := updates[key]; ok {
+			if s, ok2 := v.(string); ok2 {
+				*field = s
+			}
+		}
+	}
+	if v, ok := updates["review_due_date"]; ok {
+		if t, ok2 := v.(*time.Time); ok2 {
+			doc.ReviewDueDate = t
+		}
+	}
 
 	result := r.db.Save(&doc)
 	if result.Error != nil {
@@ -123,14 +143,14 @@ func (r *QmsDocumentsRepository) DeleteQmsDocument(id int) error {
 	return nil
 }
 
-func (r *QmsDocumentsRepository) SearchQmsDocuments(keyword string, page, pageSize int) ([]domains.QmsDocuments, int64, error) {
+func (r *QmsDocumentsRepository) SearchQmsDocuments(search domains.DocumentSearch, page, pageSize int, filter domains.QmsDocumentFilter) ([]domains.QmsDocuments, int64, error) {
 	var docs []domains.QmsDocuments
 	var total int64
 
-	// Search 'desc' column for description to align with existing schema naming
-	query := r.db.Where("(qms_documents_name LIKE ? OR desc LIKE ?) AND deleted_at IS NULL", "%"+keyword+"%", "%"+keyword+"%")
+	filter.Search = &search
+	query := r.filteredQmsDocuments(filter)
 
-	if err := query.Model(&domains.QmsDocuments{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -141,3 +161,101 @@ func (r *QmsDocumentsRepository) SearchQmsDocuments(keyword string, page, pageSi
 
 	return docs, total, nil
 }
+
+func (r *QmsDocumentsRepository) filteredQmsDocuments(filter domains.QmsDocumentFilter) *gorm.DB {
+	query := r.db.Model(&domains.QmsDocuments{}).Where("deleted_at IS NULL")
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.Search != nil {
+		query = query.Scopes(documentSearchScope(domains.DocModuleQms, *filter.Search))
+	}
+	return query
+}
+
+// TransitionQmsDocument applies a workflow step only if the document is still in fromStatus, and logs it.
+// Releasing a document obsoletes the previously effective document with the same number.
+func (r *QmsDocumentsRepository) TransitionQmsDocument(id int, fromStatus string, updates map[string]interface{}, approval *domains.QmsDocumentApproval) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates["updated_at"] = time.Now()
+		result := tx.Model(&domains.QmsDocuments{}).
+			Where("qms_documents_id = ? AND status = ? AND deleted_at IS NULL", id, fromStatus).
+			Updates(updates)
+		if result.Error != nil {
+			fmt.Printf("TransitionQmsDocument error: %v\n", result.Error)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if approval.ToStatus == domains.QmsStatusEffective {
+			var doc domains.QmsDocuments
+			if err := tx.First(&doc, id).Error; err != nil {
+				return err
+			}
+			if doc.DocumentNo != "" {
+				var superseded []domains.QmsDocuments
+				if err := tx.Where("document_no = ? AND status = ? AND qms_documents_id <> ? AND deleted_at IS NULL", doc.DocumentNo, domains.QmsStatusEffective, id).
+					Find(&superseded).Error; err != nil {
+					return err
+				}
+				now := time.Now()
+				for _, old := range superseded {
+					if err := tx.Model(&domains.QmsDocuments{}).Where("qms_documents_id = ?", old.QmsDocumentsID).
+						Updates(map[string]interface{}{"status": domains.QmsStatusObsolete, "obsoleted_at": now, "updated_at": now}).Error; err != nil {
+						return err
+					}
+					if err := tx.Create(&domains.QmsDocumentApproval{
+						QmsDocumentsID: old.QmsDocumentsID,
+						Action:         domains.QmsActionObsolete,
+						FromStatus:     domains.QmsStatusEffective,
+						ToStatus:       domains.QmsStatusObsolete,
+						EmpCode:        approval.EmpCode,
+						Comment:        fmt.Sprintf("Superseded by revision %s", doc.Revision),
+					}).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		approval.QmsDocumentsID = id
+		if err := tx.Create(approval).Error; err != nil {
+			fmt.Printf("TransitionQmsDocument approval error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *QmsDocumentsRepository) GetQmsDocumentApprovals(id int) ([]domains.QmsDocumentApproval, error) {
+	var approvals []domains.QmsDocumentApproval
+	if err := r.db.Where("qms_documents_id = ?", id).Order("created_at ASC, qms_document_approval_id ASC").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// GetQmsDocumentsReviewDue returns effective documents whose periodic review is due before the given date
+func (r *QmsDocumentsRepository) GetQmsDocumentsReviewDue(before time.Time) ([]domains.QmsDocuments, error) {
+	var docs []domains.QmsDocuments
+	if err := r.db.Where("deleted_at IS NULL AND status = ? AND review_due_date IS NOT NULL AND review_due_date <= ?", domains.QmsStatusEffective, before).
+		Order("review_due_date ASC").
+		Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// GetQmsDocumentTasks returns documents in progress where the employee is owner, reviewer or approver
+func (r *QmsDocumentsRepository) GetQmsDocumentTasks(empCode string) ([]domains.QmsDocuments, error) {
+	var docs []domains.QmsDocuments
+	if err := r.db.Where("deleted_at IS NULL AND status IN ?", []string{domains.QmsStatusDraft, domains.QmsStatusUnderReview, domains.QmsStatusApproved}).
+		Where("owner_emp_code = ? OR reviewer_emp_code = ? OR approver_emp_code = ?", empCode, empCode, empCode).
+		Order("updated_at DESC").
+		Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
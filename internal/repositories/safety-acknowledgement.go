@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type SafetyAcknowledgementRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewSafetyAcknowledgementRepositoryDB(db *gorm.DB) *SafetyAcknowledgementRepositoryDB {
+	if err := db.AutoMigrate(&domains.SafetyAckRequirement{}, &domains.SafetyAckTarget{}, &domains.SafetyAcknowledgement{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &SafetyAcknowledgementRepositoryDB{db: db}
+}
+
+// ackTargetCondition matches employee alias e against the targets of requirement alias r
+const ackTargetCondition = `EXISTS (
+		SELECT 1 FROM safety_document_ack_targets t
+		WHERE t.safety_ack_requirement_id = r.safety_ack_requirement_id
+		  AND (t.target_type = 'all'
+		    OR (t.target_type = 'department' AND t.target_value = e.UHR_Department)
+		    OR (t.target_type = 'position' AND t.target_value = e.UHR_Position))
+	)`
+
+// CreateAckRequirement replaces the active requirement of the document with a new one
+func (r *SafetyAcknowledgementRepositoryDB) CreateAckRequirement(requirement *domains.SafetyAckRequirement, targets []domains.SafetyAckTarget) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domains.SafetyAckRequirement{}).
+			Where("safety_document_id = ? AND active = 1", requirement.SafetyDocumentID).
+			Update("active", false).Error; err != nil {
+			fmt.Printf("CreateAckRequirement deactivate error: %v\n", err)
+			return err
+		}
+
+		requirement.Active = true
+		if err := tx.Create(requirement).Error; err != nil {
+			fmt.Printf("CreateAckRequirement error: %v\n", err)
+			return err
+		}
+
+		for i := range targets {
+			targets[i].SafetyAckTargetID = 0
+			targets[i].SafetyAckRequirementID = requirement.SafetyAckRequirementID
+		}
+		if err := tx.Create(&targets).Error; err != nil {
+			fmt.Printf("CreateAckRequirement targets error: %v\n", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// CloseAckRequirement stops asking for acknowledgement; recorded acknowledgements are kept
+func (r *SafetyAcknowledgementRepositoryDB) CloseAckRequirement(safetyDocumentID int) error {
+	if err := r.db.Model(&domains.SafetyAckRequirement{}).
+		Where("safety_document_id = ? AND active = 1", safetyDocumentID).
+		Update("active", false).Error; err != nil {
+		fmt.Printf("CloseAckRequirement error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// GetActiveAckRequirement returns nil when the document does not require acknowledgement
+func (r *SafetyAcknowledgementRepositoryDB) GetActiveAckRequirement(safetyDocumentID int) (*domains.SafetyAckRequirement, []domains.SafetyAckTarget, error) {
+	var requirement domains.SafetyAckRequirement
+	if err := r.db.Where("safety_document_id = ? AND active = 1", safetyDocumentID).
+		Order("safety_ack_requirement_id DESC").
+		First(&requirement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		fmt.Printf("GetActiveAckRequirement error: %v\n", err)
+		return nil, nil, err
+	}
+
+	var targets []domains.SafetyAckTarget
+	if err := r.db.Where("safety_ack_requirement_id = ?", requirement.SafetyAckRequirementID).
+		Order("target_type, target_value").
+		Find(&targets).Error; err != nil {
+		fmt.Printf("GetActiveAckRequirement targets error: %v\n", err)
+		return nil, nil, err
+	}
+	return &requirement, targets, nil
+}
+
+// IsAckTargeted reports whether the employee is one of the requirement's targets
+func (r *SafetyAcknowledgementRepositoryDB) IsAckTargeted(requirementID int, empCode string) (bool, error) {
+	var total int64
+	if err := r.db.Table("safety_document_ack_requirements r").
+		Joins("JOIN ps_employees e ON e.UHR_EmpCode = ?", empCode).
+		Where("r.safety_ack_requirement_id = ? AND "+ackTargetCondition, requirementID).
+		Count(&total).Error; err != nil {
+		fmt.Printf("IsAckTargeted error: %v\n", err)
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// GetAcknowledgement returns nil when the employee has not acknowledged the requirement yet
+func (r *SafetyAcknowledgementRepositoryDB) GetAcknowledgement(requirementID int, empCode string) (*domains.SafetyAcknowledgement, error) {
+	var ack domains.SafetyAcknowledgement
+	if err := r.db.Where("safety_ack_requirement_id = ? AND emp_code = ?", requirementID, empCode).First(&ack).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		fmt.Printf("GetAcknowledgement error: %v\n", err)
+		return nil, err
+	}
+	return &ack, nil
+}
+
+func (r *SafetyAcknowledgementRepositoryDB) CreateAcknowledgement(ack *domains.SafetyAcknowledgement) error {
+	if ack.AcknowledgedAt.IsZero() {
+		ack.AcknowledgedAt = time.Now()
+	}
+	if err := r.db.Create(ack).Error; err != nil {
+		fmt.Printf("CreateAcknowledgement error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// GetAckEmployeeStatuses lists the enabled employees targeted by the requirement with their acknowledgement time
+func (r *SafetyAcknowledgementRepositoryDB) GetAckEmployeeStatuses(requirementID int, department string) ([]domains.SafetyAckEmployeeStatus, error) {
+	query := r.db.Table("ps_employees e").
+		Select("e.UHR_EmpCode, e.UHR_FullName_th, e.UHR_FullName_en, e.UHR_Department, e.UHR_Position, a.acknowledged_at").
+		Joins("JOIN safety_document_ack_requirements r ON r.safety_ack_requirement_id = ?", requirementID).
+		Joins("LEFT JOIN safety_document_acknowledgements a ON a.safety_ack_requirement_id = r.safety_ack_requirement_id AND a.emp_code = e.UHR_EmpCode").
+		Where("e.status_login = ? AND "+ackTargetCondition, "ENABLE")
+	if department != "" {
+		query = query.Where("e.UHR_Department = ?", department)
+	}
+
+	var statuses []domains.SafetyAckEmployeeStatus
+	if err := query.Order("e.UHR_Department, e.UHR_EmpCode").Scan(&statuses).Error; err != nil {
+		fmt.Printf("GetAckEmployeeStatuses error: %v\n", err)
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// GetPendingAcknowledgements lists active requirements that target the employee and are not acknowledged yet
+func (r *SafetyAcknowledgementRepositoryDB) GetPendingAcknowledgements(empCode string) ([]domains.SafetyAckPending, error) {
+	var pending []domains.SafetyAckPending
+	if err := r.db.Table("safety_document_ack_requirements r").
+		Select("r.*, d.safety_document_name, d.category, d.department, d.file_name").
+		Joins("JOIN ps_safetys_documents d ON d.safety_document_id = r.safety_document_id AND d.deleted_at IS NULL").
+		Joins("JOIN ps_employees e ON e.UHR_EmpCode = ?", empCode).
+		Where("r.active = 1 AND "+ackTargetCondition).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM safety_document_acknowledgements a
+			WHERE a.safety_ack_requirement_id = r.safety_ack_requirement_id AND a.emp_code = ?
+		)`, empCode).
+		Order("r.due_date, r.created_at").
+		Scan(&pending).Error; err != nil {
+		fmt.Printf("GetPendingAcknowledgements error: %v\n", err)
+		return nil, err
+	}
+	return pending, nil
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -101,14 +103,12 @@ func (r *SafetyDocumentRepository) DeleteSafetyDocument(id int) error {
 	return nil
 }
 
-// SearchSafetyDocuments searches safety documents by keyword
-func (r *SafetyDocumentRepository) SearchSafetyDocuments(keyword string, limit, offset int) ([]domains.SafetyDocument, int64, error) {
+// SearchSafetyDocuments searches safety documents by keyword, including the text of their files
+func (r *SafetyDocumentRepository) SearchSafetyDocuments(search domains.DocumentSearch, limit, offset int) ([]domains.SafetyDocument, int64, error) {
 	var docs []domains.SafetyDocument
 	var total int64
 
-	query := r.db.Model(&domains.SafetyDocument{}).
-		Where("safety_document_name ILIKE ? OR safety_document_desc ILIKE ? OR department ILIKE ? OR category ILIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+	query := r.db.Model(&domains.SafetyDocument{}).Scopes(documentSearchScope(domains.DocModuleSafety, search))
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -120,3 +120,49 @@ func (r *SafetyDocumentRepository) SearchSafetyDocuments(keyword string, limit,
 
 	return docs, total, nil
 }
+
+// GetSafetyDocumentsDue retrieves documents whose next review or expiry falls on or before the given date
+func (r *SafetyDocumentRepository) GetSafetyDocumentsDue(before time.Time, department string) ([]domains.SafetyDocument, error) {
+	var docs []domains.SafetyDocument
+
+	query := r.db.Where("(next_review_date <= ? OR expiry_date <= ?)", before, before)
+	if department != "" {
+		query = query.Where("department = ?", department)
+	}
+
+	if err := query.Order("department ASC, safety_document_id ASC").Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// HasSafetyDocumentReminder reports whether a reminder of this kind was already sent for the due date
+func (r *SafetyDocumentRepository) HasSafetyDocumentReminder(id int, kind string, dueDate time.Time) (bool, error) {
+	var total int64
+	if err := r.db.Model(&domains.SafetyDocumentReminder{}).
+		Where("safety_document_id = ? AND kind = ? AND due_date = ?", id, kind, dueDate).
+		Count(&total).Error; err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// CreateSafetyDocumentReminder records a sent reminder
+func (r *SafetyDocumentRepository) CreateSafetyDocumentReminder(reminder *domains.SafetyDocumentReminder) error {
+	if err := r.db.Create(reminder).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSafetyReminderRecipients returns the document owner and every employee with the given role that can receive mail
+func (r *SafetyDocumentRepository) GetSafetyReminderRecipients(ownerEmpCode, role string) ([]domains.PSEmployee, error) {
+	var employees []domains.PSEmployee
+	if err := r.db.Where("status_login = ? AND AD_Mail IS NOT NULL AND AD_Mail <> ''", "ENABLE").
+		Where("UHR_EmpCode = ? OR UPPER(role) = ?", ownerEmpCode, strings.ToUpper(role)).
+		Order("UHR_EmpCode").
+		Find(&employees).Error; err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
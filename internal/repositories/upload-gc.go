@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+	"backend/internal/pkgs/utils"
+)
+
+const orphanUploadBatchSize = 500
+
+type UploadGCRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewUploadGCRepositoryDB(db *gorm.DB) *UploadGCRepositoryDB {
+	if err := db.AutoMigrate(&domains.OrphanUpload{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &UploadGCRepositoryDB{db: db}
+}
+
+// GetUploadReferences returns every stored file path value, plain or as a JSON array: the current and
+// older revision files of live documents, welfare benefit files and images, and the cover photos and
+// attachments of company news, deleted articles included since they can be undeleted
+func (r *UploadGCRepositoryDB) GetUploadReferences() ([]string, error) {
+	queries := make([]string, 0, 2*len(domains.DocumentModules)+4)
+	for _, mod := range domains.DocumentModules {
+		queries = append(queries,
+			fmt.Sprintf("SELECT file_name FROM %s WHERE deleted_at IS NULL", mod.Table),
+			fmt.Sprintf(`SELECT rv.file_name FROM document_revisions rv
+				INNER JOIN %s d ON d.%s = rv.document_id AND d.deleted_at IS NULL
+				WHERE rv.module = '%s'`, mod.Table, mod.IDColumn, mod.Name),
+		)
+	}
+	queries = append(queries,
+		"SELECT file_name FROM welfare_benefits WHERE deleted_at IS NULL",
+		"SELECT image_url FROM welfare_benefits WHERE deleted_at IS NULL",
+		"SELECT company_news_photo FROM company_news",
+		"SELECT file_path FROM company_news_attachments",
+	)
+
+	var refs []string
+	for _, q := range queries {
+		var values []*string
+		if err := r.db.Raw(q).Scan(&values).Error; err != nil {
+			fmt.Printf("GetUploadReferences error: %v\n", err)
+			return nil, err
+		}
+		for _, v := range values {
+			if v != nil && *v != "" {
+				refs = append(refs, *v)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// IsCompanyNewsContentFile reports whether an article, or one of its revisions that can be restored,
+// embeds the file in its content
+func (r *UploadGCRepositoryDB) IsCompanyNewsContentFile(fileName string) (bool, error) {
+	var found int
+	q := `
+		SELECT CASE WHEN EXISTS (SELECT 1 FROM company_news WHERE content LIKE ?)
+			OR EXISTS (SELECT 1 FROM company_news_revisions WHERE snapshot LIKE ?) THEN 1 ELSE 0 END`
+	like := utils.LikeContains(fileName)
+	if err := r.db.Raw(q, like, like).Scan(&found).Error; err != nil {
+		fmt.Printf("IsCompanyNewsContentFile error: %v\n", err)
+		return false, err
+	}
+	return found == 1, nil
+}
+
+func (r *UploadGCRepositoryDB) GetOrphanUploads() ([]domains.OrphanUpload, error) {
+	var orphans []domains.OrphanUpload
+	if err := r.db.Order("storage_key").Find(&orphans).Error; err != nil {
+		fmt.Printf("GetOrphanUploads error: %v\n", err)
+		return nil, err
+	}
+	return orphans, nil
+}
+
+func (r *UploadGCRepositoryDB) CreateOrphanUpload(orphan *domains.OrphanUpload) error {
+	if err := r.db.Create(orphan).Error; err != nil {
+		fmt.Printf("CreateOrphanUpload error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (r *UploadGCRepositoryDB) UpdateOrphanUploadSeen(key string, size int64, at time.Time) error {
+	err := r.db.Model(&domains.OrphanUpload{}).Where("storage_key = ?", key).
+		Updates(map[string]interface{}{"file_size": size, "last_seen_at": at}).Error
+	if err != nil {
+		fmt.Printf("UpdateOrphanUploadSeen error: %v\n", err)
+	}
+	return err
+}
+
+func (r *UploadGCRepositoryDB) DeleteOrphanUploads(keys []string) error {
+	for start := 0; start < len(keys); start += orphanUploadBatchSize {
+		end := min(start+orphanUploadBatchSize, len(keys))
+		if err := r.db.Where("storage_key IN ?", keys[start:end]).Delete(&domains.OrphanUpload{}).Error; err != nil {
+			fmt.Printf("DeleteOrphanUploads error: %v\n", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/core/domains"
+)
+
+type UploadedFileRepositoryDB struct {
+	db *gorm.DB
+}
+
+func NewUploadedFileRepositoryDB(db *gorm.DB) *UploadedFileRepositoryDB {
+	if err := db.AutoMigrate(&domains.UploadedFile{}); err != nil {
+		fmt.Printf("failed to auto migrate: %v", err)
+	}
+	return &UploadedFileRepositoryDB{db: db}
+}
+
+func (r *UploadedFileRepositoryDB) CreateUploadedFile(file *domains.UploadedFile) error {
+	if err := r.db.Create(file).Error; err != nil {
+		fmt.Printf("CreateUploadedFile error: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+func (r *UploadedFileRepositoryDB) GetUploadedFiles(query domains.UploadedFileQuery) ([]domains.UploadedFile, int64, error) {
+	var files []domains.UploadedFile
+	var total int64
+
+	tx := r.db.Model(&domains.UploadedFile{})
+	if query.ScanStatus != "" {
+		tx = tx.Where("scan_status = ?", query.ScanStatus)
+	}
+	if err := tx.Count(&total).Error; err != nil {
+		fmt.Printf("GetUploadedFiles error: %v\n", err)
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.PageSize
+	if err := tx.Order("created_at DESC").Offset(offset).Limit(query.PageSize).Find(&files).Error; err != nil {
+		fmt.Printf("GetUploadedFiles error: %v\n", err)
+		return nil, 0, err
+	}
+	return files, total, nil
+}
+
+func (r *UploadedFileRepositoryDB) GetUploadScanAlertRecipients(role string) ([]domains.PSEmployee, error) {
+	var employees []domains.PSEmployee
+	if err := r.db.Where("status_login = ? AND AD_Mail IS NOT NULL AND AD_Mail <> ''", "ENABLE").
+		Where("UPPER(role) = ?", strings.ToUpper(role)).
+		Order("UHR_EmpCode").
+		Find(&employees).Error; err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
@@ -107,8 +107,7 @@ func (r *WelfareBenefitRepository) SearchWelfareBenefits(keyword string, limit,
 	var total int64
 
 	query := r.db.Model(&domains.WelfareBenefit{}).
-		Where("title ILIKE ? OR description ILIKE ? OR category ILIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+		Scopes(keywordScope("welfare_benefits", []string{"title", "description", "category"}, keyword))
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
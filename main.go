@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,6 +17,12 @@ import (
 	"backend/app/middlewares"
 	"backend/configs"
 	database "backend/external/db"
+	"backend/internal/core/services"
+	"backend/internal/pkgs/mail"
+	"backend/internal/pkgs/scanner"
+	"backend/internal/pkgs/storage"
+	uploader "backend/internal/pkgs/utils"
+	"backend/internal/repositories"
 
 	// redisconfig "backend/external/redis"
 	"backend/internal/pkgs/logs"
@@ -30,6 +37,10 @@ func init() {
 	configs.Init()
 	logs.LogInit()
 	db = database.InitDataBase()
+	if err := storage.Init(storage.ConfigFromEnv()); err != nil {
+		log.Fatalf("Could not initialise file storage: %v", err)
+	}
+	storage.EnableContentAddressing(repositories.NewFileBlobRepositoryDB(db))
 	// redisClient = redisconfig.ConnectRedis()
 
 	// pong, err := redisClient.Ping(context.Background()).Result()
@@ -43,6 +54,10 @@ func main() {
 	app := fiber.New(fiber.Config{
 		AppName:   "atelnord",
 		BodyLimit: 50 * 1024 * 1024,
+		// the API is only reached through nginx, which sets X-Forwarded-For to the client address
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          configs.TrustedProxies(),
 	})
 
 	go func() {
@@ -56,17 +71,44 @@ func main() {
 
 	routes.SetupRoutes(app, db)
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	mailService := services.NewMailService(repositories.NewEmailOutboxRepository(db), mail.NewSender(mail.ConfigFromEnv()))
+	go mailService.StartOutboxWorker(workerCtx)
+
+	uploadScanner, err := scanner.New(scanner.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Could not initialise upload scanning: %v", err)
+	}
+	uploader.SetUploadGuard(services.NewUploadScanService(repositories.NewUploadedFileRepositoryDB(db), uploadScanner, mailService))
+
+	digestService := services.NewCompanyNewsDigestService(
+		repositories.NewCompanyNewsRepositoryDB(db),
+		repositories.NewCompanyNewsDigestRepositoryDB(db),
+		repositories.NewUserRepositoryDB(db),
+		mailService,
+	)
+	go digestService.StartDigestScheduler(workerCtx)
+
+	uploadGCService := services.NewUploadGCService(repositories.NewUploadGCRepositoryDB(db), repositories.NewFileBlobRepositoryDB(db))
+	go uploadGCService.StartScheduler(workerCtx)
+
+	safetyReminderService := services.NewSafetyDocumentReminderService(repositories.NewSafetyDocumentRepository(db), mailService)
+	go safetyReminderService.StartReminderScheduler(workerCtx)
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	go func() {
 		serv := <-c
 		if serv.String() == "interrupt" {
 			fmt.Println("Gracefully shutting down...")
+			stopWorkers()
 			app.Shutdown()
 		}
 	}()
 
-	err := app.Listen("0.0.0.0:" + os.Getenv("SERVER_PORT"))
+	err = app.Listen("0.0.0.0:" + os.Getenv("SERVER_PORT"))
 	if err != nil {
 		log.Fatal(err)
 	}
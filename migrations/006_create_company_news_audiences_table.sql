@@ -0,0 +1,22 @@
+-- Migration: Create company_news_audiences table
+-- Description: Audience targeting for company news (department, group department, org group, role).
+--              News without any audience row stays company-wide.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[company_news_audiences]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[company_news_audiences] (
+        [company_news_audience_id] INT PRIMARY KEY IDENTITY(1,1),
+        [company_news_id] UNIQUEIDENTIFIER NOT NULL,
+        [target_type] VARCHAR(30) NOT NULL,
+        [target_value] NVARCHAR(255) NOT NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- Create index for audience lookups per article
+    CREATE NONCLUSTERED INDEX [IX_company_news_audiences_company_news_id] ON [dbo].[company_news_audiences] ([company_news_id]);
+
+    -- Create index for matching an employee against targets
+    CREATE NONCLUSTERED INDEX [IX_company_news_audiences_target] ON [dbo].[company_news_audiences] ([target_type], [target_value]);
+
+    PRINT 'Table company_news_audiences created successfully'
+END
@@ -0,0 +1,25 @@
+-- Migration: Create company_news_attachments table
+-- Description: Ordered image gallery and document attachments per company news article
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[company_news_attachments]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[company_news_attachments] (
+        [company_news_attachment_id] INT PRIMARY KEY IDENTITY(1,1),
+        [company_news_id] UNIQUEIDENTIFIER NOT NULL,
+        [kind] VARCHAR(20) NOT NULL,
+        [file_path] NVARCHAR(500) NOT NULL,
+        [original_name] NVARCHAR(255) NULL,
+        [mime_type] VARCHAR(150) NULL,
+        [file_size] BIGINT NULL,
+        [caption] NVARCHAR(500) NULL,
+        [sort_order] INT NOT NULL DEFAULT 0,
+        [username_creator] NVARCHAR(100) NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE(),
+        [updated_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- Create index for loading attachments of an article in order
+    CREATE NONCLUSTERED INDEX [IX_company_news_attachments_company_news_id] ON [dbo].[company_news_attachments] ([company_news_id], [kind], [sort_order]);
+
+    PRINT 'Table company_news_attachments created successfully'
+END
@@ -0,0 +1,22 @@
+-- Migration: Create company_news_revisions table
+-- Description: Revision history (who, when, changed fields, full snapshot) for company news
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[company_news_revisions]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[company_news_revisions] (
+        [company_news_revision_id] INT PRIMARY KEY IDENTITY(1,1),
+        [company_news_id] UNIQUEIDENTIFIER NOT NULL,
+        [revision_no] INT NOT NULL,
+        [action] VARCHAR(20) NOT NULL,
+        [changed_fields] NVARCHAR(MAX) NULL,
+        [snapshot] NVARCHAR(MAX) NULL,
+        [restored_from] INT NULL,
+        [username] NVARCHAR(100) NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- One revision number per article
+    CREATE UNIQUE NONCLUSTERED INDEX [UX_company_news_revisions_news_revision] ON [dbo].[company_news_revisions] ([company_news_id], [revision_no]);
+
+    PRINT 'Table company_news_revisions created successfully'
+END
@@ -0,0 +1,30 @@
+-- Migration: Create email_outbox table
+-- Description: Persistent queue of outgoing emails. A background worker sends pending rows
+--              and retries failures with exponential backoff until max_attempts.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[email_outbox]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[email_outbox] (
+        [email_outbox_id] INT PRIMARY KEY IDENTITY(1,1),
+        [to_addresses] NVARCHAR(MAX) NOT NULL,
+        [cc_addresses] NVARCHAR(MAX) NULL,
+        [subject] NVARCHAR(500) NOT NULL,
+        [html_body] NVARCHAR(MAX) NULL,
+        [text_body] NVARCHAR(MAX) NULL,
+        [template] VARCHAR(100) NULL,
+        [lang] VARCHAR(5) NULL,
+        [status] VARCHAR(20) NOT NULL DEFAULT 'pending',
+        [attempts] INT NOT NULL DEFAULT 0,
+        [max_attempts] INT NOT NULL DEFAULT 8,
+        [next_attempt_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE(),
+        [last_error] NVARCHAR(MAX) NULL,
+        [sent_at] DATETIME2 NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE(),
+        [updated_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- Create index for the worker picking due rows
+    CREATE NONCLUSTERED INDEX [IX_email_outbox_status_next] ON [dbo].[email_outbox] ([status], [next_attempt_at]);
+
+    PRINT 'Table email_outbox created successfully'
+END
@@ -0,0 +1,40 @@
+-- Migration: Create company news digest tables
+-- Description: Employee opt-in for the weekly company news email digest, and a log of
+--              sent digests so each period is only mailed once.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[company_news_digest_subscriptions]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[company_news_digest_subscriptions] (
+        [company_news_digest_subscription_id] INT PRIMARY KEY IDENTITY(1,1),
+        [emp_code] NVARCHAR(50) NOT NULL,
+        [subscribed] BIT NOT NULL DEFAULT 1,
+        [lang] VARCHAR(5) NOT NULL DEFAULT 'th',
+        [unsubscribe_token] VARCHAR(64) NOT NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE(),
+        [updated_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- One subscription per employee
+    CREATE UNIQUE NONCLUSTERED INDEX [UX_company_news_digest_subscriptions_emp_code] ON [dbo].[company_news_digest_subscriptions] ([emp_code]);
+
+    -- Create index for unsubscribe links
+    CREATE UNIQUE NONCLUSTERED INDEX [UX_company_news_digest_subscriptions_token] ON [dbo].[company_news_digest_subscriptions] ([unsubscribe_token]);
+
+    PRINT 'Table company_news_digest_subscriptions created successfully'
+END
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[company_news_digest_runs]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[company_news_digest_runs] (
+        [company_news_digest_run_id] INT PRIMARY KEY IDENTITY(1,1),
+        [period_from] DATETIME2 NOT NULL,
+        [period_to] DATETIME2 NOT NULL,
+        [recipients] INT NOT NULL,
+        [skipped] INT NOT NULL,
+        [trigger_type] VARCHAR(20) NOT NULL,
+        [username] NVARCHAR(100) NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    PRINT 'Table company_news_digest_runs created successfully'
+END
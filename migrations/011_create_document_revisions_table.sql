@@ -0,0 +1,26 @@
+-- Migration: Create document_revisions table
+-- Description: File history for safety documents, procedure manuals, QMS documents,
+--              customer manuals and organization docs. The module table keeps the file
+--              of the revision flagged is_current.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[document_revisions]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[document_revisions] (
+        [document_revision_id] INT PRIMARY KEY IDENTITY(1,1),
+        [module] VARCHAR(30) NOT NULL,
+        [document_id] INT NOT NULL,
+        [revision_no] INT NOT NULL,
+        [revision_label] NVARCHAR(50) NULL,
+        [file_name] NVARCHAR(MAX) NOT NULL,
+        [uploaded_by] NVARCHAR(100) NULL,
+        [change_note] NVARCHAR(MAX) NULL,
+        [effective_date] DATE NULL,
+        [is_current] BIT NOT NULL DEFAULT 0,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- One revision number per document
+    CREATE UNIQUE NONCLUSTERED INDEX [UX_document_revisions_doc_revision] ON [dbo].[document_revisions] ([module], [document_id], [revision_no]);
+
+    PRINT 'Table document_revisions created successfully'
+END
@@ -0,0 +1,50 @@
+-- Migration: Add document control workflow to ps_qms_documents
+-- Description: Lifecycle draft -> under_review -> approved -> effective -> obsolete with
+--              document number, revision, owner/reviewer/approver and review-due date.
+--              Existing documents become effective so they stay visible.
+
+IF COL_LENGTH('dbo.ps_qms_documents', 'status') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[ps_qms_documents] ADD
+        [document_no] NVARCHAR(50) NULL,
+        [revision] NVARCHAR(20) NULL,
+        [status] VARCHAR(20) NOT NULL CONSTRAINT [DF_ps_qms_documents_status] DEFAULT 'effective',
+        [owner_emp_code] NVARCHAR(50) NULL,
+        [reviewer_emp_code] NVARCHAR(50) NULL,
+        [approver_emp_code] NVARCHAR(50) NULL,
+        [submitted_at] DATETIME2 NULL,
+        [reviewed_at] DATETIME2 NULL,
+        [approved_at] DATETIME2 NULL,
+        [effective_at] DATETIME2 NULL,
+        [obsoleted_at] DATETIME2 NULL,
+        [review_due_date] DATE NULL;
+
+    PRINT 'Document control columns added to ps_qms_documents'
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_ps_qms_documents_status' AND object_id = OBJECT_ID(N'[dbo].[ps_qms_documents]'))
+BEGIN
+    CREATE NONCLUSTERED INDEX [IX_ps_qms_documents_status] ON [dbo].[ps_qms_documents] ([status]);
+    CREATE NONCLUSTERED INDEX [IX_ps_qms_documents_document_no] ON [dbo].[ps_qms_documents] ([document_no]);
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[qms_document_approvals]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[qms_document_approvals] (
+        [qms_document_approval_id] INT PRIMARY KEY IDENTITY(1,1),
+        [qms_documents_id] INT NOT NULL,
+        [action] VARCHAR(20) NOT NULL,
+        [from_status] VARCHAR(20) NULL,
+        [to_status] VARCHAR(20) NULL,
+        [emp_code] NVARCHAR(50) NULL,
+        [comment] NVARCHAR(MAX) NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- Create index for the trail of one document
+    CREATE NONCLUSTERED INDEX [IX_qms_document_approvals_qms_documents_id] ON [dbo].[qms_document_approvals] ([qms_documents_id]);
+
+    PRINT 'Table qms_document_approvals created successfully'
+END
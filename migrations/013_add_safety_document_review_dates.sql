@@ -0,0 +1,39 @@
+-- Migration: Add review and expiry dates to ps_safetys_documents
+-- Description: Responsible owner, next review date and expiry date per safety document,
+--              plus safety_document_reminders so each reminder is e-mailed once per due date.
+
+IF COL_LENGTH('dbo.ps_safetys_documents', 'next_review_date') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[ps_safetys_documents] ADD
+        [owner_emp_code] NVARCHAR(50) NULL,
+        [next_review_date] DATE NULL,
+        [expiry_date] DATE NULL;
+
+    PRINT 'Review columns added to ps_safetys_documents'
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'IX_ps_safetys_documents_next_review_date' AND object_id = OBJECT_ID(N'[dbo].[ps_safetys_documents]'))
+BEGIN
+    CREATE NONCLUSTERED INDEX [IX_ps_safetys_documents_next_review_date] ON [dbo].[ps_safetys_documents] ([next_review_date]);
+    CREATE NONCLUSTERED INDEX [IX_ps_safetys_documents_expiry_date] ON [dbo].[ps_safetys_documents] ([expiry_date]);
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[safety_document_reminders]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[safety_document_reminders] (
+        [safety_document_reminder_id] INT PRIMARY KEY IDENTITY(1,1),
+        [safety_document_id] INT NOT NULL,
+        [kind] VARCHAR(20) NOT NULL,
+        [due_date] DATE NOT NULL,
+        [recipients] INT NOT NULL DEFAULT 0,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    -- One reminder of each kind per due date
+    CREATE UNIQUE NONCLUSTERED INDEX [ux_safety_document_reminder] ON [dbo].[safety_document_reminders] ([safety_document_id], [kind], [due_date]);
+
+    PRINT 'Table safety_document_reminders created successfully'
+END
+GO
@@ -0,0 +1,57 @@
+-- Migration: Create safety document acknowledgement tables
+-- Description: Read-and-acknowledge tracking. The safety team marks a document revision as
+--              requiring acknowledgement by departments, positions or everyone; employees
+--              confirm once per requirement.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[safety_document_ack_requirements]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[safety_document_ack_requirements] (
+        [safety_ack_requirement_id] INT PRIMARY KEY IDENTITY(1,1),
+        [safety_document_id] INT NOT NULL,
+        [revision_no] INT NOT NULL DEFAULT 0,
+        [due_date] DATE NULL,
+        [required_by] NVARCHAR(100) NULL,
+        [active] BIT NOT NULL DEFAULT 1,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_safety_document_ack_requirements_document] ON [dbo].[safety_document_ack_requirements] ([safety_document_id]);
+    CREATE NONCLUSTERED INDEX [IX_safety_document_ack_requirements_active] ON [dbo].[safety_document_ack_requirements] ([active]);
+
+    PRINT 'Table safety_document_ack_requirements created successfully'
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[safety_document_ack_targets]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[safety_document_ack_targets] (
+        [safety_ack_target_id] INT PRIMARY KEY IDENTITY(1,1),
+        [safety_ack_requirement_id] INT NOT NULL,
+        [target_type] VARCHAR(20) NOT NULL,
+        [target_value] NVARCHAR(255) NOT NULL
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_safety_document_ack_targets_requirement] ON [dbo].[safety_document_ack_targets] ([safety_ack_requirement_id]);
+
+    PRINT 'Table safety_document_ack_targets created successfully'
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[safety_document_acknowledgements]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[safety_document_acknowledgements] (
+        [safety_acknowledgement_id] INT PRIMARY KEY IDENTITY(1,1),
+        [safety_ack_requirement_id] INT NOT NULL,
+        [safety_document_id] INT NOT NULL,
+        [revision_no] INT NOT NULL DEFAULT 0,
+        [emp_code] NVARCHAR(50) NOT NULL,
+        [acknowledged_at] DATETIME2 NOT NULL
+    );
+
+    -- One acknowledgement per employee and requirement
+    CREATE UNIQUE NONCLUSTERED INDEX [ux_safety_acknowledgement] ON [dbo].[safety_document_acknowledgements] ([safety_ack_requirement_id], [emp_code]);
+    CREATE NONCLUSTERED INDEX [IX_safety_document_acknowledgements_document] ON [dbo].[safety_document_acknowledgements] ([safety_document_id]);
+
+    PRINT 'Table safety_document_acknowledgements created successfully'
+END
+GO
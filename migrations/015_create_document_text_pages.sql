@@ -0,0 +1,20 @@
+-- Migration: Create document_text_pages table
+-- Description: Text extracted from uploaded PDF/DOCX/XLSX files, one row per page (per sheet
+--              for XLSX), so module searches can match document content and show page snippets.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[document_text_pages]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[document_text_pages] (
+        [document_text_page_id] INT PRIMARY KEY IDENTITY(1,1),
+        [module] VARCHAR(30) NOT NULL,
+        [document_id] INT NOT NULL,
+        [page_no] INT NOT NULL,
+        [content] NVARCHAR(MAX) NOT NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETUTCDATE()
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_document_text_pages_doc] ON [dbo].[document_text_pages] ([module], [document_id]);
+
+    PRINT 'Table document_text_pages created successfully'
+END
+GO
@@ -0,0 +1,22 @@
+-- Migration: Create document_access_logs table
+-- Description: One row per view or download of a document module file (who, when, from where),
+--              used for per-document counts, most-viewed lists and the stale document report.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[document_access_logs]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[document_access_logs] (
+        [document_access_log_id] INT PRIMARY KEY IDENTITY(1,1),
+        [module] VARCHAR(30) NOT NULL,
+        [document_id] INT NOT NULL,
+        [action] VARCHAR(10) NOT NULL,
+        [emp_code] NVARCHAR(50) NULL,
+        [ip_address] VARCHAR(45) NULL,
+        [accessed_at] DATETIME2 NOT NULL DEFAULT GETDATE()
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_document_access_logs_doc] ON [dbo].[document_access_logs] ([module], [document_id], [accessed_at]);
+    CREATE NONCLUSTERED INDEX [IX_document_access_logs_emp_code] ON [dbo].[document_access_logs] ([emp_code]);
+
+    PRINT 'Table document_access_logs created successfully'
+END
+GO
@@ -0,0 +1,29 @@
+-- Migration: Create uploaded_files table
+-- Description: Registry of every file stored through the uploader with its malware scan verdict
+--              (clean, infected, error, skipped). Infected uploads are rejected or kept under
+--              uploads/quarantine/ for review, depending on UPLOAD_SCAN_ACTION.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[uploaded_files]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[uploaded_files] (
+        [uploaded_file_id] INT PRIMARY KEY IDENTITY(1,1),
+        [storage_key] NVARCHAR(500) NULL,
+        [original_name] NVARCHAR(255) NULL,
+        [mime_type] VARCHAR(150) NULL,
+        [file_size] BIGINT NULL,
+        [scan_status] VARCHAR(20) NOT NULL,
+        [scan_engine] VARCHAR(30) NULL,
+        [scan_signature] NVARCHAR(255) NULL,
+        [scan_error] NVARCHAR(500) NULL,
+        [scanned_at] DATETIME2 NULL,
+        [quarantined] BIT NOT NULL DEFAULT 0,
+        [uploaded_by] NVARCHAR(100) NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETDATE()
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_uploaded_files_storage_key] ON [dbo].[uploaded_files] ([storage_key]);
+    CREATE NONCLUSTERED INDEX [IX_uploaded_files_scan_status] ON [dbo].[uploaded_files] ([scan_status]);
+
+    PRINT 'Table uploaded_files created successfully'
+END
+GO
@@ -0,0 +1,34 @@
+-- Migration: Create file_blobs and file_links tables
+-- Description: Content-addressed upload storage. Each distinct content is stored once under its SHA-256
+--              (file_blobs, with a reference count); file_links maps the paths kept in the module tables
+--              (uploads/<folder>/<name>) to their blob.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[file_blobs]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[file_blobs] (
+        [sha256] CHAR(64) NOT NULL PRIMARY KEY,
+        [size] BIGINT NOT NULL,
+        [content_type] VARCHAR(150) NULL,
+        [ref_count] INT NOT NULL DEFAULT 0,
+        [verified_at] DATETIME2 NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETDATE(),
+        [updated_at] DATETIME2 NOT NULL DEFAULT GETDATE()
+    );
+
+    PRINT 'Table file_blobs created successfully'
+END
+GO
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[file_links]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[file_links] (
+        [storage_key] NVARCHAR(450) NOT NULL PRIMARY KEY,
+        [sha256] CHAR(64) NOT NULL,
+        [created_at] DATETIME2 NOT NULL DEFAULT GETDATE()
+    );
+
+    CREATE NONCLUSTERED INDEX [IX_file_links_sha256] ON [dbo].[file_links] ([sha256]);
+
+    PRINT 'Table file_links created successfully'
+END
+GO
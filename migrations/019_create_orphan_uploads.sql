@@ -0,0 +1,17 @@
+-- Migration: Create orphan_uploads table
+-- Description: Files under uploads/ that no record refers to, with the time the garbage collector first
+--              found them. They are deleted once they stay unreferenced for UPLOAD_GC_GRACE_DAYS.
+
+IF NOT EXISTS (SELECT * FROM sys.objects WHERE object_id = OBJECT_ID(N'[dbo].[orphan_uploads]') AND type in (N'U'))
+BEGIN
+    CREATE TABLE [dbo].[orphan_uploads] (
+        [storage_key] NVARCHAR(450) NOT NULL PRIMARY KEY,
+        [folder] NVARCHAR(100) NULL,
+        [file_size] BIGINT NULL,
+        [first_seen_at] DATETIME2 NOT NULL,
+        [last_seen_at] DATETIME2 NOT NULL
+    );
+
+    PRINT 'Table orphan_uploads created successfully'
+END
+GO
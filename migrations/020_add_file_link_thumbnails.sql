@@ -0,0 +1,21 @@
+-- Migration: Add thumbnails flag to file_links
+-- Description: Records when the previews of a stored file (<key>.thumb-<width>.jpg) have been generated,
+--              so document listings read the SHA-256 and preview state of a whole page in one query
+--              instead of looking up every file in storage. Existing links are flagged when all three
+--              preview sizes are linked.
+
+IF COL_LENGTH('dbo.file_links', 'thumbnails') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[file_links] ADD [thumbnails] BIT NOT NULL CONSTRAINT [DF_file_links_thumbnails] DEFAULT 0;
+
+    PRINT 'Column thumbnails added to file_links'
+END
+GO
+
+UPDATE l SET [thumbnails] = 1
+FROM [dbo].[file_links] l
+WHERE l.[thumbnails] = 0
+  AND EXISTS (SELECT 1 FROM [dbo].[file_links] t WHERE t.[storage_key] = l.[storage_key] + '.thumb-160.jpg')
+  AND EXISTS (SELECT 1 FROM [dbo].[file_links] t WHERE t.[storage_key] = l.[storage_key] + '.thumb-320.jpg')
+  AND EXISTS (SELECT 1 FROM [dbo].[file_links] t WHERE t.[storage_key] = l.[storage_key] + '.thumb-640.jpg');
+GO
@@ -0,0 +1,44 @@
+-- Migration: Add content_text column to company_news
+-- Description: News content without HTML, searched and highlighted instead of the rich text. The application
+--              fills it on every create and update; this fills it once for existing news the same way
+--              (tags replaced by spaces, common entities decoded, whitespace collapsed).
+
+IF COL_LENGTH('dbo.company_news', 'content_text') IS NULL
+BEGIN
+    ALTER TABLE [dbo].[company_news] ADD [content_text] NVARCHAR(MAX) NULL;
+
+    PRINT 'Column content_text added to company_news'
+END
+GO
+
+-- only news not filled yet; text the application wrote is left alone
+SELECT [company_news_id], REPLACE(REPLACE(REPLACE(ISNULL([content], ''), CHAR(13), ' '), CHAR(10), ' '), CHAR(9), ' ') AS [txt]
+INTO #news_text
+FROM [dbo].[company_news]
+WHERE [content_text] IS NULL;
+
+-- strip the first tag of each row and pass again until none is left
+WHILE EXISTS (SELECT 1 FROM #news_text WHERE CHARINDEX('<', [txt]) > 0 AND CHARINDEX('>', [txt], CHARINDEX('<', [txt])) > 0)
+BEGIN
+    UPDATE #news_text
+    SET [txt] = STUFF([txt], CHARINDEX('<', [txt]), CHARINDEX('>', [txt], CHARINDEX('<', [txt])) - CHARINDEX('<', [txt]) + 1, ' ')
+    WHERE CHARINDEX('<', [txt]) > 0 AND CHARINDEX('>', [txt], CHARINDEX('<', [txt])) > 0;
+END
+
+UPDATE #news_text
+SET [txt] = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE([txt],
+    '&nbsp;', ' '), '&lt;', '<'), '&gt;', '>'), '&quot;', '"'), '&#39;', ''''), '&amp;', '&');
+
+WHILE EXISTS (SELECT 1 FROM #news_text WHERE CHARINDEX('  ', [txt]) > 0)
+BEGIN
+    UPDATE #news_text SET [txt] = REPLACE([txt], '  ', ' ') WHERE CHARINDEX('  ', [txt]) > 0;
+END
+
+UPDATE n SET [content_text] = LTRIM(RTRIM(t.[txt]))
+FROM [dbo].[company_news] n
+JOIN #news_text t ON t.[company_news_id] = n.[company_news_id];
+
+DROP TABLE #news_text;
+
+PRINT 'content_text filled for existing company news'
+GO